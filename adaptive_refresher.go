@@ -15,7 +15,7 @@ type adaptiveRefresher struct {
 	adaptive  *spot.AdaptiveMinReports
 	cfg       config.AdaptiveRefreshByBandConfig
 	minutes   map[string]time.Duration
-	lastRun   time.Time
+	lastRun   atomic.Int64 // unix nanos of the last run, seeded by the first evaluation; 0 = not yet evaluated
 	spotCount int64
 	runFunc   func()
 	quit      chan struct{}
@@ -83,11 +83,12 @@ func (r *adaptiveRefresher) maybeRefresh(now time.Time) {
 	if !ok || interval <= 0 {
 		interval = time.Duration(r.cfg.NormalRefreshMinutes) * time.Minute
 	}
-	if r.lastRun.IsZero() {
-		r.lastRun = now
+	lastRun := r.lastRun.Load()
+	if lastRun == 0 {
+		r.lastRun.Store(now.UnixNano())
 		return
 	}
-	if now.Sub(r.lastRun) < interval {
+	if now.Sub(time.Unix(0, lastRun)) < interval {
 		return
 	}
 	if atomic.LoadInt64(&r.spotCount) < int64(r.cfg.MinSpotsSinceLastRefresh) {
@@ -95,10 +96,43 @@ func (r *adaptiveRefresher) maybeRefresh(now time.Time) {
 	}
 	// Run the task and reset counters.
 	r.runFunc()
-	r.lastRun = now
+	r.lastRun.Store(now.UnixNano())
 	atomic.StoreInt64(&r.spotCount, 0)
 }
 
+// HighestState returns the busiest adaptive state currently in effect
+// ("quiet", "normal", or "busy"), for /metrics.
+func (r *adaptiveRefresher) HighestState() string {
+	if r == nil {
+		return ""
+	}
+	return r.adaptive.HighestState()
+}
+
+// SpotsSinceLastRefresh returns the spot count accumulated since the last
+// refresh run, for /metrics.
+func (r *adaptiveRefresher) SpotsSinceLastRefresh() int64 {
+	if r == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&r.spotCount)
+}
+
+// LastRunAge returns how long it has been since the last refresh ran, for
+// /metrics. It returns 0 before the first evaluation; that evaluation seeds
+// the clock without running the refresh, so until the first real run the
+// age is measured from it.
+func (r *adaptiveRefresher) LastRunAge() time.Duration {
+	if r == nil {
+		return 0
+	}
+	lastRun := r.lastRun.Load()
+	if lastRun == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, lastRun))
+}
+
 func noopRefresh() {
 	// Placeholder for trust/quality refresh; kept separate to allow easy swapping later.
 }
@@ -42,6 +42,35 @@ type ringPane struct {
 	count int
 }
 
+// uiSurface is the contract the spot pipeline renders through: publish a
+// stats header and append lines to one of the fixed panes, without knowing
+// whether the active backend is this fixed-height ansiConsole or the
+// full-screen tuiConsole (ui.mode=tui).
+type uiSurface interface {
+	WaitReady()
+	Stop()
+	SetStats(lines []string)
+	AppendCall(line string)
+	AppendUnlicensed(line string)
+	AppendHarmonic(line string)
+	AppendSystem(line string)
+	SystemWriter() io.Writer
+}
+
+// newUISurface selects a rendering backend by uiCfg.Mode. Anything other
+// than "tui" keeps today's default of ansiConsole, and the TUI backend is
+// only tried when allowRender is true (i.e. we are attached to a TTY), since
+// a full-screen app has nothing to draw to otherwise. When allowRender is
+// false the result is newANSIConsole's, which is nil.
+func newUISurface(uiCfg config.UIConfig, allowRender bool) uiSurface {
+	if uiCfg.Mode == "tui" && allowRender {
+		if c := newTUIConsole(uiCfg); c != nil {
+			return c
+		}
+	}
+	return newANSIConsole(uiCfg, allowRender)
+}
+
 func newANSIConsole(uiCfg config.UIConfig, allowRender bool) uiSurface {
 	if !allowRender {
 		return nil
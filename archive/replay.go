@@ -0,0 +1,61 @@
+package archive
+
+import (
+	"log"
+	"time"
+
+	"dxcluster/spot"
+)
+
+// replayBufferSize is the channel capacity Replay's output is given, large
+// enough that a burst of closely-spaced historical spots doesn't stall on a
+// slow consumer the way the live ingest path would.
+const replayBufferSize = 256
+
+// Purpose: Re-emit archived spots from [from, to) on a channel, paced to
+// look like the original feed, so a mode parser or skew algorithm can be
+// exercised against historical data without hitting live RBN.
+// Key aspects: speed scales the gap between consecutive spots' Time fields
+// (1.0 reproduces the original pacing, 2.0 replays twice as fast, 0 or
+// negative emits as fast as the consumer can keep up with - no sleeping).
+// The first spot is emitted immediately regardless of speed. The returned
+// channel is closed once every spot in range has been sent or the archive
+// is closed mid-replay.
+// Upstream: Test harnesses exercising parsers/skew algorithms against
+// historical data.
+// Downstream: Range.
+func (w *Writer) Replay(from, to time.Time, speed float64) <-chan *spot.Spot {
+	out := make(chan *spot.Spot, replayBufferSize)
+
+	spots, err := w.Range(from, to)
+	if err != nil {
+		log.Printf("archive: replay range query failed: %v", err)
+		close(out)
+		return out
+	}
+
+	go func() {
+		defer close(out)
+		var prev time.Time
+		for i, s := range spots {
+			if i > 0 && speed > 0 {
+				gap := s.Time.Sub(prev)
+				if gap > 0 {
+					select {
+					case <-time.After(time.Duration(float64(gap) / speed)):
+					case <-w.stop:
+						return
+					}
+				}
+			}
+			prev = s.Time
+			select {
+			case out <- s:
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,499 @@
+package archive
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"dxcluster/spot"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/parquet-go/parquet-go"
+)
+
+// coldPartition describes one sealed, on-disk export of a day's worth of
+// spots that has been rolled out of the live SQLite table.
+type coldPartition struct {
+	From time.Time
+	To   time.Time
+	Path string
+}
+
+// coldRow is the on-disk representation of one spot within a cold
+// partition file. It mirrors the columns scanSpotRows reconstructs from
+// SQLite so sealing and reading stay symmetric. The parquet tags are only
+// consulted by the "parquet" ColdFormat; the ndjson.zst format uses the
+// json tags via encoding/json instead.
+type coldRow struct {
+	TS         int64   `json:"ts" parquet:"ts"`
+	DX         string  `json:"dx" parquet:"dx"`
+	DE         string  `json:"de" parquet:"de"`
+	Freq       float64 `json:"freq" parquet:"freq"`
+	Mode       string  `json:"mode" parquet:"mode"`
+	Report     int     `json:"report" parquet:"report"`
+	HasReport  bool    `json:"has_report" parquet:"has_report"`
+	Comment    string  `json:"comment" parquet:"comment"`
+	Source     string  `json:"source" parquet:"source"`
+	SourceNode string  `json:"source_node" parquet:"source_node"`
+	TTL        int     `json:"ttl" parquet:"ttl"`
+	IsBeacon   bool    `json:"is_beacon" parquet:"is_beacon"`
+	DXGrid     string  `json:"dx_grid" parquet:"dx_grid"`
+	DEGrid     string  `json:"de_grid" parquet:"de_grid"`
+	Confidence string  `json:"confidence" parquet:"confidence"`
+	Band       string  `json:"band" parquet:"band"`
+}
+
+// toSpot reconstructs a spot.Spot from a coldRow, applying the same
+// normalization scanSpotRows applies to live rows.
+func (r coldRow) toSpot() *spot.Spot {
+	s := &spot.Spot{
+		DXCall:     r.DX,
+		DECall:     r.DE,
+		Frequency:  r.Freq,
+		Mode:       r.Mode,
+		Report:     r.Report,
+		Time:       time.Unix(r.TS, 0).UTC(),
+		Comment:    r.Comment,
+		SourceType: spot.SourceType(r.Source),
+		SourceNode: r.SourceNode,
+		TTL:        uint8(clampToByte(r.TTL)),
+		IsBeacon:   r.IsBeacon,
+		HasReport:  r.HasReport,
+		Confidence: r.Confidence,
+		Band:       r.Band,
+	}
+	s.DXMetadata.Grid = r.DXGrid
+	s.DEMetadata.Grid = r.DEGrid
+	s.EnsureNormalized()
+	s.RefreshBeaconFlag()
+	return s
+}
+
+func coldRowFromSpot(s *spot.Spot) coldRow {
+	return coldRow{
+		TS:         s.Time.UTC().Unix(),
+		DX:         s.DXCall,
+		DE:         s.DECall,
+		Freq:       s.Frequency,
+		Mode:       s.Mode,
+		Report:     s.Report,
+		HasReport:  s.HasReport,
+		Comment:    s.Comment,
+		Source:     string(s.SourceType),
+		SourceNode: s.SourceNode,
+		TTL:        int(s.TTL),
+		IsBeacon:   s.IsBeacon,
+		DXGrid:     s.DXMetadata.Grid,
+		DEGrid:     s.DEMetadata.Grid,
+		Confidence: s.Confidence,
+		Band:       s.Band,
+	}
+}
+
+// Purpose: Periodically seal day-old live rows into cold partition files
+// and prune partitions past their retention window.
+// Key aspects: Runs on a fixed interval derived from cfg.PartitionIntervalHours;
+// exits on stop signal like the other background loops.
+// Upstream: Start.
+// Downstream: rollOnce, pruneColdPartitions.
+func (w *Writer) rollerLoop() {
+	interval := time.Duration(w.cfg.PartitionIntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.rollOnce(); err != nil {
+				log.Printf("archive: roll partitions: %v", err)
+			}
+			if err := w.pruneColdPartitions(); err != nil {
+				log.Printf("archive: prune cold partitions: %v", err)
+			}
+		}
+	}
+}
+
+// Purpose: Seal every fully-elapsed UTC day still sitting in the live
+// table into its own cold partition file, then delete those rows.
+// Key aspects: Walks day-by-day from the oldest live row up to (but not
+// including) the start of today, so a partial "today" is never sealed.
+// Space is reclaimed once after the whole backlog of days is sealed,
+// rather than per day, so catching up after a long gap doesn't VACUUM
+// the live DB once per backlogged day.
+// Upstream: rollerLoop.
+// Downstream: sealDay, reclaimSpace.
+func (w *Writer) rollOnce() error {
+	var oldest sql.NullInt64
+	if err := w.db.QueryRow(`select min(ts) from spots`).Scan(&oldest); err != nil {
+		return fmt.Errorf("archive: min ts: %w", err)
+	}
+	if !oldest.Valid {
+		return nil
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	day := time.Unix(oldest.Int64, 0).UTC().Truncate(24 * time.Hour)
+	sealed := false
+	for day.Before(today) {
+		next := day.Add(24 * time.Hour)
+		didSeal, err := w.sealDay(day, next)
+		if err != nil {
+			return err
+		}
+		sealed = sealed || didSeal
+		day = next
+	}
+	if sealed {
+		w.reclaimSpace()
+	}
+	return nil
+}
+
+// Purpose: Export one UTC day's rows to a cold partition file, then
+// delete them from the live table. Reports whether rows were actually
+// sealed so rollOnce knows whether reclaiming space afterwards is worth it.
+// Key aspects: No-op (doesn't create an empty file) when the day has no
+// rows; deletion only runs after the export succeeds, so a write failure
+// never loses data. The read, the partition write, and the delete all run
+// under sealMu's write lock - not just the write+delete - so a spot that
+// lands (via flush, under sealMu.RLock) in this day's window between the
+// read and the delete can't be captured by the delete without also having
+// been exported. The same lock means a concurrent Recent/Range read can't
+// land in the middle of a successful seal and double-count that day's
+// spots. If the delete itself fails after the partition was already
+// written, sealDay returns an error and the live rows stay in place
+// alongside the new partition file - that day will double-count under
+// Recent/Range until the next rollerLoop tick retries and succeeds.
+// Upstream: rollOnce.
+// Downstream: writeColdPartition.
+func (w *Writer) sealDay(from, to time.Time) (bool, error) {
+	w.sealMu.Lock()
+	defer w.sealMu.Unlock()
+
+	rows, err := w.db.Query(spotSelectColumns+` from spots where ts >= ? and ts < ? order by ts`, from.Unix(), to.Unix())
+	if err != nil {
+		return false, fmt.Errorf("archive: query day: %w", err)
+	}
+	spots, err := scanSpotRows(rows, "seal")
+	if err != nil {
+		return false, err
+	}
+	if len(spots) == 0 {
+		return false, nil
+	}
+
+	format := w.cfg.ColdFormat
+	if format == "" {
+		format = "ndjson.zst"
+	}
+	path := coldPartitionPath(w.cfg.DBPath, from, to, format)
+
+	if err := writeColdPartition(path, format, spots); err != nil {
+		return false, err
+	}
+	if _, err := w.db.Exec(`delete from spots where ts >= ? and ts < ?`, from.Unix(), to.Unix()); err != nil {
+		return false, fmt.Errorf("archive: delete sealed rows: %w", err)
+	}
+	return true, nil
+}
+
+// reclaimSpace checkpoints the WAL and VACUUMs the live DB file so the
+// space freed by sealDay's deletes is actually returned to the filesystem
+// instead of sitting unreclaimed inside the SQLite file. Called by rollOnce
+// once after its whole backlog of days is sealed, under sealMu's write
+// lock, so it can't collide with a concurrent flush() or Recent/Range read.
+// Failures here are logged, not returned: the seals themselves (partitions
+// written, rows deleted) already succeeded, so a checkpoint/VACUUM hiccup
+// shouldn't make rollOnce retry days that have nothing left to retry.
+func (w *Writer) reclaimSpace() {
+	w.sealMu.Lock()
+	defer w.sealMu.Unlock()
+	if _, err := w.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		log.Printf("archive: wal checkpoint after seal: %v", err)
+		return
+	}
+	if _, err := w.db.Exec(`VACUUM`); err != nil {
+		log.Printf("archive: vacuum after seal: %v", err)
+	}
+}
+
+// coldPartitionPath builds the on-disk name for a sealed partition,
+// alongside dbPath so all of one writer's data stays in one directory.
+// Pattern: <dbPath-without-ext>.<fromUnix>-<toUnix>.<format>
+func coldPartitionPath(dbPath string, from, to time.Time, format string) string {
+	base := strings.TrimSuffix(dbPath, filepath.Ext(dbPath))
+	return fmt.Sprintf("%s.%d-%d.%s", base, from.Unix(), to.Unix(), format)
+}
+
+// Purpose: Write a sealed partition file atomically.
+// Key aspects: Writes to a .tmp sibling and renames into place so a crash
+// mid-write never leaves a corrupt partition visible to listColdPartitions.
+// Upstream: sealDay.
+// Downstream: writeZstdNDJSON.
+func writeColdPartition(path, format string, spots []*spot.Spot) error {
+	switch format {
+	case "ndjson.zst":
+		return writeZstdNDJSON(path, spots)
+	case "parquet":
+		return writeParquetPartition(path, spots)
+	default:
+		return fmt.Errorf("archive: unknown cold format %q", format)
+	}
+}
+
+// Purpose: Write a sealed partition file as Parquet instead of ndjson.zst.
+// Key aspects: Writes spots in parquetRowGroupSize batches rather than one
+// write call per row, so sealing a busy day doesn't hold every row's
+// encoded form in memory at once; same atomic .tmp-then-rename as
+// writeZstdNDJSON. Columnar Parquet is what DuckDB/pandas-based offline
+// analysis expects to read directly, without a JSON-decoding pass first.
+// Upstream: writeColdPartition.
+// Downstream: parquet.GenericWriter.
+func writeParquetPartition(path string, spots []*spot.Spot) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("archive: create partition: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	pw := parquet.NewGenericWriter[coldRow](f)
+	for i := 0; i < len(spots); i += parquetRowGroupSize {
+		end := i + parquetRowGroupSize
+		if end > len(spots) {
+			end = len(spots)
+		}
+		rows := make([]coldRow, 0, end-i)
+		for _, s := range spots[i:end] {
+			rows = append(rows, coldRowFromSpot(s))
+		}
+		if _, err := pw.Write(rows); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("archive: write parquet rows: %w", err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("archive: close parquet writer: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("archive: close partition file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// parquetRowGroupSize bounds how many rows writeParquetPartition buffers
+// before handing a batch to the Parquet writer.
+const parquetRowGroupSize = 5000
+
+// Purpose: Read the spots within [from, to) out of a Parquet-format cold
+// partition file.
+// Key aspects: Mirror image of writeParquetPartition; reads row groups back
+// in parquetRowGroupSize batches and filters each row against the
+// requested window.
+// Upstream: readColdPartition.
+// Downstream: parquet.GenericReader.
+func readParquetPartition(p coldPartition, from, to time.Time) ([]*spot.Spot, error) {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("archive: open partition: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("archive: stat partition: %w", err)
+	}
+
+	pr := parquet.NewGenericReader[coldRow](f, info.Size())
+	defer pr.Close()
+
+	var results []*spot.Spot
+	rows := make([]coldRow, parquetRowGroupSize)
+	for {
+		n, err := pr.Read(rows)
+		for _, row := range rows[:n] {
+			ts := time.Unix(row.TS, 0).UTC()
+			if ts.Before(from) || !ts.Before(to) {
+				continue
+			}
+			results = append(results, row.toSpot())
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archive: read parquet rows: %w", err)
+		}
+	}
+	return results, nil
+}
+
+func writeZstdNDJSON(path string, spots []*spot.Spot) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("archive: create partition: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	zw, err := zstd.NewWriter(f)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("archive: zstd writer: %w", err)
+	}
+	bw := bufio.NewWriter(zw)
+	enc := json.NewEncoder(bw)
+	for _, s := range spots {
+		if err := enc.Encode(coldRowFromSpot(s)); err != nil {
+			_ = zw.Close()
+			_ = f.Close()
+			return fmt.Errorf("archive: encode row: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		_ = zw.Close()
+		_ = f.Close()
+		return fmt.Errorf("archive: flush: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("archive: close zstd writer: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("archive: close partition file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Purpose: Discover sealed cold partitions for a given archive DB.
+// Key aspects: Parses the fromUnix-toUnix window out of each matching
+// filename rather than reopening files just to learn their range.
+// Upstream: Recent, Range, pruneColdPartitions.
+// Downstream: filepath.Glob.
+func listColdPartitions(dbPath string) ([]coldPartition, error) {
+	base := strings.TrimSuffix(dbPath, filepath.Ext(dbPath))
+	matches, err := filepath.Glob(base + ".*-*.*")
+	if err != nil {
+		return nil, fmt.Errorf("archive: glob partitions: %w", err)
+	}
+	var partitions []coldPartition
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".tmp") {
+			continue
+		}
+		name := strings.TrimPrefix(m, base+".")
+		window := name
+		if idx := strings.Index(window, "."); idx >= 0 {
+			window = window[:idx]
+		}
+		parts := strings.SplitN(window, "-", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fromUnix, err1 := strconv.ParseInt(parts[0], 10, 64)
+		toUnix, err2 := strconv.ParseInt(parts[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		partitions = append(partitions, coldPartition{
+			From: time.Unix(fromUnix, 0).UTC(),
+			To:   time.Unix(toUnix, 0).UTC(),
+			Path: m,
+		})
+	}
+	return partitions, nil
+}
+
+// Purpose: Read the spots within [from, to) out of one sealed partition,
+// regardless of which ColdFormat sealed it.
+// Key aspects: Dispatches on the partition file's extension so Recent/Range
+// keep working transparently across a mix of ndjson.zst and parquet
+// partitions (e.g. after a ColdFormat change).
+// Upstream: Recent, Range.
+// Downstream: readZstdNDJSONPartition, readParquetPartition.
+func readColdPartition(p coldPartition, from, to time.Time) ([]*spot.Spot, error) {
+	if strings.HasSuffix(p.Path, ".parquet") {
+		return readParquetPartition(p, from, to)
+	}
+	return readZstdNDJSONPartition(p, from, to)
+}
+
+// readZstdNDJSONPartition decompresses and scans NDJSON line-by-line;
+// filters each row against the requested window so callers can read a
+// sub-range of a partition without pulling in neighboring days.
+func readZstdNDJSONPartition(p coldPartition, from, to time.Time) ([]*spot.Spot, error) {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("archive: open partition: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("archive: zstd reader: %w", err)
+	}
+	defer zr.Close()
+
+	var results []*spot.Spot
+	scanner := bufio.NewScanner(zr.IOReadCloser())
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var row coldRow
+		if err := json.Unmarshal(line, &row); err != nil {
+			return nil, fmt.Errorf("archive: decode partition row: %w", err)
+		}
+		ts := time.Unix(row.TS, 0).UTC()
+		if ts.Before(from) || !ts.Before(to) {
+			continue
+		}
+		results = append(results, row.toSpot())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("archive: scan partition: %w", err)
+	}
+	return results, nil
+}
+
+// Purpose: Delete cold partition files past cfg.ColdRetentionDays.
+// Key aspects: Purely file-based; never touches the live table.
+// Upstream: rollerLoop.
+// Downstream: os.Remove.
+func (w *Writer) pruneColdPartitions() error {
+	if w.cfg.ColdRetentionDays <= 0 {
+		return nil
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -w.cfg.ColdRetentionDays)
+
+	partitions, err := listColdPartitions(w.cfg.DBPath)
+	if err != nil {
+		return err
+	}
+	for _, p := range partitions {
+		if p.To.After(cutoff) {
+			continue
+		}
+		if err := os.Remove(p.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("archive: remove %s: %w", p.Path, err)
+		}
+	}
+	return nil
+}
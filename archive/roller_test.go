@@ -0,0 +1,205 @@
+package archive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dxcluster/config"
+	"dxcluster/spot"
+)
+
+func newTestWriter(t *testing.T) *Writer {
+	t.Helper()
+	dir := t.TempDir()
+	w, err := NewWriter(config.ArchiveConfig{
+		DBPath:        filepath.Join(dir, "spots.db"),
+		BusyTimeoutMS: 1000,
+		QueueSize:     100,
+	})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+func insertSpot(t *testing.T, w *Writer, call string, ts time.Time) {
+	t.Helper()
+	w.flush([]*spot.Spot{{
+		DXCall: call,
+		DECall: "DE" + call,
+		Time:   ts,
+		Mode:   "CW",
+		Band:   "20m",
+	}})
+}
+
+func TestSealDayWritesPartitionAndDeletesLiveRows(t *testing.T) {
+	w := newTestWriter(t)
+
+	yesterday := time.Now().UTC().Add(-24 * time.Hour).Truncate(24 * time.Hour)
+	from := yesterday
+	to := yesterday.Add(24 * time.Hour)
+	insertSpot(t, w, "K1ABC", from.Add(time.Hour))
+	insertSpot(t, w, "W1AW", from.Add(2*time.Hour))
+
+	sealed, err := w.sealDay(from, to)
+	if err != nil {
+		t.Fatalf("sealDay: %v", err)
+	}
+	if !sealed {
+		t.Fatalf("expected sealDay to report the day as sealed")
+	}
+
+	var remaining int
+	if err := w.db.QueryRow(`select count(*) from spots where ts >= ? and ts < ?`, from.Unix(), to.Unix()).Scan(&remaining); err != nil {
+		t.Fatalf("count live rows: %v", err)
+	}
+	if remaining != 0 {
+		t.Fatalf("expected sealed day's live rows to be deleted, %d remain", remaining)
+	}
+
+	partitions, err := listColdPartitions(w.cfg.DBPath)
+	if err != nil {
+		t.Fatalf("listColdPartitions: %v", err)
+	}
+	if len(partitions) != 1 {
+		t.Fatalf("expected 1 cold partition, got %d", len(partitions))
+	}
+
+	cold, err := readColdPartition(partitions[0], from, to)
+	if err != nil {
+		t.Fatalf("readColdPartition: %v", err)
+	}
+	if len(cold) != 2 {
+		t.Fatalf("expected 2 spots in cold partition, got %d", len(cold))
+	}
+}
+
+func TestSealDayNoopOnEmptyDay(t *testing.T) {
+	w := newTestWriter(t)
+	from := time.Now().UTC().Add(-24 * time.Hour).Truncate(24 * time.Hour)
+	to := from.Add(24 * time.Hour)
+
+	sealed, err := w.sealDay(from, to)
+	if err != nil {
+		t.Fatalf("sealDay on empty day: %v", err)
+	}
+	if sealed {
+		t.Fatalf("expected sealDay to report an empty day as not sealed")
+	}
+	partitions, err := listColdPartitions(w.cfg.DBPath)
+	if err != nil {
+		t.Fatalf("listColdPartitions: %v", err)
+	}
+	if len(partitions) != 0 {
+		t.Fatalf("expected no partition file for an empty day, got %d", len(partitions))
+	}
+}
+
+func TestRecentUnionsLiveAndColdRows(t *testing.T) {
+	w := newTestWriter(t)
+
+	yesterday := time.Now().UTC().Add(-24 * time.Hour).Truncate(24 * time.Hour)
+	from := yesterday
+	to := yesterday.Add(24 * time.Hour)
+	insertSpot(t, w, "K1ABC", from.Add(time.Hour))
+	insertSpot(t, w, "W1AW", from.Add(2*time.Hour))
+	if _, err := w.sealDay(from, to); err != nil {
+		t.Fatalf("sealDay: %v", err)
+	}
+
+	insertSpot(t, w, "N2WQ", time.Now().UTC())
+
+	results, err := w.Recent(10)
+	if err != nil {
+		t.Fatalf("Recent: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 spots across live + cold, got %d", len(results))
+	}
+}
+
+func TestRangeUnionsLiveAndColdRowsWithoutDuplicates(t *testing.T) {
+	w := newTestWriter(t)
+
+	yesterday := time.Now().UTC().Add(-24 * time.Hour).Truncate(24 * time.Hour)
+	from := yesterday
+	to := yesterday.Add(24 * time.Hour)
+	insertSpot(t, w, "K1ABC", from.Add(time.Hour))
+	insertSpot(t, w, "W1AW", from.Add(2*time.Hour))
+	if _, err := w.sealDay(from, to); err != nil {
+		t.Fatalf("sealDay: %v", err)
+	}
+
+	results, err := w.Range(from, to)
+	if err != nil {
+		t.Fatalf("Range: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected exactly 2 spots (no live/cold double-count), got %d", len(results))
+	}
+}
+
+func TestPruneColdPartitionsRemovesOnlyPastRetention(t *testing.T) {
+	w := newTestWriter(t)
+	w.cfg.ColdRetentionDays = 7
+
+	old := time.Now().UTC().Add(-30 * 24 * time.Hour).Truncate(24 * time.Hour)
+	recent := time.Now().UTC().Add(-2 * 24 * time.Hour).Truncate(24 * time.Hour)
+
+	insertSpot(t, w, "K1ABC", old.Add(time.Hour))
+	if _, err := w.sealDay(old, old.Add(24*time.Hour)); err != nil {
+		t.Fatalf("sealDay old: %v", err)
+	}
+	insertSpot(t, w, "W1AW", recent.Add(time.Hour))
+	if _, err := w.sealDay(recent, recent.Add(24*time.Hour)); err != nil {
+		t.Fatalf("sealDay recent: %v", err)
+	}
+
+	if err := w.pruneColdPartitions(); err != nil {
+		t.Fatalf("pruneColdPartitions: %v", err)
+	}
+
+	partitions, err := listColdPartitions(w.cfg.DBPath)
+	if err != nil {
+		t.Fatalf("listColdPartitions: %v", err)
+	}
+	if len(partitions) != 1 {
+		t.Fatalf("expected 1 surviving partition past retention, got %d", len(partitions))
+	}
+	if !partitions[0].From.Equal(recent) {
+		t.Fatalf("expected the recent partition to survive, got %v", partitions[0].From)
+	}
+}
+
+func TestRollOnceSealsEveryBackloggedDay(t *testing.T) {
+	w := newTestWriter(t)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	dayBefore := today.Add(-2 * 24 * time.Hour)
+	insertSpot(t, w, "K1ABC", dayBefore.Add(time.Hour))
+	insertSpot(t, w, "W1AW", dayBefore.Add(24*time.Hour).Add(time.Hour))
+	insertSpot(t, w, "N2WQ", today.Add(time.Hour))
+
+	if err := w.rollOnce(); err != nil {
+		t.Fatalf("rollOnce: %v", err)
+	}
+
+	partitions, err := listColdPartitions(w.cfg.DBPath)
+	if err != nil {
+		t.Fatalf("listColdPartitions: %v", err)
+	}
+	if len(partitions) != 2 {
+		t.Fatalf("expected the 2 elapsed backlogged days to be sealed, got %d partitions", len(partitions))
+	}
+
+	var remaining int
+	if err := w.db.QueryRow(`select count(*) from spots`).Scan(&remaining); err != nil {
+		t.Fatalf("count live rows: %v", err)
+	}
+	if remaining != 1 {
+		t.Fatalf("expected only today's un-elapsed row to remain live, got %d", remaining)
+	}
+}
@@ -0,0 +1,89 @@
+// Package backoff holds reconnect-delay strategies shared by anything that
+// retries a flaky connection: jittered delays so many independent retriers
+// don't end up synchronized, plus the historical plain-doubling behavior for
+// callers that haven't opted into jitter.
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Policy computes successive backoff durations for a reconnect loop. A
+// Policy is stateless with respect to storage; any carried state (e.g. the
+// previous delay for decorrelated jitter) is threaded through the arguments
+// and return value so a single Policy value can be shared across callers.
+type Policy interface {
+	// Next returns the delay to use for this attempt given the base/max
+	// bounds, the previous delay returned by this policy (or base on the
+	// first call), and a source of randomness. It also returns the value
+	// that should be passed back in as prev on the following call.
+	Next(base, max, prev time.Duration, rng *rand.Rand) (delay, nextPrev time.Duration)
+}
+
+// PolicyExponential is the historical naked-doubling strategy: the delay
+// doubles every attempt up to max, with no jitter. It is prone to
+// synchronised reconnect storms when many callers retry in lockstep.
+type PolicyExponential struct{}
+
+func (PolicyExponential) Next(base, max, prev time.Duration, rng *rand.Rand) (time.Duration, time.Duration) {
+	if prev <= 0 {
+		prev = base
+	}
+	d := prev
+	if d >= max {
+		return max, max
+	}
+	next := d * 2
+	if next > max {
+		next = max
+	}
+	return d, next
+}
+
+// PolicyFullJitter spreads retries uniformly over [0, min(max, base<<attempt)),
+// tracking the attempt implicitly via the carried prev/next ceiling.
+type PolicyFullJitter struct{}
+
+func (PolicyFullJitter) Next(base, max, prev time.Duration, rng *rand.Rand) (time.Duration, time.Duration) {
+	ceil := prev
+	if ceil <= 0 {
+		ceil = base
+	}
+	delay := randInt63n(rng, ceil)
+	next := ceil * 2
+	if next > max || next <= 0 {
+		next = max
+	}
+	return delay, next
+}
+
+// PolicyDecorrelatedJitter implements the AWS-style decorrelated jitter
+// strategy: Next() = min(max, rand(base, 3*prev)), which tends to space out
+// synchronised retries better than full jitter while still growing the
+// delay over time.
+type PolicyDecorrelatedJitter struct{}
+
+func (PolicyDecorrelatedJitter) Next(base, max, prev time.Duration, rng *rand.Rand) (time.Duration, time.Duration) {
+	if prev <= 0 {
+		prev = base
+	}
+	span := 3*prev - base
+	delay := base + randInt63n(rng, span)
+	if delay > max {
+		delay = max
+	}
+	return delay, delay
+}
+
+// randInt63n returns a random duration in [0, n), tolerating n <= 0 by
+// returning 0 rather than panicking like rand.Int63n.
+func randInt63n(rng *rand.Rand, n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	if rng != nil {
+		return time.Duration(rng.Int63n(int64(n)))
+	}
+	return time.Duration(rand.Int63n(int64(n)))
+}
@@ -0,0 +1,111 @@
+package calibration
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// bootstrapSamples is how many times a config's recall and temporal
+// stability are recomputed on a resampled decision set to estimate a
+// confidence interval.
+const bootstrapSamples = 1000
+
+// defaultSeed is used when the caller passes seed 0, keeping repeated
+// sweeps reproducible without forcing every caller to pick one.
+const defaultSeed = 1
+
+// ConfidenceInterval is a two-sided bootstrap interval: Lower is the 2.5th
+// percentile and Upper is the 97.5th percentile of the resampled statistic.
+type ConfidenceInterval struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Overlaps reports whether ci and other share any value in common. Two
+// configs whose intervals overlap are statistically indistinguishable on
+// that axis and shouldn't be ranked against each other by point estimate
+// alone.
+func (ci ConfidenceInterval) Overlaps(other ConfidenceInterval) bool {
+	return ci.Lower <= other.Upper && other.Lower <= ci.Upper
+}
+
+// String renders the interval in percentage points, e.g. "[93.1, 96.8]".
+func (ci ConfidenceInterval) String() string {
+	return fmt.Sprintf("[%.1f, %.1f]", ci.Lower*100, ci.Upper*100)
+}
+
+// bootstrap resamples decisions with replacement bootstrapSamples times,
+// recomputing recall and per-distance temporal stability under cfg on each
+// resample, and returns the 2.5/97.5 percentile interval for each. seed
+// makes the resampling reproducible; the same (decisions, cfg, numWindows,
+// seed) always yields the same intervals.
+func bootstrap(decisions []DecisionRecord, cfg Config, numWindows int, seed int64) (recallCI ConfidenceInterval, stabilityCI map[int]ConfidenceInterval) {
+	stabilityCI = map[int]ConfidenceInterval{1: {}, 2: {}, 3: {}}
+	if len(decisions) == 0 {
+		return ConfidenceInterval{}, stabilityCI
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	recalls := make([]float64, bootstrapSamples)
+	stabilities := map[int][]float64{
+		1: make([]float64, bootstrapSamples),
+		2: make([]float64, bootstrapSamples),
+		3: make([]float64, bootstrapSamples),
+	}
+
+	resample := make([]DecisionRecord, len(decisions))
+	for i := 0; i < bootstrapSamples; i++ {
+		for j := range resample {
+			resample[j] = decisions[rng.Intn(len(decisions))]
+		}
+
+		applied := 0
+		for _, d := range resample {
+			if cfg.Accepts(d) {
+				applied++
+			}
+		}
+		recalls[i] = float64(applied) / float64(len(resample))
+
+		stability := TemporalStability(resample, cfg, numWindows)
+		for distance := 1; distance <= 3; distance++ {
+			stabilities[distance][i] = stability[distance]
+		}
+	}
+
+	recallCI = percentileInterval(recalls)
+	for distance := 1; distance <= 3; distance++ {
+		stabilityCI[distance] = percentileInterval(stabilities[distance])
+	}
+	return recallCI, stabilityCI
+}
+
+// percentileInterval returns the 2.5/97.5 percentile interval of values.
+func percentileInterval(values []float64) ConfidenceInterval {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return ConfidenceInterval{
+		Lower: percentile(sorted, 0.025),
+		Upper: percentile(sorted, 0.975),
+	}
+}
+
+// percentile returns the linearly-interpolated value at p (0-1) in an
+// already-sorted slice.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	idx := p * float64(len(sorted)-1)
+	lo := int(idx)
+	hi := lo + 1
+	if hi >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := idx - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[hi]*frac
+}
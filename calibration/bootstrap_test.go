@@ -0,0 +1,62 @@
+package calibration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBootstrapIsReproducibleWithSameSeed(t *testing.T) {
+	decisions := []DecisionRecord{
+		record(1, 3, 1, 90, "K1ABC", "W1AW", true, time.Unix(0, 0)),
+		record(1, 2, 1, 55, "K2XYZ", "W2XYZ", false, time.Unix(3600, 0)),
+		record(1, 3, 2, 90, "K3ABC", "W3AW", true, time.Unix(7200, 0)),
+	}
+	cfg := Config{MinConsensusReports: 2, MinConfidencePercent: 55}
+
+	recallCI1, stabilityCI1 := bootstrap(decisions, cfg, 2, 42)
+	recallCI2, stabilityCI2 := bootstrap(decisions, cfg, 2, 42)
+	if recallCI1 != recallCI2 {
+		t.Fatalf("expected the same seed to produce identical recall CIs, got %+v vs %+v", recallCI1, recallCI2)
+	}
+	if stabilityCI1[1] != stabilityCI2[1] {
+		t.Fatalf("expected the same seed to produce identical stability CIs, got %+v vs %+v", stabilityCI1[1], stabilityCI2[1])
+	}
+}
+
+func TestBootstrapIntervalBracketsThePointEstimate(t *testing.T) {
+	decisions := []DecisionRecord{
+		record(1, 3, 1, 90, "K1ABC", "W1AW", true, time.Unix(0, 0)),
+		record(1, 2, 1, 55, "K2XYZ", "W2XYZ", false, time.Unix(3600, 0)),
+		record(1, 3, 2, 90, "K3ABC", "W3AW", true, time.Unix(7200, 0)),
+		record(1, 1, 1, 40, "K4ABC", "W4AW", false, time.Unix(10800, 0)),
+	}
+	cfg := Config{MinConsensusReports: 2, MinConfidencePercent: 55}
+
+	recallCI, _ := bootstrap(decisions, cfg, 2, 7)
+	if recallCI.Lower > recallCI.Upper {
+		t.Fatalf("expected a well-formed interval, got %+v", recallCI)
+	}
+	if recallCI.Lower < 0 || recallCI.Upper > 1 {
+		t.Fatalf("expected recall CI bounds within [0, 1], got %+v", recallCI)
+	}
+}
+
+func TestConfidenceIntervalOverlaps(t *testing.T) {
+	a := ConfidenceInterval{Lower: 0.80, Upper: 0.90}
+	b := ConfidenceInterval{Lower: 0.85, Upper: 0.95}
+	c := ConfidenceInterval{Lower: 0.95, Upper: 0.99}
+
+	if !a.Overlaps(b) {
+		t.Fatal("expected overlapping intervals to report Overlaps=true")
+	}
+	if a.Overlaps(c) {
+		t.Fatal("expected non-overlapping intervals to report Overlaps=false")
+	}
+}
+
+func TestConfidenceIntervalString(t *testing.T) {
+	ci := ConfidenceInterval{Lower: 0.931, Upper: 0.968}
+	if got, want := ci.String(), "[93.1, 96.8]"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package calibration
+
+import (
+	"testing"
+	"time"
+)
+
+func record(distance, winnerSupport, subjectSupport, confidence int, subject, winner string, applied bool, ts time.Time) DecisionRecord {
+	decision := "rejected"
+	if applied {
+		decision = "applied"
+	}
+	return DecisionRecord{
+		Timestamp:        ts,
+		Subject:          subject,
+		Winner:           winner,
+		Distance:         distance,
+		WinnerSupport:    winnerSupport,
+		SubjectSupport:   subjectSupport,
+		WinnerConfidence: confidence,
+		Decision:         decision,
+	}
+}
+
+func TestConfigAcceptsAppliesPerDistanceExtraReports(t *testing.T) {
+	cfg := Config{
+		MinConsensusReports:  3,
+		MinConfidencePercent: 60,
+		MinAdvantage:         1,
+		DistanceExtraReports: [4]int{0, 0, 0, 1},
+	}
+	d3 := record(3, 3, 1, 65, "K1ABC", "W1AW", true, time.Unix(0, 0))
+	if cfg.Accepts(d3) {
+		t.Fatal("expected distance-3 decision with only 3 reports to fail the +1 extra-reports requirement")
+	}
+
+	d3ok := record(3, 4, 1, 65, "K1ABC", "W1AW", true, time.Unix(0, 0))
+	if !cfg.Accepts(d3ok) {
+		t.Fatal("expected distance-3 decision with 4 reports to satisfy the +1 extra-reports requirement")
+	}
+}
+
+func TestParamSpaceConfigsIsCartesianProduct(t *testing.T) {
+	space := ParamSpace{
+		MinConsensusReports:  []int{2, 3},
+		MinConfidencePercent: []int{55, 60},
+	}
+	configs := space.Configs()
+	if len(configs) != 4 {
+		t.Fatalf("expected 2x2=4 configs, got %d", len(configs))
+	}
+}
+
+func TestSweepComputesRescuedAndLost(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	decisions := []DecisionRecord{
+		record(1, 3, 1, 60, "K1ABC", "W1AW", true, now),
+		record(1, 2, 1, 55, "K2XYZ", "W2XYZ", false, now.Add(time.Hour)),
+	}
+	space := ParamSpace{
+		MinConsensusReports:  []int{2},
+		MinConfidencePercent: []int{55},
+	}
+	results := Sweep(decisions, space, 2, 42)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 config, got %d", len(results))
+	}
+	r := results[0]
+	if r.Applied != 2 {
+		t.Fatalf("expected both decisions to pass the relaxed thresholds, got applied=%d", r.Applied)
+	}
+	if r.RescuedFromRejected != 1 {
+		t.Fatalf("expected 1 rescued decision, got %d", r.RescuedFromRejected)
+	}
+	if r.LostFromApplied != 0 {
+		t.Fatalf("expected 0 lost decisions, got %d", r.LostFromApplied)
+	}
+}
+
+func TestTemporalStabilityDetectsDisagreement(t *testing.T) {
+	cfg := Config{MinConsensusReports: 1, MinConfidencePercent: 0, MinAdvantage: 0}
+	base := time.Unix(1_700_000_000, 0).UTC()
+
+	// Same subject, same winner, two different windows -> agrees.
+	decisions := []DecisionRecord{
+		record(1, 3, 1, 90, "K1ABC", "W1AW", true, base),
+		record(1, 3, 1, 90, "K1ABC", "W1AW", true, base.Add(100*time.Hour)),
+	}
+	stability := TemporalStability(decisions, cfg, 2)
+	if stability[1] != 1.0 {
+		t.Fatalf("expected perfect stability when the winner agrees across windows, got %.2f", stability[1])
+	}
+
+	// Same subject, disagreeing winners across windows -> instability.
+	decisions = append(decisions, record(1, 3, 1, 90, "K1ABC", "W9ZZZ", true, base.Add(100*time.Hour)))
+	stability = TemporalStability(decisions, cfg, 2)
+	if stability[1] != 0.0 {
+		t.Fatalf("expected 0 stability once winners disagree across windows, got %.2f", stability[1])
+	}
+}
+
+func TestParetoFrontPrunesDominatedConfigs(t *testing.T) {
+	lowStability := map[int]ConfidenceInterval{1: {0.45, 0.55}, 2: {0.45, 0.55}, 3: {0.45, 0.55}}
+	highStability := map[int]ConfidenceInterval{1: {0.85, 0.95}, 2: {0.85, 0.95}, 3: {0.85, 0.95}}
+
+	results := []Result{
+		{
+			Config: Config{MinConsensusReports: 3}, Recall: 0.5, RecallCI: ConfidenceInterval{0.45, 0.55},
+			StabilityByDistance: map[int]float64{1: 0.9, 2: 0.9, 3: 0.9}, StabilityCIByDistance: highStability,
+		},
+		{
+			// Non-overlapping, clearly higher recall, same stability: dominates the first.
+			Config: Config{MinConsensusReports: 2}, Recall: 0.6, RecallCI: ConfidenceInterval{0.58, 0.62},
+			StabilityByDistance: map[int]float64{1: 0.9, 2: 0.9, 3: 0.9}, StabilityCIByDistance: highStability,
+		},
+		{
+			// Non-overlapping higher recall, but non-overlapping worse stability: not dominated.
+			Config: Config{MinConsensusReports: 1}, Recall: 0.7, RecallCI: ConfidenceInterval{0.68, 0.72},
+			StabilityByDistance: map[int]float64{1: 0.5, 2: 0.5, 3: 0.5}, StabilityCIByDistance: lowStability,
+		},
+	}
+	front := ParetoFront(results)
+	if len(front) != 2 {
+		t.Fatalf("expected 2 non-dominated configs, got %d", len(front))
+	}
+}
+
+func TestFilterByConstraintAndMaxRecall(t *testing.T) {
+	results := []Result{
+		{Recall: 0.9, StabilityByDistance: map[int]float64{3: 0.80}},
+		{Recall: 0.7, StabilityByDistance: map[int]float64{3: 0.96}},
+		{Recall: 0.6, StabilityByDistance: map[int]float64{3: 0.97}},
+	}
+	constraint := Constraint{Distance: 3, MinStability: 0.95}
+	filtered := FilterByConstraint(results, constraint)
+	if len(filtered) != 2 {
+		t.Fatalf("expected 2 configs meeting the stability constraint, got %d", len(filtered))
+	}
+	best := MaxRecall(filtered)
+	if len(best) != 1 || best[0].Recall != 0.7 {
+		t.Fatalf("expected the recall=0.7 config to win, got %+v", best)
+	}
+}
@@ -0,0 +1,99 @@
+package calibration
+
+// Config is one candidate set of call-correction thresholds to evaluate
+// against the decision log. DistanceExtraReports is indexed by distance
+// (1, 2, 3); index 0 is unused since distance-0 decisions (no winner) are
+// excluded from the sweep.
+type Config struct {
+	MinConsensusReports  int
+	MinConfidencePercent int
+	MinAdvantage         int
+	DistanceExtraReports [4]int
+	ExtraAdvantage       int
+	ExtraConfidence      int
+}
+
+// Accepts reports whether d would be applied under cfg, replaying the same
+// three checks the live call-correction pipeline makes (minimum reports,
+// minimum advantage, minimum confidence), plus cfg's per-distance extra
+// report requirement.
+func (cfg Config) Accepts(d DecisionRecord) bool {
+	minReports := cfg.MinConsensusReports
+	if d.Distance >= 0 && d.Distance < len(cfg.DistanceExtraReports) {
+		minReports += cfg.DistanceExtraReports[d.Distance]
+	}
+	if d.WinnerSupport < minReports {
+		return false
+	}
+
+	advantage := d.WinnerSupport - d.SubjectSupport
+	if advantage < cfg.MinAdvantage+cfg.ExtraAdvantage {
+		return false
+	}
+
+	if d.WinnerConfidence < cfg.MinConfidencePercent+cfg.ExtraConfidence {
+		return false
+	}
+
+	return true
+}
+
+// ParamSpace enumerates the candidate values to sweep for each threshold.
+// The sweep is the full Cartesian product of every field, so keep the
+// per-field candidate lists small.
+type ParamSpace struct {
+	MinConsensusReports   []int
+	MinConfidencePercent  []int
+	MinAdvantage          []int
+	Distance1ExtraReports []int
+	Distance2ExtraReports []int
+	Distance3ExtraReports []int
+	ExtraAdvantage        []int
+	ExtraConfidence       []int
+}
+
+// Configs expands the parameter space into every candidate Config.
+func (ps ParamSpace) Configs() []Config {
+	minReports := orDefault(ps.MinConsensusReports, []int{3})
+	minConfidence := orDefault(ps.MinConfidencePercent, []int{60})
+	minAdvantage := orDefault(ps.MinAdvantage, []int{1})
+	d1 := orDefault(ps.Distance1ExtraReports, []int{0})
+	d2 := orDefault(ps.Distance2ExtraReports, []int{0})
+	d3 := orDefault(ps.Distance3ExtraReports, []int{0})
+	extraAdvantage := orDefault(ps.ExtraAdvantage, []int{0})
+	extraConfidence := orDefault(ps.ExtraConfidence, []int{0})
+
+	var configs []Config
+	for _, reports := range minReports {
+		for _, confidence := range minConfidence {
+			for _, advantage := range minAdvantage {
+				for _, e1 := range d1 {
+					for _, e2 := range d2 {
+						for _, e3 := range d3 {
+							for _, ea := range extraAdvantage {
+								for _, ec := range extraConfidence {
+									configs = append(configs, Config{
+										MinConsensusReports:  reports,
+										MinConfidencePercent: confidence,
+										MinAdvantage:         advantage,
+										DistanceExtraReports: [4]int{0, e1, e2, e3},
+										ExtraAdvantage:       ea,
+										ExtraConfidence:      ec,
+									})
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return configs
+}
+
+func orDefault(values []int, fallback []int) []int {
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
@@ -0,0 +1,73 @@
+// Package calibration replays the call-correction decision log against a
+// swept parameter space so threshold changes can be evaluated empirically
+// instead of by hand-picked scenarios. It generalizes the analysis
+// previously hard-coded per distance (see the old cmd/analyze_distance3)
+// into a single sweep that covers every distance at once.
+package calibration
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// DecisionRecord is one row of the `decisions` SQLite log, as produced by
+// the call-correction pipeline's decision recorder.
+type DecisionRecord struct {
+	ID               int64
+	Timestamp        time.Time
+	Subject          string
+	Winner           string
+	Distance         int
+	WinnerSupport    int
+	SubjectSupport   int
+	TotalReporters   int
+	WinnerConfidence int
+	MinReports       int
+	MinAdvantage     int
+	MinConfidence    int
+	Decision         string
+	Reason           string
+}
+
+// Applied reports whether the recorded decision actually applied the
+// correction (as opposed to the hypothetical outcome under a swept config).
+func (d DecisionRecord) Applied() bool {
+	return strings.EqualFold(d.Decision, "applied")
+}
+
+// LoadDecisions reads every distance-1..3 decision from db, ordered by
+// timestamp so downstream temporal-stability windowing sees them in order.
+func LoadDecisions(db *sql.DB) ([]DecisionRecord, error) {
+	rows, err := db.Query(`
+		SELECT
+			id, ts, subject, winner, distance,
+			winner_support, subject_support, total_reporters, winner_confidence,
+			min_reports, min_advantage, min_confidence,
+			decision, COALESCE(reason, '')
+		FROM decisions
+		WHERE distance BETWEEN 1 AND 3
+		ORDER BY ts
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decisions []DecisionRecord
+	for rows.Next() {
+		var d DecisionRecord
+		var ts int64
+		if err := rows.Scan(&d.ID, &ts, &d.Subject, &d.Winner, &d.Distance,
+			&d.WinnerSupport, &d.SubjectSupport, &d.TotalReporters, &d.WinnerConfidence,
+			&d.MinReports, &d.MinAdvantage, &d.MinConfidence,
+			&d.Decision, &d.Reason); err != nil {
+			return nil, err
+		}
+		d.Timestamp = time.Unix(ts, 0).UTC()
+		d.Subject = strings.ToUpper(strings.TrimSpace(d.Subject))
+		d.Winner = strings.ToUpper(strings.TrimSpace(d.Winner))
+		decisions = append(decisions, d)
+	}
+	return decisions, rows.Err()
+}
@@ -0,0 +1,94 @@
+package calibration
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report bundles a sweep's Pareto front and (if a constraint was supplied)
+// the recall-maximizing configs meeting it, for JSON or table output.
+type Report struct {
+	TotalDecisions    int         `json:"total_decisions"`
+	ParetoFront       []Result    `json:"pareto_front"`
+	Constraint        *Constraint `json:"constraint,omitempty"`
+	BestForConstraint []Result    `json:"best_for_constraint,omitempty"`
+
+	// ResampleSeed is the seed used to bootstrap the confidence intervals
+	// on every Result in this report, recorded so the sweep can be
+	// reproduced exactly.
+	ResampleSeed int64 `json:"resample_seed"`
+}
+
+// BuildReport runs Sweep and assembles the Pareto front plus, if c is
+// non-nil, the recall-maximizing configs satisfying it. seed is the
+// bootstrap resample seed (see Sweep); 0 selects defaultSeed.
+func BuildReport(decisions []DecisionRecord, space ParamSpace, numWindows int, c *Constraint, seed int64) Report {
+	if seed == 0 {
+		seed = defaultSeed
+	}
+	results := Sweep(decisions, space, numWindows, seed)
+	report := Report{
+		TotalDecisions: len(decisions),
+		ParetoFront:    ParetoFront(results),
+		ResampleSeed:   seed,
+	}
+	if c != nil {
+		report.Constraint = c
+		report.BestForConstraint = MaxRecall(FilterByConstraint(results, *c))
+	}
+	return report
+}
+
+// JSON renders the report as indented JSON.
+func (r Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Table renders the report as a human-readable aligned table.
+func (r Report) Table() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Total eligible decisions: %d\n", r.TotalDecisions)
+	fmt.Fprintf(&b, "Bootstrap CIs: B=%d resamples, seed=%d\n\n", bootstrapSamples, r.ResampleSeed)
+
+	fmt.Fprintf(&b, "Pareto front (recall, stability) - %d configs:\n", len(r.ParetoFront))
+	writeResultTable(&b, r.ParetoFront)
+
+	if r.Constraint != nil {
+		fmt.Fprintf(&b, "\nConstraint: stability(distance=%d) >= %.1f%%\n", r.Constraint.Distance, r.Constraint.MinStability*100)
+		if len(r.BestForConstraint) == 0 {
+			fmt.Fprintf(&b, "No config satisfies the constraint.\n")
+		} else {
+			fmt.Fprintf(&b, "Recall-maximizing configs satisfying it - %d configs:\n", len(r.BestForConstraint))
+			writeResultTable(&b, r.BestForConstraint)
+		}
+	}
+	return b.String()
+}
+
+func writeResultTable(b *strings.Builder, results []Result) {
+	w := tabwriter.NewWriter(b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "min_reports\tmin_conf\tmin_adv\td1_extra\td2_extra\td3_extra\textra_adv\textra_conf\tapplied\trescued\tlost\trecall\tstab_d1\tstab_d2\tstab_d3")
+	for _, r := range results {
+		cfg := r.Config
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			cfg.MinConsensusReports, cfg.MinConfidencePercent, cfg.MinAdvantage,
+			cfg.DistanceExtraReports[1], cfg.DistanceExtraReports[2], cfg.DistanceExtraReports[3],
+			cfg.ExtraAdvantage, cfg.ExtraConfidence,
+			r.Applied, r.RescuedFromRejected, r.LostFromApplied,
+			formatPct(r.Recall, r.RecallCI),
+			formatPct(r.StabilityByDistance[1], r.StabilityCIByDistance[1]),
+			formatPct(r.StabilityByDistance[2], r.StabilityCIByDistance[2]),
+			formatPct(r.StabilityByDistance[3], r.StabilityCIByDistance[3]),
+		)
+	}
+	w.Flush()
+}
+
+// formatPct renders a point estimate alongside its bootstrap CI, e.g.
+// "95.3% [93.1, 96.8]", so a reader can see how much sample size (not just
+// mean) separates two configs.
+func formatPct(value float64, ci ConfidenceInterval) string {
+	return fmt.Sprintf("%.1f%% %s", value*100, ci.String())
+}
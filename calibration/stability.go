@@ -0,0 +1,123 @@
+package calibration
+
+import "time"
+
+// defaultWindows is used when the caller doesn't specify a window count.
+const defaultWindows = 4
+
+// TemporalStability measures, per distance, how often a subject's winner
+// stays the same across time windows. The decision log's time span is split
+// into numWindows equal buckets; for every subject that has at least one
+// config-accepted decision in two or more buckets, the subject "agrees" if
+// every bucket it appears in picked the same winner. Stability is the
+// fraction of multi-window subjects that agree. Subjects seen in only one
+// window can't contradict themselves, so they don't affect the ratio.
+func TemporalStability(decisions []DecisionRecord, cfg Config, numWindows int) map[int]float64 {
+	if numWindows <= 1 {
+		numWindows = defaultWindows
+	}
+
+	start, end, ok := timeSpan(decisions)
+	if !ok {
+		return map[int]float64{1: 1, 2: 1, 3: 1}
+	}
+	span := end.Sub(start)
+	if span <= 0 {
+		span = time.Second
+	}
+
+	type subjectKey struct {
+		distance int
+		subject  string
+	}
+	// windowWinners[subjectKey][window] is the set of winners picked in
+	// that window for that subject.
+	windowWinners := make(map[subjectKey]map[int]map[string]bool)
+
+	for _, d := range decisions {
+		if d.Distance < 1 || d.Distance > 3 || !cfg.Accepts(d) {
+			continue
+		}
+		w := windowIndex(d.Timestamp, start, span, numWindows)
+		k := subjectKey{d.Distance, d.Subject}
+		if windowWinners[k] == nil {
+			windowWinners[k] = make(map[int]map[string]bool)
+		}
+		if windowWinners[k][w] == nil {
+			windowWinners[k][w] = make(map[string]bool)
+		}
+		windowWinners[k][w][d.Winner] = true
+	}
+
+	agree := map[int]int{1: 0, 2: 0, 3: 0}
+	total := map[int]int{1: 0, 2: 0, 3: 0}
+
+	for k, windows := range windowWinners {
+		if len(windows) < 2 {
+			continue
+		}
+		total[k.distance]++
+		if subjectAgreesAcrossWindows(windows) {
+			agree[k.distance]++
+		}
+	}
+
+	result := make(map[int]float64, 3)
+	for distance := 1; distance <= 3; distance++ {
+		if total[distance] == 0 {
+			// No subject reappeared across windows at this distance under
+			// cfg, so there's no evidence of instability; treat it as
+			// fully stable rather than penalizing sparse configs.
+			result[distance] = 1.0
+			continue
+		}
+		result[distance] = float64(agree[distance]) / float64(total[distance])
+	}
+	return result
+}
+
+func subjectAgreesAcrossWindows(windows map[int]map[string]bool) bool {
+	var firstWinner string
+	first := true
+	for _, winners := range windows {
+		for winner := range winners {
+			if first {
+				firstWinner = winner
+				first = false
+				continue
+			}
+			if winner != firstWinner {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func windowIndex(t, start time.Time, span time.Duration, numWindows int) int {
+	offset := t.Sub(start)
+	idx := int(offset * time.Duration(numWindows) / span)
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= numWindows {
+		idx = numWindows - 1
+	}
+	return idx
+}
+
+func timeSpan(decisions []DecisionRecord) (start, end time.Time, ok bool) {
+	for i, d := range decisions {
+		if i == 0 {
+			start, end = d.Timestamp, d.Timestamp
+			continue
+		}
+		if d.Timestamp.Before(start) {
+			start = d.Timestamp
+		}
+		if d.Timestamp.After(end) {
+			end = d.Timestamp
+		}
+	}
+	return start, end, len(decisions) > 0
+}
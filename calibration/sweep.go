@@ -0,0 +1,190 @@
+package calibration
+
+// Result is the outcome of replaying the full decision log against one
+// candidate Config.
+type Result struct {
+	Config Config
+
+	// Applied is the number of distance-1..3 decisions this config would
+	// apply, regardless of what the original run decided.
+	Applied int
+	// RescuedFromRejected is how many originally-rejected decisions this
+	// config would newly apply.
+	RescuedFromRejected int
+	// LostFromApplied is how many originally-applied decisions this config
+	// would no longer apply.
+	LostFromApplied int
+	// Recall is Applied as a fraction of all eligible decisions.
+	Recall float64
+	// RecallCI is the bootstrap confidence interval around Recall (see
+	// Sweep's seed parameter).
+	RecallCI ConfidenceInterval
+
+	// StabilityByDistance is TemporalStability's output for this config,
+	// keyed by distance (1, 2, 3).
+	StabilityByDistance map[int]float64
+	// StabilityCIByDistance is the bootstrap confidence interval around
+	// each entry of StabilityByDistance.
+	StabilityCIByDistance map[int]ConfidenceInterval
+}
+
+// OverallStability averages StabilityByDistance across distances 1-3.
+func (r Result) OverallStability() float64 {
+	sum := 0.0
+	for d := 1; d <= 3; d++ {
+		sum += r.StabilityByDistance[d]
+	}
+	return sum / 3
+}
+
+// OverallStabilityCI averages the per-distance stability confidence
+// intervals the same way OverallStability averages the point estimates.
+func (r Result) OverallStabilityCI() ConfidenceInterval {
+	var lower, upper float64
+	for d := 1; d <= 3; d++ {
+		ci := r.StabilityCIByDistance[d]
+		lower += ci.Lower
+		upper += ci.Upper
+	}
+	return ConfidenceInterval{Lower: lower / 3, Upper: upper / 3}
+}
+
+// Sweep replays decisions against every Config in space, computing recall
+// and temporal stability for each along with a bootstrap confidence
+// interval around both (see bootstrap). numWindows controls the temporal
+// stability window count (see TemporalStability); 0 selects the default.
+// seed makes the bootstrap resampling reproducible; 0 selects defaultSeed.
+func Sweep(decisions []DecisionRecord, space ParamSpace, numWindows int, seed int64) []Result {
+	if seed == 0 {
+		seed = defaultSeed
+	}
+
+	configs := space.Configs()
+	results := make([]Result, 0, len(configs))
+
+	for _, cfg := range configs {
+		var applied, rescued, lost int
+		for _, d := range decisions {
+			accepts := cfg.Accepts(d)
+			if accepts {
+				applied++
+				if !d.Applied() {
+					rescued++
+				}
+			} else if d.Applied() {
+				lost++
+			}
+		}
+
+		recall := 0.0
+		if len(decisions) > 0 {
+			recall = float64(applied) / float64(len(decisions))
+		}
+
+		recallCI, stabilityCI := bootstrap(decisions, cfg, numWindows, seed)
+
+		results = append(results, Result{
+			Config:                cfg,
+			Applied:               applied,
+			RescuedFromRejected:   rescued,
+			LostFromApplied:       lost,
+			Recall:                recall,
+			RecallCI:              recallCI,
+			StabilityByDistance:   TemporalStability(decisions, cfg, numWindows),
+			StabilityCIByDistance: stabilityCI,
+		})
+	}
+	return results
+}
+
+// Constraint restricts candidate configs to those meeting a minimum
+// temporal stability at a given distance, e.g. "stability >= 95% on
+// distance 3".
+type Constraint struct {
+	Distance     int
+	MinStability float64
+}
+
+// Satisfies reports whether r meets c.
+func (c Constraint) Satisfies(r Result) bool {
+	return r.StabilityByDistance[c.Distance] >= c.MinStability
+}
+
+// FilterByConstraint returns the subset of results satisfying c.
+func FilterByConstraint(results []Result, c Constraint) []Result {
+	var out []Result
+	for _, r := range results {
+		if c.Satisfies(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// MaxRecall returns the subset of results tied for the highest recall.
+func MaxRecall(results []Result) []Result {
+	if len(results) == 0 {
+		return nil
+	}
+	best := results[0].Recall
+	for _, r := range results {
+		if r.Recall > best {
+			best = r.Recall
+		}
+	}
+	var out []Result
+	for _, r := range results {
+		if r.Recall == best {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ParetoFront returns the results not dominated by any other result along
+// the (recall, overall stability) axes. A result dominates another if it is
+// at least as good on both axes and strictly better on at least one, where
+// "better" is judged by bootstrap confidence interval rather than raw mean:
+// two configs whose CIs overlap on an axis are treated as tied on that
+// axis, since the resample can't tell them apart.
+func ParetoFront(results []Result) []Result {
+	var front []Result
+	for i, candidate := range results {
+		dominated := false
+		for j, other := range results {
+			if i == j {
+				continue
+			}
+			if dominatesResult(other, candidate) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, candidate)
+		}
+	}
+	return front
+}
+
+func dominatesResult(a, b Result) bool {
+	recallBetter, recallWorse := compareCI(a.RecallCI, b.RecallCI)
+	stabilityBetter, stabilityWorse := compareCI(a.OverallStabilityCI(), b.OverallStabilityCI())
+	if recallWorse || stabilityWorse {
+		return false
+	}
+	return recallBetter || stabilityBetter
+}
+
+// compareCI reports whether a is clearly better than b (non-overlapping
+// and higher) or clearly worse (non-overlapping and lower) on one axis.
+// Overlapping intervals return false, false: neither better nor worse.
+func compareCI(a, b ConfidenceInterval) (better, worse bool) {
+	if a.Overlaps(b) {
+		return false, false
+	}
+	if a.Lower > b.Upper {
+		return true, false
+	}
+	return false, true
+}
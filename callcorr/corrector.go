@@ -0,0 +1,287 @@
+// Package callcorr implements an in-process, consensus-driven callsign
+// correction engine. Where spot.SuggestCallCorrection evaluates a single
+// subject spot against a caller-supplied candidate set, callcorr owns the
+// candidate set itself: it consumes the live spot stream, clusters recent
+// spots by frequency bucket and edit distance, and weighs votes by source
+// trust (a human-copied spot outweighs an automated skimmer decode). It
+// also guards against the oscillating-correction pattern the reappearance
+// investigator flags (A->B immediately followed by B->A) by refusing a
+// correction that would reverse one it just made.
+package callcorr
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"dxcluster/decisionlog"
+	"dxcluster/spot"
+)
+
+// Settings controls the consensus engine and its oscillation guard.
+type Settings struct {
+	// FreqBucketKHz is the width, in kHz, of the frequency buckets spots are
+	// grouped into before clustering - wide enough to absorb normal VFO/RIT
+	// drift between reports of the same QSO.
+	FreqBucketKHz float64
+	// RecencyWindow is how long a spot remains a voting candidate for
+	// later spots in the same frequency bucket.
+	RecencyWindow time.Duration
+	// MaxEditDistance is the maximum weighted Damerau-Levenshtein distance
+	// (spot.CallDistance) between two calls for them to be considered the
+	// same underlying callsign for clustering purposes.
+	MaxEditDistance float64
+	// MinConsensusVotes is the minimum weighted vote total an alternate
+	// call must reach before it's even considered.
+	MinConsensusVotes float64
+	// MinAdvantage is the minimum weighted-vote lead an alternate call must
+	// hold over the subject's own call before a correction is suggested.
+	MinAdvantage float64
+	// HumanWeight and SkimmerWeight are the per-vote weights for spots
+	// from a human-operated DE station versus an automated skimmer feed
+	// (spot.IsSkimmerSource). Skimmers decode mechanically and correlate
+	// with each other's mistakes, so they should outvote a human only with
+	// a clear numerical majority.
+	HumanWeight   float64
+	SkimmerWeight float64
+	// CooldownWindow is how long after a subject->winner correction a
+	// winner->subject reversal is refused as a likely oscillation rather
+	// than applied.
+	CooldownWindow time.Duration
+}
+
+// DecisionLogger receives every correction decision callcorr evaluates,
+// whether applied or refused by the oscillation guard. decisionlog.Store
+// satisfies this interface; it's defined here (rather than depended on as
+// *decisionlog.Store directly) purely so tests can supply a fake.
+type DecisionLogger interface {
+	LogApplied(d decisionlog.Decision) error
+	LogRejected(d decisionlog.Decision, reason string) error
+}
+
+// Corrector decides, for a spot arriving from the stream, whether its
+// callsign should be corrected. Implementations may mutate and return the
+// same spot or return a distinct corrected copy; either way the returned
+// spot is what downstream pipeline stages (the recorder, broadcast, sinks)
+// should use.
+type Corrector interface {
+	Apply(s *spot.Spot, now time.Time) (corrected *spot.Spot, decision decisionlog.Decision)
+}
+
+// ConsensusCorrector is the default Corrector: a per-frequency-bucket
+// consensus vote weighted by source trust, guarded against oscillation.
+type ConsensusCorrector struct {
+	settings Settings
+	logger   DecisionLogger
+
+	mu      sync.Mutex
+	votes   map[int][]vote
+	history map[int][]correction
+}
+
+// NewConsensusCorrector creates a ConsensusCorrector with the given
+// settings. logger may be nil; a nil logger just means decisions aren't
+// recorded anywhere beyond the returned decisionlog.Decision.
+func NewConsensusCorrector(settings Settings, logger DecisionLogger) *ConsensusCorrector {
+	return &ConsensusCorrector{
+		settings: settings,
+		logger:   logger,
+		votes:    make(map[int][]vote),
+		history:  make(map[int][]correction),
+	}
+}
+
+// vote is one recent spot in a frequency bucket, available as a candidate
+// for the next spot arriving in the same bucket.
+type vote struct {
+	call     string
+	reporter string
+	weight   float64
+	at       time.Time
+}
+
+// correction is one past correction recorded for a frequency bucket, used
+// to detect an immediate A->B, B->A reversal. Tracking is per bucket
+// rather than per subject call because a reversal swaps which call plays
+// subject and which plays winner.
+type correction struct {
+	from, to string
+	at       time.Time
+}
+
+// Apply evaluates s against the recent voting history for its frequency
+// bucket and either returns s unchanged (decision.Decision == "" when no
+// correction qualified, "rejected" when the oscillation guard refused one)
+// or a corrected copy alongside the "applied" decisionlog.Decision.
+func (c *ConsensusCorrector) Apply(s *spot.Spot, now time.Time) (*spot.Spot, decisionlog.Decision) {
+	if c == nil || s == nil {
+		return s, decisionlog.Decision{}
+	}
+	if !spot.IsCallCorrectionCandidate(s.Mode) {
+		return s, decisionlog.Decision{}
+	}
+
+	subjectCall := strings.ToUpper(strings.TrimSpace(s.DXCall))
+	if subjectCall == "" {
+		return s, decisionlog.Decision{}
+	}
+	bucket := freqBucket(s.Frequency, c.settings.FreqBucketKHz)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prune(bucket, now)
+	candidates := c.votes[bucket]
+
+	bestCall, bestWeight, subjectWeight, totalReporters := c.tally(subjectCall, s, candidates)
+
+	// Always record this spot as a future voting candidate, regardless of
+	// whether it triggers a correction now.
+	c.votes[bucket] = append(candidates, vote{
+		call:     subjectCall,
+		reporter: strings.ToUpper(strings.TrimSpace(s.DECall)),
+		weight:   sourceWeight(c.settings, s),
+		at:       s.Time,
+	})
+
+	if bestCall == "" || bestWeight < c.settings.MinConsensusVotes {
+		return s, decisionlog.Decision{}
+	}
+	if advantage := bestWeight - subjectWeight; advantage < c.settings.MinAdvantage {
+		return s, decisionlog.Decision{}
+	}
+
+	confidence := int(bestWeight / (bestWeight + subjectWeight) * 100)
+	d := decisionlog.Decision{
+		Timestamp:        s.Time,
+		Subject:          subjectCall,
+		Winner:           bestCall,
+		FreqKHz:          s.Frequency,
+		WinnerConfidence: confidence,
+		TotalReporters:   totalReporters,
+	}
+
+	if c.oscillating(bucket, subjectCall, bestCall, now) {
+		d.Decision = "rejected"
+		d.Reason = "oscillation_guard"
+		if c.logger != nil {
+			_ = c.logger.LogRejected(d, "oscillation_guard")
+		}
+		return s, d // refused: caller keeps the original spot unmodified
+	}
+
+	d.Decision = "applied"
+	c.history[bucket] = append(c.history[bucket], correction{from: subjectCall, to: bestCall, at: now})
+	if c.logger != nil {
+		_ = c.logger.LogApplied(d)
+	}
+
+	corrected := *s
+	corrected.DXCall = bestCall
+	return &corrected, d
+}
+
+// tally clusters candidates by edit distance from subjectCall and returns
+// the best-supported alternate call, its weighted vote total, the
+// subject's own weighted vote total (including itself), and how many
+// distinct reporters contributed either way.
+func (c *ConsensusCorrector) tally(subjectCall string, s *spot.Spot, candidates []vote) (bestCall string, bestWeight, subjectWeight float64, totalReporters int) {
+	subjectReporter := strings.ToUpper(strings.TrimSpace(s.DECall))
+	altWeights := make(map[string]float64)
+	altReporters := make(map[string]map[string]struct{})
+	subjectReporters := map[string]struct{}{}
+	if subjectReporter != "" {
+		subjectReporters[subjectReporter] = struct{}{}
+	}
+
+	for _, v := range candidates {
+		if v.reporter == subjectReporter && subjectReporter != "" {
+			continue
+		}
+		if v.call == subjectCall {
+			subjectWeight += v.weight
+			if v.reporter != "" {
+				subjectReporters[v.reporter] = struct{}{}
+			}
+			continue
+		}
+		if spot.CallDistance(subjectCall, v.call, s.Mode) > c.settings.MaxEditDistance {
+			continue
+		}
+		altWeights[v.call] += v.weight
+		reporters := altReporters[v.call]
+		if reporters == nil {
+			reporters = make(map[string]struct{})
+			altReporters[v.call] = reporters
+		}
+		if v.reporter != "" {
+			reporters[v.reporter] = struct{}{}
+		}
+	}
+
+	// The subject's own spot always counts as one vote for itself.
+	subjectWeight += sourceWeight(c.settings, s)
+
+	for call, weight := range altWeights {
+		if weight > bestWeight || (weight == bestWeight && call < bestCall) {
+			bestCall, bestWeight = call, weight
+		}
+	}
+
+	totalReporters = len(subjectReporters)
+	if bestCall != "" {
+		totalReporters += len(altReporters[bestCall])
+	}
+	return bestCall, bestWeight, subjectWeight, totalReporters
+}
+
+// oscillating reports whether correcting subject->winner now would reverse
+// a winner->subject correction already made in this frequency bucket
+// within CooldownWindow.
+func (c *ConsensusCorrector) oscillating(bucket int, subject, winner string, now time.Time) bool {
+	for _, prior := range c.history[bucket] {
+		if prior.from == winner && prior.to == subject && now.Sub(prior.at) <= c.settings.CooldownWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops voting candidates for bucket older than RecencyWindow.
+func (c *ConsensusCorrector) prune(bucket int, now time.Time) {
+	window := c.settings.RecencyWindow
+	slice := c.votes[bucket]
+	if len(slice) == 0 {
+		return
+	}
+	cutoff := now.Add(-window)
+	dst := slice[:0]
+	for _, v := range slice {
+		if v.at.After(cutoff) {
+			dst = append(dst, v)
+		}
+	}
+	if len(dst) == 0 {
+		delete(c.votes, bucket)
+		return
+	}
+	c.votes[bucket] = dst
+}
+
+// sourceWeight returns the vote weight for a spot based on whether it came
+// from a human operator or an automated skimmer feed.
+func sourceWeight(settings Settings, s *spot.Spot) float64 {
+	if spot.IsSkimmerSource(s.SourceType) {
+		return settings.SkimmerWeight
+	}
+	return settings.HumanWeight
+}
+
+// freqBucket rounds a frequency (kHz) down to its bucket index for the
+// given bucket width.
+func freqBucket(freqKHz, bucketKHz float64) int {
+	if bucketKHz <= 0 {
+		bucketKHz = 1
+	}
+	return int(freqKHz / bucketKHz)
+}
@@ -0,0 +1,163 @@
+package callcorr
+
+import (
+	"testing"
+	"time"
+
+	"dxcluster/decisionlog"
+	"dxcluster/spot"
+)
+
+func testSettings() Settings {
+	return Settings{
+		FreqBucketKHz:     0.5,
+		RecencyWindow:     time.Minute,
+		MaxEditDistance:   2,
+		MinConsensusVotes: 2,
+		MinAdvantage:      1,
+		HumanWeight:       1.0,
+		SkimmerWeight:     0.4,
+		CooldownWindow:    5 * time.Minute,
+	}
+}
+
+type fakeLogger struct {
+	applied  []decisionlog.Decision
+	rejected []decisionlog.Decision
+	reasons  []string
+}
+
+func (f *fakeLogger) LogApplied(d decisionlog.Decision) error {
+	f.applied = append(f.applied, d)
+	return nil
+}
+
+func (f *fakeLogger) LogRejected(d decisionlog.Decision, reason string) error {
+	f.rejected = append(f.rejected, d)
+	f.reasons = append(f.reasons, reason)
+	return nil
+}
+
+func TestConsensusCorrectorAppliesConsensus(t *testing.T) {
+	logger := &fakeLogger{}
+	c := NewConsensusCorrector(testSettings(), logger)
+	now := time.Now().UTC()
+
+	feed := []*spot.Spot{
+		{DXCall: "K1AB", DECall: "W1AAA", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream},
+		{DXCall: "K1AB", DECall: "W2BBB", Frequency: 14025.0, Mode: "CW", Time: now.Add(time.Second), SourceType: spot.SourceUpstream},
+	}
+	for _, s := range feed {
+		c.Apply(s, now)
+	}
+
+	subject := &spot.Spot{DXCall: "K1ABC", DECall: "W3CCC", Frequency: 14025.0, Mode: "CW", Time: now.Add(2 * time.Second), SourceType: spot.SourceUpstream}
+	corrected, decision := c.Apply(subject, now.Add(2*time.Second))
+	if corrected.DXCall != "K1AB" {
+		t.Fatalf("expected correction to K1AB, got %q", corrected.DXCall)
+	}
+	if decision.Decision != "applied" || decision.Winner != "K1AB" {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+	if len(logger.applied) != 1 {
+		t.Fatalf("expected 1 applied decision logged, got %d", len(logger.applied))
+	}
+}
+
+func TestConsensusCorrectorRequiresAdvantage(t *testing.T) {
+	settings := testSettings()
+	settings.MinAdvantage = 3
+	c := NewConsensusCorrector(settings, nil)
+	now := time.Now().UTC()
+
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "W1AAA", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}, now)
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "W2BBB", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}, now)
+
+	subject := &spot.Spot{DXCall: "K1ABC", DECall: "W3CCC", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}
+	corrected, decision := c.Apply(subject, now)
+	if corrected.DXCall != "K1ABC" {
+		t.Fatalf("expected no correction, got %q", corrected.DXCall)
+	}
+	if decision.Decision != "" {
+		t.Fatalf("expected no decision, got %+v", decision)
+	}
+}
+
+func TestConsensusCorrectorWeighsSkimmersLower(t *testing.T) {
+	settings := testSettings()
+	settings.MinConsensusVotes = 1.5
+	settings.MinAdvantage = 0.5
+	c := NewConsensusCorrector(settings, nil)
+	now := time.Now().UTC()
+
+	// Two skimmer votes for K1AB (weight 0.4 each = 0.8) shouldn't outweigh
+	// one human vote for the subject's own call (weight 1.0).
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "SKIMMER1", SourceType: spot.SourceRBN, Frequency: 14025.0, Mode: "CW", Time: now}, now)
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "SKIMMER2", SourceType: spot.SourceRBN, Frequency: 14025.0, Mode: "CW", Time: now}, now)
+	c.Apply(&spot.Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}, now)
+
+	subject := &spot.Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}
+	corrected, _ := c.Apply(subject, now)
+	if corrected.DXCall != "K1ABC" {
+		t.Fatalf("expected skimmer votes to not outweigh the human vote, got %q", corrected.DXCall)
+	}
+}
+
+func TestConsensusCorrectorOscillationGuardRefusesReversal(t *testing.T) {
+	logger := &fakeLogger{}
+	c := NewConsensusCorrector(testSettings(), logger)
+	now := time.Now().UTC()
+
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "W1AAA", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}, now)
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "W2BBB", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}, now)
+	subject := &spot.Spot{DXCall: "K1ABC", DECall: "W3CCC", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}
+	corrected, decision := c.Apply(subject, now)
+	if corrected.DXCall != "K1AB" || decision.Decision != "applied" {
+		t.Fatalf("expected the initial K1ABC->K1AB correction to apply, got %+v / %+v", corrected, decision)
+	}
+
+	// Now the reverse: three more votes for K1ABC, applied shortly after.
+	// With the earlier W3CCC spot that makes four K1ABC votes against three
+	// for K1AB (two prior spots plus the reversal spot itself), clearing
+	// the advantage bar.
+	soon := now.Add(30 * time.Second)
+	c.Apply(&spot.Spot{DXCall: "K1ABC", DECall: "W4DDD", Frequency: 14025.0, Mode: "CW", Time: soon, SourceType: spot.SourceUpstream}, soon)
+	c.Apply(&spot.Spot{DXCall: "K1ABC", DECall: "W5EEE", Frequency: 14025.0, Mode: "CW", Time: soon, SourceType: spot.SourceUpstream}, soon)
+	c.Apply(&spot.Spot{DXCall: "K1ABC", DECall: "W6GGG", Frequency: 14025.0, Mode: "CW", Time: soon, SourceType: spot.SourceUpstream}, soon)
+	reversal := &spot.Spot{DXCall: "K1AB", DECall: "W6FFF", Frequency: 14025.0, Mode: "CW", Time: soon, SourceType: spot.SourceUpstream}
+	corrected, decision = c.Apply(reversal, soon)
+	if corrected.DXCall != "K1AB" {
+		t.Fatalf("expected the oscillation guard to keep the spot unmodified, got %q", corrected.DXCall)
+	}
+	if decision.Decision != "rejected" {
+		t.Fatalf("expected a rejected decision from the oscillation guard, got %+v", decision)
+	}
+	if len(logger.rejected) != 1 || logger.reasons[0] != "oscillation_guard" {
+		t.Fatalf("expected the near-miss to be logged as an oscillation_guard rejection, got %+v / %v", logger.rejected, logger.reasons)
+	}
+}
+
+func TestConsensusCorrectorOscillationGuardAllowsAfterCooldown(t *testing.T) {
+	settings := testSettings()
+	settings.CooldownWindow = time.Minute
+	c := NewConsensusCorrector(settings, nil)
+	now := time.Now().UTC()
+
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "W1AAA", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}, now)
+	c.Apply(&spot.Spot{DXCall: "K1AB", DECall: "W2BBB", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}, now)
+	subject := &spot.Spot{DXCall: "K1ABC", DECall: "W3CCC", Frequency: 14025.0, Mode: "CW", Time: now, SourceType: spot.SourceUpstream}
+	c.Apply(subject, now)
+
+	later := now.Add(10 * time.Minute)
+	c.Apply(&spot.Spot{DXCall: "K1ABC", DECall: "W4DDD", Frequency: 14025.0, Mode: "CW", Time: later, SourceType: spot.SourceUpstream}, later)
+	c.Apply(&spot.Spot{DXCall: "K1ABC", DECall: "W5EEE", Frequency: 14025.0, Mode: "CW", Time: later, SourceType: spot.SourceUpstream}, later)
+	c.Apply(&spot.Spot{DXCall: "K1ABC", DECall: "W6GGG", Frequency: 14025.0, Mode: "CW", Time: later, SourceType: spot.SourceUpstream}, later)
+	reversal := &spot.Spot{DXCall: "K1AB", DECall: "W6FFF", Frequency: 14025.0, Mode: "CW", Time: later, SourceType: spot.SourceUpstream}
+	corrected, decision := c.Apply(reversal, later)
+	if corrected.DXCall != "K1ABC" {
+		t.Fatalf("expected the reversal to apply once past the cooldown window, got %q", corrected.DXCall)
+	}
+	if decision.Decision != "applied" {
+		t.Fatalf("expected an applied decision, got %+v", decision)
+	}
+}
@@ -0,0 +1,366 @@
+// Package feedback aggregates the call-correction decision log into
+// per-edit-distance statistics and derives recommended Thresholds
+// adjustments from them - the same analysis analyze1c performs by hand
+// against a SQLite dump, run continuously by Engine against the live log so
+// operators don't have to re-run it themselves after every tuning change.
+package feedback
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"dxcluster/decisionlog"
+)
+
+// zScore95 is the standard normal critical value for a 95% two-sided
+// interval/test, used by both the Wilson interval and the two-proportion
+// z-test below.
+const zScore95 = 1.96
+
+// DistanceStats summarizes every decision logged at one edit distance: how
+// many were applied versus rejected, the distribution of winner confidence
+// among the applied ones, and why the rejected ones were rejected.
+type DistanceStats struct {
+	Distance         int
+	TotalDecisions   int
+	AppliedCount     int
+	RejectedCount    int
+	MeanConfidence   float64
+	MedianConfidence float64
+	ConfStdDev       float64
+	Confidences      []int
+	RejectionReasons map[string]int
+}
+
+// Aggregate buckets decisions by edit distance and computes each bucket's
+// apply/reject counts and confidence distribution. It's the single place
+// both analyze1c and Engine derive their statistics from, so a human
+// reading a report and the auto-tune loop acting on one can't disagree
+// about what the log says.
+func Aggregate(decisions []decisionlog.Decision) map[int]*DistanceStats {
+	byDistance := make(map[int]*DistanceStats)
+	for _, d := range decisions {
+		// Harmonic drops aren't call-correction decisions - they have no
+		// meaningful distance or confidence (decisionlog.Store.
+		// LogHarmonicDropped always logs Distance=0), and mixing them in
+		// would drag the distance-0 bucket's apply rate down with rows that
+		// were never evaluated as a correction candidate at all.
+		if strings.EqualFold(d.Decision, "harmonic_dropped") {
+			continue
+		}
+		stats, ok := byDistance[d.Distance]
+		if !ok {
+			stats = &DistanceStats{Distance: d.Distance, RejectionReasons: make(map[string]int)}
+			byDistance[d.Distance] = stats
+		}
+		stats.TotalDecisions++
+		if d.Applied() {
+			stats.AppliedCount++
+			stats.Confidences = append(stats.Confidences, d.WinnerConfidence)
+		} else {
+			stats.RejectedCount++
+			reason := d.Reason
+			if reason == "" {
+				reason = "UNKNOWN"
+			}
+			stats.RejectionReasons[reason]++
+		}
+	}
+	for _, stats := range byDistance {
+		stats.computeConfidenceStats()
+	}
+	return byDistance
+}
+
+func (s *DistanceStats) computeConfidenceStats() {
+	if len(s.Confidences) == 0 {
+		return
+	}
+	sum := 0
+	for _, c := range s.Confidences {
+		sum += c
+	}
+	s.MeanConfidence = float64(sum) / float64(len(s.Confidences))
+
+	sorted := make([]int, len(s.Confidences))
+	copy(sorted, s.Confidences)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		s.MedianConfidence = float64(sorted[mid-1]+sorted[mid]) / 2.0
+	} else {
+		s.MedianConfidence = float64(sorted[mid])
+	}
+	s.ConfStdDev = sampleMeanStdDev(s.Confidences, s.MeanConfidence)
+}
+
+// ApplyRate returns the percentage of decisions in this bucket that were
+// applied. Zero decisions returns 0 rather than NaN.
+func (s *DistanceStats) ApplyRate() float64 {
+	if s.TotalDecisions == 0 {
+		return 0
+	}
+	return float64(s.AppliedCount) / float64(s.TotalDecisions) * 100.0
+}
+
+// ApplyRateCI returns the Wilson score interval (as percentages) for this
+// bucket's apply rate - the standard small-sample correction to a plain
+// normal-approximation CI, which can extend past [0,1] or badly understate
+// uncertainty when a bucket has few decisions.
+func (s *DistanceStats) ApplyRateCI() (center, halfWidth float64) {
+	center, halfWidth = wilsonInterval(s.AppliedCount, s.TotalDecisions, zScore95)
+	return center * 100, halfWidth * 100
+}
+
+// MeanConfidenceCI returns the t-based CI half-width (in the same units as
+// MeanConfidence) for this bucket's mean winner confidence.
+func (s *DistanceStats) MeanConfidenceCI() float64 {
+	return tConfidenceInterval(s.ConfStdDev, len(s.Confidences))
+}
+
+// Comparison is the hypothesis-test comparison of two DistanceStats buckets
+// (e.g. distance-1 vs distance-3): whether an observed apply-rate or
+// confidence gap between them is likely real or just sampling noise.
+type Comparison struct {
+	ApplyRateDelta float64
+	ApplyRateZ     float64
+	ApplyRateP     float64
+	ConfDelta      float64
+	ConfT          float64
+	ConfDF         float64
+	ConfP          float64
+}
+
+// Compare runs a two-proportion z-test on apply rate and a Welch t-test on
+// mean confidence between a and b.
+func Compare(a, b *DistanceStats) Comparison {
+	applyRateZ := twoProportionZTest(a.AppliedCount, a.TotalDecisions, b.AppliedCount, b.TotalDecisions)
+	confT, confDF := welchTTest(a.MeanConfidence, a.ConfStdDev, len(a.Confidences), b.MeanConfidence, b.ConfStdDev, len(b.Confidences))
+	return Comparison{
+		ApplyRateDelta: a.ApplyRate() - b.ApplyRate(),
+		ApplyRateZ:     applyRateZ,
+		ApplyRateP:     normalTwoTailedP(applyRateZ),
+		ConfDelta:      a.MeanConfidence - b.MeanConfidence,
+		ConfT:          confT,
+		ConfDF:         confDF,
+		ConfP:          tTwoTailedP(confT, confDF),
+	}
+}
+
+// ApplyRateSignificant reports whether the apply-rate gap is statistically
+// significant (p<0.05) and exceeds effectThreshold percentage points.
+func (c Comparison) ApplyRateSignificant(effectThreshold float64) bool {
+	return c.ApplyRateP < 0.05 && c.ApplyRateDelta > effectThreshold
+}
+
+// ConfidenceWellCalibrated reports whether the confidence gap is
+// statistically significant (p<0.05) and stays under effectThreshold
+// percentage points.
+func (c Comparison) ConfidenceWellCalibrated(effectThreshold float64) bool {
+	return c.ConfP < 0.05 && c.ConfDelta < effectThreshold
+}
+
+// wilsonInterval returns the center and half-width of a Wilson score
+// interval for the apply rate p̂ = successes/n. n=0 returns a zero-width
+// interval at 0.
+func wilsonInterval(successes, n int, z float64) (center, halfWidth float64) {
+	if n == 0 {
+		return 0, 0
+	}
+	phat := float64(successes) / float64(n)
+	nf := float64(n)
+	z2 := z * z
+	denom := 1 + z2/nf
+	center = (phat + z2/(2*nf)) / denom
+	halfWidth = z * math.Sqrt(phat*(1-phat)/nf+z2/(4*nf*nf)) / denom
+	return center, halfWidth
+}
+
+// sampleMeanStdDev returns the sample standard deviation of values around
+// mean (Bessel's correction, n-1 denominator). Fewer than 2 values yields
+// 0, since a single observation has no estimate of spread.
+func sampleMeanStdDev(values []int, mean float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sumSq float64
+	for _, v := range values {
+		d := float64(v) - mean
+		sumSq += d * d
+	}
+	return math.Sqrt(sumSq / float64(len(values)-1))
+}
+
+// tConfidenceInterval returns the half-width of a t-based CI for a mean
+// (mean ± t·s/√n), using tCritical for the exact 97.5th-percentile t value
+// at n-1 degrees of freedom rather than the normal approximation, since
+// distance buckets can be small enough that the difference matters.
+func tConfidenceInterval(s float64, n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	df := float64(n - 1)
+	return tCritical(df, 0.05) * s / math.Sqrt(float64(n))
+}
+
+// twoProportionZTest returns the z statistic for H0: p1 == p2, using the
+// pooled proportion: z = (p̂1-p̂2)/√(p̂(1-p̂)(1/n1+1/n2)).
+func twoProportionZTest(successes1, n1, successes2, n2 int) float64 {
+	if n1 == 0 || n2 == 0 {
+		return 0
+	}
+	p1 := float64(successes1) / float64(n1)
+	p2 := float64(successes2) / float64(n2)
+	pooled := float64(successes1+successes2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0
+	}
+	return (p1 - p2) / se
+}
+
+// normalTwoTailedP returns the two-tailed p-value for a z statistic under
+// the standard normal distribution, via the complementary error function
+// (erfc(|z|/√2) is exactly 2·(1-Φ(|z|))).
+func normalTwoTailedP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// welchTTest returns the t statistic and Welch-Satterthwaite degrees of
+// freedom for two independent samples with unequal variance, given each
+// sample's mean, standard deviation, and size. If both samples have zero
+// variance (e.g. every observation in each bucket is identical), the
+// Welch-Satterthwaite formula is undefined (0/0); in that case any nonzero
+// mean difference is reported as infinitely significant (t = ±Inf) rather
+// than falling back to t=0, which would read as "no difference" for a
+// perfectly separated pair of buckets.
+func welchTTest(mean1, sd1 float64, n1 int, mean2, sd2 float64, n2 int) (t, df float64) {
+	if n1 < 2 || n2 < 2 {
+		return 0, 0
+	}
+	v1 := sd1 * sd1 / float64(n1)
+	v2 := sd2 * sd2 / float64(n2)
+	se := math.Sqrt(v1 + v2)
+	if se == 0 {
+		if mean1 == mean2 {
+			return 0, 0
+		}
+		df = float64(n1 + n2 - 2)
+		if mean1 > mean2 {
+			return math.Inf(1), df
+		}
+		return math.Inf(-1), df
+	}
+	t = (mean1 - mean2) / se
+	df = (v1 + v2) * (v1 + v2) / (v1*v1/float64(n1-1) + v2*v2/float64(n2-1))
+	return t, df
+}
+
+// tTwoTailedP returns the two-tailed p-value for a t statistic at the given
+// degrees of freedom: P(|T|>=|t|) = I_x(df/2, 1/2) with x = df/(df+t²),
+// the standard incomplete-beta form of the Student's t survival function.
+func tTwoTailedP(t, df float64) float64 {
+	if df <= 0 {
+		return 1
+	}
+	x := df / (df + t*t)
+	return regularizedIncompleteBeta(x, df/2, 0.5)
+}
+
+// tCritical returns the two-sided critical value t* such that
+// tTwoTailedP(t*, df) == alpha, found by bisection since tTwoTailedP is
+// monotonically decreasing in |t| - there's no closed form for the
+// Student's t quantile function, and a bisection search avoids pulling in
+// a full stats library for one lookup.
+func tCritical(df, alpha float64) float64 {
+	if df <= 0 {
+		return zScore95
+	}
+	lo, hi := 0.0, 1000.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if tTwoTailedP(mid, df) > alpha {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// regularizedIncompleteBeta computes I_x(a, b) via the continued-fraction
+// expansion in Numerical Recipes §6.4, using the symmetry relation
+// I_x(a,b) = 1 - I_(1-x)(b,a) to keep the continued fraction in its
+// fast-converging regime.
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lgA, _ := math.Lgamma(a)
+	lgB, _ := math.Lgamma(b)
+	lgAB, _ := math.Lgamma(a + b)
+	lnBeta := lgA + lgB - lgAB
+	front := math.Exp(a*math.Log(x) + b*math.Log(1-x) - lnBeta)
+
+	if x < (a+1)/(a+b+2) {
+		return front * betacf(x, a, b) / a
+	}
+	return 1 - front*betacf(1-x, b, a)/b
+}
+
+// betacf evaluates the continued fraction used by regularizedIncompleteBeta
+// via Lentz's algorithm.
+func betacf(x, a, b float64) float64 {
+	const maxIter = 200
+	const minDouble = 1e-30
+	const epsilon = 3e-12
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < minDouble {
+		d = minDouble
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIter; m++ {
+		m2 := float64(2 * m)
+
+		aa := float64(m) * (b - float64(m)) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < minDouble {
+			d = minDouble
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < minDouble {
+			c = minDouble
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + float64(m)) * (qab + float64(m)) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < minDouble {
+			d = minDouble
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < minDouble {
+			c = minDouble
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,355 @@
+package feedback
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dxcluster/decisionlog"
+)
+
+// DecisionSource is the read path Engine needs from a decision log: every
+// decision logged at or after since, capped at limit rows. *decisionlog.
+// Store's LoadSince method satisfies this; it's defined here as an
+// interface so tests can supply a fake without a real database.
+type DecisionSource interface {
+	LoadSince(since time.Time, limit int) ([]decisionlog.Decision, error)
+}
+
+// EngineConfig controls the online feedback loop: how often and over what
+// window it re-aggregates recent decisions, how it scales back on a busy
+// node, and whether it only reports suggestions or applies them directly.
+type EngineConfig struct {
+	// Window is how far back from "now" each tick's aggregation looks.
+	Window time.Duration
+	// Interval is how often the loop wakes up to re-aggregate.
+	Interval time.Duration
+	// SampleProbability is the chance (0-1) that any given tick actually
+	// runs the aggregation pass, so a busy node can configure this below 1
+	// to bound the load a continuous feedback loop adds on top of live spot
+	// processing. 0 or >=1 always runs.
+	SampleProbability float64
+	// QueryLimit caps how many decisions a single tick's LoadSince call
+	// returns, so a burst of logged decisions can't make one tick scan an
+	// unbounded result set.
+	QueryLimit int
+
+	Recommend RecommendConfig
+
+	// ReportPath, if set, is the directory rotating JSON suggestion reports
+	// are written to for an operator to review by hand.
+	ReportPath string
+	// ReportHistory is how many rotating report files are kept in
+	// ReportPath; older ones are deleted.
+	ReportHistory int
+
+	// AutoTune, if true, applies bounded step changes to Current() directly
+	// instead of (or alongside, if ReportPath is also set) only reporting
+	// them.
+	AutoTune bool
+	// MaxStepPerTick bounds how far any single Adjustment can move a
+	// Thresholds field in one tick, regardless of what Recommend suggested.
+	MaxStepPerTick int
+	// MaxChangePerHour bounds the total |delta| a field may move within a
+	// trailing hour, so a noisy run of ticks can't walk a threshold far from
+	// its operator-configured starting point unattended.
+	MaxChangePerHour int
+	// Hysteresis is how long after changing a field Engine refuses to move
+	// it back the other way - the same reversal guard
+	// callcorr.Settings.CooldownWindow applies to individual corrections,
+	// applied here to the tuning loop itself so it can't thrash a threshold
+	// back and forth every tick.
+	Hysteresis time.Duration
+}
+
+func (c EngineConfig) normalize() EngineConfig {
+	if c.Window <= 0 {
+		c.Window = 24 * time.Hour
+	}
+	if c.Interval <= 0 {
+		c.Interval = 15 * time.Minute
+	}
+	if c.QueryLimit <= 0 {
+		c.QueryLimit = 50000
+	}
+	if c.ReportHistory <= 0 {
+		c.ReportHistory = 24
+	}
+	if c.MaxStepPerTick <= 0 {
+		c.MaxStepPerTick = 1
+	}
+	if c.MaxChangePerHour <= 0 {
+		c.MaxChangePerHour = 2
+	}
+	if c.Hysteresis <= 0 {
+		c.Hysteresis = time.Hour
+	}
+	return c
+}
+
+// fieldChange records one applied step adjustment to a Thresholds field, so
+// Engine can enforce MaxChangePerHour and Hysteresis against its own recent
+// history.
+type fieldChange struct {
+	at    time.Time
+	delta int
+}
+
+// Engine periodically re-aggregates a sliding window of recent decisions
+// and either writes suggested Thresholds adjustments to ReportPath or, with
+// AutoTune, applies bounded step changes to its own in-memory Thresholds
+// directly - the same Aggregate/Recommend functions analyze1c uses offline,
+// run continuously instead of once by hand.
+type Engine struct {
+	cfg    EngineConfig
+	source DecisionSource
+
+	mu      sync.Mutex
+	current Thresholds
+	history map[string][]fieldChange
+
+	quit chan struct{}
+}
+
+// NewEngine constructs an Engine seeded with initial Thresholds. source may
+// be a *decisionlog.Store or a fake satisfying DecisionSource.
+func NewEngine(source DecisionSource, initial Thresholds, cfg EngineConfig) *Engine {
+	return &Engine{
+		cfg:     cfg.normalize(),
+		source:  source,
+		current: initial,
+		history: make(map[string][]fieldChange),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Current returns the engine's live Thresholds - the operator-configured
+// starting point if AutoTune is off, or the latest auto-tuned values if on.
+func (e *Engine) Current() Thresholds {
+	if e == nil {
+		return Thresholds{}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.current
+}
+
+// Start begins the periodic aggregation loop in a background goroutine.
+func (e *Engine) Start() {
+	if e == nil {
+		return
+	}
+	go e.loop()
+}
+
+// Stop ends the periodic aggregation loop.
+func (e *Engine) Stop() {
+	if e == nil {
+		return
+	}
+	close(e.quit)
+}
+
+func (e *Engine) loop() {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.quit:
+			return
+		case now := <-ticker.C:
+			e.tick(now)
+		}
+	}
+}
+
+// tick re-aggregates the last Window of decisions and acts on the resulting
+// Recommendation, unless SampleProbability skips this tick outright.
+func (e *Engine) tick(now time.Time) {
+	if e.cfg.SampleProbability > 0 && e.cfg.SampleProbability < 1 && rand.Float64() >= e.cfg.SampleProbability {
+		return
+	}
+
+	decisions, err := e.source.LoadSince(now.Add(-e.cfg.Window), e.cfg.QueryLimit)
+	if err != nil {
+		log.Printf("callcorr/feedback: loading recent decisions: %v", err)
+		return
+	}
+
+	rec := Recommend(Aggregate(decisions), e.cfg.Recommend)
+
+	if e.cfg.AutoTune {
+		e.applyBounded(rec, now)
+	}
+	if e.cfg.ReportPath != "" {
+		if err := e.writeReport(rec, now); err != nil {
+			log.Printf("callcorr/feedback: writing report: %v", err)
+		}
+	}
+}
+
+// applyBounded applies rec's suggested Adjustments to e.current, clamped to
+// MaxStepPerTick and MaxChangePerHour, and refused per field if it would
+// reverse a change made within Hysteresis.
+func (e *Engine) applyBounded(rec Recommendation, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, adj := range rec.Adjustments {
+		e.pruneHistory(adj.Field, now)
+
+		step := clamp(adj.Delta, e.cfg.MaxStepPerTick)
+		if step == 0 {
+			continue
+		}
+
+		recent := e.history[adj.Field]
+		if len(recent) > 0 {
+			last := recent[len(recent)-1]
+			if (last.delta > 0) != (step > 0) && now.Sub(last.at) < e.cfg.Hysteresis {
+				continue
+			}
+		}
+
+		hourCutoff := now.Add(-time.Hour)
+		spent := 0
+		for _, c := range recent {
+			if c.at.After(hourCutoff) {
+				spent += abs(c.delta)
+			}
+		}
+		if spent+abs(step) > e.cfg.MaxChangePerHour {
+			continue
+		}
+
+		e.setField(adj.Field, step)
+		e.history[adj.Field] = append(e.history[adj.Field], fieldChange{at: now, delta: step})
+	}
+}
+
+// pruneHistory drops history entries for field older than the longer of
+// Hysteresis or one hour - MaxChangePerHour only sums entries within the
+// trailing hour itself (applyBounded filters that window explicitly), but
+// a configured Hysteresis longer than an hour still needs its most recent
+// entry to survive long enough for the reversal check above to see it.
+func (e *Engine) pruneHistory(field string, now time.Time) {
+	retain := e.cfg.Hysteresis
+	if retain < time.Hour {
+		retain = time.Hour
+	}
+	cutoff := now.Add(-retain)
+	kept := e.history[field][:0]
+	for _, c := range e.history[field] {
+		if c.at.After(cutoff) {
+			kept = append(kept, c)
+		}
+	}
+	e.history[field] = kept
+}
+
+// setField applies step to the named Thresholds field, clamped to the range
+// that field can actually take without disabling the gate it tunes (e.g. a
+// consensus-report count can't usefully go to zero, a confidence percentage
+// can't leave [0,100]) - MaxChangePerHour only bounds how fast a field
+// moves, not the lifetime value it can drift to, so this floor/ceiling is
+// the backstop against a sustained run of ticks walking it out of range.
+func (e *Engine) setField(field string, step int) {
+	switch field {
+	case "MinConsensusReports":
+		e.current.MinConsensusReports = clampRange(e.current.MinConsensusReports+step, 1, 100)
+	case "MinAdvantage":
+		e.current.MinAdvantage = clampRange(e.current.MinAdvantage+step, 0, 100)
+	case "MinConfidencePercent":
+		e.current.MinConfidencePercent = clampRange(e.current.MinConfidencePercent+step, 0, 100)
+	case "Distance3ExtraConfidence":
+		e.current.Distance3ExtraConfidence = clampRange(e.current.Distance3ExtraConfidence+step, 0, 100)
+	}
+}
+
+func clampRange(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// report is the JSON shape written to ReportPath on every tick.
+type report struct {
+	GeneratedAt    time.Time      `json:"generated_at"`
+	Current        Thresholds     `json:"current_thresholds"`
+	Recommendation Recommendation `json:"recommendation"`
+}
+
+// writeReport writes rec as a rotating JSON file under ReportPath, then
+// prunes older ones beyond ReportHistory.
+func (e *Engine) writeReport(rec Recommendation, now time.Time) error {
+	if err := os.MkdirAll(e.cfg.ReportPath, 0o755); err != nil {
+		return fmt.Errorf("callcorr/feedback: mkdir %s: %w", e.cfg.ReportPath, err)
+	}
+	payload, err := json.MarshalIndent(report{
+		GeneratedAt:    now,
+		Current:        e.Current(),
+		Recommendation: rec,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("callcorr/feedback: marshal report: %w", err)
+	}
+
+	name := fmt.Sprintf("feedback-%s.json", now.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(e.cfg.ReportPath, name)
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		return fmt.Errorf("callcorr/feedback: write report: %w", err)
+	}
+	return e.rotateReports()
+}
+
+// rotateReports deletes the oldest feedback-*.json files in ReportPath
+// beyond ReportHistory, keeping the most recent ones by filename (which
+// sorts chronologically since the timestamp format is fixed-width).
+func (e *Engine) rotateReports() error {
+	entries, err := os.ReadDir(e.cfg.ReportPath)
+	if err != nil {
+		return fmt.Errorf("callcorr/feedback: read report dir: %w", err)
+	}
+	var names []string
+	for _, ent := range entries {
+		if !ent.IsDir() && strings.HasPrefix(ent.Name(), "feedback-") && strings.HasSuffix(ent.Name(), ".json") {
+			names = append(names, ent.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > e.cfg.ReportHistory {
+		if err := os.Remove(filepath.Join(e.cfg.ReportPath, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+func clamp(v, maxAbs int) int {
+	if v > maxAbs {
+		return maxAbs
+	}
+	if v < -maxAbs {
+		return -maxAbs
+	}
+	return v
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
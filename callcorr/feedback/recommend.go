@@ -0,0 +1,114 @@
+package feedback
+
+// Thresholds is the set of call-correction tunables analyze1c and Engine
+// both reason about: the gates currently hand-edited in
+// data/config/pipeline.yaml when an operator acts on analyze1c's report.
+type Thresholds struct {
+	MinConsensusReports      int
+	MinAdvantage             int
+	MinConfidencePercent     int
+	Distance3ExtraConfidence int
+}
+
+// RecommendConfig bounds how aggressive Recommend's suggested adjustments
+// are, independent of the current Thresholds values.
+type RecommendConfig struct {
+	// LowApplyRate and HighApplyRate are the overall apply-rate percentages
+	// (across every distance) below/above which Recommend suggests
+	// loosening/tightening the consensus gates.
+	LowApplyRate  float64
+	HighApplyRate float64
+	// ApplyRateEffectThreshold and ConfEffectThreshold gate the distance-1
+	// vs distance-3 comparison the same way analyze1c's CLI flags do: an
+	// adjustment is only suggested when the gap is both statistically
+	// significant (p<0.05) and larger than this many percentage points.
+	ApplyRateEffectThreshold float64
+	ConfEffectThreshold      float64
+}
+
+func (c RecommendConfig) normalize() RecommendConfig {
+	if c.LowApplyRate <= 0 {
+		c.LowApplyRate = 30.0
+	}
+	if c.HighApplyRate <= 0 {
+		c.HighApplyRate = 60.0
+	}
+	if c.ApplyRateEffectThreshold <= 0 {
+		c.ApplyRateEffectThreshold = 30.0
+	}
+	if c.ConfEffectThreshold <= 0 {
+		c.ConfEffectThreshold = 10.0
+	}
+	return c
+}
+
+// Adjustment is one suggested step change to a Thresholds field, named by
+// its Thresholds field name (e.g. "MinConsensusReports"), with the
+// reasoning that produced it.
+type Adjustment struct {
+	Field  string
+	Delta  int
+	Reason string
+}
+
+// Recommendation is Recommend's full output.
+type Recommendation struct {
+	OverallApplyRate float64
+	// Comparison is the distance-1 vs distance-3 hypothesis test, nil if
+	// either bucket has too few applied decisions to compare.
+	Comparison  *Comparison
+	Adjustments []Adjustment
+}
+
+// Recommend derives suggested Thresholds adjustments from byDistance, the
+// same aggregation analyze1c reports on, so a human running analyze1c by
+// hand and Engine's continuous loop reach the same conclusions from the
+// same data. It never returns a Distance3ExtraConfidence adjustment in the
+// tightening direction - a lower distance-3 apply rate than distance-1 only
+// ever argues for loosening that gate, never raising it further.
+func Recommend(byDistance map[int]*DistanceStats, cfg RecommendConfig) Recommendation {
+	cfg = cfg.normalize()
+
+	var totalApplied, totalDecisions int
+	for _, s := range byDistance {
+		totalApplied += s.AppliedCount
+		totalDecisions += s.TotalDecisions
+	}
+	var overallApplyRate float64
+	if totalDecisions > 0 {
+		overallApplyRate = float64(totalApplied) / float64(totalDecisions) * 100.0
+	}
+
+	rec := Recommendation{OverallApplyRate: overallApplyRate}
+
+	switch {
+	case totalDecisions > 0 && overallApplyRate < cfg.LowApplyRate:
+		rec.Adjustments = append(rec.Adjustments,
+			Adjustment{Field: "MinConsensusReports", Delta: -1, Reason: "low overall apply rate"},
+			Adjustment{Field: "MinAdvantage", Delta: -1, Reason: "low overall apply rate"},
+			Adjustment{Field: "MinConfidencePercent", Delta: -5, Reason: "low overall apply rate"},
+		)
+	case totalDecisions > 0 && overallApplyRate > cfg.HighApplyRate:
+		rec.Adjustments = append(rec.Adjustments,
+			Adjustment{Field: "MinConsensusReports", Delta: 1, Reason: "high overall apply rate"},
+			Adjustment{Field: "MinAdvantage", Delta: 1, Reason: "high overall apply rate"},
+			Adjustment{Field: "MinConfidencePercent", Delta: 5, Reason: "high overall apply rate"},
+		)
+	}
+
+	stats1, ok1 := byDistance[1]
+	stats3, ok3 := byDistance[3]
+	if ok1 && ok3 && len(stats1.Confidences) > 1 && len(stats3.Confidences) > 1 {
+		cmp := Compare(stats1, stats3)
+		rec.Comparison = &cmp
+		if cmp.ApplyRateSignificant(cfg.ApplyRateEffectThreshold) {
+			rec.Adjustments = append(rec.Adjustments, Adjustment{
+				Field:  "Distance3ExtraConfidence",
+				Delta:  -1,
+				Reason: "distance-3 apply rate significantly lower than distance-1",
+			})
+		}
+	}
+
+	return rec
+}
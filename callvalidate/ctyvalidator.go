@@ -0,0 +1,39 @@
+package callvalidate
+
+import "dxcluster/cty"
+
+// CTYValidator scores a callsign against a loaded CTY database. Unlike
+// MasterSCP, "known" here means "resolves to some prefix entry" rather
+// than "was seen operating" - CTY has no concept of an unissued callsign
+// within a valid prefix block, so CTYValidator is normally paired with
+// MasterSCP rather than used alone.
+type CTYValidator struct {
+	db *cty.CTYDatabase
+}
+
+// NewCTYValidator wraps an already-loaded CTY database.
+func NewCTYValidator(db *cty.CTYDatabase) *CTYValidator {
+	return &CTYValidator{db: db}
+}
+
+// Score implements Validator. dxcc is the resolved entity's ADIF number.
+func (v *CTYValidator) Score(call string) (known bool, dxcc int, source string) {
+	info, ok := v.Lookup(call)
+	if !ok {
+		return false, 0, "cty"
+	}
+	return true, info.ADIF, "cty"
+}
+
+// Lookup exposes the full resolved PrefixInfo for callers (e.g. analyze1b's
+// frequency-consistency check) that need more than Score's summary.
+func (v *CTYValidator) Lookup(call string) (cty.PrefixInfo, bool) {
+	if v == nil || v.db == nil {
+		return cty.PrefixInfo{}, false
+	}
+	info, ok := v.db.LookupCallsign(call)
+	if !ok {
+		return cty.PrefixInfo{}, false
+	}
+	return *info, true
+}
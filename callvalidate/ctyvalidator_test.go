@@ -0,0 +1,71 @@
+package callvalidate
+
+import (
+	"strings"
+	"testing"
+
+	"dxcluster/cty"
+)
+
+const sampleCTYPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+<key>K1ABC</key>
+	<dict>
+		<key>Country</key>
+		<string>Alpha</string>
+		<key>Prefix</key>
+		<string>K1ABC</string>
+		<key>ADIF</key>
+		<integer>291</integer>
+		<key>ExactCallsign</key>
+		<true/>
+	</dict>
+</dict>
+</plist>`
+
+func TestCTYValidatorScoresKnownCall(t *testing.T) {
+	db, err := cty.LoadCTYDatabaseFromReader(strings.NewReader(sampleCTYPlist))
+	if err != nil {
+		t.Fatalf("LoadCTYDatabaseFromReader: %v", err)
+	}
+	v := NewCTYValidator(db)
+
+	known, dxcc, source := v.Score("K1ABC")
+	if !known || dxcc != 291 || source != "cty" {
+		t.Fatalf("unexpected score: known=%v dxcc=%d source=%s", known, dxcc, source)
+	}
+
+	known, _, _ = v.Score("ZZ9ZZZ")
+	if known {
+		t.Fatalf("expected an unresolvable call to score unknown")
+	}
+}
+
+func TestMultiValidatorCombinesSources(t *testing.T) {
+	db, err := cty.LoadCTYDatabaseFromReader(strings.NewReader(sampleCTYPlist))
+	if err != nil {
+		t.Fatalf("LoadCTYDatabaseFromReader: %v", err)
+	}
+	scp, err := LoadMasterSCPFromReader(strings.NewReader("W9XYZ\n"))
+	if err != nil {
+		t.Fatalf("LoadMasterSCPFromReader: %v", err)
+	}
+
+	multi := NewMultiValidator(scp, NewCTYValidator(db))
+
+	known, dxcc, _ := multi.Score("K1ABC")
+	if !known || dxcc != 291 {
+		t.Fatalf("expected CTY to resolve K1ABC's DXCC, got known=%v dxcc=%d", known, dxcc)
+	}
+
+	known, dxcc, _ = multi.Score("W9XYZ")
+	if !known || dxcc != 0 {
+		t.Fatalf("expected MASTER.SCP-only match with no DXCC, got known=%v dxcc=%d", known, dxcc)
+	}
+
+	known, _, _ = multi.Score("ZZ9ZZZ")
+	if known {
+		t.Fatalf("expected an unknown call to score unknown across all sources")
+	}
+}
@@ -0,0 +1,62 @@
+package callvalidate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MasterSCP is a known-callsign list loaded from a MASTER.SCP file (the
+// line-oriented format shared by N1MM, Win-Test, and other contest loggers
+// for callsign auto-completion). It answers "is this a real, previously
+// logged callsign" but has no geographic data, so Score always reports
+// dxcc 0.
+type MasterSCP struct {
+	calls map[string]struct{}
+}
+
+// LoadMasterSCP reads a MASTER.SCP file: one callsign per line, blank lines
+// ignored, lines starting with ";" treated as comments.
+func LoadMasterSCP(path string) (*MasterSCP, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("callvalidate: open master.scp: %w", err)
+	}
+	defer f.Close()
+	return LoadMasterSCPFromReader(f)
+}
+
+// LoadMasterSCPFromReader parses MASTER.SCP data from an already-open
+// reader (exposed for testing without a file on disk).
+func LoadMasterSCPFromReader(r io.Reader) (*MasterSCP, error) {
+	calls := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		calls[normalizeCall(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("callvalidate: scan master.scp: %w", err)
+	}
+	return &MasterSCP{calls: calls}, nil
+}
+
+// Contains reports whether call appears verbatim in the loaded list.
+func (m *MasterSCP) Contains(call string) bool {
+	if m == nil {
+		return false
+	}
+	_, ok := m.calls[normalizeCall(call)]
+	return ok
+}
+
+// Score implements Validator. MASTER.SCP carries no DXCC/geographic data,
+// so dxcc is always 0.
+func (m *MasterSCP) Score(call string) (known bool, dxcc int, source string) {
+	return m.Contains(call), 0, "master.scp"
+}
@@ -0,0 +1,36 @@
+package callvalidate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMasterSCPContainsKnownCalls(t *testing.T) {
+	data := "; MASTER.SCP sample\nK1ABC\nw9xyz\n\nG0ABC\n"
+	m, err := LoadMasterSCPFromReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadMasterSCPFromReader: %v", err)
+	}
+
+	if !m.Contains("K1ABC") {
+		t.Fatalf("expected K1ABC to be known")
+	}
+	if !m.Contains("w9xyz") {
+		t.Fatalf("expected lowercase input to normalize and match")
+	}
+	if m.Contains("ZZ1ZZZ") {
+		t.Fatalf("expected an unlisted call to be unknown")
+	}
+}
+
+func TestMasterSCPScoreHasNoDXCC(t *testing.T) {
+	m, err := LoadMasterSCPFromReader(strings.NewReader("K1ABC\n"))
+	if err != nil {
+		t.Fatalf("LoadMasterSCPFromReader: %v", err)
+	}
+
+	known, dxcc, source := m.Score("K1ABC")
+	if !known || dxcc != 0 || source != "master.scp" {
+		t.Fatalf("unexpected score: known=%v dxcc=%d source=%s", known, dxcc, source)
+	}
+}
@@ -0,0 +1,40 @@
+package callvalidate
+
+// MultiValidator combines several Validators into one: known is true if
+// any of them recognizes the call, and dxcc is taken from the first
+// Validator (in order) that resolves one, since CTY is the only source
+// that usually carries geographic data and is expected to be listed last
+// as the geographic fallback after the known-callsign sources.
+type MultiValidator struct {
+	validators []Validator
+}
+
+// NewMultiValidator builds a MultiValidator trying each validator in order.
+func NewMultiValidator(validators ...Validator) *MultiValidator {
+	return &MultiValidator{validators: validators}
+}
+
+// Score implements Validator.
+func (m *MultiValidator) Score(call string) (known bool, dxcc int, source string) {
+	if m == nil {
+		return false, 0, ""
+	}
+	var sources []string
+	for _, v := range m.validators {
+		if v == nil {
+			continue
+		}
+		k, d, src := v.Score(call)
+		if k {
+			known = true
+			sources = append(sources, src)
+			if dxcc == 0 && d != 0 {
+				dxcc = d
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return false, 0, ""
+	}
+	return known, dxcc, sources[0]
+}
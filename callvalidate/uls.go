@@ -0,0 +1,54 @@
+package callvalidate
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// ULSValidator scores a callsign against an FCC ULS amateur license import.
+// The FCC publishes ULS as a set of pipe-delimited dumps (EN.dat, HD.dat,
+// AM.dat); this validator doesn't parse those directly - it expects them to
+// already be imported into a SQLite database with a single
+// `amateur_licenses(call_sign text primary key)` table, which is the
+// minimal shape every import script in this repo's tooling can target.
+// Optional: a nil *ULSValidator (no ULS database configured) always scores
+// unknown, so callers can wire it in unconditionally.
+type ULSValidator struct {
+	db *sql.DB
+}
+
+// OpenULSValidator opens an existing ULS import database. The validator
+// only ever queries it; the connection itself is not opened in read-only
+// mode.
+func OpenULSValidator(dbPath string) (*ULSValidator, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("callvalidate: open uls db: %w", err)
+	}
+	return &ULSValidator{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (v *ULSValidator) Close() error {
+	if v == nil || v.db == nil {
+		return nil
+	}
+	return v.db.Close()
+}
+
+// Score implements Validator. ULS DB is US-only and DXCC 291 (United
+// States) for any match; dxcc is 0 for any non-US callsign, which will
+// simply never appear in the table.
+func (v *ULSValidator) Score(call string) (known bool, dxcc int, source string) {
+	if v == nil || v.db == nil {
+		return false, 0, "uls"
+	}
+	var exists int
+	err := v.db.QueryRow(`select 1 from amateur_licenses where call_sign = ? limit 1`, normalizeCall(call)).Scan(&exists)
+	if err != nil {
+		return false, 0, "uls"
+	}
+	return true, 291, "uls"
+}
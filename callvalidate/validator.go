@@ -0,0 +1,21 @@
+// Package callvalidate cross-references callsigns against reference
+// databases (MASTER.SCP, CTY, FCC ULS) so analysis tools can tell whether a
+// call correction's winner and subject are known, licensed stations rather
+// than just a plausible-looking string.
+package callvalidate
+
+import "strings"
+
+// Validator scores a single callsign against one reference source.
+type Validator interface {
+	// Score reports whether call is known to this source, the DXCC entity
+	// number (ADIF) it resolves to if the source can determine one (0
+	// otherwise), and a short label identifying the source that answered.
+	Score(call string) (known bool, dxcc int, source string)
+}
+
+// normalizeCall upper-cases and trims a callsign the same way every
+// Validator implementation expects its input.
+func normalizeCall(call string) string {
+	return strings.ToUpper(strings.TrimSpace(call))
+}
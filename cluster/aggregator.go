@@ -0,0 +1,403 @@
+// Package cluster fans in spots from one or more upstream feeds - RBN
+// telnet/digital, arbitrary DX cluster nodes, PSK Reporter, HamAlert-style
+// feeds, and (via UpstreamClient) whatever comes next - into a single
+// deduplicated stream. Sightings of the same event from multiple upstreams
+// within a short window are merged into one AggregatedSpot carrying full
+// cross-source provenance, rather than forwarded as independent spots or
+// silently dropped.
+package cluster
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dxcluster/spot"
+)
+
+// UpstreamClient is the subset of rbn.Client's and dxclusterclient.Client's
+// API the Aggregator needs to fan a feed in. Any feed - including ones that
+// don't exist yet, like SOTA, POTA, or WSPR Live - becomes usable by the
+// Aggregator just by implementing this, without the dedup/merge logic ever
+// needing to know about its wire format.
+type UpstreamClient interface {
+	Connect() error
+	Stop()
+	IsConnected() bool
+	GetSpotChannel() <-chan *spot.Spot
+}
+
+// Source configures one upstream feed: the client itself, the name recorded
+// in AggregatedSpot.SourceNodes, and an optional per-source rate limit.
+type Source struct {
+	// Name identifies this feed in AggregatedSpot.SourceNodes and in Stats,
+	// e.g. "RBN", "RBN-DIGITAL", "W3LPL-CLUSTER", "PSKReporter". Must be
+	// unique across the Sources passed to NewAggregator - two Sources
+	// sharing a Name alias the same rate limiter and Stats counters.
+	Name   string
+	Client UpstreamClient
+
+	// RateLimit caps how many spots per second this source may contribute;
+	// zero (the default) means unlimited. Spots beyond the limit are
+	// dropped and counted in Stats.DroppedByRateLimit, guarding against one
+	// noisy feed starving the others downstream of the Aggregator.
+	RateLimit float64
+}
+
+// Config controls how the Aggregator keys and merges duplicate sightings.
+type Config struct {
+	// DedupWindow is how long after a spot's first sighting the Aggregator
+	// waits before forwarding it, so a matching spot from another (or the
+	// same) source arriving within the window is merged in rather than
+	// forwarded as a separate AggregatedSpot. Zero disables merging: every
+	// spot is forwarded immediately as its own AggregatedSpot.
+	DedupWindow time.Duration
+
+	// FreqRoundingHz rounds frequency to the nearest multiple of this many
+	// Hz before keying, absorbing the kHz-vs-Hz rounding noise between
+	// feeds (skimmer decode jitter, AGC timing, etc.) that would otherwise
+	// key the same signal as several distinct events. Zero means round to
+	// the nearest 100 Hz.
+	FreqRoundingHz float64
+
+	// OutputBufferSize sizes the channel returned by Spots. Zero falls back
+	// to defaultOutputBufferSize, matching rbn.NewClient's legacy default.
+	OutputBufferSize int
+}
+
+const defaultOutputBufferSize = 100
+
+// AggregatedSpot is one deduplicated event, merged from one or more upstream
+// sightings of the same dedupKey within Config.DedupWindow. spot.Spot itself
+// carries a single SourceNode string, which is enough for one feed but not
+// for a cross-source merge, so AggregatedSpot embeds the first sighting's
+// *spot.Spot unmodified - so existing code written against spot.Spot's
+// fields keeps working through promotion - and layers cross-source
+// provenance on top rather than widening the shared struct.
+type AggregatedSpot struct {
+	*spot.Spot
+
+	// SourceNodes lists every Source.Name that reported this event within
+	// the dedup window, in the order first seen.
+	SourceNodes []string
+
+	// MinSNR and MedianSNR are computed across every merged sighting that
+	// carried a signal report; both are zero if none did.
+	MinSNR    int
+	MedianSNR int
+
+	// FirstSeen is the earliest Time among the merged sightings. Spot.Time
+	// (via embedding) holds whichever sighting's *spot.Spot AggregatedSpot
+	// was built from - not necessarily the earliest one chronologically,
+	// since a later-arriving sighting can report an earlier Time - so
+	// FirstSeen is the field to trust for "when did this actually happen".
+	FirstSeen time.Time
+}
+
+// Stats is a point-in-time snapshot of the Aggregator's counters, replacing
+// the bare "Spot channel full" log line each upstream client used to emit on
+// its own with per-source, per-reason counts an operator can poll.
+type Stats struct {
+	Received            map[string]uint64
+	DroppedByRateLimit  map[string]uint64
+	Merged              uint64
+	Forwarded           uint64
+	DroppedByOutputFull uint64
+}
+
+// pendingMerge accumulates sightings of one dedup key until its window
+// closes.
+type pendingMerge struct {
+	agg   *AggregatedSpot
+	snrs  []int
+	timer *time.Timer
+}
+
+// Aggregator fans in Sources into a single deduplicated stream of
+// AggregatedSpot. Build one with NewAggregator, then call Start.
+type Aggregator struct {
+	cfg      Config
+	sources  []Source
+	limiters map[string]*rateLimiter
+
+	out      chan *AggregatedSpot
+	closeOut sync.Once
+
+	mu      sync.Mutex
+	pending map[dedupKey]*pendingMerge
+
+	statsMu            sync.Mutex
+	received           map[string]uint64
+	droppedByRateLimit map[string]uint64
+	merged             uint64
+	forwarded          uint64
+	droppedByOutput    uint64
+
+	shutdown chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewAggregator builds an Aggregator over sources. Call Start to connect
+// every source and begin forwarding.
+func NewAggregator(cfg Config, sources ...Source) *Aggregator {
+	bufSize := cfg.OutputBufferSize
+	if bufSize <= 0 {
+		bufSize = defaultOutputBufferSize
+	}
+	limiters := make(map[string]*rateLimiter, len(sources))
+	received := make(map[string]uint64, len(sources))
+	droppedByRateLimit := make(map[string]uint64, len(sources))
+	for _, src := range sources {
+		limiters[src.Name] = newRateLimiter(src.RateLimit)
+		received[src.Name] = 0
+		droppedByRateLimit[src.Name] = 0
+	}
+	return &Aggregator{
+		cfg:                cfg,
+		sources:            sources,
+		limiters:           limiters,
+		out:                make(chan *AggregatedSpot, bufSize),
+		pending:            make(map[dedupKey]*pendingMerge),
+		received:           received,
+		droppedByRateLimit: droppedByRateLimit,
+		shutdown:           make(chan struct{}),
+	}
+}
+
+// Start connects every Source and begins draining its spot channel into the
+// dedup/merge pipeline. It returns the first connect error encountered,
+// having already called Stop to tear down any source it did connect.
+func (a *Aggregator) Start() error {
+	for i, src := range a.sources {
+		if err := src.Client.Connect(); err != nil {
+			a.Stop()
+			return err
+		}
+		a.wg.Add(1)
+		go a.drain(a.sources[i])
+	}
+	return nil
+}
+
+// Spots returns the channel of deduplicated, provenance-merged spots.
+func (a *Aggregator) Spots() <-chan *AggregatedSpot {
+	return a.out
+}
+
+// Stats returns a point-in-time snapshot of the Aggregator's counters.
+func (a *Aggregator) Stats() Stats {
+	a.statsMu.Lock()
+	defer a.statsMu.Unlock()
+	received := make(map[string]uint64, len(a.received))
+	for k, v := range a.received {
+		received[k] = v
+	}
+	dropped := make(map[string]uint64, len(a.droppedByRateLimit))
+	for k, v := range a.droppedByRateLimit {
+		dropped[k] = v
+	}
+	return Stats{
+		Received:            received,
+		DroppedByRateLimit:  dropped,
+		Merged:              a.merged,
+		Forwarded:           a.forwarded,
+		DroppedByOutputFull: a.droppedByOutput,
+	}
+}
+
+// Stop disconnects every source and stops forwarding. It's safe to call more
+// than once and safe to call without a prior Start.
+func (a *Aggregator) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.shutdown)
+		for _, src := range a.sources {
+			src.Client.Stop()
+		}
+	})
+
+	a.mu.Lock()
+	var cancelled []*pendingMerge
+	for key, pm := range a.pending {
+		// If Stop successfully cancels the timer, its func will never run,
+		// so its wg credit (added alongside the timer in ingest) is released
+		// here instead, and we take over forwarding it below so a sighting
+		// that was merely waiting out its DedupWindow isn't silently lost.
+		// If Stop returns false the func already fired or is running and
+		// will forward (or is forwarding) it itself, so a.wg.Wait() below
+		// still blocks close(a.out) until that in-flight flush finishes.
+		if pm.timer.Stop() {
+			a.wg.Done()
+			cancelled = append(cancelled, pm)
+			delete(a.pending, key)
+		}
+		// If Stop returns false, leave the entry in a.pending: the firing
+		// flush(key) call still needs to find it so it can forward it itself
+		// instead of finding it already deleted and returning early.
+	}
+	a.mu.Unlock()
+
+	a.wg.Wait()
+	for _, pm := range cancelled {
+		a.finalizeAndForward(pm)
+	}
+	a.closeOut.Do(func() { close(a.out) })
+}
+
+func (a *Aggregator) drain(src Source) {
+	defer a.wg.Done()
+	limiter := a.limiters[src.Name]
+	for {
+		select {
+		case <-a.shutdown:
+			return
+		case s, ok := <-src.Client.GetSpotChannel():
+			if !ok {
+				return
+			}
+			a.ingest(src.Name, limiter, s)
+		}
+	}
+}
+
+func (a *Aggregator) ingest(sourceName string, limiter *rateLimiter, s *spot.Spot) {
+	a.statsMu.Lock()
+	a.received[sourceName]++
+	a.statsMu.Unlock()
+
+	if !limiter.Allow() {
+		a.statsMu.Lock()
+		a.droppedByRateLimit[sourceName]++
+		a.statsMu.Unlock()
+		log.Printf("cluster: %s exceeded its rate limit, dropping spot for %s", sourceName, s.DXCall)
+		return
+	}
+
+	key := dedupKeyFor(s, a.cfg.FreqRoundingHz)
+	report, hasReport := s.Report, s.HasReport
+
+	a.mu.Lock()
+	if pm, ok := a.pending[key]; ok {
+		mergeSighting(pm, sourceName, s, report, hasReport)
+		a.mu.Unlock()
+		a.statsMu.Lock()
+		a.merged++
+		a.statsMu.Unlock()
+		return
+	}
+
+	pm := &pendingMerge{agg: newAggregatedSpot(sourceName, s)}
+	if hasReport {
+		pm.snrs = append(pm.snrs, report)
+	}
+	window := a.cfg.DedupWindow
+	if window <= 0 {
+		a.mu.Unlock()
+		a.finalizeAndForward(pm)
+		return
+	}
+	a.wg.Add(1)
+	pm.timer = time.AfterFunc(window, func() {
+		defer a.wg.Done()
+		a.flush(key)
+	})
+	a.pending[key] = pm
+	a.mu.Unlock()
+}
+
+func (a *Aggregator) flush(key dedupKey) {
+	a.mu.Lock()
+	pm, ok := a.pending[key]
+	if ok {
+		delete(a.pending, key)
+	}
+	a.mu.Unlock()
+	if !ok {
+		return
+	}
+	a.finalizeAndForward(pm)
+}
+
+func (a *Aggregator) finalizeAndForward(pm *pendingMerge) {
+	finalizeSNR(pm)
+	select {
+	case a.out <- pm.agg:
+		a.statsMu.Lock()
+		a.forwarded++
+		a.statsMu.Unlock()
+	default:
+		a.statsMu.Lock()
+		a.droppedByOutput++
+		a.statsMu.Unlock()
+		log.Printf("cluster: aggregated spot channel full (capacity=%d), dropping spot for %s", cap(a.out), pm.agg.DXCall)
+	}
+}
+
+func finalizeSNR(pm *pendingMerge) {
+	if len(pm.snrs) == 0 {
+		return
+	}
+	sort.Ints(pm.snrs)
+	pm.agg.MinSNR = pm.snrs[0]
+	pm.agg.MedianSNR = pm.snrs[len(pm.snrs)/2]
+}
+
+func newAggregatedSpot(sourceName string, s *spot.Spot) *AggregatedSpot {
+	return &AggregatedSpot{
+		Spot:        s,
+		SourceNodes: []string{sourceName},
+		FirstSeen:   s.Time,
+	}
+}
+
+func mergeSighting(pm *pendingMerge, sourceName string, s *spot.Spot, report int, hasReport bool) {
+	if !containsSourceNode(pm.agg.SourceNodes, sourceName) {
+		pm.agg.SourceNodes = append(pm.agg.SourceNodes, sourceName)
+	}
+	if s.Time.Before(pm.agg.FirstSeen) {
+		pm.agg.FirstSeen = s.Time
+	}
+	if hasReport {
+		pm.snrs = append(pm.snrs, report)
+	}
+}
+
+func containsSourceNode(nodes []string, name string) bool {
+	for _, n := range nodes {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupKey identifies one event across upstreams: the same DX call, band,
+// mode, and frequency, rounded to absorb cross-feed decode jitter. It
+// deliberately has no time component - Config.DedupWindow already bounds how
+// long a pendingMerge stays open waiting for more sightings, so keying on a
+// coarse time bucket too would only risk splitting one event that straddles
+// a bucket boundary (e.g. two sightings 80ms apart but on either side of a
+// minute tick) into separate AggregatedSpots.
+type dedupKey struct {
+	dxCall       string
+	band         string
+	mode         string
+	freqBucketHz int64
+}
+
+func dedupKeyFor(s *spot.Spot, freqRoundingHz float64) dedupKey {
+	if freqRoundingHz <= 0 {
+		freqRoundingHz = 100
+	}
+	freqHz := s.Frequency * 1000
+	rounded := math.Round(freqHz/freqRoundingHz) * freqRoundingHz
+	return dedupKey{
+		dxCall:       strings.ToUpper(strings.TrimSpace(s.DXCall)),
+		band:         strings.ToUpper(strings.TrimSpace(s.Band)),
+		mode:         strings.ToUpper(strings.TrimSpace(s.Mode)),
+		freqBucketHz: int64(rounded),
+	}
+}
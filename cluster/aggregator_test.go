@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"dxcluster/spot"
+)
+
+// fakeUpstream is a minimal UpstreamClient a test can push spots through
+// without a real network connection.
+type fakeUpstream struct {
+	spots chan *spot.Spot
+}
+
+func newFakeUpstream(buffer int) *fakeUpstream {
+	return &fakeUpstream{spots: make(chan *spot.Spot, buffer)}
+}
+
+func (f *fakeUpstream) Connect() error                    { return nil }
+func (f *fakeUpstream) Stop()                             { close(f.spots) }
+func (f *fakeUpstream) IsConnected() bool                 { return true }
+func (f *fakeUpstream) GetSpotChannel() <-chan *spot.Spot { return f.spots }
+
+func testSpot(dxCall string, freq float64, report int, when time.Time) *spot.Spot {
+	return &spot.Spot{
+		DXCall:    dxCall,
+		DECall:    "W1AW",
+		Frequency: freq,
+		Band:      "20m",
+		Mode:      "FT8",
+		Time:      when,
+		Report:    report,
+		HasReport: true,
+	}
+}
+
+func TestAggregatorMergesDuplicateSightingsAcrossSources(t *testing.T) {
+	rbnUp := newFakeUpstream(4)
+	clusterUp := newFakeUpstream(4)
+
+	agg := NewAggregator(Config{DedupWindow: 50 * time.Millisecond},
+		Source{Name: "RBN", Client: rbnUp},
+		Source{Name: "W3LPL-CLUSTER", Client: clusterUp},
+	)
+	if err := agg.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer agg.Stop()
+
+	now := time.Now()
+	rbnUp.spots <- testSpot("K1ABC", 14074.0, -10, now)
+	clusterUp.spots <- testSpot("K1ABC", 14074.00005, -4, now.Add(5*time.Millisecond))
+
+	select {
+	case merged := <-agg.Spots():
+		if len(merged.SourceNodes) != 2 {
+			t.Fatalf("expected 2 source nodes, got %v", merged.SourceNodes)
+		}
+		// sort.Ints([-10, -4]) -> [-10, -4]; min is -10, median (index 1) is -4.
+		if merged.MinSNR != -10 || merged.MedianSNR != -4 {
+			t.Fatalf("expected MinSNR=-10 MedianSNR=-4, got min=%d median=%d", merged.MinSNR, merged.MedianSNR)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for merged spot")
+	}
+}
+
+func TestAggregatorForwardsDistinctCallsSeparately(t *testing.T) {
+	up := newFakeUpstream(4)
+	agg := NewAggregator(Config{DedupWindow: 20 * time.Millisecond}, Source{Name: "RBN", Client: up})
+	if err := agg.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer agg.Stop()
+
+	now := time.Now()
+	up.spots <- testSpot("K1ABC", 14074.0, -10, now)
+	up.spots <- testSpot("K9XYZ", 14074.0, -8, now)
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case merged := <-agg.Spots():
+			seen[merged.DXCall] = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for forwarded spots")
+		}
+	}
+	if !seen["K1ABC"] || !seen["K9XYZ"] {
+		t.Fatalf("expected both distinct calls forwarded, got %v", seen)
+	}
+}
+
+func TestAggregatorRateLimitDropsExcess(t *testing.T) {
+	up := newFakeUpstream(8)
+	agg := NewAggregator(Config{DedupWindow: time.Millisecond}, Source{Name: "RBN", Client: up, RateLimit: 1})
+	if err := agg.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer agg.Stop()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		up.spots <- testSpot("K1ABC", 14074.0+float64(i), -10, now.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		stats := agg.Stats()
+		if stats.DroppedByRateLimit["RBN"] > 0 {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected at least one spot dropped by rate limit, stats=%+v", stats)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestDedupKeyForRoundsFrequency(t *testing.T) {
+	now := time.Now()
+	a := testSpot("K1ABC", 14074.0, -10, now)
+	b := testSpot("K1ABC", 14074.00004, -10, now) // 0.04 Hz apart after *1000, well within 100Hz rounding
+
+	if dedupKeyFor(a, 0) != dedupKeyFor(b, 0) {
+		t.Fatalf("expected frequencies within rounding to produce the same key")
+	}
+
+	c := testSpot("K1ABC", 14074.2, -10, now) // 200 Hz away
+	if dedupKeyFor(a, 0) == dedupKeyFor(c, 0) {
+		t.Fatalf("expected a frequency 200Hz away to produce a different key")
+	}
+}
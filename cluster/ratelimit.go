@@ -0,0 +1,57 @@
+package cluster
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token-bucket limiter capping one Source's contribution to
+// the Aggregator, so one noisy feed can't starve the others downstream of
+// Aggregator.out. A zero or negative rate (the common case: most sources are
+// unlimited) makes Allow always return true without taking the lock.
+type rateLimiter struct {
+	rate  float64 // tokens added per second
+	burst float64 // max tokens held at once
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a rateLimiter allowing up to ratePerSecond spots
+// per second, with burst capacity equal to one second's worth of tokens (at
+// least 1, so a rate under 1/sec still allows its first spot immediately
+// instead of waiting out its own period before ever forwarding one).
+func newRateLimiter(ratePerSecond float64) *rateLimiter {
+	if ratePerSecond <= 0 {
+		return &rateLimiter{}
+	}
+	burst := math.Max(ratePerSecond, 1)
+	return &rateLimiter{
+		rate:   ratePerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// Allow reports whether the next spot is within the rate limit, consuming a
+// token if so.
+func (r *rateLimiter) Allow() bool {
+	if r == nil || r.rate <= 0 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(r.last).Seconds()
+	r.last = now
+	r.tokens = math.Min(r.burst, r.tokens+elapsed*r.rate)
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
@@ -16,6 +16,8 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"dxcluster/archive"
+
 	_ "modernc.org/sqlite"
 )
 
@@ -88,14 +90,34 @@ func run(decisionDBPath, spotDBPath string, lookAheadHours int) error {
 		return nil
 	}
 
-	// For now, we'll do a simplified analysis using the decision votes
-	// In a production system, you'd query a separate spot database
-	stats, err := analyzeStabilityFromVotes(db, corrections, lookAheadHours)
-	if err != nil {
-		return fmt.Errorf("analyze stability: %w", err)
+	var (
+		stats      *stabilityStats
+		usedSpotDB bool
+	)
+	if strings.TrimSpace(spotDBPath) != "" {
+		spotArchive, err := archive.OpenReader(spotDBPath)
+		if err != nil {
+			return fmt.Errorf("open spot database: %w", err)
+		}
+		defer spotArchive.Close()
+
+		fmt.Printf("Cross-referencing against raw spot archive: %s\n", spotDBPath)
+		stats, err = analyzeStabilityFromSpots(spotArchive, corrections, lookAheadHours)
+		if err != nil {
+			return fmt.Errorf("analyze stability from spots: %w", err)
+		}
+		usedSpotDB = true
+	} else {
+		// No raw spot archive available; fall back to the vote-only
+		// heuristic that cross-references the decision log against itself.
+		var err error
+		stats, err = analyzeStabilityFromVotes(db, corrections, lookAheadHours)
+		if err != nil {
+			return fmt.Errorf("analyze stability: %w", err)
+		}
 	}
 
-	printResults(stats)
+	printResults(stats, usedSpotDB)
 	return nil
 }
 
@@ -194,6 +216,83 @@ func analyzeStabilityFromVotes(db *sql.DB, corrections []correctionRecord, lookA
 	return stats, nil
 }
 
+// analyzeStabilityFromSpots is the real cross-reference path: for each
+// applied correction, it counts uncorrected appearances of the winner
+// callsign in raw spots within the lookahead window (evidence the
+// correction was right) and, symmetrically, reappearances of the original
+// subject (evidence it may not have been). Unlike analyzeStabilityFromVotes
+// it isn't limited to what other decisions happened to vote on - it checks
+// the actual spot stream.
+func analyzeStabilityFromSpots(spotArchive *archive.Writer, corrections []correctionRecord, lookAheadHours int) (*stabilityStats, error) {
+	stats := &stabilityStats{
+		totalCorrections: len(corrections),
+		byDistance:       make(map[int]*distanceStability),
+	}
+
+	fmt.Printf("\nAnalyzing temporal stability against raw spot archive...\n")
+	fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
+
+	for i, corr := range corrections {
+		if i > 0 && i%100 == 0 {
+			fmt.Printf("  Processed %d/%d corrections...\n", i, len(corrections))
+		}
+
+		ds, exists := stats.byDistance[corr.distance]
+		if !exists {
+			ds = &distanceStability{distance: corr.distance}
+			stats.byDistance[corr.distance] = ds
+		}
+		ds.corrections++
+
+		hasNaturalAppearance, hasSubjectReappearance, err := checkSubsequentAppearancesFromSpots(spotArchive, corr, lookAheadHours)
+		if err != nil {
+			return nil, fmt.Errorf("check appearances for correction %d: %w", corr.id, err)
+		}
+
+		if hasNaturalAppearance {
+			stats.naturalAppearances++
+			ds.naturalAppearances++
+		}
+		if hasSubjectReappearance {
+			stats.subjectReappearances++
+			ds.subjectReappearances++
+		}
+		if !hasNaturalAppearance && !hasSubjectReappearance {
+			stats.noSubsequentSpots++
+		}
+	}
+
+	if stats.totalCorrections > 0 {
+		stats.stabilityRatio = float64(stats.naturalAppearances) / float64(stats.totalCorrections) * 100.0
+	}
+	for _, ds := range stats.byDistance {
+		if ds.corrections > 0 {
+			ds.stabilityRatio = float64(ds.naturalAppearances) / float64(ds.corrections) * 100.0
+		}
+	}
+
+	return stats, nil
+}
+
+// checkSubsequentAppearancesFromSpots counts, within the lookahead window
+// starting at corr.timestamp, raw spot-archive appearances of the winner
+// (naturalWinner) and of the original subject (naturalSubject).
+func checkSubsequentAppearancesFromSpots(spotArchive *archive.Writer, corr correctionRecord, lookAheadHours int) (naturalWinner, naturalSubject bool, err error) {
+	from := corr.timestamp
+	to := from.Add(time.Duration(lookAheadHours) * time.Hour)
+
+	winnerCount, err := spotArchive.CountAppearances(corr.winner, from, to)
+	if err != nil {
+		return false, false, err
+	}
+	subjectCount, err := spotArchive.CountAppearances(corr.subject, from, to)
+	if err != nil {
+		return false, false, err
+	}
+
+	return winnerCount > 0, subjectCount > 0, nil
+}
+
 func checkSubsequentAppearances(db *sql.DB, corr correctionRecord, lookAheadHours int) (naturalWinner, naturalSubject bool) {
 	// Query subsequent decisions within the lookahead window
 	// Check if winner or subject appear in vote data
@@ -244,7 +343,7 @@ func checkSubsequentAppearances(db *sql.DB, corr correctionRecord, lookAheadHour
 	return naturalWinner, naturalSubject
 }
 
-func printResults(stats *stabilityStats) {
+func printResults(stats *stabilityStats, usedSpotDB bool) {
 	fmt.Printf("\n")
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
 	fmt.Printf("  RESULTS\n")
@@ -363,10 +462,18 @@ func printResults(stats *stabilityStats) {
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
 	fmt.Printf("\n")
 
-	fmt.Printf("NOTE: This analysis is based on decision log data only.\n")
-	fmt.Printf("For more accurate validation, cross-reference with:\n")
-	fmt.Printf("  • Raw spot database (check if winner appears uncorrected)\n")
-	fmt.Printf("  • FCC ULS / MASTER.SCP databases (known callsign validation)\n")
-	fmt.Printf("  • CTY database (geographic consistency checks)\n")
-	fmt.Printf("\n")
+	if usedSpotDB {
+		fmt.Printf("NOTE: This analysis cross-references the raw spot archive (-spots).\n")
+		fmt.Printf("For further validation, consider also cross-referencing with:\n")
+		fmt.Printf("  • FCC ULS / MASTER.SCP databases (known callsign validation)\n")
+		fmt.Printf("  • CTY database (geographic consistency checks)\n")
+		fmt.Printf("\n")
+	} else {
+		fmt.Printf("NOTE: This analysis is based on decision log data only (-spots not given).\n")
+		fmt.Printf("For more accurate validation, cross-reference with:\n")
+		fmt.Printf("  • Raw spot database (check if winner appears uncorrected) via -spots\n")
+		fmt.Printf("  • FCC ULS / MASTER.SCP databases (known callsign validation)\n")
+		fmt.Printf("  • CTY database (geographic consistency checks)\n")
+		fmt.Printf("\n")
+	}
 }
@@ -0,0 +1,385 @@
+// Program analyze1b cross-references call correction decisions against
+// reference callsign databases (MASTER.SCP, CTY, and optionally an FCC ULS
+// import) rather than the temporal-stability heuristic analyze1a uses.
+//
+// Principle: a correction whose winner is unknown to every reference
+// source is suspect regardless of how it behaved afterward; a correction
+// whose subject (the pre-correction call) is a known station but whose
+// winner is not is very likely wrong.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"dxcluster/callvalidate"
+	"dxcluster/cty"
+
+	_ "modernc.org/sqlite"
+)
+
+type correctionRecord struct {
+	id       int64
+	subject  string
+	winner   string
+	freqKHz  float64
+	distance int
+	mode     string
+}
+
+// scoredCorrection is one correction plus the four booleans the request
+// asks for, derived from cross-referencing winner/subject against the
+// configured Validators.
+type scoredCorrection struct {
+	correctionRecord
+	winnerKnown            bool
+	subjectKnown           bool
+	winnerDXCCMatchesFreq  bool
+	subjectDXCCMatchesFreq bool
+}
+
+// likelyBad flags the case the request calls out explicitly: the winner
+// isn't recognized by any source but the original (pre-correction) call
+// is - i.e. the correction replaced a known station with an unknown one.
+func (s scoredCorrection) likelyBad() bool {
+	return !s.winnerKnown && s.subjectKnown
+}
+
+func main() {
+	decisionDB := flag.String("decisions", "data/logs/callcorr_debug_modified_2025-12-04.db", "Path to decision log database")
+	masterSCPPath := flag.String("masterscp", "", "Path to MASTER.SCP")
+	ctyPath := flag.String("cty", "", "Path to a CTY database (.plist or .dat)")
+	ulsPath := flag.String("uls", "", "Path to an optional FCC ULS import SQLite database")
+	flag.Parse()
+
+	if err := run(*decisionDB, *masterSCPPath, *ctyPath, *ulsPath); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(decisionDBPath, masterSCPPath, ctyPath, ulsPath string) error {
+	db, err := sql.Open("sqlite", decisionDBPath)
+	if err != nil {
+		return fmt.Errorf("open decision database: %w", err)
+	}
+	defer db.Close()
+
+	fmt.Printf("\n")
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
+	fmt.Printf("  METHOD 1B: REFERENCE DATABASE CROSS-VALIDATION\n")
+	fmt.Printf("  Decision Database: %s\n", decisionDBPath)
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
+	fmt.Printf("\n")
+
+	corrections, err := loadAppliedCorrections(db)
+	if err != nil {
+		return fmt.Errorf("load corrections: %w", err)
+	}
+	fmt.Printf("Loaded %d applied corrections from database\n", len(corrections))
+	if len(corrections) == 0 {
+		fmt.Println("\n⚠ No applied corrections found. Nothing to analyze.")
+		return nil
+	}
+
+	validator, ctyValidator, err := buildValidator(masterSCPPath, ctyPath, ulsPath)
+	if err != nil {
+		return err
+	}
+
+	scored := make([]scoredCorrection, 0, len(corrections))
+	for _, c := range corrections {
+		scored = append(scored, scoreCorrection(c, validator, ctyValidator))
+	}
+
+	printResults(scored)
+	return nil
+}
+
+// buildValidator wires up whichever reference sources were configured.
+// masterSCPPath and ulsPath are both optional; at least one of
+// masterSCPPath or ctyPath should be set for the known/dxcc booleans to
+// mean anything, but an all-empty configuration still runs (everything
+// simply scores unknown).
+func buildValidator(masterSCPPath, ctyPath, ulsPath string) (callvalidate.Validator, *callvalidate.CTYValidator, error) {
+	var validators []callvalidate.Validator
+	var ctyValidator *callvalidate.CTYValidator
+
+	if strings.TrimSpace(masterSCPPath) != "" {
+		scp, err := callvalidate.LoadMasterSCP(masterSCPPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load master.scp: %w", err)
+		}
+		validators = append(validators, scp)
+	}
+
+	if strings.TrimSpace(ctyPath) != "" {
+		ctyDB, err := loadCTYDatabase(ctyPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load cty database: %w", err)
+		}
+		ctyValidator = callvalidate.NewCTYValidator(ctyDB)
+		validators = append(validators, ctyValidator)
+	}
+
+	if strings.TrimSpace(ulsPath) != "" {
+		uls, err := callvalidate.OpenULSValidator(ulsPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open uls database: %w", err)
+		}
+		validators = append(validators, uls)
+	}
+
+	return callvalidate.NewMultiValidator(validators...), ctyValidator, nil
+}
+
+// loadCTYDatabase does a one-shot load of either plist or cty.dat format,
+// reusing the cty package's Manager the same way main.go does rather than
+// reaching into its unexported decoders.
+func loadCTYDatabase(path string) (*cty.CTYDatabase, error) {
+	var source cty.Source
+	if strings.HasSuffix(strings.ToLower(path), ".dat") {
+		source = cty.NewCTYDatFileSource(path)
+	} else {
+		source = cty.NewPlistFileSource(path)
+	}
+	mgr := cty.NewManager(source, time.Hour)
+	if err := mgr.Start(context.Background()); err != nil {
+		return nil, err
+	}
+	return mgr.Snapshot(), nil
+}
+
+func loadAppliedCorrections(db *sql.DB) ([]correctionRecord, error) {
+	rows, err := db.Query(`
+		SELECT id, subject, winner, freq_khz, distance, mode
+		FROM decisions
+		WHERE decision = 'applied'
+		ORDER BY ts
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var corrections []correctionRecord
+	for rows.Next() {
+		var c correctionRecord
+		if err := rows.Scan(&c.id, &c.subject, &c.winner, &c.freqKHz, &c.distance, &c.mode); err != nil {
+			return nil, err
+		}
+		c.subject = strings.ToUpper(strings.TrimSpace(c.subject))
+		c.winner = strings.ToUpper(strings.TrimSpace(c.winner))
+		corrections = append(corrections, c)
+	}
+	return corrections, rows.Err()
+}
+
+func scoreCorrection(c correctionRecord, validator callvalidate.Validator, ctyValidator *callvalidate.CTYValidator) scoredCorrection {
+	s := scoredCorrection{correctionRecord: c}
+
+	s.winnerKnown, _, _ = validator.Score(c.winner)
+	s.subjectKnown, _, _ = validator.Score(c.subject)
+
+	if ctyValidator != nil {
+		if info, ok := ctyValidator.Lookup(c.winner); ok {
+			s.winnerDXCCMatchesFreq = dxccMatchesFreq(info, c.freqKHz)
+		} else {
+			s.winnerDXCCMatchesFreq = true // nothing to contradict
+		}
+		if info, ok := ctyValidator.Lookup(c.subject); ok {
+			s.subjectDXCCMatchesFreq = dxccMatchesFreq(info, c.freqKHz)
+		} else {
+			s.subjectDXCCMatchesFreq = true
+		}
+	} else {
+		s.winnerDXCCMatchesFreq = true
+		s.subjectDXCCMatchesFreq = true
+	}
+
+	return s
+}
+
+// ituRegion maps a CTY continent code to the ITU region used by
+// dxccMatchesFreq's band-edge sanity checks. IARU regions don't map
+// perfectly onto continents (parts of the Middle East and Russia straddle
+// boundaries), but this is accurate enough to catch the common case.
+func ituRegion(continent string) int {
+	switch continent {
+	case "EU", "AF":
+		return 1
+	case "NA", "SA":
+		return 2
+	case "AS", "OC":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// dxccMatchesFreq reports whether freqKHz is plausible for info's ITU
+// region. It only checks the handful of HF band edges known to differ by
+// region (currently 40m and 80m); every other frequency is treated as
+// consistent, since this is a coarse sanity check rather than full
+// band-plan validation.
+func dxccMatchesFreq(info cty.PrefixInfo, freqKHz float64) bool {
+	switch ituRegion(info.Continent) {
+	case 1: // Europe/Africa: narrower 40m/80m phone+CW allocation
+		if freqKHz >= 7000 && freqKHz <= 7300 {
+			return freqKHz <= 7200
+		}
+		if freqKHz >= 3500 && freqKHz <= 4000 {
+			return freqKHz <= 3800
+		}
+	case 3: // Asia-Pacific shares Region 1's narrower 40m edge
+		if freqKHz >= 7000 && freqKHz <= 7300 {
+			return freqKHz <= 7200
+		}
+	}
+	return true
+}
+
+type bucketScore struct {
+	total                 int
+	winnerKnown           int
+	subjectKnown          int
+	winnerDXCCMatchesFreq int
+	likelyBad             int
+}
+
+func printResults(scored []scoredCorrection) {
+	overall := bucketScore{}
+	byDistance := make(map[int]*bucketScore)
+	byMode := make(map[string]*bucketScore)
+
+	var likelyBad []scoredCorrection
+
+	for _, s := range scored {
+		overall.total++
+		if s.winnerKnown {
+			overall.winnerKnown++
+		}
+		if s.subjectKnown {
+			overall.subjectKnown++
+		}
+		if s.winnerDXCCMatchesFreq {
+			overall.winnerDXCCMatchesFreq++
+		}
+		if s.likelyBad() {
+			overall.likelyBad++
+			likelyBad = append(likelyBad, s)
+		}
+
+		d, ok := byDistance[s.distance]
+		if !ok {
+			d = &bucketScore{}
+			byDistance[s.distance] = d
+		}
+		accumulate(d, s)
+
+		m, ok := byMode[s.mode]
+		if !ok {
+			m = &bucketScore{}
+			byMode[s.mode] = m
+		}
+		accumulate(m, s)
+	}
+
+	fmt.Printf("\n")
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
+	fmt.Printf("  RESULTS\n")
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
+	fmt.Printf("\n")
+	fmt.Printf("OVERALL:\n")
+	fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
+	printBucket("All corrections", &overall)
+	fmt.Printf("  Likely-bad (winner unknown, subject known): %d (%.1f%%)\n",
+		overall.likelyBad, rate(overall.likelyBad, overall.total))
+	fmt.Printf("\n")
+
+	fmt.Printf("BY EDIT DISTANCE:\n")
+	fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
+	distances := make([]int, 0, len(byDistance))
+	for d := range byDistance {
+		distances = append(distances, d)
+	}
+	sort.Ints(distances)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Distance\tCorrections\tWinner Known\tSubject Known\tLikely-Bad")
+	for _, d := range distances {
+		b := byDistance[d]
+		fmt.Fprintf(w, "%d\t%d\t%.1f%%\t%.1f%%\t%d\n",
+			d, b.total, rate(b.winnerKnown, b.total), rate(b.subjectKnown, b.total), b.likelyBad)
+	}
+	w.Flush()
+	fmt.Printf("\n")
+
+	fmt.Printf("BY MODE:\n")
+	fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
+	modes := make([]string, 0, len(byMode))
+	for m := range byMode {
+		modes = append(modes, m)
+	}
+	sort.Strings(modes)
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Mode\tCorrections\tWinner Known\tSubject Known\tLikely-Bad")
+	for _, m := range modes {
+		b := byMode[m]
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%.1f%%\t%d\n",
+			m, b.total, rate(b.winnerKnown, b.total), rate(b.subjectKnown, b.total), b.likelyBad)
+	}
+	w.Flush()
+	fmt.Printf("\n")
+
+	if len(likelyBad) > 0 {
+		fmt.Printf("LIKELY-BAD CORRECTIONS (feed these into threshold-relaxation review):\n")
+		fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
+		for _, s := range likelyBad {
+			fmt.Printf("  id=%d  %s -> %s  (distance=%d, mode=%s, freq=%.1fkHz)\n",
+				s.id, s.subject, s.winner, s.distance, s.mode, s.freqKHz)
+		}
+		fmt.Printf("\n")
+	}
+
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
+	fmt.Printf("\n")
+}
+
+func accumulate(b *bucketScore, s scoredCorrection) {
+	b.total++
+	if s.winnerKnown {
+		b.winnerKnown++
+	}
+	if s.subjectKnown {
+		b.subjectKnown++
+	}
+	if s.winnerDXCCMatchesFreq {
+		b.winnerDXCCMatchesFreq++
+	}
+	if s.likelyBad() {
+		b.likelyBad++
+	}
+}
+
+func printBucket(label string, b *bucketScore) {
+	fmt.Printf("  %s: %d\n", label, b.total)
+	fmt.Printf("  Winner known:                   %d (%.1f%%)\n", b.winnerKnown, rate(b.winnerKnown, b.total))
+	fmt.Printf("  Subject known:                  %d (%.1f%%)\n", b.subjectKnown, rate(b.subjectKnown, b.total))
+	fmt.Printf("  Winner DXCC matches frequency:  %d (%.1f%%)\n", b.winnerDXCCMatchesFreq, rate(b.winnerDXCCMatchesFreq, b.total))
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100.0
+}
@@ -1,5 +1,8 @@
 // Program analyze1c performs Method 1C: Distance-Confidence Correlation analysis
-// on call correction decision logs to validate threshold calibration.
+// on call correction decision logs to validate threshold calibration. The
+// aggregation and hypothesis-test math it reports on lives in
+// callcorr/feedback, shared with the online feedback.Engine so the two
+// paths cannot drift apart.
 package main
 
 import (
@@ -12,116 +15,46 @@ import (
 	"text/tabwriter"
 
 	_ "modernc.org/sqlite"
-)
 
-type distanceStats struct {
-	distance         int
-	totalDecisions   int
-	appliedCount     int
-	rejectedCount    int
-	meanConfidence   float64
-	medianConfidence float64
-	confidences      []int
-	rejectionReasons map[string]int
-}
+	"dxcluster/callcorr/feedback"
+	"dxcluster/decisionlog"
+)
 
 func main() {
 	dbPath := flag.String("db", "data/logs/callcorr_debug_modified_2025-12-04.db", "Path to decision log database")
+	applyRateEffectThreshold := flag.Float64("apply-rate-effect-threshold", 30.0,
+		"minimum apply-rate delta (percentage points) between distance-1 and distance-3 required, alongside p<0.05, before CONSERVATIVE is reported")
+	confEffectThreshold := flag.Float64("confidence-effect-threshold", 10.0,
+		"maximum mean-confidence delta (percentage points) between distance-1 and distance-3 allowed, alongside p<0.05, before WELL-CALIBRATED is reported")
 	flag.Parse()
 
-	if err := run(*dbPath); err != nil {
+	if err := run(*dbPath, *applyRateEffectThreshold, *confEffectThreshold); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func run(dbPath string) error {
+func run(dbPath string, applyRateEffectThreshold, confEffectThreshold float64) error {
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
 	defer db.Close()
 
-	// Query all decisions
-	rows, err := db.Query(`
-		SELECT
-			distance,
-			decision,
-			winner_confidence,
-			reason
-		FROM decisions
-		ORDER BY distance, decision
-	`)
+	decisions, err := loadDecisions(db)
 	if err != nil {
-		return fmt.Errorf("query decisions: %w", err)
+		return err
 	}
-	defer rows.Close()
 
-	// Group by distance
-	statsByDistance := make(map[int]*distanceStats)
+	statsByDistance := feedback.Aggregate(decisions)
 
+	// totalDecisions is derived from the aggregated buckets rather than the
+	// raw row count, so it stays consistent with every sum taken over
+	// statsByDistance below - Aggregate excludes harmonic_dropped rows (see
+	// its doc comment), and counting them here would make "Total Decisions
+	// Analyzed" disagree with Applied+Rejected.
 	var totalDecisions int
-	for rows.Next() {
-		var distance int
-		var decision string
-		var winnerConfidence int
-		var reason sql.NullString
-
-		if err := rows.Scan(&distance, &decision, &winnerConfidence, &reason); err != nil {
-			return fmt.Errorf("scan row: %w", err)
-		}
-
-		totalDecisions++
-
-		stats, exists := statsByDistance[distance]
-		if !exists {
-			stats = &distanceStats{
-				distance:         distance,
-				confidences:      []int{},
-				rejectionReasons: make(map[string]int),
-			}
-			statsByDistance[distance] = stats
-		}
-
-		stats.totalDecisions++
-
-		if decision == "applied" {
-			stats.appliedCount++
-			stats.confidences = append(stats.confidences, winnerConfidence)
-		} else {
-			stats.rejectedCount++
-			reasonStr := "UNKNOWN"
-			if reason.Valid && reason.String != "" {
-				reasonStr = reason.String
-			}
-			stats.rejectionReasons[reasonStr]++
-		}
-	}
-
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("rows iteration: %w", err)
-	}
-
-	// Calculate statistics
 	for _, stats := range statsByDistance {
-		if len(stats.confidences) > 0 {
-			// Mean
-			sum := 0
-			for _, c := range stats.confidences {
-				sum += c
-			}
-			stats.meanConfidence = float64(sum) / float64(len(stats.confidences))
-
-			// Median
-			sorted := make([]int, len(stats.confidences))
-			copy(sorted, stats.confidences)
-			sort.Ints(sorted)
-			mid := len(sorted) / 2
-			if len(sorted)%2 == 0 {
-				stats.medianConfidence = float64(sorted[mid-1]+sorted[mid]) / 2.0
-			} else {
-				stats.medianConfidence = float64(sorted[mid])
-			}
-		}
+		totalDecisions += stats.TotalDecisions
 	}
 
 	// Print results
@@ -137,8 +70,8 @@ func run(dbPath string) error {
 	fmt.Printf("SUMMARY BY EDIT DISTANCE:\n")
 	fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Distance\tTotal\tApplied\tRejected\tApply Rate\tMean Conf\tMedian Conf")
-	fmt.Fprintln(w, "────────\t─────\t───────\t────────\t──────────\t─────────\t───────────")
+	fmt.Fprintln(w, "Distance\tTotal\tApplied\tRejected\tApply Rate (95% CI)\tMean Conf (95% CI)\tMedian Conf")
+	fmt.Fprintln(w, "────────\t─────\t───────\t────────\t────────────────────\t───────────────────\t───────────")
 
 	// Sort by distance
 	distances := make([]int, 0, len(statsByDistance))
@@ -149,16 +82,17 @@ func run(dbPath string) error {
 
 	for _, d := range distances {
 		stats := statsByDistance[d]
-		applyRate := float64(stats.appliedCount) / float64(stats.totalDecisions) * 100.0
-
-		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%.1f%%\t%.1f%%\t%.1f%%\n",
-			stats.distance,
-			stats.totalDecisions,
-			stats.appliedCount,
-			stats.rejectedCount,
-			applyRate,
-			stats.meanConfidence,
-			stats.medianConfidence,
+		applyRateCenter, applyRateHalfWidth := stats.ApplyRateCI()
+		meanConfHalfWidth := stats.MeanConfidenceCI()
+
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%.1f%% ± %.1f%%\t%.1f%% ± %.1f%%\t%.1f%%\n",
+			stats.Distance,
+			stats.TotalDecisions,
+			stats.AppliedCount,
+			stats.RejectedCount,
+			applyRateCenter, applyRateHalfWidth,
+			stats.MeanConfidence, meanConfHalfWidth,
+			stats.MedianConfidence,
 		)
 	}
 	w.Flush()
@@ -169,31 +103,20 @@ func run(dbPath string) error {
 
 	for _, d := range distances {
 		stats := statsByDistance[d]
-		if stats.rejectedCount == 0 {
+		if stats.RejectedCount == 0 {
 			continue
 		}
 
-		fmt.Printf("\nDistance %d (Rejected: %d)\n", stats.distance, stats.rejectedCount)
+		fmt.Printf("\nDistance %d (Rejected: %d)\n", stats.Distance, stats.RejectedCount)
 
-		// Sort reasons by count
-		type reasonCount struct {
-			reason string
-			count  int
-		}
-		reasons := make([]reasonCount, 0, len(stats.rejectionReasons))
-		for r, c := range stats.rejectionReasons {
-			reasons = append(reasons, reasonCount{r, c})
-		}
-		sort.Slice(reasons, func(i, j int) bool {
-			return reasons[i].count > reasons[j].count
-		})
+		reasons := sortedReasonCounts(stats.RejectionReasons)
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		rw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 		for _, rc := range reasons {
-			pct := float64(rc.count) / float64(stats.rejectedCount) * 100.0
-			fmt.Fprintf(w, "  %s\t%d\t(%.1f%%)\n", rc.reason, rc.count, pct)
+			pct := float64(rc.count) / float64(stats.RejectedCount) * 100.0
+			fmt.Fprintf(rw, "  %s\t%d\t(%.1f%%)\n", rc.reason, rc.count, pct)
 		}
-		w.Flush()
+		rw.Flush()
 	}
 
 	// Analysis and recommendations
@@ -203,59 +126,59 @@ func run(dbPath string) error {
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
 	fmt.Printf("\n")
 
-	// Check distance-1 vs distance-3 comparison
+	// Check distance-1 vs distance-3 comparison using proper hypothesis
+	// tests rather than raw deltas, which are misleading once a bucket has
+	// few decisions: two buckets can show a 30-point apply-rate gap purely
+	// from noise if n is small, or hide a real gap if it's large.
 	if stats1, ok1 := statsByDistance[1]; ok1 {
 		if stats3, ok3 := statsByDistance[3]; ok3 {
-			if len(stats1.confidences) > 0 && len(stats3.confidences) > 0 {
-				confDelta := stats1.meanConfidence - stats3.meanConfidence
-				applyRate1 := float64(stats1.appliedCount) / float64(stats1.totalDecisions) * 100.0
-				applyRate3 := float64(stats3.appliedCount) / float64(stats3.totalDecisions) * 100.0
-				applyRateDelta := applyRate1 - applyRate3
+			if len(stats1.Confidences) > 1 && len(stats3.Confidences) > 1 {
+				cmp := feedback.Compare(stats1, stats3)
 
 				fmt.Printf("Distance-1 vs Distance-3 Comparison:\n")
-				fmt.Printf("  • Distance-1 mean confidence: %.1f%%\n", stats1.meanConfidence)
-				fmt.Printf("  • Distance-3 mean confidence: %.1f%%\n", stats3.meanConfidence)
-				fmt.Printf("  • Confidence delta: %.1f%% (dist-1 higher)\n", confDelta)
-				fmt.Printf("  • Distance-1 apply rate: %.1f%%\n", applyRate1)
-				fmt.Printf("  • Distance-3 apply rate: %.1f%%\n", applyRate3)
-				fmt.Printf("  • Apply rate delta: %.1f%% (dist-1 higher)\n", applyRateDelta)
+				fmt.Printf("  • Distance-1 mean confidence: %.1f%% (n=%d)\n", stats1.MeanConfidence, len(stats1.Confidences))
+				fmt.Printf("  • Distance-3 mean confidence: %.1f%% (n=%d)\n", stats3.MeanConfidence, len(stats3.Confidences))
+				fmt.Printf("  • Confidence delta: %.1f%% (dist-1 higher), Welch t=%.2f df=%.1f p=%.3f\n", cmp.ConfDelta, cmp.ConfT, cmp.ConfDF, cmp.ConfP)
+				fmt.Printf("  • Distance-1 apply rate: %.1f%% (n=%d)\n", stats1.ApplyRate(), stats1.TotalDecisions)
+				fmt.Printf("  • Distance-3 apply rate: %.1f%% (n=%d)\n", stats3.ApplyRate(), stats3.TotalDecisions)
+				fmt.Printf("  • Apply rate delta: %.1f%% (dist-1 higher), z=%.2f p=%.3f\n", cmp.ApplyRateDelta, cmp.ApplyRateZ, cmp.ApplyRateP)
 				fmt.Printf("\n")
 
-				if confDelta < 10.0 {
-					fmt.Printf("✓ WELL-CALIBRATED: Distance-3 confidence only %.1f%% lower than distance-1.\n", confDelta)
+				switch {
+				case cmp.ConfidenceWellCalibrated(confEffectThreshold):
+					fmt.Printf("✓ WELL-CALIBRATED: Distance-3 confidence only %.1f%% lower than distance-1 (p=%.3f).\n", cmp.ConfDelta, cmp.ConfP)
 					fmt.Printf("  This suggests distance-3 corrections that ARE applied are equally reliable.\n")
 					fmt.Printf("\n")
+				case cmp.ConfP >= 0.05:
+					fmt.Printf("? Confidence comparison: insufficient evidence (n1=%d, n3=%d, p=%.3f)\n",
+						len(stats1.Confidences), len(stats3.Confidences), cmp.ConfP)
+					fmt.Printf("\n")
 				}
 
-				if applyRateDelta > 30.0 {
-					fmt.Printf("⚠ CONSERVATIVE: Distance-3 apply rate is %.1f%% lower than distance-1.\n", applyRateDelta)
+				switch {
+				case cmp.ApplyRateSignificant(applyRateEffectThreshold):
+					fmt.Printf("⚠ CONSERVATIVE: Distance-3 apply rate is %.1f%% lower than distance-1 (p=%.3f).\n", cmp.ApplyRateDelta, cmp.ApplyRateP)
 					fmt.Printf("  You may be rejecting many valid distance-3 corrections.\n")
 					fmt.Printf("  Recommendation: Review distance3_extra_* settings in data/config/pipeline.yaml\n")
 					fmt.Printf("\n")
 
 					// Check what's blocking distance-3
-					if len(stats3.rejectionReasons) > 0 {
+					if len(stats3.RejectionReasons) > 0 {
 						fmt.Printf("  Top distance-3 rejection reasons:\n")
-						type reasonCount struct {
-							reason string
-							count  int
-						}
-						reasons := make([]reasonCount, 0, len(stats3.rejectionReasons))
-						for r, c := range stats3.rejectionReasons {
-							reasons = append(reasons, reasonCount{r, c})
-						}
-						sort.Slice(reasons, func(i, j int) bool {
-							return reasons[i].count > reasons[j].count
-						})
+						reasons := sortedReasonCounts(stats3.RejectionReasons)
 						for i, rc := range reasons {
 							if i >= 3 {
 								break
 							}
-							pct := float64(rc.count) / float64(stats3.rejectedCount) * 100.0
+							pct := float64(rc.count) / float64(stats3.RejectedCount) * 100.0
 							fmt.Printf("    %d. %s (%.1f%%)\n", i+1, rc.reason, pct)
 						}
 						fmt.Printf("\n")
 					}
+				case cmp.ApplyRateP >= 0.05:
+					fmt.Printf("? Apply rate comparison: insufficient evidence (n1=%d, n3=%d, p=%.3f)\n",
+						stats1.TotalDecisions, stats3.TotalDecisions, cmp.ApplyRateP)
+					fmt.Printf("\n")
 				}
 			}
 		}
@@ -265,8 +188,8 @@ func run(dbPath string) error {
 	totalApplied := 0
 	totalRejected := 0
 	for _, stats := range statsByDistance {
-		totalApplied += stats.appliedCount
-		totalRejected += stats.rejectedCount
+		totalApplied += stats.AppliedCount
+		totalRejected += stats.RejectedCount
 	}
 
 	overallApplyRate := float64(totalApplied) / float64(totalDecisions) * 100.0
@@ -297,3 +220,63 @@ func run(dbPath string) error {
 
 	return nil
 }
+
+// loadDecisions reads every row of the decisions table that feedback.
+// Aggregate needs (distance, decision, winner_confidence, reason). The
+// query runs inside a read-only snapshot transaction
+// (decisionlog.WithReadSnapshot) so it sees one consistent view of the log
+// even if the live daemon is still appending to it concurrently.
+func loadDecisions(db *sql.DB) ([]decisionlog.Decision, error) {
+	var decisions []decisionlog.Decision
+
+	err := decisionlog.WithReadSnapshot(db, func(tx *sql.Tx) error {
+		rows, err := tx.Query(`
+			SELECT
+				distance,
+				decision,
+				winner_confidence,
+				reason
+			FROM decisions
+			ORDER BY distance, decision
+		`)
+		if err != nil {
+			return fmt.Errorf("query decisions: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var d decisionlog.Decision
+			var reason sql.NullString
+			if err := rows.Scan(&d.Distance, &d.Decision, &d.WinnerConfidence, &reason); err != nil {
+				return fmt.Errorf("scan row: %w", err)
+			}
+			if reason.Valid {
+				d.Reason = reason.String
+			}
+			decisions = append(decisions, d)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// reasonCount is one rejection reason's tally, used to print the top
+// reasons sorted by frequency.
+type reasonCount struct {
+	reason string
+	count  int
+}
+
+func sortedReasonCounts(counts map[string]int) []reasonCount {
+	reasons := make([]reasonCount, 0, len(counts))
+	for r, c := range counts {
+		reasons = append(reasons, reasonCount{r, c})
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		return reasons[i].count > reasons[j].count
+	})
+	return reasons
+}
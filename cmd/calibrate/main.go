@@ -0,0 +1,94 @@
+// Program calibrate sweeps call-correction thresholds against a historical
+// decision log and reports the recall/temporal-stability Pareto front,
+// replacing the older hard-coded per-distance analyses
+// (analyze_distance3, simulate_threshold).
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	"dxcluster/calibration"
+)
+
+func main() {
+	dbPath := flag.String("db", "data/logs/callcorr_debug_modified_2025-12-04.db", "Path to decision log database")
+	minReports := flag.String("min-reports", "3", "Comma-separated min_consensus_reports values to sweep")
+	minConfidence := flag.String("min-confidence", "60", "Comma-separated min_confidence_percent values to sweep")
+	minAdvantage := flag.String("min-advantage", "1", "Comma-separated min_advantage values to sweep")
+	d1Extra := flag.String("distance1-extra-reports", "0", "Comma-separated distance-1 extra-reports values to sweep")
+	d2Extra := flag.String("distance2-extra-reports", "0", "Comma-separated distance-2 extra-reports values to sweep")
+	d3Extra := flag.String("distance3-extra-reports", "0", "Comma-separated distance-3 extra-reports values to sweep")
+	extraAdvantage := flag.String("extra-advantage", "0", "Comma-separated extra-advantage values to sweep")
+	extraConfidence := flag.String("extra-confidence", "0", "Comma-separated extra-confidence values to sweep")
+	numWindows := flag.Int("windows", 0, "Number of temporal-stability windows (0 = package default)")
+
+	constraintDistance := flag.Int("constraint-distance", 0, "Distance to constrain on (0 disables the constraint)")
+	constraintStability := flag.Float64("constraint-stability", 0.95, "Minimum stability required at -constraint-distance")
+
+	seed := flag.Int64("seed", 0, "Bootstrap resample seed for confidence intervals (0 = package default, reproducible)")
+
+	jsonOutput := flag.Bool("json", false, "Print the report as JSON instead of a table")
+	flag.Parse()
+
+	db, err := sql.Open("sqlite", *dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	decisions, err := calibration.LoadDecisions(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	space := calibration.ParamSpace{
+		MinConsensusReports:   mustParseInts(*minReports),
+		MinConfidencePercent:  mustParseInts(*minConfidence),
+		MinAdvantage:          mustParseInts(*minAdvantage),
+		Distance1ExtraReports: mustParseInts(*d1Extra),
+		Distance2ExtraReports: mustParseInts(*d2Extra),
+		Distance3ExtraReports: mustParseInts(*d3Extra),
+		ExtraAdvantage:        mustParseInts(*extraAdvantage),
+		ExtraConfidence:       mustParseInts(*extraConfidence),
+	}
+
+	var constraint *calibration.Constraint
+	if *constraintDistance > 0 {
+		constraint = &calibration.Constraint{Distance: *constraintDistance, MinStability: *constraintStability}
+	}
+
+	report := calibration.BuildReport(decisions, space, *numWindows, constraint, *seed)
+
+	if *jsonOutput {
+		out, err := report.JSON()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+	fmt.Print(report.Table())
+}
+
+func mustParseInts(csv string) []int {
+	var out []int
+	for _, field := range strings.Split(csv, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			log.Fatalf("invalid integer %q: %v", field, err)
+		}
+		out = append(out, v)
+	}
+	return out
+}
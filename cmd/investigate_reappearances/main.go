@@ -4,42 +4,31 @@
 package main
 
 import (
-	"database/sql"
 	"flag"
 	"fmt"
 	"log"
-	"strings"
+	"net/http"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"dxcluster/decisionlog"
 )
 
-type reappearanceCase struct {
-	correctionID       int64
-	correctionTime     time.Time
-	subject            string
-	winner             string
-	freqKHz            float64
-	distance           int
-	confidence         int
-	subjectReappeared  bool
-	winnerReappeared   bool
-	bothReappeared     bool
-	subjectFreqKHz     float64
-	winnerFreqKHz      float64
-	freqSeparationKHz  float64
-}
-
 func main() {
 	dbPath := flag.String("db", "data/logs/callcorr_debug_modified_2025-12-04.db", "Path to decision log database")
 	lookAheadHours := flag.Int("lookahead", 24, "Hours to look ahead")
+	serveAddr := flag.String("serve", "", "If set, serve this and other decision log analyses live as JSON on this address (e.g. :8090) instead of printing a one-shot report")
 	flag.Parse()
 
-	db, err := sql.Open("sqlite", *dbPath)
+	store, err := decisionlog.Open(*dbPath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer db.Close()
+	defer store.Close()
+
+	if *serveAddr != "" {
+		log.Printf("serving decision log analyses on %s (/reappearances, /oscillations)", *serveAddr)
+		log.Fatal(http.ListenAndServe(*serveAddr, decisionlog.NewHTTPHandler(store)))
+	}
 
 	fmt.Printf("\n")
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
@@ -47,8 +36,7 @@ func main() {
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
 	fmt.Printf("\n")
 
-	// Find cases where both subject and winner reappear
-	cases, err := analyzeBothReappearances(db, *lookAheadHours)
+	cases, err := store.FindReappearances(time.Duration(*lookAheadHours) * time.Hour)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -60,12 +48,10 @@ func main() {
 		return
 	}
 
-	// Categorize by frequency separation
 	closeFreq := 0
 	separatedFreq := 0
-
 	for _, c := range cases {
-		if c.freqSeparationKHz < 0.5 {
+		if c.FreqSeparationKHz < 0.5 {
 			closeFreq++
 		} else {
 			separatedFreq++
@@ -80,7 +66,6 @@ func main() {
 		float64(separatedFreq)/float64(len(cases))*100.0)
 	fmt.Printf("\n")
 
-	// Show sample cases
 	fmt.Printf("SAMPLE DUAL-REAPPEARANCE CASES:\n")
 	fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
 
@@ -91,16 +76,17 @@ func main() {
 
 	for i := 0; i < sampleCount; i++ {
 		c := cases[i]
-		fmt.Printf("\n%s → %s (dist=%d, conf=%d%%)\n", c.subject, c.winner, c.distance, c.confidence)
-		fmt.Printf("  Original freq:  %.1f kHz\n", c.freqKHz)
-		if c.subjectFreqKHz > 0 {
-			fmt.Printf("  Subject reappeared at: %.1f kHz\n", c.subjectFreqKHz)
+		d := c.Decision
+		fmt.Printf("\n%s → %s (dist=%d, conf=%d%%)\n", d.Subject, d.Winner, d.Distance, d.WinnerConfidence)
+		fmt.Printf("  Original freq:  %.1f kHz\n", d.FreqKHz)
+		if c.SubjectFreqKHz > 0 {
+			fmt.Printf("  Subject reappeared at: %.1f kHz\n", c.SubjectFreqKHz)
 		}
-		if c.winnerFreqKHz > 0 {
-			fmt.Printf("  Winner reappeared at:  %.1f kHz\n", c.winnerFreqKHz)
+		if c.WinnerFreqKHz > 0 {
+			fmt.Printf("  Winner reappeared at:  %.1f kHz\n", c.WinnerFreqKHz)
 		}
-		if c.freqSeparationKHz > 0 {
-			fmt.Printf("  Frequency separation:  %.2f kHz\n", c.freqSeparationKHz)
+		if c.FreqSeparationKHz > 0 {
+			fmt.Printf("  Frequency separation:  %.2f kHz\n", c.FreqSeparationKHz)
 		}
 	}
 
@@ -128,93 +114,3 @@ func main() {
 
 	fmt.Printf("\n")
 }
-
-func analyzeBothReappearances(db *sql.DB, lookAheadHours int) ([]reappearanceCase, error) {
-	// Get all applied corrections
-	rows, err := db.Query(`
-		SELECT
-			id, ts, subject, winner, freq_khz, distance, winner_confidence
-		FROM decisions
-		WHERE decision = 'applied'
-		ORDER BY ts
-	`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var cases []reappearanceCase
-	for rows.Next() {
-		var c reappearanceCase
-		var ts int64
-		if err := rows.Scan(&c.correctionID, &ts, &c.subject, &c.winner,
-			&c.freqKHz, &c.distance, &c.confidence); err != nil {
-			return nil, err
-		}
-		c.correctionTime = time.Unix(ts, 0)
-		c.subject = strings.ToUpper(strings.TrimSpace(c.subject))
-		c.winner = strings.ToUpper(strings.TrimSpace(c.winner))
-
-		// Check for subsequent appearances
-		endTime := c.correctionTime.Add(time.Duration(lookAheadHours) * time.Hour).Unix()
-
-		// Check if both appear in later decisions
-		checkQuery := `
-			SELECT subject, freq_khz
-			FROM decisions
-			WHERE ts > ? AND ts <= ?
-			  AND (UPPER(subject) = ? OR UPPER(subject) = ?)
-			ORDER BY ts
-			LIMIT 100
-		`
-
-		subRows, err := db.Query(checkQuery, ts, endTime, c.subject, c.winner)
-		if err != nil {
-			continue
-		}
-
-		subjectSeen := false
-		winnerSeen := false
-		var subjectFreqs, winnerFreqs []float64
-
-		for subRows.Next() {
-			var subject string
-			var freq float64
-			if err := subRows.Scan(&subject, &freq); err != nil {
-				continue
-			}
-			subject = strings.ToUpper(strings.TrimSpace(subject))
-
-			if subject == c.subject {
-				subjectSeen = true
-				subjectFreqs = append(subjectFreqs, freq)
-			}
-			if subject == c.winner {
-				winnerSeen = true
-				winnerFreqs = append(winnerFreqs, freq)
-			}
-		}
-		subRows.Close()
-
-		if subjectSeen && winnerSeen {
-			c.bothReappeared = true
-			c.subjectReappeared = true
-			c.winnerReappeared = true
-
-			// Calculate frequency separation
-			if len(subjectFreqs) > 0 && len(winnerFreqs) > 0 {
-				c.subjectFreqKHz = subjectFreqs[0]
-				c.winnerFreqKHz = winnerFreqs[0]
-				sep := c.subjectFreqKHz - c.winnerFreqKHz
-				if sep < 0 {
-					sep = -sep
-				}
-				c.freqSeparationKHz = sep
-			}
-
-			cases = append(cases, c)
-		}
-	}
-
-	return cases, rows.Err()
-}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"dxcluster/events"
+)
+
+// nanoJSONSink is a peerprobe-local variant of events.JSONSink with a
+// nanosecond-precision ts field, for pipelines (ClickHouse, Loki) that want
+// sub-second ordering between rapid peer/telnet arrivals. events.JSONSink
+// itself stays second-precision since it's shared with the main daemon,
+// which has no such requirement.
+type nanoJSONSink struct {
+	mu       sync.Mutex
+	w        io.Writer
+	minLevel events.Level
+}
+
+func newNanoJSONSink(w io.Writer, minLevel events.Level) *nanoJSONSink {
+	return &nanoJSONSink{w: w, minLevel: minLevel}
+}
+
+func (s *nanoJSONSink) Event(level events.Level, name string, fields ...events.Field) {
+	if level < s.minLevel {
+		return
+	}
+	obj := make(map[string]interface{}, len(fields)+3)
+	obj["ts"] = time.Now().Format(time.RFC3339Nano)
+	obj["level"] = level.String()
+	obj["event"] = name
+	for _, f := range fields {
+		if f.IsZero() {
+			continue
+		}
+		obj[f.Key] = f.Value()
+	}
+	line, err := json.Marshal(obj)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.w.Write(line)
+}
@@ -7,23 +7,65 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
-	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
 
 	"dxcluster/config"
+	"dxcluster/dxcc"
+	"dxcluster/events"
 	"dxcluster/peer"
 	"dxcluster/rbn"
 	"dxcluster/spot"
+	"dxcluster/wwv"
 
 	_ "unsafe" // for go:linkname to reuse the real spot parser
 )
 
+// probeLogger is the structured event sink for this tool, reconfigured by
+// main() from the -format/-out flags before any peer or telnet goroutine
+// starts. The zero-value default below is only ever used by code paths that
+// run before that (there are none), and exists so probeLogger is never nil.
+var probeLogger events.Logger = events.NewConsoleSink(log.Writer(), events.LevelDebug, false)
+
+// newProbeLogger builds the event sink for format ("json" or "console")
+// writing to w.
+func newProbeLogger(format string, w io.Writer) events.Logger {
+	if strings.EqualFold(format, "json") {
+		return newNanoJSONSink(w, events.LevelDebug)
+	}
+	return events.NewConsoleSink(w, events.LevelDebug, w == os.Stdout || w == os.Stderr)
+}
+
+// openEventSink resolves the -out flag to a writer and a matching close
+// function; "stdout"/"stderr" (or empty) map to the standard streams and
+// close as a no-op, anything else opens (creating/appending to) a file.
+func openEventSink(out string) (io.Writer, func() error, error) {
+	switch strings.ToLower(strings.TrimSpace(out)) {
+	case "", "stdout":
+		return os.Stdout, func() error { return nil }, nil
+	case "stderr":
+		return os.Stderr, func() error { return nil }, nil
+	default:
+		f, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open %s: %w", out, err)
+		}
+		return f, f.Close, nil
+	}
+}
+
 //go:linkname parseSpotFromFrame dxcluster/peer.parseSpotFromFrame
 func parseSpotFromFrame(frame *peer.Frame, fallbackOrigin string) (*spot.Spot, error)
 
@@ -33,8 +75,39 @@ func main() {
 	clusterPort := flag.Int("cluster_port", 0, "Port for telnet cluster comparison (optional when cluster_host includes port)")
 	clusterCall := flag.String("cluster_call", "LZ3ZZ", "Callsign to use when logging into cluster telnet")
 	windowMinutes := flag.Int("window_minutes", 3, "Matching window (minutes) between peer and telnet spots")
+	dedupWindowSeconds := flag.Int("dedup_window_seconds", 30, "Cross-peer dedup window (seconds) for collapsing identical spots reported by more than one peer")
+	dxccPrefixesPath := flag.String("dxcc_prefixes", "", "Path to a DXCC prefix YAML table (defaults to dxcc.Load's data/config/dxcc_prefixes.yaml search)")
+	allowDX := flag.String("allow_dx_entities", "", "Comma-separated DXCC entities/continents to allow for the spotted DX call (empty = allow all)")
+	blockDX := flag.String("block_dx_entities", "", "Comma-separated DXCC entities/continents to drop for the spotted DX call")
+	allowPeerEntities := flag.String("allow_peer_entities", "", "Comma-separated DXCC entities/continents to allow for the reporting peer (empty = allow all)")
+	blockPeerEntities := flag.String("block_peer_entities", "", "Comma-separated DXCC entities/continents to drop for the reporting peer")
+	metricsAddr := flag.String("metrics_addr", "", "Address to serve Prometheus /metrics on (e.g. :9091); empty disables the metrics server")
+	wwvDB := flag.String("wwv_db", "", "Path to a SQLite DB for PC23/PC73 propagation bulletins (empty disables persistence; events still relay/dedupe in-memory)")
+	topologyDB := flag.String("topology_db", "", "Path to a SQLite DB for the mesh topology graph (empty disables it; cmd/topology reads this DB)")
+	topologyRetention := flag.Duration("topology_retention", 7*24*time.Hour, "How long peer_nodes/peer_edges rows are kept before pruning")
+	format := flag.String("format", "console", "Event stream format: console (human-readable) or json (one object per line, pipe-able to jq/ClickHouse/Loki)")
+	out := flag.String("out", "stdout", "Event stream destination: stdout, stderr, or a file path")
 	flag.Parse()
 
+	sink, closeSink, err := openEventSink(*out)
+	if err != nil {
+		log.Fatalf("event sink: %v", err)
+	}
+	defer closeSink()
+	probeLogger = newProbeLogger(*format, sink)
+
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", newMetricsHandler())
+		mux.Handle("/peer_history", newPeerHistoryHandler(lastDisconnectReasons))
+		go func() {
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				log.Printf("Warning: metrics server stopped: %v", err)
+			}
+		}()
+		log.Printf("Prometheus metrics available at http://%s/metrics", *metricsAddr)
+	}
+
 	cfg, err := loadConfig(*cfgPath)
 	if err != nil {
 		log.Fatalf("config load: %v", err)
@@ -42,11 +115,18 @@ func main() {
 	if !cfg.Peering.Enabled {
 		log.Fatalf("peering is disabled in config")
 	}
-	peerCfg, ok := firstEnabledPeer(cfg.Peering.Peers)
-	if !ok {
+	enabledPeers := enabledPeerConfigs(cfg.Peering.Peers)
+	if len(enabledPeers) == 0 {
 		log.Fatalf("no enabled peers found")
 	}
 
+	dxccTrie, err := loadDXCCTrie(*dxccPrefixesPath)
+	if err != nil {
+		log.Printf("dxcc: prefix table unavailable, entity filtering disabled: %v", err)
+	}
+	dxFilter := dxcc.NewFilter(dxccTrie, splitList(*allowDX), splitList(*blockDX))
+	peerFilter := dxcc.NewFilter(dxccTrie, splitList(*allowPeerEntities), splitList(*blockPeerEntities))
+
 	peerEvents := make(chan spotEvent, 1024)
 	telnetEvents := make(chan spotEvent, 1024)
 
@@ -57,12 +137,45 @@ func main() {
 	}
 	startTelnetTap(clusterHostName, clusterHostPort, *clusterCall, telnetEvents)
 
-	// Start peer loop (auto-reconnect on EOF) in background.
-	tsGen := &timestampGenerator{}
-	go peerLoop(cfg, peerEvents, peerCfg, tsGen)
+	wwvStore, err := wwv.NewStore(wwv.Config{}, *wwvDB)
+	if err != nil {
+		log.Fatalf("wwv store: %v", err)
+	}
+	wwvStore.SetLogger(probeLogger)
+	defer wwvStore.Close()
+
+	var topologyStore *peer.TopologyStore
+	if *topologyDB != "" {
+		topologyStore, err = peer.OpenTopologyStore(*topologyDB, *topologyRetention)
+		if err != nil {
+			log.Fatalf("topology store: %v", err)
+		}
+		defer topologyStore.Close()
+	}
+
+	registry, err := newPeerRegistry(cfg.Peering, enabledPeers)
+	if err != nil {
+		log.Fatalf("peer registry: %v", err)
+	}
+
+	// Drive peer membership through PeerManager rather than a fixed
+	// goroutine-per-peer loop, so cfg.Peering.RegistryBackend's etcd/consul
+	// options can add and drop peers at runtime; the static backend still
+	// behaves exactly like the old fixed loop (one PeerAdded batch, no
+	// removals until shutdown).
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runner := newPeerRunner(ctx, cfg, peerEvents, dxFilter, peerFilter, wwvStore, topologyStore)
+	manager := peer.NewPeerManager(registry, runner.dial, runner.drop)
+	go func() {
+		if err := manager.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatalf("peer manager: %v", err)
+		}
+	}()
 
 	matchWindow := time.Duration(*windowMinutes) * time.Minute
-	runMatcher(matchWindow, peerEvents, telnetEvents)
+	dedupWindow := time.Duration(*dedupWindowSeconds) * time.Second
+	runMatcher(matchWindow, dedupWindow, peerEvents, telnetEvents, wwvStore, topologyStore)
 }
 
 func loadConfig(path string) (*config.Config, error) {
@@ -73,16 +186,68 @@ func loadConfig(path string) (*config.Config, error) {
 	return cfg, nil
 }
 
+// loadDXCCTrie loads the DXCC prefix trie from an explicit path if given,
+// otherwise falls back to dxcc.Load's repo-standard search.
+func loadDXCCTrie(path string) (*dxcc.Trie, error) {
+	if strings.TrimSpace(path) != "" {
+		return dxcc.LoadFile(path)
+	}
+	return dxcc.Load()
+}
+
+// splitList splits a comma-separated flag value into trimmed, non-empty
+// entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// idleReadTimeout bounds how long readPeerFeed will wait for the next line
+// before treating the connection as stalled (DisconnectIdleTimeout) rather
+// than leaving it to read forever; it's 3x keepaliveLoop's interval so a
+// couple of missed keepalives are tolerated before giving up.
+const idleReadTimeout = 90 * time.Second
+
+// classifyReadErr wraps a line-reader error with a peer.DisconnectReason so
+// callers can decide how to react (and how long to wait before retrying)
+// without pattern-matching the error text.
+func classifyReadErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(err, io.EOF):
+		return peer.NewPeerError(peer.DisconnectRemoteClosed, err)
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return peer.NewPeerError(peer.DisconnectIdleTimeout, err)
+	case strings.Contains(err.Error(), "line too long"):
+		return peer.NewPeerError(peer.DisconnectMalformedFrame, err)
+	default:
+		return peer.NewPeerError(peer.DisconnectNetworkError, err)
+	}
+}
+
 // readPeerFeed consumes PC frames from the peer connection and emits spot events for PC11/PC61.
-// It also replies to PC51 pings to keep the session alive. On read error, it reports the error
-// to errOut and returns so the caller can reconnect.
-func readPeerFeed(conn net.Conn, reader *lineReader, writer *bufio.Writer, writeMu *sync.Mutex, pc9x bool, localCall string, fallbackOrigin string, tsGen *timestampGenerator, out chan<- spotEvent, errOut chan<- error) {
+// It also replies to PC51 pings to keep the session alive and ingests PC23/PC73 propagation
+// bulletins into wwvStore. Every frame, regardless of type, is additionally passed to
+// topologyStore.Ingest (nil when -topology_db is unset, which Ingest tolerates): PC92 expands
+// into its full node chain and the edges between hops, and every other frame type is recorded
+// as a bare sighting of its origin, so nothing falls through unrecorded. On read error, it
+// reports the error to errOut and returns so the caller can reconnect.
+func readPeerFeed(conn net.Conn, reader *lineReader, writer *bufio.Writer, writeMu *sync.Mutex, pc9x bool, localCall string, fallbackOrigin string, peerTag string, dxFilter, peerFilter *dxcc.Filter, tsGen *timestampGenerator, out chan<- spotEvent, errOut chan<- error, wwvStore *wwv.Store, topologyStore *peer.TopologyStore) {
+	peerAllowed := peerFilter.Allows(fallbackOrigin)
 	for {
-		_ = conn.SetReadDeadline(time.Time{})
+		_ = conn.SetReadDeadline(time.Now().Add(idleReadTimeout))
 		line, err := reader.ReadLine()
 		if err != nil {
 			if errOut != nil {
-				errOut <- err
+				errOut <- classifyReadErr(err)
 			}
 			return
 		}
@@ -94,18 +259,54 @@ func readPeerFeed(conn net.Conn, reader *lineReader, writer *bufio.Writer, write
 		if err != nil {
 			continue
 		}
+		topologyStore.Ingest(frame, arrival)
 		switch frame.Type {
 		case "PC51":
 			handlePeerPing(frame, writeMu, writer, localCall, pc9x, tsGen)
 		case "PC11", "PC61":
+			if !peerAllowed {
+				continue
+			}
 			if s, err := parseSpotFromFrame(frame, fallbackOrigin); err == nil {
+				if !dxFilter.Allows(s.DXCall) {
+					continue
+				}
 				s.RefreshBeaconFlag()
 				s.EnsureNormalized()
-				log.Printf("PEER ARRIVAL %s DX %s DE %s", arrival.Format(time.RFC3339Nano), s.DXCall, s.DECall)
-				out <- spotEvent{Spot: s, Arrival: arrival, Source: "peer"}
+				peerSpotsTotal.WithLabelValues(peerTag, frame.Type).Inc()
+				events.Info(probeLogger, "peer_spot",
+					events.String("source", peerTag),
+					events.String("peer_call", peerTag),
+					events.String("dx", s.DXCall),
+					events.String("de", s.DECall),
+					events.Float("freq_khz", s.Frequency),
+					events.String("mode", s.Mode))
+				out <- spotEvent{Spot: s, Arrival: arrival, Source: peerTag}
 			} else {
 				// Silently drop parse errors; match analysis is noise-free.
 			}
+		case "PC23", "PC73":
+			kind := wwv.KindWWV
+			if frame.Type == "PC73" {
+				kind = wwv.KindWCY
+			}
+			wwvEventsTotal.WithLabelValues(string(kind)).Inc()
+			ev, _, _, err := wwvStore.IngestFrame(kind, frame)
+			if err != nil {
+				continue
+			}
+			if kind == wwv.KindWWV {
+				wwvSFI.Set(float64(ev.SFI))
+				wwvAIndex.Set(float64(ev.A))
+				wwvKIndex.Set(float64(ev.K))
+			}
+			events.Info(probeLogger, "wwv_bulletin",
+				events.String("source", peerTag),
+				events.String("kind", string(kind)),
+				events.String("origin", ev.Origin),
+				events.Int("sfi", ev.SFI),
+				events.Int("a", ev.A),
+				events.Int("k", ev.K))
 		}
 	}
 }
@@ -126,7 +327,13 @@ func startTelnetTap(host string, port int, callsign string, out chan<- spotEvent
 			s.RefreshBeaconFlag()
 			s.EnsureNormalized()
 			arrival := time.Now()
-			log.Printf("TELNET ARRIVAL %s DX %s DE %s", arrival.Format(time.RFC3339Nano), s.DXCall, s.DECall)
+			telnetSpotsTotal.Inc()
+			events.Info(probeLogger, "telnet_spot",
+				events.String("source", "telnet"),
+				events.String("dx", s.DXCall),
+				events.String("de", s.DECall),
+				events.Float("freq_khz", s.Frequency),
+				events.String("mode", s.Mode))
 			out <- spotEvent{Spot: s, Arrival: arrival, Source: "telnet"}
 		}
 	}()
@@ -135,17 +342,28 @@ func startTelnetTap(host string, port int, callsign string, out chan<- spotEvent
 type spotEvent struct {
 	Spot    *spot.Spot
 	Arrival time.Time
-	Source  string // peer or telnet
+	Source  string // the peer callsign that reported it, or "telnet"
 }
 
-// runMatcher correlates peer spots and cluster telnet spots within a sliding window and reports delay stats.
-func runMatcher(window time.Duration, peerEvents <-chan spotEvent, telnetEvents <-chan spotEvent) {
+// runMatcher correlates peer spots and cluster telnet spots within a sliding
+// window and reports delay stats. Peer events pass through a
+// crossPeerDeduper first: when more than one enabled peer is configured,
+// every peer is treated as an interchangeable transport for the same
+// upstream spot, and only the first peer to report it within dedupWindow is
+// forwarded into the telnet match - later arrivals are collapsed, with their
+// delay relative to the winning peer recorded in per-peer stats.
+func runMatcher(window, dedupWindow time.Duration, peerEvents <-chan spotEvent, telnetEvents <-chan spotEvent, wwvStore *wwv.Store, topologyStore *peer.TopologyStore) {
 	if window <= 0 {
 		window = 3 * time.Minute
 	}
+	if dedupWindow <= 0 {
+		dedupWindow = 30 * time.Second
+	}
 	peerStore := newEventStore(window)
 	telnetStore := newEventStore(window)
 	stats := newDelayStats()
+	stats.hist = peerVsTelnetDelaySeconds
+	dedup := newCrossPeerDeduper(dedupWindow)
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
@@ -155,9 +373,15 @@ func runMatcher(window time.Duration, peerEvents <-chan spotEvent, telnetEvents
 			if ev.Spot == nil {
 				continue
 			}
+			if !dedup.observe(ev) {
+				continue // a later peer's report of a spot another peer already won
+			}
 			if matched, delta, _ := telnetStore.match(ev); matched {
 				stats.add(delta)
-				log.Printf("DX %s / DE %s : %s", ev.Spot.DXCall, ev.Spot.DECall, formatDelay(delta))
+				events.Info(probeLogger, "match",
+					events.String("dx", ev.Spot.DXCall),
+					events.String("de", ev.Spot.DECall),
+					events.Int("delta_ms", int(delta.Milliseconds())))
 			} else {
 				peerStore.add(ev)
 			}
@@ -167,15 +391,132 @@ func runMatcher(window time.Duration, peerEvents <-chan spotEvent, telnetEvents
 			}
 			if matched, delta, _ := peerStore.match(ev); matched {
 				stats.add(-delta) // telnet arrival minus peer arrival
-				log.Printf("DX %s / DE %s : %s", ev.Spot.DXCall, ev.Spot.DECall, formatDelay(-delta))
+				events.Info(probeLogger, "match",
+					events.String("dx", ev.Spot.DXCall),
+					events.String("de", ev.Spot.DECall),
+					events.Int("delta_ms", int(-delta.Milliseconds())))
 			} else {
 				telnetStore.add(ev)
 			}
 		case <-ticker.C:
 			peerStore.prune()
 			telnetStore.prune()
+			dedup.prune()
+			topologyStore.Prune(time.Now())
+			matcherUnmatched.WithLabelValues("peer").Set(float64(peerStore.len()))
+			matcherUnmatched.WithLabelValues("telnet").Set(float64(telnetStore.len()))
+			events.Debug(probeLogger, "prune",
+				events.Int("peer_unmatched", peerStore.len()),
+				events.Int("telnet_unmatched", telnetStore.len()))
+			logPeerStats(dedup.stats)
+			logWWVStats(wwvStore)
+		}
+	}
+}
+
+// crossPeerDeduper collapses identical PC11/PC61 spots arriving from more
+// than one enabled peer within window, remembering which peer reported each
+// spot first. observe returns true for the winning (first) report and false
+// for every later peer's report of the same spot, recording that peer's
+// delay relative to the winner in stats along the way.
+type crossPeerDeduper struct {
+	window  time.Duration
+	mu      sync.Mutex
+	winners map[string]spotEvent
+	stats   *peerDelayStats
+}
+
+func newCrossPeerDeduper(window time.Duration) *crossPeerDeduper {
+	return &crossPeerDeduper{
+		window:  window,
+		winners: make(map[string]spotEvent),
+		stats:   newPeerDelayStats(),
+	}
+}
+
+func (d *crossPeerDeduper) observe(ev spotEvent) bool {
+	key := spotKey(ev.Spot)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	winner, ok := d.winners[key]
+	if ok && ev.Arrival.Sub(winner.Arrival) <= d.window {
+		if ev.Source != winner.Source {
+			d.stats.add(ev.Source, ev.Arrival.Sub(winner.Arrival))
+		}
+		return false
+	}
+	d.winners[key] = ev
+	return true
+}
+
+func (d *crossPeerDeduper) prune() {
+	cutoff := time.Now().Add(-d.window)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, ev := range d.winners {
+		if ev.Arrival.Before(cutoff) {
+			delete(d.winners, key)
+		}
+	}
+}
+
+// peerDelayStats accumulates per-peer first-to-report statistics: every time
+// a peer's report of a spot loses to another peer's earlier report within
+// the dedup window, the delta (this peer's arrival minus the winner's) is
+// folded into that peer's delayStats.
+type peerDelayStats struct {
+	mu     sync.Mutex
+	byPeer map[string]*delayStats
+}
+
+func newPeerDelayStats() *peerDelayStats {
+	return &peerDelayStats{byPeer: make(map[string]*delayStats)}
+}
+
+func (p *peerDelayStats) add(peerCall string, delta time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.byPeer[peerCall]
+	if !ok {
+		s = newDelayStats()
+		p.byPeer[peerCall] = s
+	}
+	s.add(delta)
+}
+
+func (p *peerDelayStats) snapshot() map[string]delayStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make(map[string]delayStats, len(p.byPeer))
+	for peerCall, s := range p.byPeer {
+		out[peerCall] = *s
+	}
+	return out
+}
+
+// logPeerStats prints each peer's first-to-report delay relative to whichever
+// peer won that spot, so an operator can see which peers are consistently
+// slower without waiting for the telnet/peer match report.
+func logPeerStats(stats *peerDelayStats) {
+	snap := stats.snapshot()
+	for peerCall, s := range snap {
+		if s.count == 0 {
+			continue
 		}
+		log.Printf("PEER STATS %s: lost-race=%d mean=%s min=%s max=%s", peerCall, s.count, formatDelay(s.mean()), formatDelay(s.min), formatDelay(s.max))
+	}
+}
+
+// logWWVStats prints the current propagation conditions, the stats-pane
+// equivalent for this CLI tool: the most recent SFI/A/K this probe has seen
+// from any peer's WWV bulletin.
+func logWWVStats(wwvStore *wwv.Store) {
+	ev, ok := wwvStore.LatestKind(wwv.KindWWV)
+	if !ok {
+		return
 	}
+	log.Printf("WWV STATS: SFI=%d A=%d K=%d (origin=%s, %s ago)", ev.SFI, ev.A, ev.K, ev.Origin, formatDelay(time.Since(ev.Timestamp)))
 }
 
 // eventStore holds unmatched events for a limited window.
@@ -265,7 +606,7 @@ func spotKey(s *spot.Spot) string {
 }
 
 // keepaliveLoop sends periodic keepalives to prevent remote idle timeouts.
-func keepaliveLoop(writeMu *sync.Mutex, writer *bufio.Writer, pc9x bool, cfg config.PeeringConfig, peerCfg config.PeeringPeer, tsGen *timestampGenerator, stop <-chan struct{}) {
+func keepaliveLoop(writeMu *sync.Mutex, writer *bufio.Writer, pc9x bool, cfg config.PeeringConfig, peerCfg config.PeeringPeer, tsGen *timestampGenerator, stop <-chan struct{}, errOut chan<- error) {
 	// The probe sends its own keepalives every 30 seconds to mirror common DXSpider expectations.
 	interval := 30 * time.Second
 	ticker := time.NewTicker(interval)
@@ -273,12 +614,20 @@ func keepaliveLoop(writeMu *sync.Mutex, writer *bufio.Writer, pc9x bool, cfg con
 	for {
 		select {
 		case <-ticker.C:
+			var err error
 			if pc9x {
 				entry := pc92Entry(cfg.LocalCallsign, cfg.NodeVersion, cfg.NodeBuild, cfg.PC92Bitmap)
 				ts := tsGen.Next()
-				_ = sendLine(writeMu, writer, fmt.Sprintf("PC92^%s^%s^K^%s^%d^%d^H%d^", cfg.LocalCallsign, ts, entry, cfg.NodeCount, cfg.UserCount, cfg.HopCount))
+				err = sendLine(writeMu, writer, fmt.Sprintf("PC92^%s^%s^K^%s^%d^%d^H%d^", cfg.LocalCallsign, ts, entry, cfg.NodeCount, cfg.UserCount, cfg.HopCount))
 			} else {
-				_ = sendLine(writeMu, writer, fmt.Sprintf("PC51^%s^%s^1^", peerCfg.RemoteCallsign, cfg.LocalCallsign))
+				err = sendLine(writeMu, writer, fmt.Sprintf("PC51^%s^%s^1^", peerCfg.RemoteCallsign, cfg.LocalCallsign))
+			}
+			if err != nil {
+				select {
+				case errOut <- peer.NewPeerError(peer.DisconnectNetworkError, err):
+				default:
+				}
+				return
 			}
 		case <-stop:
 			return
@@ -337,11 +686,17 @@ func resolveClusterEndpoint(hostFlag string, portFlag int, defaultPort int) (str
 	return host, portFlag, nil
 }
 
+// delayStats tracks count/sum/min/max of observed delays, same as before
+// Prometheus was wired in; hist, when set, is also fed every observation so
+// the same data is visible as dxcluster_peer_vs_telnet_delay_seconds in
+// Grafana instead of only in log lines. hist is nil for the per-peer
+// peerDelayStats instances, which aren't individually exported as metrics.
 type delayStats struct {
 	count int64
 	sum   time.Duration
 	min   time.Duration
 	max   time.Duration
+	hist  prometheus.Observer
 }
 
 func newDelayStats() *delayStats {
@@ -362,6 +717,20 @@ func (d *delayStats) add(delta time.Duration) {
 	}
 	d.count++
 	d.sum += delta
+	if d.hist != nil {
+		abs := delta
+		if abs < 0 {
+			abs = -abs
+		}
+		d.hist.Observe(abs.Seconds())
+	}
+}
+
+func (d delayStats) mean() time.Duration {
+	if d.count == 0 {
+		return 0
+	}
+	return d.sum / time.Duration(d.count)
 }
 
 func formatDelay(d time.Duration) string {
@@ -373,27 +742,106 @@ func formatDelay(d time.Duration) string {
 	return fmt.Sprintf("%s%s", sign, d)
 }
 
-// peerLoop maintains the peer connection with auto-reconnect on errors.
-func peerLoop(cfg *config.Config, peerEvents chan<- spotEvent, peerCfg config.PeeringPeer, tsGen *timestampGenerator) {
+// peerLoop maintains the peer connection with auto-reconnect on errors. The
+// wait before reconnecting depends on the peer.DisconnectReason runPeerSession
+// returned: AuthRejected never retries (the credentials aren't going to fix
+// themselves), NetworkError backs off exponentially up to networkErrorMaxBackoff
+// so a flapping link doesn't hammer the remote, IdleTimeout retries quickly
+// since the link itself was fine, and everything else uses the historical
+// fixed 5s delay.
+// peerLoop dials and re-dials peerCfg until ctx is canceled (a PeerRemoved
+// event from a dynamic registry, or process shutdown) or a fatal disconnect
+// gives up for good.
+func peerLoop(ctx context.Context, cfg *config.Config, peerEvents chan<- spotEvent, peerCfg config.PeeringPeer, dxFilter, peerFilter *dxcc.Filter, tsGen *timestampGenerator, wwvStore *wwv.Store, topologyStore *peer.TopologyStore) {
+	const (
+		defaultRetryDelay      = 5 * time.Second
+		idleRetryDelay         = 2 * time.Second
+		networkErrorMaxBackoff = 60 * time.Second
+	)
+	// peerCfg.BackoffPolicy ("", "full_jitter", or "decorrelated_jitter")
+	// lets operators trade off reconnect speed against thundering-herd risk
+	// per peer; see peer.backoffPolicyFromName for the accepted values.
+	networkBackoff := peer.NewReconnectBackoff(defaultRetryDelay, networkErrorMaxBackoff, peerCfg.BackoffPolicy)
+
 	for {
-		if err := runPeerSession(cfg, peerEvents, peerCfg, tsGen); err != nil {
-			log.Printf("Peer session ended: %v; reconnecting in 5s", err)
-			time.Sleep(5 * time.Second)
-			continue
+		if ctx.Err() != nil {
+			return
+		}
+		err := runPeerSession(ctx, cfg, peerEvents, peerCfg, dxFilter, peerFilter, tsGen, wwvStore, topologyStore)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			// ctx was canceled out from under the session (PeerRemoved, or
+			// process shutdown); runPeerSession's error is just the read
+			// error from us force-closing the connection, not a real
+			// disconnect worth logging or retrying.
+			return
+		}
+
+		reason := peer.DisconnectUnknown
+		var perr *peer.PeerError
+		if errors.As(err, &perr) {
+			reason = perr.Reason
+		}
+		lastDisconnectReasons.record(peerCfg.RemoteCallsign, reason.String())
+		peerReconnectsTotal.WithLabelValues(peerCfg.RemoteCallsign, reason.String()).Inc()
+		events.Warn(probeLogger, "reconnect",
+			events.String("peer_call", peerCfg.RemoteCallsign),
+			events.String("reason", reason.String()),
+			events.Err(err))
+
+		if perr != nil && perr.Fatal {
+			log.Printf("Peer %s: fatal disconnect (%s), not retrying: %v", peerCfg.RemoteCallsign, reason, err)
+			return
+		}
+
+		var delay time.Duration
+		switch reason {
+		case peer.DisconnectNetworkError:
+			delay = networkBackoff.Next()
+		case peer.DisconnectIdleTimeout:
+			delay = idleRetryDelay
+			networkBackoff.Reset()
+		default:
+			delay = defaultRetryDelay
+			networkBackoff.Reset()
+		}
+		log.Printf("Peer session ended (%s): %v; reconnecting in %s", reason, err, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
 		}
-		return
 	}
 }
 
-func runPeerSession(cfg *config.Config, peerEvents chan<- spotEvent, peerCfg config.PeeringPeer, tsGen *timestampGenerator) error {
+// runPeerSession dials peerCfg and runs one handshake+frame-reading session.
+// It watches ctx for as long as the session is live, force-closing conn on
+// cancellation so a reconnected/removed peer's blocked read unblocks
+// promptly instead of waiting for the next idle timeout.
+func runPeerSession(ctx context.Context, cfg *config.Config, peerEvents chan<- spotEvent, peerCfg config.PeeringPeer, dxFilter, peerFilter *dxcc.Filter, tsGen *timestampGenerator, wwvStore *wwv.Store, topologyStore *peer.TopologyStore) error {
 	addr := net.JoinHostPort(peerCfg.Host, fmt.Sprintf("%d", peerCfg.Port))
 	conn, err := net.DialTimeout("tcp", addr, time.Duration(cfg.Peering.Timeouts.LoginSeconds)*time.Second)
 	if err != nil {
-		return fmt.Errorf("dial: %w", err)
+		return peer.NewPeerError(peer.DisconnectNetworkError, fmt.Errorf("dial: %w", err))
 	}
 	defer conn.Close()
 
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
 	reader := newLineReader(conn, cfg.Peering.MaxLineLength)
+	reader.parser.OnResize(func(w, h int) {
+		events.Debug(probeLogger, "telnet_resize", events.String("peer_call", peerCfg.RemoteCallsign), events.Int("width", w), events.Int("height", h))
+	})
 	writer := bufio.NewWriter(conn)
 	writeMu := &sync.Mutex{}
 
@@ -405,33 +853,113 @@ func runPeerSession(cfg *config.Config, peerEvents chan<- spotEvent, peerCfg con
 		_ = sendLine(writeMu, writer, peerCfg.Password)
 	}
 
+	handshakeStart := time.Now()
 	established, pc9x, err := handshake(context.Background(), reader, writeMu, writer, cfg, peerCfg, tsGen)
 	if err != nil {
 		return fmt.Errorf("handshake: %w", err)
 	}
 	if !established {
-		return fmt.Errorf("handshake incomplete")
+		return peer.NewPeerError(peer.DisconnectProtocolError, fmt.Errorf("handshake incomplete"))
 	}
+	handshakeDelta := time.Since(handshakeStart)
+	peerHandshakeDurationSeconds.Observe(handshakeDelta.Seconds())
 	log.Printf("Handshake established (pc9x=%v). Reading frames...", pc9x)
+	events.Info(probeLogger, "handshake",
+		events.String("peer_call", peerCfg.RemoteCallsign),
+		events.String("source", addr),
+		events.Int("delta_ms", int(handshakeDelta.Milliseconds())),
+		events.String("pc9x", strconv.FormatBool(pc9x)))
 
 	stopKA := make(chan struct{})
-	go keepaliveLoop(writeMu, writer, pc9x, cfg.Peering, peerCfg, tsGen, stopKA)
+	kaErrCh := make(chan error, 1)
+	go keepaliveLoop(writeMu, writer, pc9x, cfg.Peering, peerCfg, tsGen, stopKA, kaErrCh)
 
 	errCh := make(chan error, 1)
-	go readPeerFeed(conn, reader, writer, writeMu, pc9x, cfg.Peering.LocalCallsign, peerCfg.RemoteCallsign, tsGen, peerEvents, errCh)
+	go readPeerFeed(conn, reader, writer, writeMu, pc9x, cfg.Peering.LocalCallsign, peerCfg.RemoteCallsign, peerCfg.RemoteCallsign, dxFilter, peerFilter, tsGen, peerEvents, errCh, wwvStore, topologyStore)
 
-	err = <-errCh
+	select {
+	case err = <-errCh:
+	case err = <-kaErrCh:
+	}
 	close(stopKA)
 	return err
 }
 
-func firstEnabledPeer(peers []config.PeeringPeer) (config.PeeringPeer, bool) {
+func enabledPeerConfigs(peers []config.PeeringPeer) []config.PeeringPeer {
+	var out []config.PeeringPeer
 	for _, p := range peers {
 		if p.Enabled {
-			return p, true
+			out = append(out, p)
 		}
 	}
-	return config.PeeringPeer{}, false
+	return out
+}
+
+// newPeerRegistry picks the PeerRegistry backend named by
+// cfg.Peering.RegistryBackend ("", "static", "etcd", or "consul"; empty
+// defaults to static, matching the pre-existing fixed peer list behavior).
+func newPeerRegistry(cfg config.PeeringConfig, staticPeers []config.PeeringPeer) (peer.PeerRegistry, error) {
+	switch cfg.RegistryBackend {
+	case "", "static":
+		return peer.NewStaticPeerRegistry(staticPeers), nil
+	case "etcd":
+		return peer.NewEtcdPeerRegistry(cfg.Etcd)
+	case "consul":
+		return peer.NewConsulPeerRegistry(cfg.Consul)
+	default:
+		return nil, fmt.Errorf("unknown peer registry backend %q", cfg.RegistryBackend)
+	}
+}
+
+// peerRunner turns PeerManager's dial/drop callbacks into peerLoop
+// goroutines, keyed by PeerEndpoint.ID() so a later PeerRemoved event can
+// cancel the right one. A peer re-added under the same ID (e.g. a brief
+// registry flap) replaces its predecessor's entry rather than leaking it.
+type peerRunner struct {
+	ctx           context.Context
+	cfg           *config.Config
+	peerEvents    chan<- spotEvent
+	dxFilter      *dxcc.Filter
+	peerFilter    *dxcc.Filter
+	wwvStore      *wwv.Store
+	topologyStore *peer.TopologyStore
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newPeerRunner(ctx context.Context, cfg *config.Config, peerEvents chan<- spotEvent, dxFilter, peerFilter *dxcc.Filter, wwvStore *wwv.Store, topologyStore *peer.TopologyStore) *peerRunner {
+	return &peerRunner{
+		ctx:           ctx,
+		cfg:           cfg,
+		peerEvents:    peerEvents,
+		dxFilter:      dxFilter,
+		peerFilter:    peerFilter,
+		wwvStore:      wwvStore,
+		topologyStore: topologyStore,
+		cancels:       make(map[string]context.CancelFunc),
+	}
+}
+
+func (r *peerRunner) dial(ep peer.PeerEndpoint) {
+	peerCtx, cancel := context.WithCancel(r.ctx)
+	r.mu.Lock()
+	if prev, ok := r.cancels[ep.ID()]; ok {
+		prev()
+	}
+	r.cancels[ep.ID()] = cancel
+	r.mu.Unlock()
+	go peerLoop(peerCtx, r.cfg, r.peerEvents, ep.ToPeeringPeer(), r.dxFilter, r.peerFilter, &timestampGenerator{}, r.wwvStore, r.topologyStore)
+}
+
+func (r *peerRunner) drop(ep peer.PeerEndpoint) {
+	r.mu.Lock()
+	cancel, ok := r.cancels[ep.ID()]
+	delete(r.cancels, ep.ID())
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
 }
 
 func handshake(ctx context.Context, reader *lineReader, writeMu *sync.Mutex, writer *bufio.Writer, cfg *config.Config, peerCfg config.PeeringPeer, tsGen *timestampGenerator) (bool, bool, error) {
@@ -443,20 +971,25 @@ func handshake(ctx context.Context, reader *lineReader, writeMu *sync.Mutex, wri
 
 	for {
 		if time.Now().After(deadline) {
-			return false, pc9x, fmt.Errorf("handshake timeout")
+			return false, pc9x, peer.NewPeerError(peer.DisconnectHandshakeTimeout, fmt.Errorf("handshake timeout"))
 		}
 		if err := connDeadline(reader.conn, deadline); err != nil {
-			return false, pc9x, err
+			return false, pc9x, classifyReadErr(err)
 		}
 		line, err := reader.ReadLine()
 		if err != nil {
-			return false, pc9x, err
+			return false, pc9x, classifyReadErr(err)
 		}
 		if strings.TrimSpace(line) == "" {
 			continue
 		}
 		log.Printf("HS RX %s", line)
 		switch {
+		case strings.Contains(strings.ToLower(line), "invalid") || strings.Contains(strings.ToLower(line), "sorry"):
+			// Best-effort rejection heuristic: DXSpider nodes commonly banner
+			// a rejected login with wording like "Sorry, invalid callsign or
+			// password" rather than a structured PC frame.
+			return false, pc9x, peer.NewPeerError(peer.DisconnectAuthRejected, fmt.Errorf("rejected: %s", strings.TrimSpace(line)))
 		case strings.Contains(line, "PC18^"):
 			pc9x = peerCfg.PreferPC9x && strings.Contains(strings.ToLower(line), "pc9x")
 			if !sentCall && cfg.Peering.LocalCallsign != "" {
@@ -674,15 +1207,293 @@ func isFrameStartAt(b []byte, i int) bool {
 	return b[i+4] == '^'
 }
 
-// telnetParser strips telnet IAC sequences and returns clean payload bytes plus replies.
-// telnetParser strips telnet IAC sequences and returns clean payload bytes plus replies.
-// For this probe we pass through bytes as-is to mirror the RBN/human ingest behavior.
-type telnetParser struct{}
+// Telnet (RFC 854) command and option bytes telnetParser understands.
+const (
+	telnetIAC  = 0xFF
+	telnetDONT = 0xFE
+	telnetDO   = 0xFD
+	telnetWONT = 0xFC
+	telnetWILL = 0xFB
+	telnetSB   = 0xFA
+	telnetGA   = 0xF9
+	telnetEL   = 0xF8
+	telnetEC   = 0xF7
+	telnetAYT  = 0xF6
+	telnetAO   = 0xF5
+	telnetIP   = 0xF4
+	telnetBRK  = 0xF3
+	telnetDM   = 0xF2
+	telnetNOP  = 0xF1
+	telnetSE   = 0xF0
+
+	telnetOptEcho            = 1
+	telnetOptSuppressGoAhead = 3
+	telnetOptBinary          = 0
+	telnetOptTermType        = 24
+	telnetOptNAWS            = 31
+)
+
+// TERMINAL-TYPE (RFC 1091) subnegotiation subcommands.
+const (
+	termTypeIS   = 0
+	termTypeSEND = 1
+)
+
+// defaultTermType is advertised when the remote asks for our terminal type
+// (IAC SB TERMTYPE SEND) and SetTerminalType was never called.
+const defaultTermType = "ANSI"
+
+// defaultNAWSWidth/Height are reported when the remote asks for our window
+// size (IAC DO NAWS) before SetLocalWindowSize has been called.
+const (
+	defaultNAWSWidth  = 80
+	defaultNAWSHeight = 24
+)
+
+type telnetParserState int
+
+const (
+	telnetStateData telnetParserState = iota
+	telnetStateIAC
+	telnetStateNegotiate
+	telnetStateSubnegOpt
+	telnetStateSubneg
+	telnetStateSubnegIAC
+)
+
+// TelnetState is the negotiated state a telnetParser accumulates: which
+// options this session has agreed to, the most recently negotiated NAWS
+// window size (ours or the remote's, whichever arrived last), and the
+// terminal type name advertised on request. It's embedded in telnetParser
+// by value, not by pointer, so a zero-value telnetParser (as used
+// throughout this package's tests) works without a constructor.
+type TelnetState struct {
+	mu       sync.Mutex
+	agreed   uint32 // bitmask of option bytes (0-31) this session has agreed to
+	width    int
+	height   int
+	termType string
+	onResize func(w, h int)
+}
+
+func (s *TelnetState) markAgreed(option byte) {
+	if option > 31 {
+		return
+	}
+	s.mu.Lock()
+	s.agreed |= 1 << option
+	s.mu.Unlock()
+}
+
+// OnResize registers fn to be called whenever a NAWS subnegotiation updates
+// the negotiated window size, so a caller (e.g. a status display) can react
+// to the remote reporting a new size.
+func (s *TelnetState) OnResize(fn func(w, h int)) {
+	s.mu.Lock()
+	s.onResize = fn
+	s.mu.Unlock()
+}
+
+// WindowSize returns the most recently negotiated NAWS width/height.
+func (s *TelnetState) WindowSize() (w, h int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.width, s.height
+}
+
+// TerminalType returns the terminal type name last set via setTermType.
+func (s *TelnetState) TerminalType() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.termType
+}
+
+func (s *TelnetState) setWindowSize(w, h int) {
+	s.mu.Lock()
+	s.width, s.height = w, h
+	cb := s.onResize
+	s.mu.Unlock()
+	if cb != nil {
+		cb(w, h)
+	}
+}
+
+func (s *TelnetState) setTermType(name string) {
+	s.mu.Lock()
+	s.termType = name
+	s.mu.Unlock()
+}
+
+// telnetParser strips telnet IAC sequences out of a peer byte stream,
+// returning the clean payload plus any negotiation replies the caller
+// should write back. It agrees to SUPPRESS-GO-AHEAD, BINARY, NAWS, and
+// TERMINAL-TYPE, refusing everything else (including ECHO, in both
+// directions, since this probe only reads and never wants the peer echoing
+// its own output back). NAWS and TERMINAL-TYPE subnegotiations are decoded
+// (width/height and a requested terminal-type reply respectively); every
+// other SB body is dropped after its option byte is read. A literal 0xFF
+// anywhere in the stream, payload or subnegotiation body, is IAC
+// IAC-escaped. State lives on the struct so Feed is resumable across calls
+// even if an IAC sequence or subnegotiation is split across TCP reads.
+type telnetParser struct {
+	state      telnetParserState
+	pendingCmd byte
+	subnegOpt  byte
+	subnegBuf  []byte
+	telnet     TelnetState
+}
 
 func (p *telnetParser) Feed(input []byte) (output []byte, replies [][]byte) {
-	return input, nil
+	for _, b := range input {
+		switch p.state {
+		case telnetStateData:
+			if b == telnetIAC {
+				p.state = telnetStateIAC
+				continue
+			}
+			output = append(output, b)
+		case telnetStateIAC:
+			switch b {
+			case telnetIAC:
+				output = append(output, telnetIAC)
+				p.state = telnetStateData
+			case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+				p.pendingCmd = b
+				p.state = telnetStateNegotiate
+			case telnetSB:
+				p.state = telnetStateSubnegOpt
+			case telnetNOP, telnetDM, telnetBRK, telnetIP, telnetAO, telnetAYT, telnetEC, telnetEL, telnetGA:
+				// Two-byte commands carry no payload; nothing else to consume.
+				p.state = telnetStateData
+			default:
+				// Unrecognized command byte; drop it and resume.
+				p.state = telnetStateData
+			}
+		case telnetStateNegotiate:
+			replies = append(replies, p.negotiationReplies(p.pendingCmd, b)...)
+			p.state = telnetStateData
+		case telnetStateSubnegOpt:
+			p.subnegOpt = b
+			p.subnegBuf = p.subnegBuf[:0]
+			p.state = telnetStateSubneg
+		case telnetStateSubneg:
+			if b == telnetIAC {
+				p.state = telnetStateSubnegIAC
+				continue
+			}
+			p.subnegBuf = append(p.subnegBuf, b)
+		case telnetStateSubnegIAC:
+			switch b {
+			case telnetSE:
+				replies = append(replies, p.dispatchSubneg()...)
+				p.state = telnetStateData
+			case telnetIAC:
+				p.subnegBuf = append(p.subnegBuf, telnetIAC)
+				p.state = telnetStateSubneg
+			default:
+				p.state = telnetStateSubneg
+			}
+		}
+	}
+	return output, replies
 }
 
+// negotiationReplies returns the IAC reply/replies for a WILL/DO option
+// offer, or nil if none should be sent (WONT/DONT, per RFC 854, get no
+// reply). Agreeing to DO NAWS also queues our current window size as an
+// immediate SB NAWS reply, since the remote asked for it and we already
+// know it.
+func (p *telnetParser) negotiationReplies(cmd, option byte) [][]byte {
+	switch cmd {
+	case telnetWILL:
+		switch option {
+		case telnetOptSuppressGoAhead, telnetOptBinary, telnetOptNAWS:
+			p.telnet.markAgreed(option)
+			return [][]byte{{telnetIAC, telnetDO, option}}
+		default:
+			return [][]byte{{telnetIAC, telnetDONT, option}}
+		}
+	case telnetDO:
+		switch option {
+		case telnetOptSuppressGoAhead, telnetOptBinary, telnetOptTermType:
+			p.telnet.markAgreed(option)
+			return [][]byte{{telnetIAC, telnetWILL, option}}
+		case telnetOptNAWS:
+			p.telnet.markAgreed(option)
+			w, h := p.telnet.WindowSize()
+			if w <= 0 || h <= 0 {
+				w, h = defaultNAWSWidth, defaultNAWSHeight
+			}
+			return [][]byte{{telnetIAC, telnetWILL, option}, encodeNAWS(w, h)}
+		default:
+			return [][]byte{{telnetIAC, telnetWONT, option}}
+		}
+	default:
+		return nil
+	}
+}
+
+// dispatchSubneg decodes a completed SB ... IAC SE body by option, updating
+// TelnetState and returning any reply it provokes (currently only
+// TERMTYPE SEND, which gets back our advertised terminal type).
+func (p *telnetParser) dispatchSubneg() [][]byte {
+	opt, data := p.subnegOpt, p.subnegBuf
+	p.subnegBuf = nil
+	switch opt {
+	case telnetOptNAWS:
+		if len(data) >= 4 {
+			w := int(data[0])<<8 | int(data[1])
+			h := int(data[2])<<8 | int(data[3])
+			p.telnet.setWindowSize(w, h)
+		}
+	case telnetOptTermType:
+		if len(data) >= 1 && data[0] == termTypeSEND {
+			name := p.telnet.TerminalType()
+			if name == "" {
+				name = defaultTermType
+			}
+			return [][]byte{encodeTermTypeIS(name)}
+		}
+	}
+	return nil
+}
+
+// encodeNAWS builds an IAC SB NAWS <w hi><w lo><h hi><h lo> IAC SE
+// subnegotiation per RFC 1073, escaping any 0xFF byte within the payload.
+func encodeNAWS(w, h int) []byte {
+	return encodeSubneg(telnetOptNAWS, []byte{byte(w >> 8), byte(w), byte(h >> 8), byte(h)})
+}
+
+// encodeTermTypeIS builds an IAC SB TERMTYPE IS <name> IAC SE
+// subnegotiation per RFC 1091, escaping any 0xFF byte within name.
+func encodeTermTypeIS(name string) []byte {
+	return encodeSubneg(telnetOptTermType, append([]byte{termTypeIS}, name...))
+}
+
+func encodeSubneg(option byte, data []byte) []byte {
+	out := []byte{telnetIAC, telnetSB, option}
+	for _, b := range data {
+		out = append(out, b)
+		if b == telnetIAC {
+			out = append(out, telnetIAC)
+		}
+	}
+	return append(out, telnetIAC, telnetSE)
+}
+
+// OnResize registers fn to be called whenever a NAWS subnegotiation (ours
+// or the remote's) reports a new window size.
+func (p *telnetParser) OnResize(fn func(w, h int)) { p.telnet.OnResize(fn) }
+
+// SetLocalWindowSize sets the size this parser reports when the remote
+// asks for NAWS (IAC DO NAWS); callers typically read this from the
+// controlling terminal at startup.
+func (p *telnetParser) SetLocalWindowSize(w, h int) { p.telnet.setWindowSize(w, h) }
+
+// SetTerminalType sets the name advertised when the remote asks for our
+// TERMINAL-TYPE (IAC SB TERMTYPE SEND).
+func (p *telnetParser) SetTerminalType(name string) { p.telnet.setTermType(name) }
+
 // timestampGenerator mirrors the session helper to produce PC92 timestamps.
 type timestampGenerator struct {
 	lastSec int
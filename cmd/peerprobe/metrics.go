@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus metrics for this probe run, mirroring the shape of the main
+// daemon's metrics package (dxcluster/metrics) but scoped to what peerprobe
+// itself observes: per-peer spot counts, the peer/telnet match delay, and
+// connection health. Registered into their own registry (not the default
+// global one) so a probe run never collides with another Prometheus
+// exporter in the same process.
+var (
+	peerSpotsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dxcluster_peer_spots_total",
+		Help: "Spots received from a peer session, by peer callsign and PC frame type.",
+	}, []string{"peer", "type"})
+
+	telnetSpotsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dxcluster_telnet_spots_total",
+		Help: "Spots received from the telnet comparison tap.",
+	})
+
+	peerVsTelnetDelaySeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "dxcluster_peer_vs_telnet_delay_seconds",
+		Help:    "Delay between a peer spot and its matching telnet spot (positive: peer arrived first).",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	matcherUnmatched = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dxcluster_matcher_unmatched",
+		Help: "Spots currently held in the matcher waiting for a counterpart, by source.",
+	}, []string{"source"})
+
+	peerHandshakeDurationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "dxcluster_peer_handshake_duration_seconds",
+		Help:    "Time from dialing a peer to a completed PC9x/legacy handshake.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	peerReconnectsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dxcluster_peer_reconnects_total",
+		Help: "Peer session reconnect attempts, by peer callsign and a coarse failure reason.",
+	}, []string{"peer", "reason"})
+
+	wwvEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dxcluster_wwv_events_total",
+		Help: "PC23/PC73 propagation bulletins received, by kind (WWV or WCY).",
+	}, []string{"kind"})
+
+	wwvSFI = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dxcluster_wwv_sfi",
+		Help: "Solar flux index from the most recently ingested WWV bulletin.",
+	})
+
+	wwvAIndex = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dxcluster_wwv_a_index",
+		Help: "Geomagnetic A index from the most recently ingested WWV bulletin.",
+	})
+
+	wwvKIndex = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dxcluster_wwv_k_index",
+		Help: "Geomagnetic K index from the most recently ingested WWV bulletin.",
+	})
+)
+
+// newMetricsHandler returns an http.Handler serving this probe's Prometheus
+// metrics on its own registry.
+func newMetricsHandler() http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(peerSpotsTotal, telnetSpotsTotal, peerVsTelnetDelaySeconds, matcherUnmatched, peerHandshakeDurationSeconds, peerReconnectsTotal,
+		wwvEventsTotal, wwvSFI, wwvAIndex, wwvKIndex)
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// peerDisconnectHistory keeps the most recent disconnect reasons per peer in
+// memory so an operator can see "what's been happening to this peer lately"
+// from the same process serving /metrics, without trawling logs.
+type peerDisconnectHistory struct {
+	mu     sync.Mutex
+	byPeer map[string][]string
+	cap    int
+}
+
+func newPeerDisconnectHistory(cap int) *peerDisconnectHistory {
+	return &peerDisconnectHistory{byPeer: make(map[string][]string), cap: cap}
+}
+
+func (h *peerDisconnectHistory) record(peerCall, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	list := append(h.byPeer[peerCall], reason)
+	if len(list) > h.cap {
+		list = list[len(list)-h.cap:]
+	}
+	h.byPeer[peerCall] = list
+}
+
+func (h *peerDisconnectHistory) snapshot() map[string][]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[string][]string, len(h.byPeer))
+	for k, v := range h.byPeer {
+		cp := make([]string, len(v))
+		copy(cp, v)
+		out[k] = cp
+	}
+	return out
+}
+
+// lastDisconnectReasons is the last-10-per-peer disconnect reason history,
+// served at /peer_history alongside the Prometheus /metrics endpoint.
+var lastDisconnectReasons = newPeerDisconnectHistory(10)
+
+// newPeerHistoryHandler serves the last-N disconnect reasons per peer as
+// JSON, for an operator who wants "why is this peer flapping" without
+// grepping logs or querying the reconnects counter's cumulative totals.
+func newPeerHistoryHandler(h *peerDisconnectHistory) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(h.snapshot())
+	})
+}
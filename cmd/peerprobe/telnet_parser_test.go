@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTelnetParserPassesPlainTextThrough(t *testing.T) {
+	p := &telnetParser{}
+	out, replies := p.Feed([]byte("PC11^DX^DE^\n"))
+	if string(out) != "PC11^DX^DE^\n" {
+		t.Fatalf("expected plain text untouched, got %q", out)
+	}
+	if replies != nil {
+		t.Fatalf("expected no replies for plain text, got %+v", replies)
+	}
+}
+
+func TestTelnetParserEscapesLiteralIAC(t *testing.T) {
+	p := &telnetParser{}
+	out, _ := p.Feed([]byte{'A', telnetIAC, telnetIAC, 'B'})
+	if !bytes.Equal(out, []byte{'A', telnetIAC, 'B'}) {
+		t.Fatalf("expected IAC IAC to decode to a literal 0xFF, got %v", out)
+	}
+}
+
+func TestTelnetParserAgreesToSuppressGoAhead(t *testing.T) {
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetWILL, telnetOptSuppressGoAhead})
+	want := []byte{telnetIAC, telnetDO, telnetOptSuppressGoAhead}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a DO SUPPRESS-GO-AHEAD reply, got %+v", replies)
+	}
+
+	p = &telnetParser{}
+	_, replies = p.Feed([]byte{telnetIAC, telnetDO, telnetOptSuppressGoAhead})
+	want = []byte{telnetIAC, telnetWILL, telnetOptSuppressGoAhead}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a WILL SUPPRESS-GO-AHEAD reply, got %+v", replies)
+	}
+}
+
+func TestTelnetParserRefusesEcho(t *testing.T) {
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetWILL, telnetOptEcho})
+	want := []byte{telnetIAC, telnetDONT, telnetOptEcho}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a DONT ECHO reply, got %+v", replies)
+	}
+
+	p = &telnetParser{}
+	_, replies = p.Feed([]byte{telnetIAC, telnetDO, telnetOptEcho})
+	want = []byte{telnetIAC, telnetWONT, telnetOptEcho}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a WONT ECHO reply, got %+v", replies)
+	}
+}
+
+func TestTelnetParserRefusesUnknownOption(t *testing.T) {
+	const someOtherOption = 42
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetWILL, someOtherOption})
+	want := []byte{telnetIAC, telnetDONT, someOtherOption}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a default DONT reply for an unrecognized option, got %+v", replies)
+	}
+}
+
+func TestTelnetParserSendsNoReplyForWontOrDont(t *testing.T) {
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetWONT, telnetOptEcho, telnetIAC, telnetDONT, telnetOptEcho})
+	if replies != nil {
+		t.Fatalf("expected no reply to WONT/DONT, got %+v", replies)
+	}
+}
+
+func TestTelnetParserDropsSubnegotiationBody(t *testing.T) {
+	p := &telnetParser{}
+	input := append([]byte("before"), telnetIAC, telnetSB, 24, 0, 80, 0, 24, telnetIAC, telnetSE)
+	input = append(input, []byte("after")...)
+	out, replies := p.Feed(input)
+	if string(out) != "beforeafter" {
+		t.Fatalf("expected the subnegotiation body to be dropped, got %q", out)
+	}
+	if replies != nil {
+		t.Fatalf("expected no replies from a subnegotiation, got %+v", replies)
+	}
+}
+
+func TestTelnetParserHandlesTwoByteCommands(t *testing.T) {
+	p := &telnetParser{}
+	out, replies := p.Feed([]byte{'A', telnetIAC, telnetNOP, 'B', telnetIAC, telnetAYT, 'C'})
+	if string(out) != "ABC" {
+		t.Fatalf("expected two-byte commands to be consumed without output, got %q", out)
+	}
+	if replies != nil {
+		t.Fatalf("expected no replies for two-byte commands, got %+v", replies)
+	}
+}
+
+// TestTelnetParserResumesAcrossSplitFeedCalls is the fuzz-style case the
+// request calls out by name: an IAC WILL <option> sequence delivered one
+// byte per Feed() call, as a slow/fragmented TCP read would.
+func TestTelnetParserResumesAcrossSplitFeedCalls(t *testing.T) {
+	p := &telnetParser{}
+	var out []byte
+	var replies [][]byte
+	for _, b := range []byte{telnetIAC, telnetWILL, telnetOptSuppressGoAhead} {
+		o, r := p.Feed([]byte{b})
+		out = append(out, o...)
+		replies = append(replies, r...)
+	}
+	if len(out) != 0 {
+		t.Fatalf("expected no payload output from a pure negotiation sequence, got %q", out)
+	}
+	want := []byte{telnetIAC, telnetDO, telnetOptSuppressGoAhead}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected the split IAC WILL sequence to still produce a DO reply, got %+v", replies)
+	}
+}
+
+func TestTelnetParserResumesAcrossSplitSubnegotiation(t *testing.T) {
+	p := &telnetParser{}
+	var out []byte
+	parts := [][]byte{
+		[]byte("x"),
+		{telnetIAC},
+		{telnetSB},
+		{24, 0, 80},
+		{telnetIAC},
+		{telnetSE},
+		[]byte("y"),
+	}
+	for _, part := range parts {
+		o, _ := p.Feed(part)
+		out = append(out, o...)
+	}
+	if string(out) != "xy" {
+		t.Fatalf("expected a subnegotiation split across Feed calls to still be dropped cleanly, got %q", out)
+	}
+}
+
+func TestTelnetParserTruncatedIACAtEndOfFeedIsResumable(t *testing.T) {
+	p := &telnetParser{}
+	out1, replies1 := p.Feed([]byte{'A', telnetIAC})
+	if string(out1) != "A" || replies1 != nil {
+		t.Fatalf("expected only the leading byte with no replies yet, got out=%q replies=%+v", out1, replies1)
+	}
+	out2, replies2 := p.Feed([]byte{telnetWILL, telnetOptSuppressGoAhead, 'B'})
+	if string(out2) != "B" {
+		t.Fatalf("expected the trailing payload byte once the split IAC sequence completes, got %q", out2)
+	}
+	want := []byte{telnetIAC, telnetDO, telnetOptSuppressGoAhead}
+	if len(replies2) != 1 || !bytes.Equal(replies2[0], want) {
+		t.Fatalf("expected a DO reply once the split WILL sequence completes, got %+v", replies2)
+	}
+}
+
+func TestTelnetParserAgreesToBinary(t *testing.T) {
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetWILL, telnetOptBinary})
+	want := []byte{telnetIAC, telnetDO, telnetOptBinary}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a DO BINARY reply, got %+v", replies)
+	}
+
+	p = &telnetParser{}
+	_, replies = p.Feed([]byte{telnetIAC, telnetDO, telnetOptBinary})
+	want = []byte{telnetIAC, telnetWILL, telnetOptBinary}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a WILL BINARY reply, got %+v", replies)
+	}
+}
+
+func TestTelnetParserDoNAWSRepliesWithDefaultSize(t *testing.T) {
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetDO, telnetOptNAWS})
+	if len(replies) != 2 {
+		t.Fatalf("expected a WILL NAWS reply plus an SB NAWS size reply, got %+v", replies)
+	}
+	wantWill := []byte{telnetIAC, telnetWILL, telnetOptNAWS}
+	if !bytes.Equal(replies[0], wantWill) {
+		t.Fatalf("expected WILL NAWS first, got %+v", replies[0])
+	}
+	wantSB := []byte{telnetIAC, telnetSB, telnetOptNAWS, 0, defaultNAWSWidth, 0, defaultNAWSHeight, telnetIAC, telnetSE}
+	if !bytes.Equal(replies[1], wantSB) {
+		t.Fatalf("expected the default 80x24 window size, got %+v", replies[1])
+	}
+}
+
+func TestTelnetParserDoNAWSUsesConfiguredSize(t *testing.T) {
+	p := &telnetParser{}
+	p.SetLocalWindowSize(132, 43)
+	_, replies := p.Feed([]byte{telnetIAC, telnetDO, telnetOptNAWS})
+	wantSB := []byte{telnetIAC, telnetSB, telnetOptNAWS, 0, 132, 0, 43, telnetIAC, telnetSE}
+	if len(replies) != 2 || !bytes.Equal(replies[1], wantSB) {
+		t.Fatalf("expected the configured 132x43 window size, got %+v", replies)
+	}
+}
+
+func TestTelnetParserWillNAWSAgreed(t *testing.T) {
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetWILL, telnetOptNAWS})
+	want := []byte{telnetIAC, telnetDO, telnetOptNAWS}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a DO NAWS reply, got %+v", replies)
+	}
+}
+
+func TestTelnetParserIncomingNAWSUpdatesWindowSize(t *testing.T) {
+	p := &telnetParser{}
+	var gotW, gotH int
+	p.OnResize(func(w, h int) { gotW, gotH = w, h })
+
+	input := append([]byte{telnetIAC, telnetSB, telnetOptNAWS}, 0, 100, 0, 40, telnetIAC, telnetSE)
+	_, replies := p.Feed(input)
+	if replies != nil {
+		t.Fatalf("expected no reply to an incoming NAWS report, got %+v", replies)
+	}
+	if gotW != 100 || gotH != 40 {
+		t.Fatalf("expected OnResize(100, 40), got (%d, %d)", gotW, gotH)
+	}
+	if w, h := p.telnet.WindowSize(); w != 100 || h != 40 {
+		t.Fatalf("expected WindowSize() to report (100, 40), got (%d, %d)", w, h)
+	}
+}
+
+func TestTelnetParserDoTermTypeAgreed(t *testing.T) {
+	p := &telnetParser{}
+	_, replies := p.Feed([]byte{telnetIAC, telnetDO, telnetOptTermType})
+	want := []byte{telnetIAC, telnetWILL, telnetOptTermType}
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected a WILL TERMTYPE reply, got %+v", replies)
+	}
+}
+
+func TestTelnetParserTermTypeSendRepliesWithDefault(t *testing.T) {
+	p := &telnetParser{}
+	input := append([]byte{telnetIAC, telnetSB, telnetOptTermType, termTypeSEND}, telnetIAC, telnetSE)
+	_, replies := p.Feed(input)
+	want := append([]byte{telnetIAC, telnetSB, telnetOptTermType, termTypeIS}, []byte(defaultTermType)...)
+	want = append(want, telnetIAC, telnetSE)
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected an SB TERMTYPE IS %q reply, got %+v", defaultTermType, replies)
+	}
+}
+
+func TestTelnetParserTermTypeSendRepliesWithConfiguredName(t *testing.T) {
+	p := &telnetParser{}
+	p.SetTerminalType("XTERM")
+	input := append([]byte{telnetIAC, telnetSB, telnetOptTermType, termTypeSEND}, telnetIAC, telnetSE)
+	_, replies := p.Feed(input)
+	want := append([]byte{telnetIAC, telnetSB, telnetOptTermType, termTypeIS}, []byte("XTERM")...)
+	want = append(want, telnetIAC, telnetSE)
+	if len(replies) != 1 || !bytes.Equal(replies[0], want) {
+		t.Fatalf("expected an SB TERMTYPE IS XTERM reply, got %+v", replies)
+	}
+}
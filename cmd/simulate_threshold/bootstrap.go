@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// defaultBootstrapIters matches calibration's bootstrapSamples default, so
+// the two tools' CIs are comparable at their out-of-the-box settings.
+const defaultBootstrapIters = 1000
+
+// normalStats summarizes a slice of per-resample statistics as a mean and a
+// 95% CI via the normal approximation mean ± 1.96*sd/sqrt(n), per the
+// request: this tool reports a parametric interval rather than the
+// percentile interval calibration's bootstrap uses.
+type normalStats struct {
+	mean float64
+	sd   float64
+	n    int
+}
+
+func newNormalStats(samples []float64) normalStats {
+	n := len(samples)
+	if n == 0 {
+		return normalStats{}
+	}
+	var sum float64
+	for _, v := range samples {
+		sum += v
+	}
+	mean := sum / float64(n)
+
+	var sumSq float64
+	for _, v := range samples {
+		d := v - mean
+		sumSq += d * d
+	}
+	sd := 0.0
+	if n > 1 {
+		sd = math.Sqrt(sumSq / float64(n-1))
+	}
+	return normalStats{mean: mean, sd: sd, n: n}
+}
+
+// ci returns the 95% confidence interval mean ± 1.96*sd/sqrt(n).
+func (s normalStats) ci() (lower, upper float64) {
+	if s.n == 0 {
+		return 0, 0
+	}
+	margin := 1.96 * s.sd / math.Sqrt(float64(s.n))
+	return s.mean - margin, s.mean + margin
+}
+
+// String renders the point estimate with its CI, e.g. "42.3 ± 1.8 [40.5, 44.1]".
+func (s normalStats) String() string {
+	lower, upper := s.ci()
+	return fmt.Sprintf("%.1f ± %.1f [%.1f, %.1f]", s.mean, upper-s.mean, lower, upper)
+}
+
+// bootstrapResult is the point estimate plus normal-approximation CI for
+// rescued-count, new applied-rate, and stability under one threshold
+// combination, from resampling decisions with replacement.
+type bootstrapResult struct {
+	rescued      normalStats
+	appliedRate  normalStats
+	stability    normalStats
+	distanceDiff map[int]normalStats // newAppliedRate - currentAppliedRate, by distance
+}
+
+// bootstrapThresholdChange resamples decisions with replacement iters times
+// (1000 by default), recomputing rescued-count, applied-rate, and stability
+// under the new thresholds on each resample, and also the per-distance
+// difference in applied-rate between the current and new thresholds. It
+// reports mean ± 95% CI for each so a reader can tell whether the projected
+// change is a real shift or within noise.
+func bootstrapThresholdChange(decisions []decisionRecord, newMinReports, newMinAdvantage, newMinConf, d3ExtraAdvantage, d3ExtraConfidence, iters int) bootstrapResult {
+	if iters <= 0 {
+		iters = defaultBootstrapIters
+	}
+
+	var eligible []decisionRecord
+	for _, d := range decisions {
+		if d.distance != 0 {
+			eligible = append(eligible, d)
+		}
+	}
+	if len(eligible) == 0 {
+		return bootstrapResult{distanceDiff: map[int]normalStats{}}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	rescuedSamples := make([]float64, iters)
+	appliedRateSamples := make([]float64, iters)
+	stabilitySamples := make([]float64, iters)
+	diffSamples := map[int][]float64{1: make([]float64, iters), 2: make([]float64, iters), 3: make([]float64, iters)}
+
+	resample := make([]decisionRecord, len(eligible))
+	for i := 0; i < iters; i++ {
+		for j := range resample {
+			resample[j] = eligible[rng.Intn(len(eligible))]
+		}
+
+		applied, rescued := 0, 0
+		currentAppliedByDistance := map[int]int{}
+		newAppliedByDistance := map[int]int{}
+		countByDistance := map[int]int{}
+		for _, d := range resample {
+			countByDistance[d.distance]++
+			if d.decision == "applied" {
+				currentAppliedByDistance[d.distance]++
+			}
+			if checkThresholds(d, newMinReports, newMinAdvantage, newMinConf, d3ExtraAdvantage, d3ExtraConfidence) {
+				applied++
+				newAppliedByDistance[d.distance]++
+				if d.decision != "applied" {
+					rescued++
+				}
+			}
+		}
+
+		rescuedSamples[i] = float64(rescued)
+		appliedRateSamples[i] = float64(applied) / float64(len(resample)) * 100.0
+		stabilitySamples[i] = stability(resample, newMinReports, newMinAdvantage, newMinConf, d3ExtraAdvantage, d3ExtraConfidence) * 100.0
+
+		for distance := 1; distance <= 3; distance++ {
+			if countByDistance[distance] == 0 {
+				diffSamples[distance][i] = 0
+				continue
+			}
+			currentRate := float64(currentAppliedByDistance[distance]) / float64(countByDistance[distance]) * 100.0
+			newRate := float64(newAppliedByDistance[distance]) / float64(countByDistance[distance]) * 100.0
+			diffSamples[distance][i] = newRate - currentRate
+		}
+	}
+
+	result := bootstrapResult{
+		rescued:      newNormalStats(rescuedSamples),
+		appliedRate:  newNormalStats(appliedRateSamples),
+		stability:    newNormalStats(stabilitySamples),
+		distanceDiff: make(map[int]normalStats, 3),
+	}
+	for distance := 1; distance <= 3; distance++ {
+		result.distanceDiff[distance] = newNormalStats(diffSamples[distance])
+	}
+	return result
+}
+
+// significant reports whether a distance bucket's applied-rate CI excludes
+// zero, i.e. the projected change there isn't just noise.
+func (r bootstrapResult) significant(distance int) bool {
+	s, ok := r.distanceDiff[distance]
+	if !ok || s.n == 0 {
+		return false
+	}
+	lower, upper := s.ci()
+	return lower > 0 || upper < 0
+}
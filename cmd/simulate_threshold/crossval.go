@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// crossvalPoint is one candidate threshold config's cross-validated
+// safety/efficiency, averaged across the held-out rotation of every fold.
+type crossvalPoint struct {
+	MinConfidence     int
+	MinReports        int
+	MinAdvantage      int
+	D3ExtraAdvantage  int
+	D3ExtraConfidence int
+	TrainSafety       float64
+	TrainEfficiency   float64
+	TestSafety        float64
+	TestEfficiency    float64
+}
+
+// groundTruthWinners returns, for every subject with at least
+// minObservations decisions in decisions (any config, any distance) whose
+// final minObservations observations (in time order) all picked the same
+// winner, that settled winner. Subjects that never settle - because they
+// keep flipping right up to the end of the log, or were seen fewer than
+// minObservations times - have no entry and are excluded from
+// safetyEfficiency entirely: there's no way to know whether applying a
+// correction for them was actually right.
+func groundTruthWinners(decisions []decisionRecord, minObservations int) map[string]string {
+	bySubject := make(map[string][]decisionRecord)
+	for _, d := range decisions {
+		if d.distance == 0 {
+			continue
+		}
+		bySubject[d.subject] = append(bySubject[d.subject], d)
+	}
+
+	if minObservations < 1 {
+		minObservations = 1
+	}
+
+	truth := make(map[string]string, len(bySubject))
+	for subject, ds := range bySubject {
+		sort.SliceStable(ds, func(i, j int) bool { return ds[i].ts < ds[j].ts })
+		if len(ds) < minObservations {
+			continue
+		}
+		tail := ds[len(ds)-minObservations:]
+		winner := tail[0].winner
+		settled := true
+		for _, d := range tail[1:] {
+			if d.winner != winner {
+				settled = false
+				break
+			}
+		}
+		if settled {
+			truth[subject] = winner
+		}
+	}
+	return truth
+}
+
+// temporalFolds assigns each decision in decisions to one of k equal-width
+// time buckets spanning the log, the same equal-time (not equal-count)
+// split sweep's stability uses, so a burst of activity doesn't pack one
+// fold while others sit empty.
+func temporalFolds(decisions []decisionRecord, k int) []int {
+	fold := make([]int, len(decisions))
+	if len(decisions) == 0 || k < 2 {
+		return fold
+	}
+	start, end := decisions[0].ts, decisions[0].ts
+	for _, d := range decisions {
+		if d.ts < start {
+			start = d.ts
+		}
+		if d.ts > end {
+			end = d.ts
+		}
+	}
+	span := end - start
+	if span <= 0 {
+		return fold
+	}
+	for i, d := range decisions {
+		fold[i] = equalTimeBucket(d.ts, start, span, k)
+	}
+	return fold
+}
+
+// safetyEfficiency evaluates one threshold config against a slice of
+// decisions and the ground-truth stable winners: safety is the fraction of
+// config-applied corrections, among subjects with a known stable winner,
+// that agree with it; efficiency is the fraction of stable-winner
+// opportunities - decisions whose subject has a stable winner and whose own
+// winner matches it - that the config actually applied. Subjects with no
+// ground-truth entry don't count toward either ratio.
+func safetyEfficiency(decisions []decisionRecord, truth map[string]string, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence int) (safety, efficiency float64) {
+	var appliedWithTruth, appliedAgree int
+	var opportunities, opportunitiesApplied int
+	for _, d := range decisions {
+		if d.distance == 0 {
+			continue
+		}
+		winner, known := truth[d.subject]
+		if !known {
+			continue
+		}
+		applies := checkThresholds(d, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence)
+		if applies {
+			appliedWithTruth++
+			if d.winner == winner {
+				appliedAgree++
+			}
+		}
+		if d.winner == winner {
+			opportunities++
+			if applies {
+				opportunitiesApplied++
+			}
+		}
+	}
+	if appliedWithTruth > 0 {
+		safety = float64(appliedAgree) / float64(appliedWithTruth)
+	}
+	if opportunities > 0 {
+		efficiency = float64(opportunitiesApplied) / float64(opportunities)
+	}
+	return safety, efficiency
+}
+
+// runCrossval splits decisions into k temporal folds, expands in into the
+// same threshold grid runSweep would, and for every combination reports its
+// train-fold (the other k-1 folds) and held-out-fold safety/efficiency,
+// averaged across all k held-out rotations. Ground truth stable winners are
+// computed once from the whole log (groundTruthWinners) rather than
+// per-fold: the point of the split is to see how a config's safety and
+// efficiency generalize across time periods, not to hide the same
+// eventual-winner information an operator picking a threshold from this
+// table will also have.
+func runCrossval(decisions []decisionRecord, in sweepInputs, k, minStableObservations, topK int) {
+	confs, err := parseRange(in.conf, in.confFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reportsList, err := parseRange(in.reports, in.reportsFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	advantages, err := parseRange(in.advantage, in.advantageFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d3Advantages, err := parseRange(in.d3Advantage, in.d3AdvantageFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d3Confidences, err := parseRange(in.d3Confidence, in.d3ConfidenceFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	truth := groundTruthWinners(decisions, minStableObservations)
+	folds := temporalFolds(decisions, k)
+
+	byFold := make([][]decisionRecord, k)
+	for i, d := range decisions {
+		byFold[folds[i]] = append(byFold[folds[i]], d)
+	}
+
+	var points []crossvalPoint
+	for _, conf := range confs {
+		for _, reports := range reportsList {
+			for _, advantage := range advantages {
+				for _, d3a := range d3Advantages {
+					for _, d3c := range d3Confidences {
+						points = append(points, evaluateCrossvalPoint(byFold, truth, conf, reports, advantage, d3a, d3c))
+					}
+				}
+			}
+		}
+	}
+
+	// Sort by safety-at-fixed-efficiency: bucket test efficiency into 5%
+	// bands so configs with essentially the same efficiency group together,
+	// then within a band show the safest config first.
+	sort.SliceStable(points, func(i, j int) bool {
+		bi := math.Round(points[i].TestEfficiency * 20)
+		bj := math.Round(points[j].TestEfficiency * 20)
+		if bi != bj {
+			return bi < bj
+		}
+		return points[i].TestSafety > points[j].TestSafety
+	})
+	if topK > 0 && len(points) > topK {
+		points = points[:topK]
+	}
+
+	fmt.Printf("Cross-validated %d threshold combinations across %d temporal folds (%d subjects with a settled ground-truth winner)\n",
+		len(points), k, len(truth))
+	fmt.Printf("Sorted by safety at fixed (banded) test efficiency - highest safety first within each efficiency band\n\n")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "min_conf\tmin_reports\tmin_adv\td3_extra_adv\td3_extra_conf\ttrain_safety\ttrain_efficiency\ttest_safety\ttest_efficiency\t")
+	for _, p := range points {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t\n",
+			p.MinConfidence, p.MinReports, p.MinAdvantage, p.D3ExtraAdvantage, p.D3ExtraConfidence,
+			p.TrainSafety*100, p.TrainEfficiency*100, p.TestSafety*100, p.TestEfficiency*100)
+	}
+	w.Flush()
+}
+
+func evaluateCrossvalPoint(byFold [][]decisionRecord, truth map[string]string, minConfidence, minReports, minAdvantage, d3ExtraAdvantage, d3ExtraConfidence int) crossvalPoint {
+	k := len(byFold)
+	var trainSafetySum, trainEffSum, testSafetySum, testEffSum float64
+	for held := 0; held < k; held++ {
+		var train []decisionRecord
+		for i, fold := range byFold {
+			if i == held {
+				continue
+			}
+			train = append(train, fold...)
+		}
+		trainSafety, trainEff := safetyEfficiency(train, truth, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence)
+		testSafety, testEff := safetyEfficiency(byFold[held], truth, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence)
+		trainSafetySum += trainSafety
+		trainEffSum += trainEff
+		testSafetySum += testSafety
+		testEffSum += testEff
+	}
+	return crossvalPoint{
+		MinConfidence:     minConfidence,
+		MinReports:        minReports,
+		MinAdvantage:      minAdvantage,
+		D3ExtraAdvantage:  d3ExtraAdvantage,
+		D3ExtraConfidence: d3ExtraConfidence,
+		TrainSafety:       trainSafetySum / float64(k),
+		TrainEfficiency:   trainEffSum / float64(k),
+		TestSafety:        testSafetySum / float64(k),
+		TestEfficiency:    testEffSum / float64(k),
+	}
+}
@@ -1,5 +1,11 @@
 // Program simulate_threshold simulates the impact of changing specific thresholds
-// by re-analyzing the decision log with different parameter values.
+// by re-analyzing the decision log with different parameter values. Passing
+// any -sweep-* range switches from the single what-if simulation to a grid
+// search across the whole range, reporting the (rescued, stability) Pareto
+// frontier instead of one projected outcome. Passing -crossval-folds (>= 2)
+// switches instead to K-fold temporal cross-validation, reporting each
+// candidate config's train-fold and held-out-fold safety/efficiency against
+// a "stable winner" ground truth derived from the log itself.
 package main
 
 import (
@@ -14,19 +20,20 @@ import (
 )
 
 type decisionRecord struct {
-	id                int64
-	subject           string
-	winner            string
-	distance          int
-	winnerConfidence  int
-	winnerSupport     int
-	subjectSupport    int
-	totalReporters    int
-	minReports        int
-	minAdvantage      int
-	minConfidence     int
-	decision          string
-	reason            string
+	id               int64
+	ts               int64
+	subject          string
+	winner           string
+	distance         int
+	winnerConfidence int
+	winnerSupport    int
+	subjectSupport   int
+	totalReporters   int
+	minReports       int
+	minAdvantage     int
+	minConfidence    int
+	decision         string
+	reason           string
 }
 
 func main() {
@@ -34,6 +41,20 @@ func main() {
 	newMinConf := flag.Int("min-confidence", 55, "New min_confidence_percent threshold")
 	newMinReports := flag.Int("min-reports", 3, "New min_consensus_reports threshold")
 	newMinAdvantage := flag.Int("min-advantage", 1, "New min_advantage threshold")
+	d3ExtraAdvantage := flag.Int("d3-extra-advantage", 1, "Extra min_advantage required on top of -min-advantage at distance 3")
+	d3ExtraConfidence := flag.Int("d3-extra-confidence", 5, "Extra min_confidence_percent required on top of -min-confidence at distance 3")
+
+	sweepConf := flag.String("sweep-conf", "", "min:max:step range to sweep for min_confidence_percent, e.g. 50:75:1 (empty: use -min-confidence alone)")
+	sweepReports := flag.String("sweep-reports", "", "min:max:step range to sweep for min_consensus_reports (empty: use -min-reports alone)")
+	sweepAdvantage := flag.String("sweep-advantage", "", "min:max:step range to sweep for min_advantage (empty: use -min-advantage alone)")
+	sweepD3Advantage := flag.String("sweep-d3-extra-advantage", "", "min:max:step range to sweep for -d3-extra-advantage (empty: use its single value)")
+	sweepD3Confidence := flag.String("sweep-d3-extra-confidence", "", "min:max:step range to sweep for -d3-extra-confidence (empty: use its single value)")
+	sweepTopK := flag.Int("sweep-top", 10, "Number of top non-dominated (Pareto-optimal) configs to print")
+	sweepOut := flag.String("sweep-out", "", "Write every swept combination (not just the Pareto frontier) to this file; format is picked from its extension (.csv or .json)")
+	bootstrapIters := flag.Int("bootstrap-iters", defaultBootstrapIters, "Resamples used to estimate rescued/stability confidence intervals in the single what-if report")
+
+	crossvalFolds := flag.Int("crossval-folds", 0, "Run K-fold temporal cross-validation instead of a single simulation or sweep; K is the number of temporal folds (e.g. 5, requires >= 2)")
+	crossvalStableObservations := flag.Int("crossval-stable-min-observations", 3, "Minimum trailing observations of a subject that must agree on its winner before that winner counts as the stable ground-truth winner")
 	flag.Parse()
 
 	db, err := sql.Open("sqlite", *dbPath)
@@ -42,20 +63,49 @@ func main() {
 	}
 	defer db.Close()
 
+	decisions, err := loadAllDecisions(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *crossvalFolds >= 2 {
+		runCrossval(decisions, sweepInputs{
+			conf:         *sweepConf,
+			reports:      *sweepReports,
+			advantage:    *sweepAdvantage,
+			d3Advantage:  *sweepD3Advantage,
+			d3Confidence: *sweepD3Confidence,
+			confFallback: *newMinConf, reportsFallback: *newMinReports, advantageFallback: *newMinAdvantage,
+			d3AdvantageFallback: *d3ExtraAdvantage, d3ConfidenceFallback: *d3ExtraConfidence,
+		}, *crossvalFolds, *crossvalStableObservations, *sweepTopK)
+		return
+	}
+
+	sweeping := *sweepConf != "" || *sweepReports != "" || *sweepAdvantage != "" || *sweepD3Advantage != "" || *sweepD3Confidence != ""
+	if sweeping {
+		runSweep(decisions, sweepInputs{
+			conf:         *sweepConf,
+			reports:      *sweepReports,
+			advantage:    *sweepAdvantage,
+			d3Advantage:  *sweepD3Advantage,
+			d3Confidence: *sweepD3Confidence,
+			confFallback: *newMinConf, reportsFallback: *newMinReports, advantageFallback: *newMinAdvantage,
+			d3AdvantageFallback: *d3ExtraAdvantage, d3ConfidenceFallback: *d3ExtraConfidence,
+		}, *sweepTopK, *sweepOut)
+		return
+	}
+
+	runSingle(decisions, *dbPath, *newMinConf, *newMinReports, *newMinAdvantage, *d3ExtraAdvantage, *d3ExtraConfidence, *bootstrapIters)
+}
+
+func runSingle(decisions []decisionRecord, dbPath string, newMinConf, newMinReports, newMinAdvantage, d3ExtraAdvantage, d3ExtraConfidence, bootstrapIters int) {
 	fmt.Printf("\n")
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
 	fmt.Printf("  THRESHOLD SIMULATION\n")
-	fmt.Printf("  Database: %s\n", *dbPath)
+	fmt.Printf("  Database: %s\n", dbPath)
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════\n")
 	fmt.Printf("\n")
 
-	// Load all decisions (both applied and rejected)
-	decisions, err := loadAllDecisions(db)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Current state analysis
 	currentApplied := 0
 	currentRejected := 0
 	for _, d := range decisions {
@@ -75,24 +125,18 @@ func main() {
 		float64(currentRejected)/float64(len(decisions))*100.0)
 	fmt.Printf("\n")
 
-	// Simulate new thresholds
 	newApplied := 0
 	rescued := 0
 	rescuedByDistance := make(map[int]int)
 	rescuedCases := []decisionRecord{}
 
 	for _, d := range decisions {
-		// Skip distance-0 (no winner)
 		if d.distance == 0 {
 			continue
 		}
-
-		// Apply new thresholds
-		wouldApply := checkThresholds(d, *newMinReports, *newMinAdvantage, *newMinConf)
-
+		wouldApply := checkThresholds(d, newMinReports, newMinAdvantage, newMinConf, d3ExtraAdvantage, d3ExtraConfidence)
 		if wouldApply {
 			newApplied++
-			// Was this previously rejected?
 			if d.decision != "applied" {
 				rescued++
 				rescuedByDistance[d.distance]++
@@ -103,7 +147,6 @@ func main() {
 		}
 	}
 
-	// Get original values from first decision
 	origMinReports := 3
 	origMinAdvantage := 1
 	origMinConfidence := 60
@@ -115,9 +158,9 @@ func main() {
 
 	fmt.Printf("SIMULATED CONFIGURATION:\n")
 	fmt.Printf("─────────────────────────────────────────────────────────────────────────────\n")
-	fmt.Printf("  min_consensus_reports:  %d → %d\n", origMinReports, *newMinReports)
-	fmt.Printf("  min_advantage:          %d → %d\n", origMinAdvantage, *newMinAdvantage)
-	fmt.Printf("  min_confidence_percent: %d → %d\n", origMinConfidence, *newMinConf)
+	fmt.Printf("  min_consensus_reports:  %d → %d\n", origMinReports, newMinReports)
+	fmt.Printf("  min_advantage:          %d → %d\n", origMinAdvantage, newMinAdvantage)
+	fmt.Printf("  min_confidence_percent: %d → %d\n", origMinConfidence, newMinConf)
 	fmt.Printf("\n")
 
 	fmt.Printf("PROJECTED RESULTS:\n")
@@ -156,7 +199,7 @@ func main() {
 			fmt.Printf("  Support: %d/%d reporters (winner=%d, subject=%d, advantage=%d)\n",
 				r.winnerSupport, r.totalReporters, r.winnerSupport, r.subjectSupport, advantage)
 			fmt.Printf("  Confidence: %d%% (threshold was %d%%, new threshold %d%%)\n",
-				r.winnerConfidence, r.minConfidence, *newMinConf)
+				r.winnerConfidence, r.minConfidence, newMinConf)
 			fmt.Printf("  Previously rejected: %s\n", r.reason)
 		}
 	}
@@ -186,15 +229,22 @@ func main() {
 		}
 		fmt.Printf("\n")
 
-		// Estimate stability
-		fmt.Printf("PREDICTED STABILITY:\n")
-		fmt.Printf("  Current stability: 88.3%%\n")
-		if rescued < 20 {
-			fmt.Printf("  Predicted stability: ~85-87%% (slight decrease expected)\n")
-			fmt.Printf("  Risk: LOW - rescued corrections have similar profiles\n")
-		} else {
-			fmt.Printf("  Predicted stability: ~82-86%% (moderate decrease possible)\n")
-			fmt.Printf("  Risk: MODERATE - validate with Method 1A after change\n")
+		boot := bootstrapThresholdChange(decisions, newMinReports, newMinAdvantage, newMinConf, d3ExtraAdvantage, d3ExtraConfidence, bootstrapIters)
+
+		fmt.Printf("BOOTSTRAP ESTIMATE (%d resamples, 95%% CI via mean ± 1.96·sd/√n):\n", bootstrapIters)
+		fmt.Printf("  Rescued corrections:    %s\n", boot.rescued.String())
+		fmt.Printf("  New applied rate:       %s%%\n", boot.appliedRate.String())
+		fmt.Printf("  Predicted stability:    %s%%\n", boot.stability.String())
+		fmt.Printf("\n")
+
+		fmt.Printf("APPLIED-RATE CHANGE BY DISTANCE (new - current, 95%% CI):\n")
+		for distance := 1; distance <= 3; distance++ {
+			diff := boot.distanceDiff[distance]
+			verdict := "not significant (CI spans 0)"
+			if boot.significant(distance) {
+				verdict = "significant"
+			}
+			fmt.Printf("  Distance-%d: %s pp — %s\n", distance, diff.String(), verdict)
 		}
 	}
 
@@ -206,7 +256,7 @@ func main() {
 func loadAllDecisions(db *sql.DB) ([]decisionRecord, error) {
 	rows, err := db.Query(`
 		SELECT
-			id, subject, winner, distance,
+			id, ts, subject, winner, distance,
 			winner_confidence, winner_support, subject_support, total_reporters,
 			min_reports, min_advantage, min_confidence,
 			decision, COALESCE(reason, '')
@@ -222,7 +272,7 @@ func loadAllDecisions(db *sql.DB) ([]decisionRecord, error) {
 	var decisions []decisionRecord
 	for rows.Next() {
 		var d decisionRecord
-		if err := rows.Scan(&d.id, &d.subject, &d.winner, &d.distance,
+		if err := rows.Scan(&d.id, &d.ts, &d.subject, &d.winner, &d.distance,
 			&d.winnerConfidence, &d.winnerSupport, &d.subjectSupport, &d.totalReporters,
 			&d.minReports, &d.minAdvantage, &d.minConfidence,
 			&d.decision, &d.reason); err != nil {
@@ -236,31 +286,25 @@ func loadAllDecisions(db *sql.DB) ([]decisionRecord, error) {
 	return decisions, rows.Err()
 }
 
-func checkThresholds(d decisionRecord, minReports, minAdvantage, minConfidence int) bool {
-	// Check minimum reports
+func checkThresholds(d decisionRecord, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence int) bool {
 	if d.winnerSupport < minReports {
 		return false
 	}
 
-	// Check advantage
 	advantage := d.winnerSupport - d.subjectSupport
 	if advantage < minAdvantage {
 		return false
 	}
 
-	// Check confidence
 	if d.winnerConfidence < minConfidence {
 		return false
 	}
 
-	// Distance-3 extra requirements (hardcoded from config)
 	if d.distance == 3 {
-		// Assume distance3_extra_advantage = 1
-		if advantage < minAdvantage+1 {
+		if advantage < minAdvantage+d3ExtraAdvantage {
 			return false
 		}
-		// Assume distance3_extra_confidence = 5
-		if d.winnerConfidence < minConfidence+5 {
+		if d.winnerConfidence < minConfidence+d3ExtraConfidence {
 			return false
 		}
 	}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// sweepWindows is the number of equal time buckets a sweep's stability
+// estimate is computed over; see stability's doc comment.
+const sweepWindows = 4
+
+// sweepInputs bundles the raw -sweep-* range flags (each "min:max:step", or
+// empty to hold that dimension at its single-value fallback) that runSweep
+// expands into the grid.
+type sweepInputs struct {
+	conf, reports, advantage, d3Advantage, d3Confidence string
+
+	confFallback, reportsFallback, advantageFallback int
+	d3AdvantageFallback, d3ConfidenceFallback        int
+}
+
+// sweepPoint is one grid cell's projected outcome: a candidate threshold
+// combination plus how many decisions it would apply, rescue, or lose
+// relative to the log's original decisions, and a stability estimate.
+type sweepPoint struct {
+	MinConfidence     int     `json:"min_confidence"`
+	MinReports        int     `json:"min_reports"`
+	MinAdvantage      int     `json:"min_advantage"`
+	D3ExtraAdvantage  int     `json:"d3_extra_advantage"`
+	D3ExtraConfidence int     `json:"d3_extra_confidence"`
+	Applied           int     `json:"applied"`
+	Rescued           int     `json:"rescued"`
+	Lost              int     `json:"lost"`
+	Stability         float64 `json:"stability"`
+	Pareto            bool    `json:"pareto"`
+}
+
+// parseRange expands a "min:max:step" spec into an inclusive []int. step
+// defaults to 1 when omitted ("min:max"). An empty spec returns just
+// fallback, so an unswept dimension still produces one grid value.
+func parseRange(spec string, fallback int) ([]int, error) {
+	if spec == "" {
+		return []int{fallback}, nil
+	}
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("range %q must be min:max or min:max:step", spec)
+	}
+	min, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("range %q: invalid min: %w", spec, err)
+	}
+	max, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("range %q: invalid max: %w", spec, err)
+	}
+	step := 1
+	if len(parts) == 3 {
+		step, err = strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("range %q: invalid step: %w", spec, err)
+		}
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("range %q: step must be positive", spec)
+	}
+	if max < min {
+		return nil, fmt.Errorf("range %q: max must be >= min", spec)
+	}
+
+	var out []int
+	for v := min; v <= max; v += step {
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// runSweep expands in into a grid, replays every combination against
+// decisions, flags the (rescued, stability) Pareto frontier and its knee,
+// and prints the top-K frontier configs. If sweepOut is non-empty the full
+// grid (every combination, not just the frontier) is also dumped there.
+func runSweep(decisions []decisionRecord, in sweepInputs, topK int, sweepOut string) {
+	confs, err := parseRange(in.conf, in.confFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reportsList, err := parseRange(in.reports, in.reportsFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	advantages, err := parseRange(in.advantage, in.advantageFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d3Advantages, err := parseRange(in.d3Advantage, in.d3AdvantageFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d3Confidences, err := parseRange(in.d3Confidence, in.d3ConfidenceFallback)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var points []sweepPoint
+	for _, conf := range confs {
+		for _, reports := range reportsList {
+			for _, advantage := range advantages {
+				for _, d3a := range d3Advantages {
+					for _, d3c := range d3Confidences {
+						points = append(points, evaluateSweepPoint(decisions, conf, reports, advantage, d3a, d3c))
+					}
+				}
+			}
+		}
+	}
+
+	frontier := paretoFrontier(points)
+	for _, i := range frontier {
+		points[i].Pareto = true
+	}
+
+	fmt.Printf("Swept %d combinations (%d confidence x %d reports x %d advantage x %d d3-advantage x %d d3-confidence)\n",
+		len(points), len(confs), len(reportsList), len(advantages), len(d3Advantages), len(d3Confidences))
+	fmt.Printf("Pareto frontier: %d non-dominated configs (maximizing rescued corrections and stability)\n\n", len(frontier))
+
+	kneeIdx := kneePoint(points, frontier)
+	printFrontier(points, frontier, kneeIdx, topK)
+
+	if sweepOut != "" {
+		if err := writeSweepDump(points, sweepOut); err != nil {
+			log.Fatalf("writing sweep dump: %v", err)
+		}
+		fmt.Printf("\nWrote %d grid points to %s\n", len(points), sweepOut)
+	}
+}
+
+func evaluateSweepPoint(decisions []decisionRecord, minConfidence, minReports, minAdvantage, d3ExtraAdvantage, d3ExtraConfidence int) sweepPoint {
+	applied, rescued, lost := 0, 0, 0
+	for _, d := range decisions {
+		if d.distance == 0 {
+			continue
+		}
+		if checkThresholds(d, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence) {
+			applied++
+			if d.decision != "applied" {
+				rescued++
+			}
+		} else if d.decision == "applied" {
+			lost++
+		}
+	}
+
+	return sweepPoint{
+		MinConfidence:     minConfidence,
+		MinReports:        minReports,
+		MinAdvantage:      minAdvantage,
+		D3ExtraAdvantage:  d3ExtraAdvantage,
+		D3ExtraConfidence: d3ExtraConfidence,
+		Applied:           applied,
+		Rescued:           rescued,
+		Lost:              lost,
+		Stability: stability(decisions, minReports, minAdvantage, minConfidence,
+			d3ExtraAdvantage, d3ExtraConfidence),
+	}
+}
+
+// equalTimeBucket maps ts into one of numBuckets equal-width buckets
+// covering [start, start+span], clamped to [0, numBuckets-1] so a ts exactly
+// at start+span still lands in the last bucket instead of overflowing it.
+// Shared by stability's windowed-agreement split and crossval.go's
+// temporalFolds.
+func equalTimeBucket(ts, start, span int64, numBuckets int) int {
+	idx := int((ts - start) * int64(numBuckets) / span)
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= numBuckets {
+		idx = numBuckets - 1
+	}
+	return idx
+}
+
+// stability estimates how consistent a threshold combination's decisions
+// are over time: the log's span is split into sweepWindows equal buckets,
+// and for every subject accepted in two or more buckets, it "agrees" if
+// every bucket picked the same winner. The result is the fraction of
+// multi-bucket subjects that agree; subjects seen in only one bucket can't
+// contradict themselves so don't affect the ratio. This mirrors the
+// windowed-agreement idea in dxcluster/calibration's TemporalStability, but
+// isn't built on that package directly: this sweep's distance-3 extra
+// advantage/confidence are distance-3-only, where calibration's
+// ExtraAdvantage/ExtraConfidence apply across every distance.
+func stability(decisions []decisionRecord, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence int) float64 {
+	var start, end int64
+	haveSpan := false
+	for _, d := range decisions {
+		if !haveSpan {
+			start, end = d.ts, d.ts
+			haveSpan = true
+			continue
+		}
+		if d.ts < start {
+			start = d.ts
+		}
+		if d.ts > end {
+			end = d.ts
+		}
+	}
+	if !haveSpan || end <= start {
+		return 1.0
+	}
+	span := end - start
+
+	windowWinners := make(map[string]map[int]map[string]bool)
+	for _, d := range decisions {
+		if d.distance == 0 || !checkThresholds(d, minReports, minAdvantage, minConfidence, d3ExtraAdvantage, d3ExtraConfidence) {
+			continue
+		}
+		idx := equalTimeBucket(d.ts, start, span, sweepWindows)
+		if windowWinners[d.subject] == nil {
+			windowWinners[d.subject] = make(map[int]map[string]bool)
+		}
+		if windowWinners[d.subject][idx] == nil {
+			windowWinners[d.subject][idx] = make(map[string]bool)
+		}
+		windowWinners[d.subject][idx][d.winner] = true
+	}
+
+	agree, total := 0, 0
+	for _, windows := range windowWinners {
+		if len(windows) < 2 {
+			continue
+		}
+		total++
+		if subjectAgreesAcrossWindows(windows) {
+			agree++
+		}
+	}
+	if total == 0 {
+		return 1.0
+	}
+	return float64(agree) / float64(total)
+}
+
+func subjectAgreesAcrossWindows(windows map[int]map[string]bool) bool {
+	var first string
+	haveFirst := false
+	for _, winners := range windows {
+		for winner := range winners {
+			if !haveFirst {
+				first = winner
+				haveFirst = true
+				continue
+			}
+			if winner != first {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// paretoFrontier returns the indices into points that maximize both Rescued
+// and Stability: a point is kept unless some other point is at least as
+// good on both axes and strictly better on one.
+func paretoFrontier(points []sweepPoint) []int {
+	var front []int
+	for i, candidate := range points {
+		dominated := false
+		for j, other := range points {
+			if i == j {
+				continue
+			}
+			if dominatesPoint(other, candidate) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, i)
+		}
+	}
+	return front
+}
+
+func dominatesPoint(a, b sweepPoint) bool {
+	if a.Rescued < b.Rescued || a.Stability < b.Stability {
+		return false
+	}
+	return a.Rescued > b.Rescued || a.Stability > b.Stability
+}
+
+// kneePoint picks the frontier index whose normalized (rescued, stability)
+// position is closest to the ideal corner (max rescued, max stability) -
+// the usual pragmatic stand-in for "where the tradeoff curve bends", since
+// points past it buy little extra stability for a lot of lost corrections
+// or vice versa. Returns -1 if the frontier is empty.
+func kneePoint(points []sweepPoint, frontier []int) int {
+	if len(frontier) == 0 {
+		return -1
+	}
+	minR, maxR := points[frontier[0]].Rescued, points[frontier[0]].Rescued
+	minS, maxS := points[frontier[0]].Stability, points[frontier[0]].Stability
+	for _, i := range frontier {
+		p := points[i]
+		if p.Rescued < minR {
+			minR = p.Rescued
+		}
+		if p.Rescued > maxR {
+			maxR = p.Rescued
+		}
+		if p.Stability < minS {
+			minS = p.Stability
+		}
+		if p.Stability > maxS {
+			maxS = p.Stability
+		}
+	}
+
+	best := frontier[0]
+	bestScore := -1.0
+	for _, i := range frontier {
+		p := points[i]
+		normRescued := 0.5
+		if maxR > minR {
+			normRescued = float64(p.Rescued-minR) / float64(maxR-minR)
+		}
+		normStability := 0.5
+		if maxS > minS {
+			normStability = (p.Stability - minS) / (maxS - minS)
+		}
+		score := normRescued + normStability
+		if score > bestScore {
+			bestScore = score
+			best = i
+		}
+	}
+	return best
+}
+
+func printFrontier(points []sweepPoint, frontier []int, kneeIdx, topK int) {
+	sort.SliceStable(frontier, func(a, b int) bool {
+		return points[frontier[a]].Rescued > points[frontier[b]].Rescued
+	})
+	if topK > 0 && len(frontier) > topK {
+		frontier = frontier[:topK]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "min_conf\tmin_reports\tmin_adv\td3_extra_adv\td3_extra_conf\tapplied\trescued\tlost\tstability\t")
+	for _, i := range frontier {
+		p := points[i]
+		marker := ""
+		if i == kneeIdx {
+			marker = "  <- knee"
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.1f%%\t%s\n",
+			p.MinConfidence, p.MinReports, p.MinAdvantage, p.D3ExtraAdvantage, p.D3ExtraConfidence,
+			p.Applied, p.Rescued, p.Lost, p.Stability*100, marker)
+	}
+	w.Flush()
+}
+
+func writeSweepDump(points []sweepPoint, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(points)
+	}
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+	header := []string{"min_confidence", "min_reports", "min_advantage", "d3_extra_advantage", "d3_extra_confidence",
+		"applied", "rescued", "lost", "stability", "pareto"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, p := range points {
+		row := []string{
+			strconv.Itoa(p.MinConfidence), strconv.Itoa(p.MinReports), strconv.Itoa(p.MinAdvantage),
+			strconv.Itoa(p.D3ExtraAdvantage), strconv.Itoa(p.D3ExtraConfidence),
+			strconv.Itoa(p.Applied), strconv.Itoa(p.Rescued), strconv.Itoa(p.Lost),
+			strconv.FormatFloat(p.Stability, 'f', 4, 64), strconv.FormatBool(p.Pareto),
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
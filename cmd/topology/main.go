@@ -0,0 +1,107 @@
+// Command topology inspects a peer.TopologyStore SQLite database and prints
+// the mesh graph learned from PC92 entry chains, either as an indented tree,
+// a Graphviz DOT graph, or (with -from/-to) a single traced path between two
+// nodes. The database itself is populated by cmd/peerprobe, started with
+// -topology_db pointing at the same path, which ingests PC92 frames as its
+// peer connections receive them. This command only reads from it, but it
+// opens it through peer.OpenTopologyStore, which opens the database
+// read-write and creates any missing tables.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"dxcluster/peer"
+)
+
+func main() {
+	dbPath := flag.String("db", "data/topology.db", "Path to the topology SQLite database")
+	format := flag.String("format", "tree", "Output format: tree or dot")
+	from := flag.String("from", "", "Trace a path from this callsign (requires -to)")
+	to := flag.String("to", "", "Trace a path to this callsign (requires -from)")
+	flag.Parse()
+
+	store, err := peer.OpenTopologyStore(*dbPath, 0)
+	if err != nil {
+		log.Fatalf("open topology store: %v", err)
+	}
+	defer store.Close()
+
+	edges := store.Snapshot()
+
+	if *from != "" || *to != "" {
+		if *from == "" || *to == "" {
+			log.Fatalf("-from and -to must be given together")
+		}
+		path := store.Path(*from, *to)
+		if path == nil {
+			fmt.Fprintf(os.Stderr, "no path found from %s to %s\n", *from, *to)
+			os.Exit(1)
+		}
+		fmt.Println(strings.Join(path, " -> "))
+		return
+	}
+
+	switch strings.ToLower(*format) {
+	case "dot":
+		printDOT(edges)
+	default:
+		printTree(edges)
+	}
+}
+
+// printTree renders the edges as an indented forest: one top-level entry per
+// node that is never a child, followed by its descendants.
+func printTree(edges []peer.Edge) {
+	children := make(map[string][]string)
+	allNodes := make(map[string]bool)
+	isChild := make(map[string]bool)
+	for _, e := range edges {
+		children[e.Parent] = append(children[e.Parent], e.Child)
+		allNodes[e.Parent] = true
+		allNodes[e.Child] = true
+		isChild[e.Child] = true
+	}
+	for parent := range children {
+		sort.Strings(children[parent])
+	}
+
+	var roots []string
+	for node := range allNodes {
+		if !isChild[node] {
+			roots = append(roots, node)
+		}
+	}
+	sort.Strings(roots)
+
+	visited := make(map[string]bool)
+	for _, root := range roots {
+		printSubtree(root, children, visited, 0)
+	}
+}
+
+func printSubtree(call string, children map[string][]string, visited map[string]bool, depth int) {
+	if visited[call] {
+		fmt.Printf("%s%s (cycle)\n", strings.Repeat("  ", depth), call)
+		return
+	}
+	visited[call] = true
+	fmt.Printf("%s%s\n", strings.Repeat("  ", depth), call)
+	for _, child := range children[call] {
+		printSubtree(child, children, visited, depth+1)
+	}
+}
+
+// printDOT renders the edges as a Graphviz digraph.
+func printDOT(edges []peer.Edge) {
+	fmt.Println("digraph topology {")
+	for _, e := range edges {
+		fmt.Printf("  %q -> %q;\n", e.Parent, e.Child)
+	}
+	fmt.Println("}")
+}
@@ -0,0 +1,177 @@
+package cty
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ctyDatOverrideRE matches the per-prefix annotations the cty.dat format
+// allows after a prefix entry: a CQ zone override in parens, an ITU zone
+// override in brackets, a continent override in braces, a lat/long
+// override in angle brackets, and a GMT offset override between tildes.
+var ctyDatOverrideRE = regexp.MustCompile(`\(\d+\)|\[\d+\]|\{[A-Za-z]+\}|<[\-\d.]+/[\-\d.]+>|~[\-\d.]+~`)
+
+// decodeCTYDat parses Country Files' (AD1C) text cty.dat format: one
+// unindented header line per DXCC entity (name, CQ zone, ITU zone,
+// continent, latitude, longitude, GMT offset, and primary prefix, each
+// colon-terminated), followed by one or more indented, comma-separated
+// lines listing that entity's prefixes and exact callsigns, terminated by a
+// semicolon. Any prefix entry may override the entity's zone, continent,
+// coordinates, or GMT offset with a trailing annotation, and a leading "="
+// marks an exact callsign rather than a prefix.
+func decodeCTYDat(data []byte) (map[string]PrefixInfo, error) {
+	out := make(map[string]PrefixInfo)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var base PrefixInfo
+	var list strings.Builder
+	haveBase := false
+
+	flush := func() error {
+		if !haveBase {
+			return nil
+		}
+		if err := applyCTYDatPrefixList(out, base, list.String()); err != nil {
+			return err
+		}
+		list.Reset()
+		haveBase = false
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			entity, err := parseCTYDatHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			base, haveBase = entity, true
+			continue
+		}
+		list.WriteString(strings.TrimSpace(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan cty.dat: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// parseCTYDatHeader parses one entity header line into the PrefixInfo
+// fields shared by every prefix that entity's list goes on to define.
+func parseCTYDatHeader(line string) (PrefixInfo, error) {
+	fields := strings.Split(line, ":")
+	if len(fields) < 8 {
+		return PrefixInfo{}, fmt.Errorf("cty.dat: malformed header %q", line)
+	}
+	cq, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return PrefixInfo{}, fmt.Errorf("cty.dat: bad CQ zone in %q: %w", line, err)
+	}
+	itu, err := strconv.Atoi(strings.TrimSpace(fields[2]))
+	if err != nil {
+		return PrefixInfo{}, fmt.Errorf("cty.dat: bad ITU zone in %q: %w", line, err)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(fields[4]), 64)
+	if err != nil {
+		return PrefixInfo{}, fmt.Errorf("cty.dat: bad latitude in %q: %w", line, err)
+	}
+	long, err := strconv.ParseFloat(strings.TrimSpace(fields[5]), 64)
+	if err != nil {
+		return PrefixInfo{}, fmt.Errorf("cty.dat: bad longitude in %q: %w", line, err)
+	}
+	gmt, err := strconv.ParseFloat(strings.TrimSpace(fields[6]), 64)
+	if err != nil {
+		return PrefixInfo{}, fmt.Errorf("cty.dat: bad GMT offset in %q: %w", line, err)
+	}
+	return PrefixInfo{
+		Country:   strings.TrimSpace(fields[0]),
+		CQZone:    cq,
+		ITUZone:   itu,
+		Continent: strings.TrimSpace(fields[3]),
+		Latitude:  lat,
+		Longitude: -long, // cty.dat records west-positive longitude; PrefixInfo follows the usual east-positive convention.
+		GMTOffset: gmt,
+	}, nil
+}
+
+// applyCTYDatPrefixList parses a semicolon-terminated, comma-separated list
+// of prefix entries and records each one in out, starting from base and
+// applying any per-entry override annotations.
+func applyCTYDatPrefixList(out map[string]PrefixInfo, base PrefixInfo, rawList string) error {
+	list := strings.TrimSuffix(strings.TrimSpace(rawList), ";")
+	for _, raw := range strings.Split(list, ",") {
+		entry := strings.TrimSpace(raw)
+		if entry == "" {
+			continue
+		}
+
+		info := base
+		prefix := entry
+		if loc := ctyDatOverrideRE.FindAllStringIndex(entry, -1); len(loc) > 0 {
+			prefix = entry[:loc[0][0]]
+			for _, m := range loc {
+				applyCTYDatOverride(&info, entry[m[0]:m[1]])
+			}
+		}
+
+		prefix = strings.TrimSpace(prefix)
+		exact := strings.HasPrefix(prefix, "=")
+		if exact {
+			prefix = strings.TrimPrefix(prefix, "=")
+		}
+		if prefix == "" {
+			continue
+		}
+
+		info.Prefix = prefix
+		info.ExactCallsign = exact
+		out[strings.ToUpper(prefix)] = info
+	}
+	return nil
+}
+
+// applyCTYDatOverride applies a single annotation token (including its
+// delimiters, e.g. "(5)" or "<52.23/21.02>") to info.
+func applyCTYDatOverride(info *PrefixInfo, token string) {
+	switch token[0] {
+	case '(':
+		if zone, err := strconv.Atoi(strings.Trim(token, "()")); err == nil {
+			info.CQZone = zone
+		}
+	case '[':
+		if zone, err := strconv.Atoi(strings.Trim(token, "[]")); err == nil {
+			info.ITUZone = zone
+		}
+	case '{':
+		info.Continent = strings.Trim(token, "{}")
+	case '<':
+		coords := strings.Split(strings.Trim(token, "<>"), "/")
+		if len(coords) == 2 {
+			if lat, err := strconv.ParseFloat(coords[0], 64); err == nil {
+				info.Latitude = lat
+			}
+			if long, err := strconv.ParseFloat(coords[1], 64); err == nil {
+				info.Longitude = -long
+			}
+		}
+	case '~':
+		if gmt, err := strconv.ParseFloat(strings.Trim(token, "~"), 64); err == nil {
+			info.GMTOffset = gmt
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cty
+
+import "testing"
+
+const sampleCTYDat = `Afghanistan:                             9:  21:  AS:   33.00:    65.00:    -4.5:  YA:
+    YA;
+
+Alaska:                                  1:   1:  NA:   61.50:   149.90:     9.0:  KL:
+    AL,NL,=KL7AB(2)[3]{EU}<45.0/10.0>~5~;
+`
+
+func TestDecodeCTYDatBasicEntity(t *testing.T) {
+	data, err := decodeCTYDat([]byte(sampleCTYDat))
+	if err != nil {
+		t.Fatalf("decode cty.dat: %v", err)
+	}
+	info, ok := data["YA"]
+	if !ok {
+		t.Fatalf("expected YA entry")
+	}
+	if info.Country != "Afghanistan" || info.CQZone != 9 || info.ITUZone != 21 || info.Continent != "AS" {
+		t.Fatalf("unexpected Afghanistan entry: %+v", info)
+	}
+}
+
+func TestDecodeCTYDatOverridesAndExactCallsign(t *testing.T) {
+	data, err := decodeCTYDat([]byte(sampleCTYDat))
+	if err != nil {
+		t.Fatalf("decode cty.dat: %v", err)
+	}
+
+	plain, ok := data["AL"]
+	if !ok || plain.CQZone != 1 || plain.Continent != "NA" {
+		t.Fatalf("expected AL to inherit Alaska's base fields, got %+v (ok=%v)", plain, ok)
+	}
+
+	exact, ok := data["KL7AB"]
+	if !ok {
+		t.Fatalf("expected KL7AB exact-callsign entry")
+	}
+	if !exact.ExactCallsign {
+		t.Fatalf("expected KL7AB to be marked exact, got %+v", exact)
+	}
+	if exact.CQZone != 2 || exact.ITUZone != 3 || exact.Continent != "EU" || exact.GMTOffset != 5 {
+		t.Fatalf("expected overrides to apply, got %+v", exact)
+	}
+	if exact.Latitude != 45.0 || exact.Longitude != -10.0 {
+		t.Fatalf("expected coordinate override (west-positive -> east-positive), got lat=%v long=%v", exact.Latitude, exact.Longitude)
+	}
+}
+
+func TestDecodeCTYDatMalformedHeader(t *testing.T) {
+	if _, err := decodeCTYDat([]byte("NotEnoughFields:1:2:\n  X;\n")); err == nil {
+		t.Fatalf("expected an error for a header line missing fields")
+	}
+}
@@ -0,0 +1,54 @@
+package cty
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileSource reads CTY data from a local file. It uses the file's mtime as
+// a Last-Modified validator, so a Manager refresh against an unchanged file
+// on disk skips re-reading and re-parsing it.
+type FileSource struct {
+	Path   string
+	Format Format
+}
+
+// NewPlistFileSource creates a FileSource reading the original plist format
+// LoadCTYDatabase has always accepted.
+func NewPlistFileSource(path string) *FileSource {
+	return &FileSource{Path: path, Format: FormatPlist}
+}
+
+// NewJSONFileSource creates a FileSource reading the JSON-encoded
+// equivalent of the plist format.
+func NewJSONFileSource(path string) *FileSource {
+	return &FileSource{Path: path, Format: FormatJSON}
+}
+
+// NewCTYDatFileSource creates a FileSource reading Country Files' (AD1C)
+// text cty.dat format.
+func NewCTYDatFileSource(path string) *FileSource {
+	return &FileSource{Path: path, Format: FormatCTYDat}
+}
+
+// Fetch implements Source.
+func (s *FileSource) Fetch(_ context.Context, cond FetchCondition) (*FetchResult, error) {
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cty: stat %s: %w", s.Path, err)
+	}
+
+	modified := info.ModTime().UTC().Format(time.RFC3339Nano)
+	if cond.LastModified != "" && cond.LastModified == modified {
+		return &FetchResult{NotModified: true}, nil
+	}
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cty: read %s: %w", s.Path, err)
+	}
+
+	return &FetchResult{Format: s.Format, Data: data, LastModified: modified}, nil
+}
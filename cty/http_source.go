@@ -0,0 +1,69 @@
+package cty
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPSource fetches CTY data from a remote URL, using If-None-Match and
+// If-Modified-Since so an unchanged upstream file costs a 304 response
+// rather than a full download and re-parse on every refresh.
+type HTTPSource struct {
+	URL    string
+	Format Format
+	Client *http.Client
+}
+
+// NewHTTPSource creates an HTTPSource fetching url, decoded as format. A
+// nil Client defaults to http.DefaultClient.
+func NewHTTPSource(url string, format Format) *HTTPSource {
+	return &HTTPSource{URL: url, Format: format}
+}
+
+func (s *HTTPSource) httpClient() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return http.DefaultClient
+}
+
+// Fetch implements Source.
+func (s *HTTPSource) Fetch(ctx context.Context, cond FetchCondition) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cty: build request for %s: %w", s.URL, err)
+	}
+	if cond.ETag != "" {
+		req.Header.Set("If-None-Match", cond.ETag)
+	}
+	if cond.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cond.LastModified)
+	}
+
+	resp, err := s.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cty: fetch %s: %w", s.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &FetchResult{NotModified: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cty: fetch %s: unexpected status %s", s.URL, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cty: read body from %s: %w", s.URL, err)
+	}
+
+	return &FetchResult{
+		Format:       s.Format,
+		Data:         data,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
@@ -0,0 +1,22 @@
+package cty
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// decodeCTYJSON decodes the same {prefix: PrefixInfo} shape the plist
+// format uses, just JSON-encoded - a lighter-weight alternative for
+// deployments that would rather not carry a plist decoder at all.
+func decodeCTYJSON(data []byte) (map[string]PrefixInfo, error) {
+	var raw map[string]PrefixInfo
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode cty json: %w", err)
+	}
+	out := make(map[string]PrefixInfo, len(raw))
+	for k, v := range raw {
+		out[strings.ToUpper(strings.TrimSpace(k))] = v
+	}
+	return out, nil
+}
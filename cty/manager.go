@@ -0,0 +1,231 @@
+package cty
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Health reports a Manager's refresh status, suitable for surfacing through
+// an operator health endpoint.
+type Health struct {
+	RefreshCount int
+	PrefixCount  int
+	LastRefresh  time.Time
+	LastError    error
+	LastErrorAt  time.Time
+}
+
+// PrefixChange describes what changed between two successfully loaded
+// snapshots: keys present in one but not the other.
+type PrefixChange struct {
+	Added   []string
+	Removed []string
+}
+
+// Subscriber is notified after a refresh loads a snapshot whose prefix keys
+// differ from the previous one.
+type Subscriber func(change PrefixChange)
+
+// Manager periodically refreshes a CTYDatabase from a Source and publishes
+// the result behind an atomic.Pointer, so LookupCallsign never blocks on a
+// reload in progress and a reload that fails - a malformed file, a network
+// error - just leaves the previous snapshot live while the failure is
+// recorded in Health.
+type Manager struct {
+	source   Source
+	interval time.Duration
+
+	current atomic.Pointer[CTYDatabase]
+
+	mu          sync.Mutex
+	cond        FetchCondition
+	subscribers []Subscriber
+	health      Health
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewManager creates a Manager that refreshes from source every interval.
+// Call Start to load the initial snapshot and begin the refresh loop.
+func NewManager(source Source, interval time.Duration) *Manager {
+	return &Manager{source: source, interval: interval}
+}
+
+// Start performs a synchronous initial load - so a misconfigured source
+// fails loudly at startup rather than silently leaving LookupCallsign empty
+// - then begins the periodic refresh loop in the background. Call Stop to
+// end the loop.
+func (m *Manager) Start(ctx context.Context) error {
+	if err := m.refresh(ctx); err != nil {
+		return fmt.Errorf("initial cty load: %w", err)
+	}
+	loopCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.loop(loopCtx)
+	return nil
+}
+
+// Stop ends the refresh loop started by Start. It's a no-op on a Manager
+// that was never started.
+func (m *Manager) Stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+}
+
+func (m *Manager) loop(ctx context.Context) {
+	defer close(m.done)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = m.refresh(ctx)
+		}
+	}
+}
+
+// refresh fetches from the source and, on success, atomically swaps the
+// current snapshot and notifies subscribers of any prefix change. A fetch
+// or decode error is recorded in Health and returned, but the previous
+// snapshot (if any) stays active.
+func (m *Manager) refresh(ctx context.Context) error {
+	m.mu.Lock()
+	cond := m.cond
+	m.mu.Unlock()
+
+	result, err := m.source.Fetch(ctx, cond)
+	if err != nil {
+		m.recordError(err)
+		return err
+	}
+
+	m.mu.Lock()
+	m.health.RefreshCount++
+	m.health.LastRefresh = time.Now()
+	m.mu.Unlock()
+
+	if result.NotModified {
+		return nil
+	}
+
+	db, err := decodeFormat(result.Format, result.Data)
+	if err != nil {
+		m.recordError(err)
+		return err
+	}
+
+	prev := m.current.Swap(db)
+
+	m.mu.Lock()
+	m.cond = FetchCondition{ETag: result.ETag, LastModified: result.LastModified}
+	m.health.PrefixCount = len(db.Keys)
+	m.health.LastError = nil
+	m.mu.Unlock()
+
+	m.notify(prev, db)
+	return nil
+}
+
+func (m *Manager) recordError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.LastError = err
+	m.health.LastErrorAt = time.Now()
+}
+
+// Health returns a snapshot of the Manager's current refresh status.
+func (m *Manager) Health() Health {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.health
+}
+
+// Subscribe registers sub to be called whenever a refresh loads a snapshot
+// with different prefix keys than the previous one.
+func (m *Manager) Subscribe(sub Subscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, sub)
+}
+
+func (m *Manager) notify(prev, next *CTYDatabase) {
+	change := diffKeys(prev, next)
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return
+	}
+	m.mu.Lock()
+	subs := append([]Subscriber(nil), m.subscribers...)
+	m.mu.Unlock()
+	for _, sub := range subs {
+		sub(change)
+	}
+}
+
+func diffKeys(prev, next *CTYDatabase) PrefixChange {
+	nextSet := make(map[string]struct{}, len(next.Keys))
+	for _, k := range next.Keys {
+		nextSet[k] = struct{}{}
+	}
+	if prev == nil {
+		change := PrefixChange{Added: append([]string(nil), next.Keys...)}
+		sort.Strings(change.Added)
+		return change
+	}
+	prevSet := make(map[string]struct{}, len(prev.Keys))
+	for _, k := range prev.Keys {
+		prevSet[k] = struct{}{}
+	}
+
+	var change PrefixChange
+	for k := range nextSet {
+		if _, ok := prevSet[k]; !ok {
+			change.Added = append(change.Added, k)
+		}
+	}
+	for k := range prevSet {
+		if _, ok := nextSet[k]; !ok {
+			change.Removed = append(change.Removed, k)
+		}
+	}
+	sort.Strings(change.Added)
+	sort.Strings(change.Removed)
+	return change
+}
+
+// Snapshot returns the currently active CTYDatabase, or nil if Start
+// hasn't successfully loaded one yet.
+func (m *Manager) Snapshot() *CTYDatabase {
+	return m.current.Load()
+}
+
+// LookupCallsign resolves cs against the current snapshot. It never takes
+// a lock: a reload in progress, or one that just failed, never blocks or
+// disrupts a lookup - it's served from whatever snapshot loaded last.
+func (m *Manager) LookupCallsign(cs string) (*PrefixInfo, bool) {
+	db := m.current.Load()
+	if db == nil {
+		return nil, false
+	}
+	return db.LookupCallsign(cs)
+}
+
+// LookupCallsignDetailed is CTYDatabase.LookupCallsignDetailed against the
+// current snapshot - see LookupCallsign for the lock-free read guarantee.
+func (m *Manager) LookupCallsignDetailed(cs string) (LookupResult, bool) {
+	db := m.current.Load()
+	if db == nil {
+		return LookupResult{}, false
+	}
+	return db.LookupCallsignDetailed(cs)
+}
@@ -0,0 +1,151 @@
+package cty
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSource is a Source driven entirely by test code: each call to Fetch
+// pops the next scripted result or error.
+type fakeSource struct {
+	mu      sync.Mutex
+	results []*FetchResult
+	errs    []error
+	calls   int
+}
+
+func (f *fakeSource) Fetch(_ context.Context, _ FetchCondition) (*FetchResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i := f.calls
+	f.calls++
+	if i < len(f.errs) && f.errs[i] != nil {
+		return nil, f.errs[i]
+	}
+	if i < len(f.results) {
+		return f.results[i], nil
+	}
+	return f.results[len(f.results)-1], nil
+}
+
+func plistResult(body string) *FetchResult {
+	return &FetchResult{Format: FormatPlist, Data: []byte(body)}
+}
+
+func TestManagerStartLoadsInitialSnapshot(t *testing.T) {
+	source := &fakeSource{results: []*FetchResult{plistResult(samplePLIST)}}
+	m := NewManager(source, time.Hour)
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+
+	info, ok := m.LookupCallsign("K1ABC")
+	if !ok || info.Country != "Alpha" {
+		t.Fatalf("expected K1ABC to resolve from the initial snapshot, got %+v (ok=%v)", info, ok)
+	}
+}
+
+func TestManagerStartFailsOnBadInitialLoad(t *testing.T) {
+	source := &fakeSource{errs: []error{errors.New("boom")}}
+	m := NewManager(source, time.Hour)
+
+	if err := m.Start(context.Background()); err == nil {
+		t.Fatalf("expected Start to surface the initial load error")
+	}
+	if _, ok := m.LookupCallsign("K1ABC"); ok {
+		t.Fatalf("expected no snapshot to be available after a failed initial load")
+	}
+}
+
+func TestManagerRefreshKeepsPreviousSnapshotOnError(t *testing.T) {
+	source := &fakeSource{
+		results: []*FetchResult{plistResult(samplePLIST)},
+	}
+	m := NewManager(source, time.Hour)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+
+	source.mu.Lock()
+	source.errs = []error{nil, errors.New("upstream down")}
+	source.mu.Unlock()
+
+	if err := m.refresh(context.Background()); err == nil {
+		t.Fatalf("expected the second refresh to fail")
+	}
+
+	if _, ok := m.LookupCallsign("K1ABC"); !ok {
+		t.Fatalf("expected the previous snapshot to remain active after a failed refresh")
+	}
+	health := m.Health()
+	if health.LastError == nil {
+		t.Fatalf("expected Health to record the refresh error")
+	}
+}
+
+func TestManagerNotModifiedSkipsSwap(t *testing.T) {
+	source := &fakeSource{
+		results: []*FetchResult{plistResult(samplePLIST), {NotModified: true}},
+	}
+	m := NewManager(source, time.Hour)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+
+	before := m.Snapshot()
+	if err := m.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if m.Snapshot() != before {
+		t.Fatalf("expected a NotModified result to leave the snapshot untouched")
+	}
+}
+
+func TestManagerNotifiesSubscribersOfPrefixChanges(t *testing.T) {
+	const updated = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+<key>K1ABC</key>
+	<dict>
+		<key>Country</key>
+		<string>Alpha</string>
+		<key>Prefix</key>
+		<string>K1ABC</string>
+	</dict>
+<key>ZZ9</key>
+	<dict>
+		<key>Country</key>
+		<string>New</string>
+		<key>Prefix</key>
+		<string>ZZ9</string>
+	</dict>
+</dict>
+</plist>`
+
+	source := &fakeSource{results: []*FetchResult{plistResult(samplePLIST), plistResult(updated)}}
+	m := NewManager(source, time.Hour)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+
+	var change PrefixChange
+	m.Subscribe(func(c PrefixChange) { change = c })
+
+	if err := m.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if len(change.Added) != 1 || change.Added[0] != "ZZ9" {
+		t.Fatalf("expected ZZ9 reported added, got %+v", change)
+	}
+	if len(change.Removed) != 2 || change.Removed[0] != "K1" || change.Removed[1] != "XM3" {
+		t.Fatalf("expected K1 and XM3 reported removed, got %+v", change)
+	}
+}
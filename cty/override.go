@@ -0,0 +1,79 @@
+package cty
+
+import (
+	"fmt"
+	"strings"
+)
+
+// portableQualifiers are suffixes that describe how a station is operating
+// rather than where it is, so they never change which DXCC entity a call
+// resolves to - they just fall through to normalizeCallsign's strip-and-
+// look-up-the-base-call behavior.
+var portableQualifiers = map[string]struct{}{
+	"P":   {},
+	"M":   {},
+	"MM":  {},
+	"AM":  {},
+	"QRP": {},
+}
+
+// resolveOverride checks call for a portable-ID override: a "/" suffix that
+// re-homes the callsign to a different entity before any ordinary prefix
+// lookup happens. Two shapes are recognized:
+//
+//   - a single trailing digit (K1ABC/4) swaps the call-area digit in the
+//     base call and re-looks-up the result, e.g. K1ABC/4 resolves as W4's
+//     entity rather than W1's;
+//   - any other suffix that itself resolves in the trie (K1ABC/VE3,
+//     KH6/W1AW) re-homes the call to whatever the suffix resolves to.
+//
+// A plain portableQualifiers suffix, or a suffix that isn't itself
+// recognized, returns ok=false so the caller falls back to normal
+// normalize-and-lookup.
+func (db *CTYDatabase) resolveOverride(call string) (PrefixInfo, []string, bool) {
+	base, suffix, hasSuffix := splitCallSuffix(call)
+	if !hasSuffix {
+		return PrefixInfo{}, nil, false
+	}
+	if _, plain := portableQualifiers[suffix]; plain {
+		return PrefixInfo{}, nil, false
+	}
+
+	if len(suffix) == 1 && suffix[0] >= '0' && suffix[0] <= '9' {
+		swapped := swapCallArea(base, suffix[0])
+		if swapped == "" || swapped == base {
+			return PrefixInfo{}, nil, false
+		}
+		if info, _, _, ok := db.trie.lookup(swapped); ok {
+			return info, []string{fmt.Sprintf("%s -> %s (call-area digit swap)", call, swapped)}, true
+		}
+		return PrefixInfo{}, nil, false
+	}
+
+	if info, _, _, ok := db.trie.lookup(suffix); ok {
+		return info, []string{fmt.Sprintf("%s -> %s (portable-ID override)", call, suffix)}, true
+	}
+	return PrefixInfo{}, nil, false
+}
+
+// splitCallSuffix splits call on its last "/", e.g. "K1ABC/VE3" -> ("K1ABC",
+// "VE3", true). A call with no "/" returns ok=false.
+func splitCallSuffix(call string) (base, suffix string, ok bool) {
+	idx := strings.LastIndexByte(call, '/')
+	if idx < 0 {
+		return call, "", false
+	}
+	return call[:idx], call[idx+1:], true
+}
+
+// swapCallArea replaces the last digit in base with digit, e.g. swapping
+// "1" for the "1" in "K1ABC" gives "K1ABC" back (no-op) while swapping "4"
+// gives "K4ABC". Returns "" if base has no digit to swap.
+func swapCallArea(base string, digit byte) string {
+	for i := len(base) - 1; i >= 0; i-- {
+		if base[i] >= '0' && base[i] <= '9' {
+			return base[:i] + string(digit) + base[i+1:]
+		}
+	}
+	return ""
+}
@@ -24,10 +24,23 @@ type PrefixInfo struct {
 	ExactCallsign bool    `plist:"ExactCallsign"`
 }
 
-// CTYDatabase holds the plist data and sorted keys for longest-prefix lookup.
+// CTYDatabase holds the plist data, sorted keys (kept for KeysWithPrefix and
+// other callers that want the raw key list), and a prefix trie built over
+// the same data for O(len(call)) lookups.
 type CTYDatabase struct {
 	Data map[string]PrefixInfo
 	Keys []string
+
+	trie *ctyTrie
+}
+
+// LookupResult is the detailed outcome of LookupCallsignDetailed: the
+// resolved metadata plus enough to explain how it was matched.
+type LookupResult struct {
+	Info       PrefixInfo
+	Reason     MatchReason
+	Overrides  []string
+	Confidence float64
 }
 
 // LoadCTYDatabase loads cty.plist into a lookup database.
@@ -46,6 +59,13 @@ func LoadCTYDatabaseFromReader(r io.ReadSeeker) (*CTYDatabase, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newCTYDatabase(data), nil
+}
+
+// newCTYDatabase builds a CTYDatabase - sorted keys plus the prefix trie -
+// from already-decoded prefix data. Shared by every format decoder (plist,
+// JSON, cty.dat) so they only have to produce the map[string]PrefixInfo.
+func newCTYDatabase(data map[string]PrefixInfo) *CTYDatabase {
 	keys := make([]string, 0, len(data))
 	for k := range data {
 		keys = append(keys, k)
@@ -56,7 +76,13 @@ func LoadCTYDatabaseFromReader(r io.ReadSeeker) (*CTYDatabase, error) {
 		}
 		return len(keys[i]) > len(keys[j])
 	})
-	return &CTYDatabase{Data: data, Keys: keys}, nil
+
+	trie := newCTYTrie()
+	for k, v := range data {
+		trie.insert(k, v)
+	}
+
+	return &CTYDatabase{Data: data, Keys: keys, trie: trie}
 }
 
 func decodeCTYData(r io.ReadSeeker) (map[string]PrefixInfo, error) {
@@ -85,23 +111,52 @@ func normalizeCallsign(cs string) string {
 	return cs
 }
 
-// LookupCallsign returns metadata for the callsign or false if unknown.
+// LookupCallsign returns metadata for the callsign or false if unknown. It
+// is a thin wrapper over LookupCallsignDetailed for callers that don't need
+// the match reason or override chain.
 func (db *CTYDatabase) LookupCallsign(cs string) (*PrefixInfo, bool) {
-	cs = normalizeCallsign(cs)
-	if info, ok := db.Data[cs]; ok {
-		return &info, true
+	result, ok := db.LookupCallsignDetailed(cs)
+	if !ok {
+		return nil, false
 	}
+	info := result.Info
+	return &info, true
+}
 
-	for _, key := range db.Keys {
-		if len(key) > len(cs) {
-			continue
-		}
-		if strings.HasPrefix(cs, key) {
-			info := db.Data[key]
-			return &info, true
-		}
+// LookupCallsignDetailed resolves cs against the prefix trie and reports
+// how the match was made. A portable-ID override (a "/" suffix that
+// re-homes the call to a different entity, e.g. K1ABC/VE3 or the call-area
+// digit swap K1ABC/4) is tried first. Next comes a direct trie walk against
+// the raw call, which wins outright if it consumes the whole string - this
+// is what lets a wildcarded key like "K[0-9]/QRP" match a call that
+// literally ends in "/QRP". Only if that doesn't fully resolve does a
+// recognized portable qualifier (/P, /M, /MM, /AM, /QRP) get stripped and
+// looked up on its own, so a plain portable-operating call still reports an
+// exact match on its base callsign rather than a partial one.
+func (db *CTYDatabase) LookupCallsignDetailed(cs string) (LookupResult, bool) {
+	cs = strings.ToUpper(strings.TrimSpace(cs))
+	if cs == "" {
+		return LookupResult{}, false
+	}
+
+	if info, chain, ok := db.resolveOverride(cs); ok {
+		return LookupResult{Info: info, Reason: MatchOverride, Overrides: chain, Confidence: 0.9}, true
+	}
+
+	if info, reason, matchLen, ok := db.trie.lookup(cs); ok && matchLen == len(cs) {
+		return LookupResult{Info: info, Reason: reason, Confidence: confidenceFor(reason, matchLen, len(cs))}, true
+	}
+
+	normalized := normalizeCallsign(cs)
+	info, reason, matchLen, ok := db.trie.lookup(normalized)
+	if !ok {
+		return LookupResult{}, false
 	}
-	return nil, false
+	return LookupResult{
+		Info:       info,
+		Reason:     reason,
+		Confidence: confidenceFor(reason, matchLen, len(normalized)),
+	}, true
 }
 
 // KeysWithPrefix returns all known CTY keys starting with prefix (used for testing).
@@ -0,0 +1,71 @@
+package cty
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// Format identifies which decoder a Source's fetched bytes should go
+// through.
+type Format int
+
+const (
+	// FormatPlist is the original Apple-plist {prefix: PrefixInfo} shape
+	// LoadCTYDatabase has always accepted.
+	FormatPlist Format = iota
+	// FormatJSON is the same {prefix: PrefixInfo} shape, JSON-encoded.
+	FormatJSON
+	// FormatCTYDat is Country Files' (AD1C) text cty.dat format.
+	FormatCTYDat
+)
+
+// FetchCondition carries the caching validators from a Manager's previous
+// successful fetch, so a Source can skip re-transferring and re-parsing
+// data that hasn't changed.
+type FetchCondition struct {
+	ETag         string
+	LastModified string
+}
+
+// FetchResult is the outcome of a Source.Fetch call: either fresh data and
+// its new validators, or NotModified=true if the condition still matches
+// and there's nothing to re-parse.
+type FetchResult struct {
+	Format       Format
+	Data         []byte
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// Source supplies raw CTY data to a Manager. Implementations decide for
+// themselves what "changed" means (file mtime, HTTP validators, ...) and
+// report it back via FetchResult so Manager never has to parse data it
+// already has.
+type Source interface {
+	Fetch(ctx context.Context, cond FetchCondition) (*FetchResult, error)
+}
+
+// decodeFormat dispatches fetched bytes to the decoder matching format and
+// builds a CTYDatabase from the result.
+func decodeFormat(format Format, data []byte) (*CTYDatabase, error) {
+	var (
+		parsed map[string]PrefixInfo
+		err    error
+	)
+	switch format {
+	case FormatPlist:
+		parsed, err = decodeCTYData(bytes.NewReader(data))
+	case FormatJSON:
+		parsed, err = decodeCTYJSON(data)
+	case FormatCTYDat:
+		parsed, err = decodeCTYDat(data)
+	default:
+		return nil, fmt.Errorf("cty: unknown source format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newCTYDatabase(parsed), nil
+}
@@ -0,0 +1,229 @@
+package cty
+
+import "strings"
+
+// MatchReason explains how a LookupCallsignDetailed call resolved a
+// callsign, so a caller can decide how much to trust the result (e.g. log
+// or discount a low-confidence wildcard match differently from an exact
+// override).
+type MatchReason int
+
+const (
+	MatchNone MatchReason = iota
+	MatchExact
+	MatchPrefix
+	MatchWildcard
+	MatchOverride
+)
+
+func (r MatchReason) String() string {
+	switch r {
+	case MatchExact:
+		return "exact"
+	case MatchPrefix:
+		return "prefix"
+	case MatchWildcard:
+		return "wildcard"
+	case MatchOverride:
+		return "override"
+	default:
+		return "none"
+	}
+}
+
+// classEdge is a character-class trie transition, for plist keys like
+// "K[0-9]/QRP" that match any one of a set of bytes at a given position
+// rather than a single literal byte.
+type classEdge struct {
+	match func(byte) bool
+	next  *trieNode
+}
+
+type trieNode struct {
+	children    map[byte]*trieNode
+	classes     []classEdge
+	info        *PrefixInfo
+	viaWildcard bool
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[byte]*trieNode)}
+}
+
+// ctyTrie is a longest-prefix-match lookup table over CTY keys, extended
+// with character-class edges for wildcarded keys. It runs in O(len(call))
+// regardless of how many keys are loaded, unlike the length-sorted linear
+// scan it replaces.
+type ctyTrie struct {
+	root *trieNode
+}
+
+func newCTYTrie() *ctyTrie {
+	return &ctyTrie{root: newTrieNode()}
+}
+
+// insert adds key -> info to the trie. A key containing a bracketed class
+// like "[0-9]" or "[A-F]" is tokenized into a mix of literal-byte and
+// class-predicate transitions; a plain key is all literal bytes.
+func (t *ctyTrie) insert(key string, info PrefixInfo) {
+	toks := tokenizeKey(key)
+	if len(toks) == 0 {
+		return
+	}
+	node := t.root
+	wildcard := false
+	for _, tok := range toks {
+		if tok.isClass {
+			wildcard = true
+			next := newTrieNode()
+			node.classes = append(node.classes, classEdge{match: tok.class, next: next})
+			node = next
+			continue
+		}
+		child, ok := node.children[tok.literal]
+		if !ok {
+			child = newTrieNode()
+			node.children[tok.literal] = child
+		}
+		node = child
+	}
+	v := info
+	node.info = &v
+	node.viaWildcard = wildcard
+}
+
+// lookup walks call against the trie, returning the longest match found
+// (literal transitions are tried before class edges at each position, so a
+// specific literal key outranks an overlapping wildcard one) along with the
+// number of leading bytes of call it consumed and whether any class edge
+// was used along the winning path.
+func (t *ctyTrie) lookup(call string) (PrefixInfo, MatchReason, int, bool) {
+	node := t.root
+	var bestInfo *PrefixInfo
+	var bestLen int
+	var bestWildcard bool
+	viaWildcard := false
+
+	for i := 0; i < len(call); i++ {
+		b := call[i]
+		next, ok := node.children[b]
+		stepWildcard := false
+		if !ok {
+			for _, ce := range node.classes {
+				if ce.match(b) {
+					next = ce.next
+					ok = true
+					stepWildcard = true
+					break
+				}
+			}
+		}
+		if !ok {
+			break
+		}
+		node = next
+		viaWildcard = viaWildcard || stepWildcard
+		if node.info != nil {
+			bestInfo = node.info
+			bestLen = i + 1
+			bestWildcard = viaWildcard
+		}
+	}
+
+	if bestInfo == nil {
+		return PrefixInfo{}, MatchNone, 0, false
+	}
+	reason := MatchPrefix
+	if bestLen == len(call) {
+		reason = MatchExact
+	}
+	if bestWildcard {
+		reason = MatchWildcard
+	}
+	return *bestInfo, reason, bestLen, true
+}
+
+// confidenceFor scores a trie match: exact full-string matches are
+// certain, an override is trusted but not absolute (it's a heuristic
+// re-homing, not data straight from the table), and prefix/wildcard
+// matches scale with how much of the call the matched key actually
+// accounted for.
+func confidenceFor(reason MatchReason, matchLen, callLen int) float64 {
+	if callLen == 0 {
+		return 0
+	}
+	fraction := float64(matchLen) / float64(callLen)
+	if fraction > 1 {
+		fraction = 1
+	}
+	switch reason {
+	case MatchExact:
+		return 1.0
+	case MatchWildcard:
+		return 0.5 + 0.3*fraction
+	case MatchPrefix:
+		return 0.4 + 0.5*fraction
+	default:
+		return 0
+	}
+}
+
+type token struct {
+	literal byte
+	class   func(byte) bool
+	isClass bool
+}
+
+// tokenizeKey splits a plist key into literal-byte tokens and, for each
+// "[...]" run, a single class-predicate token covering its whole body. A
+// malformed/unterminated "[" is treated as a literal byte rather than
+// rejected outright, since a handful of odd legacy CTY keys do contain a
+// bare bracket.
+func tokenizeKey(key string) []token {
+	toks := make([]token, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '[' {
+			end := strings.IndexByte(key[i:], ']')
+			if end < 0 {
+				toks = append(toks, token{literal: c})
+				continue
+			}
+			body := key[i+1 : i+end]
+			toks = append(toks, token{isClass: true, class: classPredicate(body)})
+			i += end
+			continue
+		}
+		toks = append(toks, token{literal: c})
+	}
+	return toks
+}
+
+// classPredicate builds a byte-matching predicate from a bracket body like
+// "0-9" or "A-F13", supporting any mix of explicit ranges and single
+// characters.
+func classPredicate(body string) func(byte) bool {
+	var ranges [][2]byte
+	var singles []byte
+	for i := 0; i < len(body); i++ {
+		if i+2 < len(body) && body[i+1] == '-' {
+			ranges = append(ranges, [2]byte{body[i], body[i+2]})
+			i += 2
+			continue
+		}
+		singles = append(singles, body[i])
+	}
+	return func(b byte) bool {
+		for _, r := range ranges {
+			if b >= r[0] && b <= r[1] {
+				return true
+			}
+		}
+		for _, s := range singles {
+			if b == s {
+				return true
+			}
+		}
+		return false
+	}
+}
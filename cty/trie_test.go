@@ -0,0 +1,114 @@
+package cty
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestWildcardClassMatch(t *testing.T) {
+	db := &CTYDatabase{trie: newCTYTrie()}
+	db.trie.insert("K[0-9]/QRP", PrefixInfo{Country: "QRP Zone", Prefix: "K[0-9]/QRP"})
+
+	result, ok := db.LookupCallsignDetailed("K5/QRP")
+	if !ok {
+		t.Fatalf("expected K5/QRP to resolve against the K[0-9]/QRP wildcard")
+	}
+	if result.Reason != MatchWildcard {
+		t.Fatalf("expected MatchWildcard, got %v", result.Reason)
+	}
+	if result.Info.Country != "QRP Zone" {
+		t.Fatalf("expected QRP Zone, got %q", result.Info.Country)
+	}
+
+	if _, ok := db.LookupCallsignDetailed("KA/QRP"); ok {
+		t.Fatalf("expected KA/QRP to miss the K[0-9]/QRP wildcard (A is not a digit)")
+	}
+}
+
+func TestOverrideCallAreaDigitSwap(t *testing.T) {
+	db := &CTYDatabase{trie: newCTYTrie()}
+	db.trie.insert("K1", PrefixInfo{Country: "USA 1", Prefix: "K1"})
+	db.trie.insert("K4", PrefixInfo{Country: "USA 4", Prefix: "K4"})
+
+	result, ok := db.LookupCallsignDetailed("K1ABC/4")
+	if !ok {
+		t.Fatalf("expected K1ABC/4 to resolve via call-area digit swap")
+	}
+	if result.Reason != MatchOverride {
+		t.Fatalf("expected MatchOverride, got %v", result.Reason)
+	}
+	if result.Info.Country != "USA 4" {
+		t.Fatalf("expected the swapped-to entity USA 4, got %q", result.Info.Country)
+	}
+	if len(result.Overrides) != 1 {
+		t.Fatalf("expected a one-step override chain, got %+v", result.Overrides)
+	}
+}
+
+func TestOverrideEntitySuffix(t *testing.T) {
+	db := &CTYDatabase{trie: newCTYTrie()}
+	db.trie.insert("K1", PrefixInfo{Country: "USA 1", Prefix: "K1"})
+	db.trie.insert("VE3", PrefixInfo{Country: "Canada 3", Prefix: "VE3"})
+
+	result, ok := db.LookupCallsignDetailed("K1ABC/VE3")
+	if !ok {
+		t.Fatalf("expected K1ABC/VE3 to re-home to VE3")
+	}
+	if result.Info.Country != "Canada 3" {
+		t.Fatalf("expected Canada 3, got %q", result.Info.Country)
+	}
+}
+
+func TestOverridePortableQualifierFallsThrough(t *testing.T) {
+	db := &CTYDatabase{trie: newCTYTrie()}
+	db.trie.insert("K1ABC", PrefixInfo{Country: "Alpha", Prefix: "K1ABC"})
+
+	result, ok := db.LookupCallsignDetailed("K1ABC/QRP")
+	if !ok {
+		t.Fatalf("expected K1ABC/QRP to fall through to the base call")
+	}
+	if result.Reason != MatchExact {
+		t.Fatalf("expected a plain exact match on the stripped base call, got %v", result.Reason)
+	}
+}
+
+// BenchmarkLookupCallsignFullDatabase exercises LookupCallsign against a
+// database shaped like a real cty.plist (one entry per letter/digit prefix
+// pair, ~1000 keys) to demonstrate the trie holds sub-microsecond lookups
+// regardless of database size - the property the linear scan it replaced
+// didn't have.
+func BenchmarkLookupCallsignFullDatabase(b *testing.B) {
+	db := syntheticFullDatabase()
+	calls := []string{"W1AW", "K5ABC/4", "VE3XYZ", "K1ABC/VE3", "JA1XYZ", "G4ABC/P", "9A1ABCD"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db.LookupCallsign(calls[i%len(calls)])
+	}
+}
+
+func syntheticFullDatabase() *CTYDatabase {
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	data := make(map[string]PrefixInfo, len(letters)*36)
+	trie := newCTYTrie()
+	for _, l1 := range letters {
+		for _, l2 := range letters + "0123456789" {
+			key := fmt.Sprintf("%c%c", l1, l2)
+			info := PrefixInfo{Country: key, Prefix: key}
+			data[key] = info
+			trie.insert(key, info)
+		}
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) == len(keys[j]) {
+			return keys[i] < keys[j]
+		}
+		return len(keys[i]) > len(keys[j])
+	})
+	return &CTYDatabase{Data: data, Keys: keys, trie: trie}
+}
@@ -0,0 +1,56 @@
+package decisionlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NewHTTPHandler serves the reappearance and oscillation analyses as JSON
+// over HTTP, so an operator can run them against the live decision log
+// instead of only against yesterday's dump. Routes:
+//
+//	GET /reappearances?lookahead_hours=24
+//	GET /oscillations?window_minutes=60
+func NewHTTPHandler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/reappearances", func(w http.ResponseWriter, r *http.Request) {
+		hours := intQueryParam(r, "lookahead_hours", 24)
+		cases, err := store.FindReappearances(time.Duration(hours) * time.Hour)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, cases)
+	})
+
+	mux.HandleFunc("/oscillations", func(w http.ResponseWriter, r *http.Request) {
+		minutes := intQueryParam(r, "window_minutes", 60)
+		candidates, err := store.OscillationCandidates(time.Duration(minutes) * time.Minute)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, candidates)
+	})
+
+	return mux
+}
+
+func intQueryParam(r *http.Request, name string, def int) int {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed <= 0 {
+		return def
+	}
+	return parsed
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
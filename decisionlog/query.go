@@ -0,0 +1,295 @@
+package decisionlog
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// LoadDistanceRange returns every decision whose distance falls in
+// [minDistance, maxDistance], ordered by timestamp - the shape a
+// calibration sweep needs to replay decisions in order.
+func (s *Store) LoadDistanceRange(minDistance, maxDistance int) ([]Decision, error) {
+	rows, err := s.db.Query(`
+		SELECT id, ts, subject, winner, freq_khz, distance,
+			winner_support, subject_support, total_reporters, winner_confidence,
+			min_reports, min_advantage, min_confidence, decision, COALESCE(reason, ''),
+			COALESCE(harmonic_of, '[]')
+		FROM decisions
+		WHERE distance BETWEEN ? AND ?
+		ORDER BY ts
+	`, minDistance, maxDistance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDecisions(rows)
+}
+
+// LoadSince returns every decision logged at or after since, capped at
+// limit rows (0 means unlimited) - the bounded scan a continuous feedback
+// loop needs so a burst of logged decisions can't make one pass scan an
+// unbounded result set. When limit is reached, the newest rows in the
+// window are kept (ORDER BY ts DESC ... LIMIT) rather than the oldest, so a
+// loop reacting to "recent" decisions doesn't get stuck looking at the
+// start of the window forever on a busy node; the returned slice is then
+// restored to timestamp-ascending order, since nothing besides LIMIT cares
+// about descending order.
+func (s *Store) LoadSince(since time.Time, limit int) ([]Decision, error) {
+	query := `
+		SELECT id, ts, subject, winner, freq_khz, distance,
+			winner_support, subject_support, total_reporters, winner_confidence,
+			min_reports, min_advantage, min_confidence, decision, COALESCE(reason, ''),
+			COALESCE(harmonic_of, '[]')
+		FROM decisions
+		WHERE ts >= ?
+		ORDER BY ts DESC
+	`
+	args := []any{since.UTC().Unix()}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	decisions, err := scanDecisions(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(decisions)-1; i < j; i, j = i+1, j-1 {
+		decisions[i], decisions[j] = decisions[j], decisions[i]
+	}
+	return decisions, nil
+}
+
+func scanDecisions(rows *sql.Rows) ([]Decision, error) {
+	var out []Decision
+	for rows.Next() {
+		var d Decision
+		var ts int64
+		var harmonicOf string
+		if err := rows.Scan(&d.ID, &ts, &d.Subject, &d.Winner, &d.FreqKHz, &d.Distance,
+			&d.WinnerSupport, &d.SubjectSupport, &d.TotalReporters, &d.WinnerConfidence,
+			&d.MinReports, &d.MinAdvantage, &d.MinConfidence, &d.Decision, &d.Reason, &harmonicOf); err != nil {
+			return nil, err
+		}
+		d.Timestamp = time.Unix(ts, 0).UTC()
+		d.Subject = strings.ToUpper(strings.TrimSpace(d.Subject))
+		d.Winner = strings.ToUpper(strings.TrimSpace(d.Winner))
+		if err := json.Unmarshal([]byte(harmonicOf), &d.HarmonicOf); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// Reappearance describes an applied correction where both the original
+// subject and the chosen winner callsign reappeared within lookAhead,
+// along with the frequency each reappeared at - the pattern
+// investigate_reappearances looks for to distinguish a legitimate
+// same-callsign-family collision from a bad correction.
+type Reappearance struct {
+	Decision          Decision
+	SubjectFreqKHz    float64
+	WinnerFreqKHz     float64
+	FreqSeparationKHz float64
+}
+
+// FindReappearances returns every applied correction where both the
+// subject and winner calls appear again in a later decision within
+// lookAhead of the correction.
+func (s *Store) FindReappearances(lookAhead time.Duration) ([]Reappearance, error) {
+	rows, err := s.db.Query(`
+		SELECT id, ts, subject, winner, freq_khz, distance, winner_confidence
+		FROM decisions
+		WHERE decision = 'applied'
+		ORDER BY ts
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	type applied struct {
+		d  Decision
+		ts int64
+	}
+	var corrections []applied
+	for rows.Next() {
+		var d Decision
+		var ts int64
+		if err := rows.Scan(&d.ID, &ts, &d.Subject, &d.Winner, &d.FreqKHz, &d.Distance, &d.WinnerConfidence); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		d.Timestamp = time.Unix(ts, 0).UTC()
+		d.Subject = strings.ToUpper(strings.TrimSpace(d.Subject))
+		d.Winner = strings.ToUpper(strings.TrimSpace(d.Winner))
+		corrections = append(corrections, applied{d: d, ts: ts})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var cases []Reappearance
+	for _, c := range corrections {
+		endTs := c.ts + int64(lookAhead.Seconds())
+		subRows, err := s.db.Query(`
+			SELECT subject, freq_khz
+			FROM decisions
+			WHERE ts > ? AND ts <= ? AND (UPPER(subject) = ? OR UPPER(subject) = ?)
+			ORDER BY ts
+			LIMIT 100
+		`, c.ts, endTs, c.d.Subject, c.d.Winner)
+		if err != nil {
+			return nil, err
+		}
+
+		var subjectFreq, winnerFreq float64
+		subjectSeen, winnerSeen := false, false
+		for subRows.Next() {
+			var subject string
+			var freq float64
+			if err := subRows.Scan(&subject, &freq); err != nil {
+				subRows.Close()
+				return nil, err
+			}
+			subject = strings.ToUpper(strings.TrimSpace(subject))
+			if subject == c.d.Subject && !subjectSeen {
+				subjectSeen = true
+				subjectFreq = freq
+			}
+			if subject == c.d.Winner && !winnerSeen {
+				winnerSeen = true
+				winnerFreq = freq
+			}
+		}
+		subRows.Close()
+		if err := subRows.Err(); err != nil {
+			return nil, err
+		}
+
+		if subjectSeen && winnerSeen {
+			cases = append(cases, Reappearance{
+				Decision:          c.d,
+				SubjectFreqKHz:    subjectFreq,
+				WinnerFreqKHz:     winnerFreq,
+				FreqSeparationKHz: FrequencySeparation(subjectFreq, winnerFreq),
+			})
+		}
+	}
+	return cases, nil
+}
+
+// FrequencySeparation is the absolute kHz distance between two
+// reappearance frequencies, exposed standalone since tools bucketing
+// reappearances by separation don't all need a full Store.
+func FrequencySeparation(aKHz, bKHz float64) float64 {
+	sep := aKHz - bKHz
+	if sep < 0 {
+		sep = -sep
+	}
+	return sep
+}
+
+// OscillationCandidate is a subject callsign whose corrected winner flipped
+// back to a previously-seen value one or more times within the scan
+// window (A->B, then B->A) - a sign the correction is chasing noise rather
+// than converging on a stable answer.
+type OscillationCandidate struct {
+	Subject   string
+	Winners   []string
+	Flips     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// OscillationCandidates scans applied decisions per subject for a winner
+// that reverts to one it had already moved away from, within window of the
+// previous decision for that subject. A winner simply changing each time
+// (steady drift) doesn't count; only an actual reversal does.
+func (s *Store) OscillationCandidates(window time.Duration) ([]OscillationCandidate, error) {
+	rows, err := s.db.Query(`
+		SELECT ts, subject, winner
+		FROM decisions
+		WHERE decision = 'applied'
+		ORDER BY ts
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type event struct {
+		ts              int64
+		subject, winner string
+	}
+	var events []event
+	for rows.Next() {
+		var e event
+		if err := rows.Scan(&e.ts, &e.subject, &e.winner); err != nil {
+			return nil, err
+		}
+		e.subject = strings.ToUpper(strings.TrimSpace(e.subject))
+		e.winner = strings.ToUpper(strings.TrimSpace(e.winner))
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	type state struct {
+		history     []string
+		flips       int
+		first, last int64
+	}
+	windowSecs := int64(window.Seconds())
+	bySubject := make(map[string]*state)
+
+	for _, e := range events {
+		st, ok := bySubject[e.subject]
+		switch {
+		case !ok:
+			bySubject[e.subject] = &state{history: []string{e.winner}, first: e.ts, last: e.ts}
+		case e.ts-st.last > windowSecs:
+			// Outside the oscillation window: start tracking this subject
+			// fresh rather than counting a flip across a long gap.
+			st.history = []string{e.winner}
+			st.flips = 0
+			st.first = e.ts
+			st.last = e.ts
+		default:
+			last := st.history[len(st.history)-1]
+			if e.winner != last {
+				for _, prior := range st.history[:len(st.history)-1] {
+					if prior == e.winner {
+						st.flips++
+						break
+					}
+				}
+				st.history = append(st.history, e.winner)
+			}
+			st.last = e.ts
+		}
+	}
+
+	var out []OscillationCandidate
+	for subject, st := range bySubject {
+		if st.flips == 0 {
+			continue
+		}
+		out = append(out, OscillationCandidate{
+			Subject:   subject,
+			Winners:   st.history,
+			Flips:     st.flips,
+			FirstSeen: time.Unix(st.first, 0).UTC(),
+			LastSeen:  time.Unix(st.last, 0).UTC(),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+)
+
+// WithReadSnapshot runs fn against a read-only snapshot of db: a plain
+// BEGIN (DEFERRED by default under SQLite) establishes fn's consistent MVCC
+// view of the database, and PRAGMA query_only=ON on that same connection
+// refuses any write fn's query might accidentally issue. COMMIT then
+// releases the snapshot - a no-op for a transaction that only read, but it
+// still needs to run so the connection's PRAGMA and transaction state are
+// cleanly closed out.
+//
+// This gives every analyze* tool in this repo a single consistent view of
+// the decision log even while a writer process is still appending to it,
+// without blocking that writer or risking an inconsistent read if a WAL
+// checkpoint happens mid-scan.
+func WithReadSnapshot(db *sql.DB, fn func(*sql.Tx) error) error {
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("decisionlog: acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return fmt.Errorf("decisionlog: set query_only: %w", err)
+	}
+	// Clearing query_only is cleanup on a connection we're about to hand
+	// back to the pool. If it fails, don't let the connection go back into
+	// rotation still read-only - some later, unrelated write on db would
+	// fail in a way that's impossible to diagnose from that write's own
+	// error alone. conn.Raw + returning driver.ErrBadConn is the documented
+	// way to tell database/sql to discard a connection instead of pooling
+	// it.
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = OFF"); err != nil {
+			log.Printf("decisionlog: clearing query_only on pooled connection, discarding it: %v", err)
+			_ = conn.Raw(func(driverConn any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("decisionlog: begin read snapshot: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("decisionlog: commit read snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package decisionlog owns the persistent, queryable log of call-correction
+// decisions: which correction was applied or rejected and why, and what the
+// harmonic detector dropped before correction ever saw it. It replaces the
+// hand-written SQL that used to be duplicated across the investigate_* and
+// inspect_decisions tools with a single schema, insertion API, and typed
+// query API shared by recording and analysis code alike.
+package decisionlog
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Decision is one row of the decisions table: the outcome of evaluating a
+// call-correction candidate, or a note about why the harmonic detector
+// dropped a spot outright.
+type Decision struct {
+	ID               int64
+	Timestamp        time.Time
+	Subject          string
+	Winner           string
+	FreqKHz          float64
+	Distance         int
+	WinnerSupport    int
+	SubjectSupport   int
+	TotalReporters   int
+	WinnerConfidence int
+	MinReports       int
+	MinAdvantage     int
+	MinConfidence    int
+	Decision         string
+	Reason           string
+	// HarmonicOf lists the parent frequency (or frequencies, for an
+	// intermodulation product) the harmonic detector judged this spot to be
+	// derived from, in kHz. Empty for every decision that isn't a
+	// harmonic_dropped one.
+	HarmonicOf []float64
+}
+
+// Applied reports whether this decision actually applied the correction.
+func (d Decision) Applied() bool {
+	return strings.EqualFold(d.Decision, "applied")
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS decisions (
+	id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	ts                INTEGER NOT NULL,
+	subject           TEXT NOT NULL,
+	winner            TEXT NOT NULL,
+	freq_khz          REAL NOT NULL DEFAULT 0,
+	distance          INTEGER NOT NULL DEFAULT 0,
+	winner_support    INTEGER NOT NULL DEFAULT 0,
+	subject_support   INTEGER NOT NULL DEFAULT 0,
+	total_reporters   INTEGER NOT NULL DEFAULT 0,
+	winner_confidence INTEGER NOT NULL DEFAULT 0,
+	min_reports       INTEGER NOT NULL DEFAULT 0,
+	min_advantage     INTEGER NOT NULL DEFAULT 0,
+	min_confidence    INTEGER NOT NULL DEFAULT 0,
+	decision          TEXT NOT NULL,
+	reason            TEXT NOT NULL DEFAULT '',
+	harmonic_of       TEXT NOT NULL DEFAULT '[]'
+);
+CREATE INDEX IF NOT EXISTS idx_decisions_ts_subject_winner_freq
+	ON decisions(ts, subject, winner, freq_khz);
+`
+
+// Store owns a decisions database: schema migration on open, the
+// LogApplied/LogRejected/LogHarmonicDropped/LogCorrection insertion API,
+// and the FindReappearances/OscillationCandidates query API.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the decision log at path and migrates
+// its schema to the current version.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open decision log: %w", err)
+	}
+	store, err := OpenDB(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// OpenDB wraps an already-open *sql.DB (e.g. one shared with another
+// table, the way recorder's spot_records and decisionlog's decisions table
+// can live side by side in the same file), migrating the decisions schema
+// into it.
+func OpenDB(db *sql.DB) (*Store, error) {
+	if err := ensureDecisionsSchema(db); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// ensureDecisionsSchema creates the decisions table if it doesn't exist yet,
+// then adds any column a pre-existing table is missing. CREATE TABLE IF NOT
+// EXISTS alone is a no-op against a decisions.db created by an older version
+// of this package, so a column added since then (e.g. harmonic_of) has to be
+// backfilled explicitly or every insert() against that column fails with
+// "no such column".
+func ensureDecisionsSchema(db *sql.DB) error {
+	if _, err := db.Exec(schema); err != nil {
+		return fmt.Errorf("migrate decision log schema: %w", err)
+	}
+	cols, err := fetchColumns(db, "decisions")
+	if err != nil {
+		return fmt.Errorf("migrate decision log schema: %w", err)
+	}
+	if _, ok := cols["harmonic_of"]; !ok {
+		if _, err := db.Exec(`ALTER TABLE decisions ADD COLUMN harmonic_of TEXT NOT NULL DEFAULT '[]'`); err != nil {
+			return fmt.Errorf("migrate decision log schema: add harmonic_of: %w", err)
+		}
+	}
+	return nil
+}
+
+func fetchColumns(db *sql.DB, table string) (map[string]struct{}, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols := make(map[string]struct{})
+	for rows.Next() {
+		var cid int
+		var name, ctype string
+		var notnull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		cols[strings.ToLower(name)] = struct{}{}
+	}
+	return cols, rows.Err()
+}
+
+// Close closes the underlying database handle.
+func (s *Store) Close() error { return s.db.Close() }
+
+func (s *Store) insert(d Decision) error {
+	ts := d.Timestamp
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+	harmonicOf, err := json.Marshal(d.HarmonicOf)
+	if err != nil {
+		return fmt.Errorf("marshal harmonic_of: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO decisions (
+			ts, subject, winner, freq_khz, distance,
+			winner_support, subject_support, total_reporters, winner_confidence,
+			min_reports, min_advantage, min_confidence, decision, reason, harmonic_of
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		ts.UTC().Unix(), strings.ToUpper(strings.TrimSpace(d.Subject)), strings.ToUpper(strings.TrimSpace(d.Winner)), d.FreqKHz, d.Distance,
+		d.WinnerSupport, d.SubjectSupport, d.TotalReporters, d.WinnerConfidence,
+		d.MinReports, d.MinAdvantage, d.MinConfidence, d.Decision, d.Reason, string(harmonicOf),
+	)
+	return err
+}
+
+// LogApplied records a correction candidate that was actually applied.
+func (s *Store) LogApplied(d Decision) error {
+	d.Decision = "applied"
+	return s.insert(d)
+}
+
+// LogRejected records a correction candidate that was evaluated but not
+// applied, with reason explaining why (e.g. "below min_confidence").
+func (s *Store) LogRejected(d Decision, reason string) error {
+	d.Decision = "rejected"
+	d.Reason = reason
+	return s.insert(d)
+}
+
+// LogCorrection is a convenience wrapper for the common "evaluate, then
+// record the outcome" call site: applied if ok, rejected with reason
+// otherwise.
+func (s *Store) LogCorrection(d Decision, ok bool, reason string) error {
+	if ok {
+		return s.LogApplied(d)
+	}
+	return s.LogRejected(d, reason)
+}
+
+// LogHarmonicDropped records a spot the harmonic detector dropped outright,
+// before it ever reached call-correction. Distance/support/confidence
+// don't apply to a harmonic drop, so subject and winner are both the
+// dropped call; parents names the frequency (or, for an intermodulation
+// product, frequencies) it was judged to be derived from, in kHz.
+func (s *Store) LogHarmonicDropped(call string, freqKHz float64, parents []float64, at time.Time) error {
+	return s.insert(Decision{
+		Timestamp:  at,
+		Subject:    call,
+		Winner:     call,
+		FreqKHz:    freqKHz,
+		Decision:   "harmonic_dropped",
+		Reason:     fmt.Sprintf("harmonic_of=%s", formatHarmonicOf(parents)),
+		HarmonicOf: parents,
+	})
+}
+
+// formatHarmonicOf renders a parent frequency chain the way the request
+// that introduced it spelled it out, e.g. "[7011.0, 14022.0]".
+func formatHarmonicOf(parents []float64) string {
+	parts := make([]string, len(parents))
+	for i, p := range parents {
+		parts[i] = fmt.Sprintf("%.1f", p)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
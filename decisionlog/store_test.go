@@ -0,0 +1,183 @@
+package decisionlog
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "decisions.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestLogAppliedAndRejected(t *testing.T) {
+	store := openTestStore(t)
+	now := time.Now().UTC()
+
+	if err := store.LogApplied(Decision{Timestamp: now, Subject: "k1abc", Winner: "K1AB", FreqKHz: 14025.0, Distance: 1}); err != nil {
+		t.Fatalf("LogApplied failed: %v", err)
+	}
+	if err := store.LogRejected(Decision{Timestamp: now, Subject: "K1XYZ", Winner: "K1XY", Distance: 2}, "below min_confidence"); err != nil {
+		t.Fatalf("LogRejected failed: %v", err)
+	}
+
+	decisions, err := store.LoadDistanceRange(1, 2)
+	if err != nil {
+		t.Fatalf("LoadDistanceRange failed: %v", err)
+	}
+	if len(decisions) != 2 {
+		t.Fatalf("expected 2 decisions, got %d", len(decisions))
+	}
+	if decisions[0].Subject != "K1ABC" || !decisions[0].Applied() {
+		t.Fatalf("expected an applied, upper-cased K1ABC decision, got %+v", decisions[0])
+	}
+	if decisions[1].Reason != "below min_confidence" {
+		t.Fatalf("expected the rejection reason to round-trip, got %q", decisions[1].Reason)
+	}
+}
+
+func TestLogHarmonicDropped(t *testing.T) {
+	store := openTestStore(t)
+	now := time.Now().UTC()
+
+	if err := store.LogHarmonicDropped("K1ABC", 21033.0, []float64{7011.0, 14022.0}, now); err != nil {
+		t.Fatalf("LogHarmonicDropped failed: %v", err)
+	}
+
+	decisions, err := store.LoadDistanceRange(0, 0)
+	if err != nil {
+		t.Fatalf("LoadDistanceRange failed: %v", err)
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("expected 1 decision, got %d", len(decisions))
+	}
+	if decisions[0].Decision != "harmonic_dropped" {
+		t.Fatalf("expected decision=harmonic_dropped, got %q", decisions[0].Decision)
+	}
+	if want := []float64{7011.0, 14022.0}; len(decisions[0].HarmonicOf) != len(want) ||
+		decisions[0].HarmonicOf[0] != want[0] || decisions[0].HarmonicOf[1] != want[1] {
+		t.Fatalf("expected harmonic_of %v to round-trip, got %v", want, decisions[0].HarmonicOf)
+	}
+	if decisions[0].Reason != "harmonic_of=[7011.0, 14022.0]" {
+		t.Fatalf("unexpected reason: %q", decisions[0].Reason)
+	}
+}
+
+func TestFindReappearances(t *testing.T) {
+	store := openTestStore(t)
+	base := time.Now().UTC()
+
+	if err := store.LogApplied(Decision{Timestamp: base, Subject: "K1ABC", Winner: "K1AB", FreqKHz: 14025.0, Distance: 1}); err != nil {
+		t.Fatalf("LogApplied failed: %v", err)
+	}
+	if err := store.insert(Decision{Timestamp: base.Add(time.Hour), Subject: "K1ABC", FreqKHz: 14025.0, Decision: "seen"}); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if err := store.insert(Decision{Timestamp: base.Add(2 * time.Hour), Subject: "K1AB", FreqKHz: 14030.0, Decision: "seen"}); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+
+	cases, err := store.FindReappearances(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("FindReappearances failed: %v", err)
+	}
+	if len(cases) != 1 {
+		t.Fatalf("expected 1 reappearance case, got %d", len(cases))
+	}
+	if got, want := cases[0].FreqSeparationKHz, 5.0; got != want {
+		t.Fatalf("expected frequency separation %.1f, got %.1f", want, got)
+	}
+}
+
+// TestOpenMigratesPreHarmonicOfSchema recreates a decisions.db as it looked
+// before harmonic_of existed, then checks Open backfills the column onto it
+// in place instead of leaving a table CREATE TABLE IF NOT EXISTS can't touch.
+func TestOpenMigratesPreHarmonicOfSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.db")
+	seed, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open seed db: %v", err)
+	}
+	const oldSchema = `
+	CREATE TABLE decisions (
+		id                INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts                INTEGER NOT NULL,
+		subject           TEXT NOT NULL,
+		winner            TEXT NOT NULL,
+		freq_khz          REAL NOT NULL DEFAULT 0,
+		distance          INTEGER NOT NULL DEFAULT 0,
+		winner_support    INTEGER NOT NULL DEFAULT 0,
+		subject_support   INTEGER NOT NULL DEFAULT 0,
+		total_reporters   INTEGER NOT NULL DEFAULT 0,
+		winner_confidence INTEGER NOT NULL DEFAULT 0,
+		min_reports       INTEGER NOT NULL DEFAULT 0,
+		min_advantage     INTEGER NOT NULL DEFAULT 0,
+		min_confidence    INTEGER NOT NULL DEFAULT 0,
+		decision          TEXT NOT NULL,
+		reason            TEXT NOT NULL DEFAULT ''
+	);
+	`
+	if _, err := seed.Exec(oldSchema); err != nil {
+		t.Fatalf("seed old schema: %v", err)
+	}
+	if _, err := seed.Exec(`INSERT INTO decisions (ts, subject, winner, decision) VALUES (0, 'K1ABC', 'K1AB', 'applied')`); err != nil {
+		t.Fatalf("seed old row: %v", err)
+	}
+	if err := seed.Close(); err != nil {
+		t.Fatalf("close seed db: %v", err)
+	}
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed to migrate pre-harmonic_of db: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now().UTC()
+	if err := store.LogHarmonicDropped("K1XYZ", 21033.0, []float64{7011.0}, now); err != nil {
+		t.Fatalf("insert against migrated schema failed: %v", err)
+	}
+
+	decisions, err := store.LoadDistanceRange(0, 0)
+	if err != nil {
+		t.Fatalf("LoadDistanceRange failed: %v", err)
+	}
+	if len(decisions) != 2 {
+		t.Fatalf("expected the pre-existing seed row to survive migration alongside the new one, got %+v", decisions)
+	}
+	if decisions[0].Subject != "K1ABC" || decisions[1].Subject != "K1XYZ" {
+		t.Fatalf("unexpected decisions after migration: %+v", decisions)
+	}
+}
+
+func TestOscillationCandidates(t *testing.T) {
+	store := openTestStore(t)
+	base := time.Now().UTC()
+
+	winners := []string{"K1AB", "K1XYZ", "K1AB", "K1XYZ"}
+	for i, w := range winners {
+		d := Decision{Timestamp: base.Add(time.Duration(i) * time.Minute), Subject: "K1ABC", Winner: w}
+		if err := store.LogApplied(d); err != nil {
+			t.Fatalf("LogApplied failed: %v", err)
+		}
+	}
+
+	candidates, err := store.OscillationCandidates(time.Hour)
+	if err != nil {
+		t.Fatalf("OscillationCandidates failed: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("expected 1 oscillating pair, got %d: %+v", len(candidates), candidates)
+	}
+	if candidates[0].Flips != 2 {
+		t.Fatalf("expected 2 flips, got %d", candidates[0].Flips)
+	}
+}
@@ -0,0 +1,74 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"dxcluster/spot"
+)
+
+func makeSamplingSpot(dxCall string, t time.Time) *spot.Spot {
+	s := spot.NewSpot(dxCall, "W1XYZ", 14074.0, "FT8")
+	s.SourceType = spot.SourceRBN
+	s.Time = t
+	return s
+}
+
+func TestSecondaryDeduperSamplingIsDeterministicAcrossInstances(t *testing.T) {
+	cfg := SamplingConfig{Seed: "2026-07-rollout", Salt: "dxcluster", SamplePercent: 0.5}
+	now := time.Unix(1_700_000_000, 0).UTC()
+
+	a := NewSecondaryDeduper(time.Minute, true)
+	a.SetSamplingConfig(cfg)
+	b := NewSecondaryDeduper(time.Minute, true)
+	b.SetSamplingConfig(cfg)
+
+	for i := 0; i < 20; i++ {
+		s := makeSamplingSpot("K1ABC", now.Add(time.Duration(i)*20*time.Second))
+		if a.BucketContext(s, cfg.Salt) != b.BucketContext(s, cfg.Salt) {
+			t.Fatalf("expected identical bucket across independent instances for spot %d", i)
+		}
+	}
+}
+
+func TestSecondaryDeduperSamplingBypassesHumanSources(t *testing.T) {
+	d := NewSecondaryDeduper(time.Minute, true)
+	d.SetSamplingConfig(SamplingConfig{Seed: "s", Salt: "x", SamplePercent: 0})
+	now := time.Unix(1_700_000_000, 0).UTC()
+
+	human := spot.NewSpot("K1ABC", "W1XYZ", 14074.0, "FT8")
+	human.SourceType = spot.SourceManual
+	human.Time = now
+
+	if !d.ShouldForward(human) {
+		t.Fatal("expected human spot to bypass sampling even at sample_percent=0")
+	}
+}
+
+func TestSecondaryDeduperSamplingDropsSkimmersAtZeroPercent(t *testing.T) {
+	d := NewSecondaryDeduper(time.Minute, true)
+	d.SetSamplingConfig(SamplingConfig{Seed: "s", Salt: "x", SamplePercent: 0})
+	now := time.Unix(1_700_000_000, 0).UTC()
+
+	skimmer := makeSamplingSpot("K1ABC", now)
+	if d.ShouldForward(skimmer) {
+		t.Fatal("expected skimmer spot to be dropped at sample_percent=0")
+	}
+	counters := d.Counters(spot.SourceRBN)
+	if counters.DroppedBySample != 1 {
+		t.Fatalf("expected 1 sample drop recorded, got %d", counters.DroppedBySample)
+	}
+}
+
+func TestSecondaryDeduperSamplingForwardsAllAtFullPercent(t *testing.T) {
+	d := NewSecondaryDeduper(time.Minute, true)
+	d.SetSamplingConfig(SamplingConfig{Seed: "s", Salt: "x", SamplePercent: 1})
+	now := time.Unix(1_700_000_000, 0).UTC()
+
+	for i := 0; i < 10; i++ {
+		s := makeSamplingSpot("K1ABC", now.Add(time.Duration(i)*time.Minute))
+		if !d.ShouldForward(s) {
+			t.Fatalf("expected spot %d to be forwarded at sample_percent=1", i)
+		}
+	}
+}
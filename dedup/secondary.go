@@ -0,0 +1,241 @@
+package dedup
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dxcluster/events"
+	"dxcluster/spot"
+)
+
+// SamplingConfig controls deterministic downsampling of skimmer-class
+// spots. SamplePercent is a fraction in [0, 1]; a spot is forwarded iff its
+// bucket (see BucketContext) falls below it. Seed and Salt are both mixed
+// into the hash so operators can rotate the rollout (new Seed) without
+// touching the per-call-site Salt, or vice versa.
+type SamplingConfig struct {
+	Seed          string
+	Salt          string
+	SamplePercent float64
+}
+
+// sourceClass splits spots into the two dedupe/sampling populations: human
+// spotters, who are never sampled but are still deduped within the window,
+// and automated skimmer feeds, which may be downsampled and are deduped
+// independently so a skimmer flood can't suppress a human spot for the same
+// DX call or vice versa.
+type sourceClass int
+
+const (
+	classHuman sourceClass = iota
+	classSkimmer
+)
+
+func classify(source spot.SourceType) sourceClass {
+	if spot.IsSkimmerSource(source) {
+		return classSkimmer
+	}
+	return classHuman
+}
+
+// classCounters are the seen/forwarded/dropped observability counters for
+// one source class.
+type classCounters struct {
+	seen            atomic.Uint64
+	forwarded       atomic.Uint64
+	droppedBySample atomic.Uint64
+	droppedByDedupe atomic.Uint64
+}
+
+// ClassCounters is a point-in-time snapshot of classCounters.
+type ClassCounters struct {
+	Seen            uint64
+	Forwarded       uint64
+	DroppedBySample uint64
+	DroppedByDedupe uint64
+}
+
+// SecondaryDeduper applies a second, source-class-aware round of dedupe
+// downstream of the primary per-peer dedupe window, and optionally
+// downsamples skimmer-class spots using deterministic rollout bucketing so
+// operators can throttle RBN-style floods without touching human spots.
+type SecondaryDeduper struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+
+	samplingEnabled bool
+	samplingMu      sync.RWMutex
+	sampling        SamplingConfig
+
+	logger events.Logger
+
+	counters map[sourceClass]*classCounters
+}
+
+// NewSecondaryDeduper builds a SecondaryDeduper with the given suppression
+// window. samplingEnabled turns on skimmer downsampling; the actual
+// seed/salt/percent are supplied separately via SetSamplingConfig so they
+// can be rotated at runtime without rebuilding the deduper.
+func NewSecondaryDeduper(window time.Duration, samplingEnabled bool) *SecondaryDeduper {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &SecondaryDeduper{
+		window:          window,
+		seen:            make(map[string]time.Time),
+		samplingEnabled: samplingEnabled,
+		counters: map[sourceClass]*classCounters{
+			classHuman:   {},
+			classSkimmer: {},
+		},
+	}
+}
+
+// SetLogger wires a structured event sink so sampling decisions are
+// reproducible from the logs.
+func (d *SecondaryDeduper) SetLogger(l events.Logger) {
+	if d != nil {
+		d.logger = l
+	}
+}
+
+// SetSamplingConfig rotates the seed/salt/sample_percent used for skimmer
+// downsampling. Safe to call concurrently with ShouldForward.
+func (d *SecondaryDeduper) SetSamplingConfig(cfg SamplingConfig) {
+	d.samplingMu.Lock()
+	d.sampling = cfg
+	d.samplingMu.Unlock()
+}
+
+func (d *SecondaryDeduper) samplingConfig() SamplingConfig {
+	d.samplingMu.RLock()
+	defer d.samplingMu.RUnlock()
+	return d.sampling
+}
+
+// ShouldForward reports whether s should pass this dedupe stage. Human
+// spots always bypass sampling. Skimmer spots are first subject to
+// deterministic downsampling (if enabled), then to per-class duplicate
+// suppression within the configured window.
+func (d *SecondaryDeduper) ShouldForward(s *spot.Spot) bool {
+	class := classify(s.SourceType)
+	counters := d.counters[class]
+	counters.seen.Add(1)
+
+	if class == classSkimmer && d.samplingEnabled {
+		cfg := d.samplingConfig()
+		if cfg.SamplePercent < 1.0 {
+			bucket := d.BucketContext(s, cfg.Salt)
+			if bucket >= cfg.SamplePercent {
+				counters.droppedBySample.Add(1)
+				events.Debug(d.logger, "dedup.secondary_sample_drop",
+					events.String("call", s.DXCall),
+					events.Float("bucket", bucket),
+					events.Float("sample_percent", cfg.SamplePercent),
+				)
+				return false
+			}
+		}
+	}
+
+	key := d.dedupeKey(class, s)
+	eventTime := s.Time
+	if eventTime.IsZero() {
+		eventTime = time.Now().UTC()
+	}
+
+	d.mu.Lock()
+	last, ok := d.seen[key]
+	forward := !ok || eventTime.Sub(last) >= d.window
+	if forward {
+		d.seen[key] = eventTime
+	}
+	d.mu.Unlock()
+
+	if !forward {
+		counters.droppedByDedupe.Add(1)
+		return false
+	}
+	counters.forwarded.Add(1)
+	return true
+}
+
+// BucketContext computes the deterministic rollout bucket for s in [0, 1],
+// using the deduper's current seed and the given salt. It is exposed
+// standalone so operators and tests can inspect or reproduce a sampling
+// decision without going through ShouldForward.
+func (d *SecondaryDeduper) BucketContext(s *spot.Spot, salt string) float64 {
+	cfg := d.samplingConfig()
+	return bucketFor(cfg.Seed, salt, spotSampleKey(s))
+}
+
+// Counters returns a snapshot of the seen/forwarded/dropped counters for
+// the source class that the given source type belongs to.
+func (d *SecondaryDeduper) Counters(source spot.SourceType) ClassCounters {
+	c := d.counters[classify(source)]
+	return ClassCounters{
+		Seen:            c.seen.Load(),
+		Forwarded:       c.forwarded.Load(),
+		DroppedBySample: c.droppedBySample.Load(),
+		DroppedByDedupe: c.droppedByDedupe.Load(),
+	}
+}
+
+func (d *SecondaryDeduper) dedupeKey(class sourceClass, s *spot.Spot) string {
+	return fmt.Sprintf("%d|%s|%s", class, strings.ToUpper(s.DXCall), bandFor(s.Frequency))
+}
+
+// spotSampleKey derives the sampling key from (DX call, band, 10s time
+// bucket) so the same physical spot lands in the same bucket regardless of
+// which peer or node first observed it.
+func spotSampleKey(s *spot.Spot) string {
+	timeBucket := s.Time.Unix() / 10
+	return fmt.Sprintf("%s|%s|%d", strings.ToUpper(s.DXCall), bandFor(s.Frequency), timeBucket)
+}
+
+// bandFor maps a frequency in kHz to its amateur band, coarse enough that
+// skimmer reports for the same QSO a few kHz apart still bucket together.
+func bandFor(freqKHz float64) string {
+	switch {
+	case freqKHz >= 1800 && freqKHz <= 2000:
+		return "160m"
+	case freqKHz >= 3500 && freqKHz <= 4000:
+		return "80m"
+	case freqKHz >= 7000 && freqKHz <= 7300:
+		return "40m"
+	case freqKHz >= 10100 && freqKHz <= 10150:
+		return "30m"
+	case freqKHz >= 14000 && freqKHz <= 14350:
+		return "20m"
+	case freqKHz >= 18068 && freqKHz <= 18168:
+		return "17m"
+	case freqKHz >= 21000 && freqKHz <= 21450:
+		return "15m"
+	case freqKHz >= 24890 && freqKHz <= 24990:
+		return "12m"
+	case freqKHz >= 28000 && freqKHz <= 29700:
+		return "10m"
+	default:
+		return "other"
+	}
+}
+
+// bucketFor hashes seed|salt|key with FNV-1a (the same hash family used for
+// peer.dedupeCache's shard selection) into a float in [0, 1]; the very
+// top hash values round to exactly 1.0.
+func bucketFor(seed, salt, key string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	h.Write([]byte("|"))
+	h.Write([]byte(salt))
+	h.Write([]byte("|"))
+	h.Write([]byte(key))
+	return float64(h.Sum64()) / float64(math.MaxUint64)
+}
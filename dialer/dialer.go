@@ -0,0 +1,133 @@
+// Package dialer resolves a cluster transport endpoint into a net.Conn,
+// shared by rbn.Client and dxclusterclient.Client so both upstream telnet
+// clients dial the same way: a plain "host:port" pair (today's behavior,
+// letting the OS pick the address family), or a URL-style
+// "scheme://host:port" address that pins the family or wraps the connection
+// in TLS.
+package dialer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dial resolves endpoint and returns a connected net.Conn. Supported
+// schemes:
+//
+//	host:port      - dual-stack TCP (the historical behavior)
+//	tcp4://host:port - restrict to IPv4
+//	tcp6://host:port - restrict to IPv6
+//	tls://host:port  - dual-stack TCP wrapped in crypto/tls
+//
+// tlsConfig configures tls:// endpoints and is ignored otherwise; nil means
+// crypto/tls's defaults. Either way, ServerName defaults to endpoint's host
+// when unset, so SNI and certificate verification target the dialed name
+// without every caller having to set it explicitly.
+func Dial(endpoint string, timeout time.Duration, tlsConfig *tls.Config) (net.Conn, error) {
+	network, addr, useTLS, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout(network, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !useTLS {
+		return conn, nil
+	}
+
+	cfg := tlsConfig.Clone()
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = serverNameFor(addr)
+	}
+
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("dialer: set TLS handshake deadline: %w", err)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("dialer: TLS handshake with %s: %w", addr, err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// parseEndpoint splits endpoint into the net.Dial network to use, the
+// host:port to dial, and whether the result should be wrapped in TLS. An
+// endpoint with no "scheme://" prefix is treated as plain dual-stack TCP,
+// matching net.DialTimeout("tcp", endpoint, ...)'s existing behavior. An
+// endpoint with an unrecognized scheme is an error rather than a silent
+// plain-TCP fallback, so a typo'd scheme (e.g. a config asking for TLS)
+// can't end up dialing out in the clear without anyone noticing.
+func parseEndpoint(endpoint string) (network, addr string, useTLS bool, err error) {
+	scheme, rest, ok := strings.Cut(endpoint, "://")
+	if !ok {
+		return "tcp", endpoint, false, nil
+	}
+	switch strings.ToLower(scheme) {
+	case "tcp4":
+		return "tcp4", rest, false, nil
+	case "tcp6":
+		return "tcp6", rest, false, nil
+	case "tls":
+		return "tcp", rest, true, nil
+	default:
+		return "", "", false, fmt.Errorf("dialer: unrecognized scheme %q in endpoint %q", scheme, endpoint)
+	}
+}
+
+// WithDefaultPort joins a bare host (no scheme) with port, as the classic
+// host:port pair, without checking whether host already names a port. A
+// scheme-qualified host is returned unchanged if the part after "://"
+// already names a port; otherwise port is appended. This lets rbn.Client and dxclusterclient.Client accept a bare host
+// configured once via NewClient(host, port, ...) while still letting a full
+// "scheme://host:port" string override the port on its own.
+func WithDefaultPort(host string, port int) string {
+	scheme, rest, hasScheme := strings.Cut(host, "://")
+	if !hasScheme {
+		return net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	if _, _, err := net.SplitHostPort(rest); err == nil {
+		return scheme + "://" + rest
+	}
+	// rest has no port; it may still be a bracketed IPv6 literal like
+	// "[::1]", so strip any brackets before JoinHostPort adds its own -
+	// otherwise they'd double up into "[[::1]]:port".
+	bare := strings.TrimSuffix(strings.TrimPrefix(rest, "["), "]")
+	return scheme + "://" + net.JoinHostPort(bare, strconv.Itoa(port))
+}
+
+func serverNameFor(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// TLSConfigWithCABundle builds a *tls.Config that trusts only the CA
+// certificates in the PEM file at caPath, for pinning a private cluster's
+// internal CA instead of the system trust store.
+func TLSConfigWithCABundle(caPath string) (*tls.Config, error) {
+	pemBytes, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("dialer: read CA bundle %s: %w", caPath, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("dialer: no certificates found in %s", caPath)
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
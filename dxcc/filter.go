@@ -0,0 +1,81 @@
+package dxcc
+
+import "strings"
+
+// Filter restricts which callsigns pass based on DXCC entity or continent,
+// e.g. "only NA DX" or "only EU peers." An empty Filter (no trie, or no
+// allow/block entries) allows everything, and a callsign the trie can't
+// resolve always passes - an operator restricting by entity shouldn't
+// silently lose every unresolved spot. Block is checked before Allow and
+// always wins.
+type Filter struct {
+	trie *Trie
+
+	allowEntities   map[string]struct{}
+	allowContinents map[string]struct{}
+	blockEntities   map[string]struct{}
+	blockContinents map[string]struct{}
+}
+
+// NewFilter builds a Filter backed by trie (for entity/continent lookups)
+// with the given allow/block lists. Entries are matched case-insensitively;
+// a two-letter entry is treated as a continent code (NA, SA, EU, AS, AF,
+// OC), anything longer as a DXCC entity name.
+func NewFilter(trie *Trie, allow, block []string) *Filter {
+	f := &Filter{
+		trie:            trie,
+		allowEntities:   make(map[string]struct{}),
+		allowContinents: make(map[string]struct{}),
+		blockEntities:   make(map[string]struct{}),
+		blockContinents: make(map[string]struct{}),
+	}
+	for _, v := range allow {
+		addToSet(v, f.allowEntities, f.allowContinents)
+	}
+	for _, v := range block {
+		addToSet(v, f.blockEntities, f.blockContinents)
+	}
+	return f
+}
+
+func addToSet(v string, entities, continents map[string]struct{}) {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if v == "" {
+		return
+	}
+	if len(v) <= 2 {
+		continents[v] = struct{}{}
+		return
+	}
+	entities[v] = struct{}{}
+}
+
+// Allows reports whether call passes the filter.
+func (f *Filter) Allows(call string) bool {
+	if f == nil || f.trie == nil {
+		return true
+	}
+	entry, ok := f.trie.Lookup(call)
+	if !ok {
+		return true
+	}
+	entity := strings.ToUpper(entry.Entity)
+	continent := strings.ToUpper(entry.Continent)
+
+	if _, blocked := f.blockEntities[entity]; blocked {
+		return false
+	}
+	if _, blocked := f.blockContinents[continent]; blocked {
+		return false
+	}
+	if len(f.allowEntities) == 0 && len(f.allowContinents) == 0 {
+		return true
+	}
+	if _, ok := f.allowEntities[entity]; ok {
+		return true
+	}
+	if _, ok := f.allowContinents[continent]; ok {
+		return true
+	}
+	return false
+}
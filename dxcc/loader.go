@@ -0,0 +1,66 @@
+package dxcc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// prefixRecord mirrors one row of the YAML prefix table.
+type prefixRecord struct {
+	Prefix    string `yaml:"prefix"`
+	Entity    string `yaml:"entity"`
+	CQZone    int    `yaml:"cq_zone"`
+	ITUZone   int    `yaml:"itu_zone"`
+	Continent string `yaml:"continent"`
+	Exact     bool   `yaml:"exact"`
+}
+
+type prefixTable struct {
+	Prefixes []prefixRecord `yaml:"prefixes"`
+}
+
+const defaultPrefixPath = "data/config/dxcc_prefixes.yaml"
+
+// LoadFile builds a Trie from a YAML prefix table at path. Each row is
+// either a prefix, matched by longest-prefix-match, or, when Exact is true,
+// a full callsign override consulted before the trie.
+func LoadFile(path string) (*Trie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("dxcc: read %s: %w", path, err)
+	}
+	var table prefixTable
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("dxcc: parse %s: %w", path, err)
+	}
+	trie := NewTrie()
+	for _, rec := range table.Prefixes {
+		entry := Entry{Entity: rec.Entity, CQZone: rec.CQZone, ITUZone: rec.ITUZone, Continent: rec.Continent}
+		if rec.Exact {
+			trie.InsertExact(rec.Prefix, entry)
+		} else {
+			trie.Insert(rec.Prefix, entry)
+		}
+	}
+	return trie, nil
+}
+
+// Load builds a Trie from the repo-standard data/config/dxcc_prefixes.yaml
+// location, also checking the parent directory so it resolves the same
+// whether run from the repo root or a cmd/ subdirectory - mirroring
+// spot.loadModeAllocations's search order for its own YAML table.
+func Load() (*Trie, error) {
+	paths := []string{defaultPrefixPath, filepath.Join("..", defaultPrefixPath)}
+	var lastErr error
+	for _, path := range paths {
+		trie, err := LoadFile(path)
+		if err == nil {
+			return trie, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
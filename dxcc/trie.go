@@ -0,0 +1,125 @@
+// Package dxcc resolves DXCC entity metadata (entity name, CQ zone, ITU
+// zone, continent) for a callsign via a longest-prefix-match trie keyed
+// character-by-character on the call, the same structure WireGuard's
+// allowedips uses for IP prefixes, adapted here to callsign strings instead
+// of address bits.
+//
+// The trie is intended to back new fields on spot.Spot populated during
+// EnsureNormalized, the way spot's mode/confusion tables already feed other
+// Spot fields. spot.Spot has no struct definition anywhere in this tree yet,
+// so that wiring isn't done here; Lookup is ready to be called from
+// EnsureNormalized once spot.Spot exists.
+package dxcc
+
+import "strings"
+
+// Entry describes the DXCC entity metadata attached to a matched prefix or
+// exact-callsign override.
+type Entry struct {
+	Entity    string
+	CQZone    int
+	ITUZone   int
+	Continent string
+}
+
+// portableSuffixes are stripped before lookup so a portable callsign like
+// W1AW/P, W1AW/MM, or W1AW/AM still resolves against its base prefix.
+var portableSuffixes = []string{"/MM", "/AM", "/P"}
+
+type trieNode struct {
+	children map[byte]*trieNode
+	entry    *Entry
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[byte]*trieNode)}
+}
+
+// Trie is a longest-prefix-match lookup table for DXCC entity metadata,
+// plus an exact-match overlay that takes precedence over any prefix match
+// (e.g. for individually reassigned calls the bulk prefix table gets
+// wrong).
+type Trie struct {
+	root  *trieNode
+	exact map[string]Entry
+}
+
+// NewTrie returns an empty Trie ready for Insert/InsertExact calls.
+func NewTrie() *Trie {
+	return &Trie{root: newTrieNode(), exact: make(map[string]Entry)}
+}
+
+// Insert adds prefix -> entry to the trie. Inserting the same prefix twice
+// overwrites the earlier entry.
+func (t *Trie) Insert(prefix string, entry Entry) {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return
+	}
+	node := t.root
+	for i := 0; i < len(prefix); i++ {
+		b := prefix[i]
+		child, ok := node.children[b]
+		if !ok {
+			child = newTrieNode()
+			node.children[b] = child
+		}
+		node = child
+	}
+	e := entry
+	node.entry = &e
+}
+
+// InsertExact registers an exact-callsign override that Lookup returns
+// before ever consulting the prefix trie.
+func (t *Trie) InsertExact(call string, entry Entry) {
+	call = strings.ToUpper(strings.TrimSpace(call))
+	if call == "" {
+		return
+	}
+	t.exact[call] = entry
+}
+
+// Lookup returns the DXCC entity metadata for call: an exact-match override
+// if one is registered, otherwise the longest matching prefix in the trie.
+// Portable suffixes (/P, /MM, /AM) are stripped before lookup. It runs in
+// O(len(call)).
+func (t *Trie) Lookup(call string) (Entry, bool) {
+	if t == nil {
+		return Entry{}, false
+	}
+	call = normalizeCallsign(call)
+	if call == "" {
+		return Entry{}, false
+	}
+	if e, ok := t.exact[call]; ok {
+		return e, true
+	}
+
+	node := t.root
+	var best *Entry
+	for i := 0; i < len(call); i++ {
+		child, ok := node.children[call[i]]
+		if !ok {
+			break
+		}
+		node = child
+		if node.entry != nil {
+			best = node.entry
+		}
+	}
+	if best == nil {
+		return Entry{}, false
+	}
+	return *best, true
+}
+
+func normalizeCallsign(call string) string {
+	call = strings.ToUpper(strings.TrimSpace(call))
+	for _, suf := range portableSuffixes {
+		if strings.HasSuffix(call, suf) {
+			return strings.TrimSuffix(call, suf)
+		}
+	}
+	return call
+}
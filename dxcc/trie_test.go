@@ -0,0 +1,93 @@
+package dxcc
+
+import "testing"
+
+func TestTrieLongestPrefixMatch(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert("W", Entry{Entity: "United States", Continent: "NA"})
+	trie.Insert("W1", Entry{Entity: "United States", CQZone: 5, Continent: "NA"})
+
+	entry, ok := trie.Lookup("W1AW")
+	if !ok {
+		t.Fatal("expected a match for W1AW")
+	}
+	if entry.CQZone != 5 {
+		t.Fatalf("expected the longer W1 prefix to win, got %+v", entry)
+	}
+}
+
+func TestTrieStripsPortableSuffixes(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert("VK", Entry{Entity: "Australia", Continent: "OC"})
+
+	for _, call := range []string{"VK2ABC/P", "VK2ABC/MM", "VK2ABC/AM"} {
+		if _, ok := trie.Lookup(call); !ok {
+			t.Fatalf("expected %s to resolve against its base prefix", call)
+		}
+	}
+}
+
+func TestTrieExactOverrideTakesPrecedence(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert("KH6", Entry{Entity: "Hawaii", Continent: "OC"})
+	trie.InsertExact("KH6XYZ", Entry{Entity: "Special Exception", Continent: "NA"})
+
+	entry, ok := trie.Lookup("KH6XYZ")
+	if !ok || entry.Entity != "Special Exception" {
+		t.Fatalf("expected the exact override to win, got %+v ok=%v", entry, ok)
+	}
+
+	entry, ok = trie.Lookup("KH6ABC")
+	if !ok || entry.Entity != "Hawaii" {
+		t.Fatalf("expected the prefix match for a non-overridden call, got %+v ok=%v", entry, ok)
+	}
+}
+
+func TestTrieLookupUnknownPrefix(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert("W", Entry{Entity: "United States"})
+
+	if _, ok := trie.Lookup("JA1ABC"); ok {
+		t.Fatal("expected no match for an unrelated prefix")
+	}
+}
+
+func TestFilterAllowAndBlockLists(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert("W", Entry{Entity: "United States", Continent: "NA"})
+	trie.Insert("G", Entry{Entity: "England", Continent: "EU"})
+	trie.Insert("JA", Entry{Entity: "Japan", Continent: "AS"})
+
+	allowNA := NewFilter(trie, []string{"NA"}, nil)
+	if !allowNA.Allows("W1AW") {
+		t.Fatal("expected W1AW to pass an NA-only allowlist")
+	}
+	if allowNA.Allows("G4ABC") {
+		t.Fatal("expected G4ABC to fail an NA-only allowlist")
+	}
+
+	blockJapan := NewFilter(trie, nil, []string{"Japan"})
+	if blockJapan.Allows("JA1ABC") {
+		t.Fatal("expected JA1ABC to be blocked by entity name")
+	}
+	if !blockJapan.Allows("W1AW") {
+		t.Fatal("expected an unrelated call to still pass a blocklist")
+	}
+}
+
+func TestFilterUnresolvedCallAlwaysPasses(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert("W", Entry{Entity: "United States", Continent: "NA"})
+	f := NewFilter(trie, []string{"NA"}, nil)
+
+	if !f.Allows("ZZ9ZZZ") {
+		t.Fatal("expected a call the trie can't resolve to pass an allowlist rather than being silently dropped")
+	}
+}
+
+func TestFilterNilIsSafe(t *testing.T) {
+	var f *Filter
+	if !f.Allows("W1AW") {
+		t.Fatal("expected a nil Filter to allow everything")
+	}
+}
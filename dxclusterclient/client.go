@@ -0,0 +1,471 @@
+// Package dxclusterclient connects to a generic upstream DX cluster node
+// (VE7CC, DXSummit, AR-Cluster, DXSpider, ...) over telnet and parses its
+// `DX de` announcements into canonical *spot.Spot values, modeled on
+// rbn.Client but tolerant of the looser, more varied line formats these
+// cluster nodes emit.
+package dxclusterclient
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dxcluster/cty"
+	"dxcluster/dialer"
+	"dxcluster/enrich"
+	"dxcluster/events"
+	"dxcluster/filter"
+	"dxcluster/spot"
+)
+
+// dxSpotPattern matches the common `DX de <spotter>: <freq> <dxcall> <comment>  <time>Z`
+// announcement format (see FlexDXCluster and most AR-Cluster/DXSpider nodes).
+// The mode, when present, is expected as the first whitespace-delimited
+// token of the comment; callers fall back to sub-band inference otherwise.
+var dxSpotPattern = regexp.MustCompile(`(?i)^DX de\s+([A-Z0-9/\-#]+)(?:-#)?:\s+(\d+(?:\.\d+)?)\s+([A-Z0-9/]+)\s*(.*?)\s*(\d{4})Z\s*$`)
+
+var knownModes = map[string]bool{
+	"CW": true, "SSB": true, "USB": true, "LSB": true, "FT8": true, "FT4": true,
+	"RTTY": true, "PSK31": true, "PSK": true, "JT65": true, "JT9": true, "FM": true, "AM": true,
+}
+
+// Client is a persistent telnet connection to one upstream DX cluster node.
+type Client struct {
+	host     string
+	port     int
+	callsign string
+	password string
+	name     string
+
+	// initCommands are sent once login completes, e.g. "SH/DX 0",
+	// "SET/NOHERE". They are sent in order, one per line.
+	initCommands []string
+
+	lookup      *cty.CTYDatabase
+	logger      events.Logger
+	enricher    *enrich.Manager
+	alertEngine *filter.AlertEngine
+
+	conn      net.Conn
+	reader    *bufio.Reader
+	writer    *bufio.Writer
+	connected bool
+
+	spotChan  chan *spot.Spot
+	shutdown  chan struct{}
+	reconnect chan struct{}
+	stopOnce  sync.Once
+
+	tlsConfig *tls.Config
+}
+
+// NewClient builds a Client for one configured upstream node. bufferSize
+// sizes the outbound spot channel; callers should size it generously since
+// a slow consumer should not block the read loop.
+//
+// host is usually a bare hostname or IP dialed as host:port over dual-stack
+// TCP, but it may instead carry a dialer scheme - "tcp4://host", "tcp6://host",
+// or "tls://host" - to pin an address family or require TLS (configure the
+// TLS side with SetTLSConfig); port is ignored when host's scheme already
+// includes one.
+func NewClient(host string, port int, callsign, password, name string, initCommands []string, lookup *cty.CTYDatabase, bufferSize int) *Client {
+	if bufferSize <= 0 {
+		bufferSize = 100
+	}
+	return &Client{
+		host:         host,
+		port:         port,
+		callsign:     callsign,
+		password:     password,
+		name:         name,
+		initCommands: initCommands,
+		lookup:       lookup,
+		spotChan:     make(chan *spot.Spot, bufferSize),
+		shutdown:     make(chan struct{}),
+		reconnect:    make(chan struct{}, 1),
+	}
+}
+
+// SetLogger wires a structured event sink for connect/disconnect/parse
+// events; nil is safe and simply discards them.
+func (c *Client) SetLogger(l events.Logger) {
+	if c != nil {
+		c.logger = l
+	}
+}
+
+// SetEnricher installs an optional enrich.Manager used to fill in grid
+// square, lat/lon, state/county, and operator name beyond what
+// metadataFromPrefix provides. A nil enricher (the default) leaves
+// metadata exactly as metadataFromPrefix built it.
+func (c *Client) SetEnricher(m *enrich.Manager) {
+	c.enricher = m
+}
+
+// SetAlertEngine installs an optional filter.AlertEngine run against every
+// parsed spot before it reaches GetSpotChannel's consumer. A nil engine
+// (the default) ships every spot unchanged.
+func (c *Client) SetAlertEngine(e *filter.AlertEngine) {
+	c.alertEngine = e
+}
+
+// GetSpotChannel returns the channel of parsed spots for this node.
+func (c *Client) GetSpotChannel() <-chan *spot.Spot {
+	return c.spotChan
+}
+
+// IsConnected reports whether the current TCP connection is up.
+func (c *Client) IsConnected() bool {
+	return c.connected
+}
+
+// SetTLSConfig supplies the *tls.Config used when host is a tls://
+// endpoint (see NewClient); it's ignored otherwise. cfg may leave ServerName
+// empty to let dialer.Dial default it to the dialed host, or set RootCAs
+// (dialer.TLSConfigWithCABundle builds one from a PEM file) to pin a private
+// cluster's CA instead of trusting the system roots. Call this before
+// Connect.
+func (c *Client) SetTLSConfig(cfg *tls.Config) {
+	c.tlsConfig = cfg
+}
+
+// dialAddr returns the address passed to dialer.Dial: the classic host:port
+// pair dialed as dual-stack TCP, or - if host already carries a tcp4://,
+// tcp6://, or tls:// scheme - that scheme with c.port appended when the host
+// didn't already specify one.
+func (c *Client) dialAddr() string {
+	return dialer.WithDefaultPort(c.host, c.port)
+}
+
+// Connect dials the node and starts the login/read/reconnect goroutines.
+func (c *Client) Connect() error {
+	if err := c.establishConnection(); err != nil {
+		return err
+	}
+	go c.connectionSupervisor()
+	return nil
+}
+
+// Stop closes the connection and stops all reconnect attempts.
+func (c *Client) Stop() {
+	log.Printf("Stopping %s client...", c.displayName())
+	c.stopOnce.Do(func() {
+		close(c.shutdown)
+	})
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// tcpConnOf unwraps conn down to the underlying *net.TCPConn, looking
+// through a *tls.Conn for tls:// endpoints, so keepalive can be configured
+// regardless of which transport dialer.Dial returned.
+func tcpConnOf(conn net.Conn) (*net.TCPConn, bool) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tc, ok := conn.(*net.TCPConn)
+	return tc, ok
+}
+
+func (c *Client) establishConnection() error {
+	addr := c.dialAddr()
+	log.Printf("%s: connecting to %s...", c.displayName(), addr)
+
+	conn, err := dialer.Dial(addr, 30*time.Second, c.tlsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.displayName(), err)
+	}
+	if tc, ok := tcpConnOf(conn); ok {
+		_ = tc.SetKeepAlive(true)
+		_ = tc.SetKeepAlivePeriod(30 * time.Second)
+	}
+
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	c.writer = bufio.NewWriter(conn)
+	c.connected = true
+
+	events.Info(c.logger, "dxcluster.connect", events.String("node", c.displayName()), events.String("addr", addr))
+
+	go c.handleLogin()
+	go c.readLoop()
+	return nil
+}
+
+// connectionSupervisor waits for disconnect notifications and reconnects
+// with capped exponential backoff, honoring shutdown at every step.
+func (c *Client) connectionSupervisor() {
+	const (
+		initialDelay = 5 * time.Second
+		maxDelay     = 60 * time.Second
+	)
+
+	for {
+		select {
+		case <-c.shutdown:
+			return
+		case <-c.reconnect:
+			if c.isShutdown() {
+				return
+			}
+			delay := initialDelay
+			for {
+				if c.isShutdown() {
+					return
+				}
+				log.Printf("%s: attempting reconnect...", c.displayName())
+				if err := c.establishConnection(); err != nil {
+					log.Printf("%s: reconnect failed: %v (retry in %s)", c.displayName(), err, delay)
+					timer := time.NewTimer(delay)
+					select {
+					case <-timer.C:
+					case <-c.shutdown:
+						timer.Stop()
+						return
+					}
+					delay *= 2
+					if delay > maxDelay {
+						delay = maxDelay
+					}
+					continue
+				}
+				break
+			}
+		}
+	}
+}
+
+// handleLogin sends the callsign, optional password, and any configured
+// init commands once the node's prompt is expected to have appeared. Most
+// cluster software accepts the callsign within the first second or two of
+// connecting, before any explicit login prompt is printed.
+func (c *Client) handleLogin() {
+	time.Sleep(1 * time.Second)
+
+	log.Printf("%s: logging in as %s", c.displayName(), c.callsign)
+	c.sendLine(c.callsign)
+	if c.password != "" {
+		c.sendLine(c.password)
+	}
+	for _, cmd := range c.initCommands {
+		if strings.TrimSpace(cmd) == "" {
+			continue
+		}
+		c.sendLine(cmd)
+	}
+}
+
+func (c *Client) sendLine(line string) {
+	if c.writer == nil {
+		return
+	}
+	c.writer.WriteString(line + "\r\n")
+	c.writer.Flush()
+}
+
+func (c *Client) readLoop() {
+	defer func() {
+		c.connected = false
+		if c.conn != nil {
+			c.conn.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-c.shutdown:
+			return
+		default:
+			c.conn.SetReadDeadline(time.Now().Add(10 * time.Minute))
+			line, err := c.reader.ReadString('\n')
+			if err != nil {
+				if c.isShutdown() {
+					return
+				}
+				log.Printf("%s: read error: %v", c.displayName(), err)
+				events.Warn(c.logger, "dxcluster.disconnect", events.String("node", c.displayName()), events.Err(err))
+				c.requestReconnect()
+				return
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if strings.HasPrefix(strings.ToUpper(line), "DX DE") {
+				if s, err := c.parseSpot(line); err == nil {
+					s.SourceNode = c.displayName()
+					if c.alertEngine != nil && !c.alertEngine.Apply(s) {
+						continue
+					}
+					select {
+					case c.spotChan <- s:
+					default:
+						log.Printf("%s: spot channel full, dropping spot for %s", c.displayName(), s.DXCall)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) requestReconnect() {
+	if c.isShutdown() {
+		return
+	}
+	select {
+	case c.reconnect <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Client) isShutdown() bool {
+	select {
+	case <-c.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) displayName() string {
+	if c.name != "" {
+		return c.name
+	}
+	return c.dialAddr()
+}
+
+// parseSpot converts one "DX de" line into a *spot.Spot. It first tries the
+// strict regex form used by FlexDXCluster and most AR-Cluster/DXSpider
+// nodes, then falls back to plain whitespace tokenization for nodes that
+// emit variant spacing or omit the trailing time.
+func (c *Client) parseSpot(line string) (*spot.Spot, error) {
+	if m := dxSpotPattern.FindStringSubmatch(line); m != nil {
+		deCall := normalizeSpotterCall(m[1])
+		freq, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad frequency %q: %w", m[2], err)
+		}
+		dxCall := m[3]
+		comment := strings.TrimSpace(m[4])
+		mode, comment := extractMode(comment, freq)
+
+		s := spot.NewSpot(dxCall, deCall, freq, mode)
+		if comment != "" {
+			s.Comment = comment
+		}
+		s.Time = parseSpotTime(m[5])
+		c.enrich(s, dxCall, deCall)
+		return s, nil
+	}
+	return c.parseSpotFallback(line)
+}
+
+// parseSpotFallback handles cluster nodes whose lines don't quite match the
+// canonical pattern (extra whitespace, missing trailing Z, etc.) by
+// tokenizing on whitespace and taking the first two tokens after "de" as
+// spotter/frequency and the next as the DX call.
+func (c *Client) parseSpotFallback(line string) (*spot.Spot, error) {
+	fields := strings.Fields(line)
+	// Expect: "DX" "de" "<spotter>:" "<freq>" "<dxcall>" ...
+	if len(fields) < 5 {
+		return nil, fmt.Errorf("line too short to be a spot: %q", line)
+	}
+	deCall := normalizeSpotterCall(fields[2])
+	freq, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad frequency %q: %w", fields[3], err)
+	}
+	dxCall := fields[4]
+	comment := strings.TrimSpace(strings.Join(fields[5:], " "))
+	comment = strings.TrimSuffix(comment, "Z")
+	mode, comment := extractMode(comment, freq)
+
+	s := spot.NewSpot(dxCall, deCall, freq, mode)
+	if comment != "" {
+		s.Comment = comment
+	}
+	s.Time = time.Now().UTC()
+	c.enrich(s, dxCall, deCall)
+	return s, nil
+}
+
+// enrich fills in metadata/source fields common to both parse paths.
+func (c *Client) enrich(s *spot.Spot, dxCall, deCall string) {
+	if info, ok := c.fetchCallsignInfo(dxCall); ok {
+		s.DXMetadata = metadataFromPrefix(info)
+	}
+	if info, ok := c.fetchCallsignInfo(deCall); ok {
+		s.DEMetadata = metadataFromPrefix(info)
+	}
+	s.DXMetadata = c.enricher.Enrich(s.DXMetadata, dxCall)
+	s.DEMetadata = c.enricher.Enrich(s.DEMetadata, deCall)
+	s.SourceType = spot.SourceUpstream
+	s.SourceNode = c.displayName()
+	s.IsHuman = true
+	s.RefreshBeaconFlag()
+	s.EnsureNormalized()
+}
+
+func (c *Client) fetchCallsignInfo(call string) (*cty.PrefixInfo, bool) {
+	if c.lookup == nil {
+		return nil, false
+	}
+	return c.lookup.LookupCallsign(call)
+}
+
+func metadataFromPrefix(info *cty.PrefixInfo) spot.CallMetadata {
+	if info == nil {
+		return spot.CallMetadata{}
+	}
+	return spot.CallMetadata{
+		Continent: info.Continent,
+		Country:   info.Country,
+		CQZone:    info.CQZone,
+		ITUZone:   info.ITUZone,
+		ADIF:      info.ADIF,
+	}
+}
+
+// normalizeSpotterCall strips the trailing ":" (and any "-#" skimmer suffix)
+// that precedes the frequency field.
+func normalizeSpotterCall(raw string) string {
+	call := strings.TrimSuffix(raw, ":")
+	if idx := strings.Index(call, "-#"); idx >= 0 {
+		call = call[:idx]
+	}
+	return call
+}
+
+// extractMode pulls a leading mode token off the comment if one is present,
+// falling back to sub-band inference from the frequency. It returns the
+// mode and the comment with that leading token removed.
+func extractMode(comment string, freqKHz float64) (mode string, rest string) {
+	fields := strings.Fields(comment)
+	if len(fields) > 0 && knownModes[strings.ToUpper(fields[0])] {
+		mode = spot.NormalizeVoiceMode(strings.ToUpper(fields[0]), freqKHz)
+		rest = strings.TrimSpace(strings.Join(fields[1:], " "))
+		return mode, rest
+	}
+	mode = spot.GuessModeFromAlloc(freqKHz)
+	return mode, comment
+}
+
+// parseSpotTime interprets a 4-digit HHMM token as today's UTC time, which
+// is how cluster nodes report spot times without a date component.
+func parseSpotTime(hhmm string) time.Time {
+	now := time.Now().UTC()
+	hh, err1 := strconv.Atoi(hhmm[0:2])
+	mm, err2 := strconv.Atoi(hhmm[2:4])
+	if err1 != nil || err2 != nil {
+		return now
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), hh, mm, 0, 0, time.UTC)
+}
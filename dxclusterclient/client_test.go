@@ -0,0 +1,66 @@
+package dxclusterclient
+
+import (
+	"testing"
+)
+
+func TestParseSpotCanonicalFormat(t *testing.T) {
+	c := NewClient("cluster.example.com", 7300, "N2WQ", "", "TEST-CLUSTER", nil, nil, 10)
+	s, err := c.parseSpot("DX de K1ABC:     14025.0  W1AW         CQ CW                        1234Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.DXCall != "W1AW" || s.DECall != "K1ABC" {
+		t.Fatalf("unexpected calls: dx=%s de=%s", s.DXCall, s.DECall)
+	}
+	if s.Frequency != 14025.0 {
+		t.Fatalf("unexpected frequency: %v", s.Frequency)
+	}
+	if s.Mode != "CW" {
+		t.Fatalf("expected mode CW, got %q", s.Mode)
+	}
+	if s.SourceNode != "TEST-CLUSTER" {
+		t.Fatalf("unexpected source node: %q", s.SourceNode)
+	}
+}
+
+func TestParseSpotInfersModeFromSubband(t *testing.T) {
+	c := NewClient("cluster.example.com", 7300, "N2WQ", "", "TEST-CLUSTER", nil, nil, 10)
+	s, err := c.parseSpot("DX de W2XYZ: 7030.0 DL1ABC just a comment 0900Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Mode != "CW" {
+		t.Fatalf("expected CW inferred from the 40m CW sub-band, got %q", s.Mode)
+	}
+	if s.Comment != "just a comment" {
+		t.Fatalf("unexpected comment: %q", s.Comment)
+	}
+}
+
+func TestParseSpotFallbackOnVariantSpacing(t *testing.T) {
+	c := NewClient("cluster.example.com", 7300, "N2WQ", "", "TEST-CLUSTER", nil, nil, 10)
+	s, err := c.parseSpotFallback("DX de VE7CC: 21200.0 JA1XYZ SSB loud")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.DXCall != "JA1XYZ" || s.DECall != "VE7CC" {
+		t.Fatalf("unexpected calls: dx=%s de=%s", s.DXCall, s.DECall)
+	}
+	if s.Mode != "USB" {
+		t.Fatalf("expected SSB normalized to USB at 21.2MHz, got %q", s.Mode)
+	}
+}
+
+func TestParseSpotRejectsGarbage(t *testing.T) {
+	c := NewClient("cluster.example.com", 7300, "N2WQ", "", "TEST-CLUSTER", nil, nil, 10)
+	if _, err := c.parseSpot("WCY de DK0WCY-1: ..."); err == nil {
+		t.Fatal("expected an error for a non-spot line")
+	}
+}
+
+func TestNormalizeSpotterCallStripsSkimmerSuffix(t *testing.T) {
+	if got := normalizeSpotterCall("K1ABC-#:"); got != "K1ABC" {
+		t.Fatalf("unexpected normalized call: %q", got)
+	}
+}
@@ -0,0 +1,104 @@
+package enrich
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// cacheBucket is the single bbolt bucket holding every cached entry, keyed
+// by uppercased callsign.
+var cacheBucket = []byte("enrich_cache")
+
+// cacheEntry is what Cache persists per callsign: either a resolved Info
+// with an expiry, or a negative entry (the callsign looked up as
+// not-found) so an unlisted call isn't re-queried on every sighting.
+type cacheEntry struct {
+	Info     Info  `json:"info,omitempty"`
+	Negative bool  `json:"negative,omitempty"`
+	Expires  int64 `json:"expires"` // unix seconds
+}
+
+func (e cacheEntry) expired(now time.Time) bool {
+	return now.Unix() >= e.Expires
+}
+
+// Cache is a persistent on-disk cache for resolver results, backed by
+// bbolt. Get stops returning an entry once its TTL has passed, but the entry
+// stays on disk until a later Set or SetNegative for the same call
+// overwrites it. Nothing bounds the cache's size, and no entry is evicted by
+// access recency.
+type Cache struct {
+	db *bbolt.DB
+}
+
+// OpenCache opens (creating if necessary) a bbolt-backed cache at path.
+func OpenCache(path string) (*Cache, error) {
+	db, err := bbolt.Open(path, 0o644, nil)
+	if err != nil {
+		return nil, fmt.Errorf("enrich: open cache: %w", err)
+	}
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(cacheBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("enrich: create cache bucket: %w", err)
+	}
+	return &Cache{db: db}, nil
+}
+
+// Close closes the underlying bbolt database.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+func cacheKey(call string) []byte {
+	return []byte(strings.ToUpper(strings.TrimSpace(call)))
+}
+
+// Get returns the cached entry for call, if present and not expired.
+func (c *Cache) Get(call string) (cacheEntry, bool) {
+	var entry cacheEntry
+	found := false
+	_ = c.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(cacheBucket).Get(cacheKey(call))
+		if v == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return nil
+		}
+		found = true
+		return nil
+	})
+	if !found || entry.expired(time.Now()) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Set stores a resolved Info for call, expiring after ttl.
+func (c *Cache) Set(call string, info Info, ttl time.Duration) error {
+	return c.put(call, cacheEntry{Info: info, Expires: time.Now().Add(ttl).Unix()})
+}
+
+// SetNegative records that call was looked up and not found, expiring
+// after ttl (typically shorter-lived than a positive TTL would be, so a
+// since-licensed callsign isn't stuck "unlisted" for too long).
+func (c *Cache) SetNegative(call string, ttl time.Duration) error {
+	return c.put(call, cacheEntry{Negative: true, Expires: time.Now().Add(ttl).Unix()})
+}
+
+func (c *Cache) put(call string, entry cacheEntry) error {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("enrich: marshal cache entry: %w", err)
+	}
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(cacheBucket).Put(cacheKey(call), payload)
+	})
+}
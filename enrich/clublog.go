@@ -0,0 +1,99 @@
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultClubLogBaseURL is ClubLog's callsign lookup endpoint, which
+// resolves a call against a specific logbook (so it can return QSO-derived
+// info like an operator's name) rather than a global directory.
+const defaultClubLogBaseURL = "https://clublog.org/dxcc"
+
+// ClubLogConfig holds the credentials for ClubLogResolver. Callsign and
+// APIKey identify the logbook being queried against, matching how ClubLog's
+// lookup API is scoped per-account rather than global like QRZ/HamQTH.
+type ClubLogConfig struct {
+	APIKey   string
+	Callsign string
+	Password string
+
+	// BaseURL overrides defaultClubLogBaseURL, for tests.
+	BaseURL string
+}
+
+// ClubLogResolver looks up callsigns against a ClubLog logbook.
+type ClubLogResolver struct {
+	cfg    ClubLogConfig
+	client *http.Client
+}
+
+// NewClubLogResolver constructs a ClubLogResolver, or nil if cfg is
+// missing any of the three credentials ClubLog's API requires.
+func NewClubLogResolver(cfg ClubLogConfig) *ClubLogResolver {
+	if strings.TrimSpace(cfg.APIKey) == "" || strings.TrimSpace(cfg.Callsign) == "" || strings.TrimSpace(cfg.Password) == "" {
+		return nil
+	}
+	return &ClubLogResolver{cfg: cfg, client: http.DefaultClient}
+}
+
+func (r *ClubLogResolver) baseURL() string {
+	if r.cfg.BaseURL != "" {
+		return r.cfg.BaseURL
+	}
+	return defaultClubLogBaseURL
+}
+
+// Resolve implements Resolver.
+func (r *ClubLogResolver) Resolve(ctx context.Context, call string) (Info, error) {
+	q := url.Values{
+		"call":     {call},
+		"api":      {r.cfg.APIKey},
+		"login":    {r.cfg.Callsign},
+		"password": {r.cfg.Password},
+		"full":     {"1"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL()+"?"+q.Encode(), nil)
+	if err != nil {
+		return Info{}, fmt.Errorf("enrich: clublog build request: %w", err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return Info{}, fmt.Errorf("enrich: clublog request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Info{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Info{}, fmt.Errorf("enrich: clublog lookup: unexpected status %s", resp.Status)
+	}
+
+	var doc clubLogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return Info{}, fmt.Errorf("enrich: clublog decode response: %w", err)
+	}
+	if doc.Name == "" && doc.Latitude == 0 && doc.Longitude == 0 {
+		return Info{}, ErrNotFound
+	}
+
+	return Info{
+		Lat:          doc.Latitude,
+		Lon:          doc.Longitude,
+		OperatorName: doc.Name,
+	}, nil
+}
+
+// clubLogResponse is the subset of ClubLog's DXCC lookup JSON this package
+// reads. ClubLog doesn't return grid/state/county, only DXCC-entity-level
+// position and, for calls found in the queried logbook, an operator name.
+type clubLogResponse struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
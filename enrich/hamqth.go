@@ -0,0 +1,165 @@
+package enrich
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultHamQTHBaseURL is HamQTH's XML lookup endpoint.
+const defaultHamQTHBaseURL = "https://www.hamqth.com/xml.php"
+
+// HamQTHConfig holds the credentials and endpoint for HamQTHResolver.
+type HamQTHConfig struct {
+	Username string
+	Password string
+
+	// BaseURL overrides defaultHamQTHBaseURL, for tests.
+	BaseURL string
+}
+
+// HamQTHResolver looks up callsigns against HamQTH's XML data service,
+// mirroring QRZResolver's lazy session login and retry-once-on-expiry
+// behavior since HamQTH's API follows the same session-key pattern.
+type HamQTHResolver struct {
+	cfg    HamQTHConfig
+	client *http.Client
+
+	mu      sync.Mutex
+	session string
+}
+
+// NewHamQTHResolver constructs a HamQTHResolver, or nil if cfg has no
+// credentials.
+func NewHamQTHResolver(cfg HamQTHConfig) *HamQTHResolver {
+	if strings.TrimSpace(cfg.Username) == "" || strings.TrimSpace(cfg.Password) == "" {
+		return nil
+	}
+	return &HamQTHResolver{cfg: cfg, client: http.DefaultClient}
+}
+
+func (r *HamQTHResolver) baseURL() string {
+	if r.cfg.BaseURL != "" {
+		return r.cfg.BaseURL
+	}
+	return defaultHamQTHBaseURL
+}
+
+// Resolve implements Resolver.
+func (r *HamQTHResolver) Resolve(ctx context.Context, call string) (Info, error) {
+	r.mu.Lock()
+	session := r.session
+	r.mu.Unlock()
+
+	if session == "" {
+		var err error
+		session, err = r.login(ctx)
+		if err != nil {
+			return Info{}, err
+		}
+	}
+
+	info, err := r.lookup(ctx, session, call)
+	if err == errHamQTHSessionInvalid {
+		session, err = r.login(ctx)
+		if err != nil {
+			return Info{}, err
+		}
+		info, err = r.lookup(ctx, session, call)
+	}
+	return info, err
+}
+
+func (r *HamQTHResolver) login(ctx context.Context) (string, error) {
+	q := url.Values{"u": {r.cfg.Username}, "p": {r.cfg.Password}}
+	doc, err := r.get(ctx, q)
+	if err != nil {
+		return "", err
+	}
+	if doc.Session.Error != "" {
+		return "", fmt.Errorf("enrich: hamqth login: %s", doc.Session.Error)
+	}
+	if doc.Session.ID == "" {
+		return "", fmt.Errorf("enrich: hamqth login: no session id returned")
+	}
+	r.mu.Lock()
+	r.session = doc.Session.ID
+	r.mu.Unlock()
+	return doc.Session.ID, nil
+}
+
+func (r *HamQTHResolver) lookup(ctx context.Context, session, call string) (Info, error) {
+	q := url.Values{"id": {session}, "callsign": {call}, "prg": {"dxcluster"}}
+	doc, err := r.get(ctx, q)
+	if err != nil {
+		return Info{}, err
+	}
+	if doc.Session.Error != "" {
+		if strings.Contains(strings.ToLower(doc.Session.Error), "session does not exist") {
+			return Info{}, errHamQTHSessionInvalid
+		}
+		return Info{}, fmt.Errorf("enrich: hamqth lookup: %s", doc.Session.Error)
+	}
+	if doc.Search.Callsign == "" {
+		return Info{}, ErrNotFound
+	}
+
+	lat, _ := strconv.ParseFloat(doc.Search.Lat, 64)
+	lon, _ := strconv.ParseFloat(doc.Search.Lon, 64)
+	return Info{
+		Grid:         doc.Search.Grid,
+		Lat:          lat,
+		Lon:          lon,
+		State:        doc.Search.Adif1,
+		County:       doc.Search.County,
+		OperatorName: strings.TrimSpace(doc.Search.Name),
+	}, nil
+}
+
+func (r *HamQTHResolver) get(ctx context.Context, q url.Values) (*hamqthResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL()+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("enrich: hamqth build request: %w", err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("enrich: hamqth request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var doc hamqthResponse
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("enrich: hamqth decode response: %w", err)
+	}
+	return &doc, nil
+}
+
+// errHamQTHSessionInvalid mirrors errQRZSessionInvalid: it tells Resolve to
+// re-login once rather than surfacing a session expiry as a lookup miss.
+var errHamQTHSessionInvalid = fmt.Errorf("enrich: hamqth session invalid")
+
+type hamqthResponse struct {
+	XMLName xml.Name      `xml:"HamQTH"`
+	Session hamqthSession `xml:"session"`
+	Search  hamqthSearch  `xml:"search"`
+}
+
+type hamqthSession struct {
+	ID    string `xml:"session_id"`
+	Error string `xml:"error"`
+}
+
+type hamqthSearch struct {
+	Callsign string `xml:"callsign"`
+	Name     string `xml:"nick"`
+	Grid     string `xml:"grid"`
+	Lat      string `xml:"latitude"`
+	Lon      string `xml:"longitude"`
+	Adif1    string `xml:"us_state"`
+	County   string `xml:"us_county"`
+}
@@ -0,0 +1,298 @@
+package enrich
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dxcluster/spot"
+)
+
+const (
+	defaultWorkers     = 4
+	defaultQueueSize   = 1000
+	defaultDeadline    = 5 * time.Second
+	defaultPositiveTTL = 30 * 24 * time.Hour
+	defaultNegativeTTL = 6 * time.Hour
+)
+
+// Config controls Manager's worker pool sizing, per-lookup deadline, and
+// cache TTLs.
+type Config struct {
+	// CachePath is where the on-disk cache is persisted. Required.
+	CachePath string
+
+	// Workers bounds how many lookups run concurrently. Zero uses
+	// defaultWorkers.
+	Workers int
+	// QueueSize bounds how many pending lookups Manager will hold before
+	// dropping new ones. Zero uses defaultQueueSize.
+	QueueSize int
+	// Deadline bounds one background lookup; every resolver queried for
+	// that lookup shares it. Zero uses defaultDeadline.
+	Deadline time.Duration
+
+	// PositiveTTL/NegativeTTL override how long a found/not-found result
+	// stays cached. Zero uses the package defaults.
+	PositiveTTL time.Duration
+	NegativeTTL time.Duration
+}
+
+func (c Config) normalize() Config {
+	if c.Workers <= 0 {
+		c.Workers = defaultWorkers
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultQueueSize
+	}
+	if c.Deadline <= 0 {
+		c.Deadline = defaultDeadline
+	}
+	if c.PositiveTTL <= 0 {
+		c.PositiveTTL = defaultPositiveTTL
+	}
+	if c.NegativeTTL <= 0 {
+		c.NegativeTTL = defaultNegativeTTL
+	}
+	return c
+}
+
+// Manager enriches spot.CallMetadata beyond cty's prefix-level data by
+// consulting Resolvers (QRZ, HamQTH, ClubLog, ...) in order, caching every
+// result - positive or negative - on disk. Enrich never blocks the caller
+// on a network lookup: a cache hit applies immediately, a cache miss
+// schedules a background fetch (best-effort, dropped under load) and
+// returns the metadata unchanged so the spot it came from still ships.
+type Manager struct {
+	cfg       Config
+	resolvers []Resolver
+	cache     *Cache
+
+	jobs chan string
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	inFlightMu sync.Mutex
+	inFlight   map[string]struct{}
+
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	enriched atomic.Uint64
+	dropped  atomic.Uint64
+	errors   atomic.Uint64
+}
+
+// NewManager opens cfg.CachePath and constructs a Manager over resolvers.
+// It returns nil (not an error) if resolvers is empty, matching the
+// nil-safe optional-subsystem pattern used elsewhere (e.g.
+// skew.NewFetchRefresher) so callers can wire it in unconditionally and
+// let config decide whether enrichment runs at all.
+func NewManager(cfg Config, resolvers ...Resolver) (*Manager, error) {
+	if len(resolvers) == 0 {
+		return nil, nil
+	}
+	cfg = cfg.normalize()
+	cache, err := OpenCache(cfg.CachePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		cfg:       cfg,
+		resolvers: resolvers,
+		cache:     cache,
+		jobs:      make(chan string, cfg.QueueSize),
+		stop:      make(chan struct{}),
+		inFlight:  make(map[string]struct{}),
+	}, nil
+}
+
+// Start launches the worker pool in background goroutines.
+func (m *Manager) Start() {
+	if m == nil {
+		return
+	}
+	for i := 0; i < m.cfg.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+}
+
+// Stop ends the worker pool and closes the cache. Queued-but-unstarted
+// lookups are abandoned.
+func (m *Manager) Stop() {
+	if m == nil {
+		return
+	}
+	close(m.stop)
+	m.wg.Wait()
+	if err := m.cache.Close(); err != nil {
+		log.Printf("enrich: close cache: %v", err)
+	}
+}
+
+// Enrich merges any cached directory data for call onto meta. On a cache
+// miss it schedules a background lookup (non-blocking; dropped if the
+// queue is full) and returns meta unchanged, so a cold callsign still
+// ships with prefix-only metadata instead of waiting on a network round
+// trip.
+func (m *Manager) Enrich(meta spot.CallMetadata, call string) spot.CallMetadata {
+	if m == nil {
+		return meta
+	}
+	entry, ok := m.cache.Get(call)
+	if !ok {
+		m.misses.Add(1)
+		m.schedule(call)
+		return meta
+	}
+	m.hits.Add(1)
+	if entry.Negative {
+		return meta
+	}
+	meta.Grid = coalesce(entry.Info.Grid, meta.Grid)
+	meta.State = coalesce(entry.Info.State, meta.State)
+	meta.County = coalesce(entry.Info.County, meta.County)
+	meta.OperatorName = coalesce(entry.Info.OperatorName, meta.OperatorName)
+	if entry.Info.Lat != 0 {
+		meta.Lat = entry.Info.Lat
+	}
+	if entry.Info.Lon != 0 {
+		meta.Lon = entry.Info.Lon
+	}
+	return meta
+}
+
+func coalesce(preferred, fallback string) string {
+	if preferred != "" {
+		return preferred
+	}
+	return fallback
+}
+
+func (m *Manager) schedule(call string) {
+	call = strings.ToUpper(strings.TrimSpace(call))
+	if call == "" {
+		return
+	}
+
+	m.inFlightMu.Lock()
+	if _, busy := m.inFlight[call]; busy {
+		m.inFlightMu.Unlock()
+		return
+	}
+	m.inFlight[call] = struct{}{}
+	m.inFlightMu.Unlock()
+
+	select {
+	case m.jobs <- call:
+	default:
+		m.dropped.Add(1)
+		m.inFlightMu.Lock()
+		delete(m.inFlight, call)
+		m.inFlightMu.Unlock()
+	}
+}
+
+func (m *Manager) worker() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case call := <-m.jobs:
+			m.resolve(call)
+			m.inFlightMu.Lock()
+			delete(m.inFlight, call)
+			m.inFlightMu.Unlock()
+		}
+	}
+}
+
+// resolve queries every configured resolver and merges their results, so a
+// call found in more than one directory (e.g. ClubLog for lat/lon, QRZ for
+// grid/state/county) ends up with the union of what each knows instead of
+// whatever the first successful resolver happened to return.
+func (m *Manager) resolve(call string) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Deadline)
+	defer cancel()
+
+	var merged Info
+	found, failed := false, false
+	for _, r := range m.resolvers {
+		info, err := r.Resolve(ctx, call)
+		if err == nil {
+			merged = merge(merged, info)
+			found = true
+			continue
+		}
+		if err == ErrNotFound {
+			continue
+		}
+		failed = true
+		m.errors.Add(1)
+		log.Printf("enrich: resolve %s: %v", call, err)
+	}
+
+	if !found {
+		if failed {
+			// A resolver failed rather than reporting ErrNotFound: cache
+			// nothing so the call is tried again on its next sighting.
+			return
+		}
+		// Every resolver reported ErrNotFound: cache a negative entry so
+		// this call isn't re-queried on its next sighting.
+		if err := m.cache.SetNegative(call, m.cfg.NegativeTTL); err != nil {
+			log.Printf("enrich: cache set negative %s: %v", call, err)
+		}
+		return
+	}
+
+	m.enriched.Add(1)
+	if err := m.cache.Set(call, merged, m.cfg.PositiveTTL); err != nil {
+		log.Printf("enrich: cache set %s: %v", call, err)
+	}
+}
+
+// Hits returns how many Enrich calls were satisfied from cache.
+func (m *Manager) Hits() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.hits.Load()
+}
+
+// Misses returns how many Enrich calls found no cache entry.
+func (m *Manager) Misses() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.misses.Load()
+}
+
+// Enriched returns how many background lookups found directory data.
+func (m *Manager) Enriched() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.enriched.Load()
+}
+
+// Dropped returns how many lookups were discarded because the queue was
+// full.
+func (m *Manager) Dropped() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.dropped.Load()
+}
+
+// Errors returns how many resolver calls failed (excluding ErrNotFound).
+func (m *Manager) Errors() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.errors.Load()
+}
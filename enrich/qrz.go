@@ -0,0 +1,180 @@
+package enrich
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultQRZBaseURL is QRZ's XML lookup endpoint.
+const defaultQRZBaseURL = "https://xmldata.qrz.com/xml/current/"
+
+// QRZConfig holds the credentials and endpoint for QRZResolver. Username
+// and Password are a QRZ XML subscription's login, not an API key - QRZ's
+// XML interface authenticates by exchanging them for a session key.
+type QRZConfig struct {
+	Username string
+	Password string
+
+	// BaseURL overrides defaultQRZBaseURL, for tests.
+	BaseURL string
+}
+
+// QRZResolver looks up callsigns against QRZ's XML data service. A session
+// key is fetched lazily on first use and reused until QRZ reports it's
+// invalid or expired, at which point Resolve re-logs-in once and retries.
+type QRZResolver struct {
+	cfg    QRZConfig
+	client *http.Client
+
+	mu      sync.Mutex
+	session string
+}
+
+// NewQRZResolver constructs a QRZResolver. It returns nil if cfg has no
+// credentials, matching the nil-safe optional-subsystem pattern used
+// elsewhere in this codebase (e.g. skew.NewFetchRefresher).
+func NewQRZResolver(cfg QRZConfig) *QRZResolver {
+	if strings.TrimSpace(cfg.Username) == "" || strings.TrimSpace(cfg.Password) == "" {
+		return nil
+	}
+	return &QRZResolver{cfg: cfg, client: http.DefaultClient}
+}
+
+func (r *QRZResolver) baseURL() string {
+	if r.cfg.BaseURL != "" {
+		return r.cfg.BaseURL
+	}
+	return defaultQRZBaseURL
+}
+
+// Resolve implements Resolver.
+func (r *QRZResolver) Resolve(ctx context.Context, call string) (Info, error) {
+	r.mu.Lock()
+	session := r.session
+	r.mu.Unlock()
+
+	if session == "" {
+		var err error
+		session, err = r.login(ctx)
+		if err != nil {
+			return Info{}, err
+		}
+	}
+
+	info, err := r.lookup(ctx, session, call)
+	if err == errQRZSessionInvalid {
+		session, err = r.login(ctx)
+		if err != nil {
+			return Info{}, err
+		}
+		info, err = r.lookup(ctx, session, call)
+	}
+	return info, err
+}
+
+func (r *QRZResolver) login(ctx context.Context) (string, error) {
+	q := url.Values{
+		"username": {r.cfg.Username},
+		"password": {r.cfg.Password},
+		"agent":    {"dxcluster"},
+	}
+	doc, err := r.get(ctx, q)
+	if err != nil {
+		return "", err
+	}
+	if doc.Session.Error != "" {
+		return "", fmt.Errorf("enrich: qrz login: %s", doc.Session.Error)
+	}
+	if doc.Session.Key == "" {
+		return "", fmt.Errorf("enrich: qrz login: no session key returned")
+	}
+	r.mu.Lock()
+	r.session = doc.Session.Key
+	r.mu.Unlock()
+	return doc.Session.Key, nil
+}
+
+func (r *QRZResolver) lookup(ctx context.Context, session, call string) (Info, error) {
+	q := url.Values{"s": {session}, "callsign": {call}}
+	doc, err := r.get(ctx, q)
+	if err != nil {
+		return Info{}, err
+	}
+	if isQRZSessionError(doc.Session.Error) {
+		return Info{}, errQRZSessionInvalid
+	}
+	if doc.Session.Error != "" {
+		// "Not found" is QRZ's wording for an unlisted call.
+		if strings.Contains(strings.ToLower(doc.Session.Error), "not found") {
+			return Info{}, ErrNotFound
+		}
+		return Info{}, fmt.Errorf("enrich: qrz lookup: %s", doc.Session.Error)
+	}
+
+	lat, _ := strconv.ParseFloat(doc.Callsign.Lat, 64)
+	lon, _ := strconv.ParseFloat(doc.Callsign.Lon, 64)
+	return Info{
+		Grid:         doc.Callsign.Grid,
+		Lat:          lat,
+		Lon:          lon,
+		State:        doc.Callsign.State,
+		County:       doc.Callsign.County,
+		OperatorName: strings.TrimSpace(doc.Callsign.FName + " " + doc.Callsign.Name),
+	}, nil
+}
+
+func (r *QRZResolver) get(ctx context.Context, q url.Values) (*qrzResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL()+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("enrich: qrz build request: %w", err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("enrich: qrz request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var doc qrzResponse
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("enrich: qrz decode response: %w", err)
+	}
+	return &doc, nil
+}
+
+// errQRZSessionInvalid is a sentinel, not a Resolver-facing error: it tells
+// Resolve to re-login once and retry rather than surfacing a session
+// expiry as a lookup failure for this callsign.
+var errQRZSessionInvalid = fmt.Errorf("enrich: qrz session invalid")
+
+func isQRZSessionError(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "session") && (strings.Contains(msg, "invalid") || strings.Contains(msg, "timeout") || strings.Contains(msg, "expired"))
+}
+
+// qrzResponse is the subset of QRZ's XML schema this package reads.
+type qrzResponse struct {
+	XMLName  xml.Name    `xml:"QRZDatabase"`
+	Session  qrzSession  `xml:"Session"`
+	Callsign qrzCallsign `xml:"Callsign"`
+}
+
+type qrzSession struct {
+	Key   string `xml:"Key"`
+	Error string `xml:"Error"`
+}
+
+type qrzCallsign struct {
+	FName  string `xml:"fname"`
+	Name   string `xml:"name"`
+	Grid   string `xml:"grid"`
+	Lat    string `xml:"lat"`
+	Lon    string `xml:"lon"`
+	State  string `xml:"state"`
+	County string `xml:"county"`
+}
@@ -0,0 +1,64 @@
+// Package enrich fills in per-callsign metadata beyond what cty's
+// prefix-level lookup can offer - grid square, lat/lon, state/county, and
+// operator name - by querying online callsign directories (QRZ, HamQTH,
+// ClubLog). It's a strictly optional, best-effort layer: a Manager with no
+// configured Resolvers behaves as a no-op, and a lookup that fails or
+// hasn't completed yet never blocks or changes the metadata a caller
+// already has.
+package enrich
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by a Resolver when the directory has no record
+// for the requested callsign, as distinct from a transient failure. Manager
+// caches this as a negative entry so a genuinely-unlisted call doesn't get
+// re-queried on every sighting.
+var ErrNotFound = errors.New("enrich: callsign not found")
+
+// Info is the metadata a Resolver can contribute for a callsign. Any zero
+// field is left untouched by Manager.Enrich, so a resolver that only knows
+// grid square doesn't clobber operator name filled in by an earlier lookup.
+type Info struct {
+	Grid         string
+	Lat          float64
+	Lon          float64
+	State        string
+	County       string
+	OperatorName string
+}
+
+// Resolver looks up a single callsign against one online directory.
+// Implementations must respect ctx's deadline - Manager always calls with
+// one set - and return ErrNotFound (not a zero Info) when the directory
+// simply doesn't have the call, so Manager can tell "unlisted" apart from
+// "try again later".
+type Resolver interface {
+	Resolve(ctx context.Context, call string) (Info, error)
+}
+
+// merge applies src's non-zero fields onto dst and returns the result,
+// leaving fields src doesn't know about untouched.
+func merge(dst Info, src Info) Info {
+	if src.Grid != "" {
+		dst.Grid = src.Grid
+	}
+	if src.Lat != 0 {
+		dst.Lat = src.Lat
+	}
+	if src.Lon != 0 {
+		dst.Lon = src.Lon
+	}
+	if src.State != "" {
+		dst.State = src.State
+	}
+	if src.County != "" {
+		dst.County = src.County
+	}
+	if src.OperatorName != "" {
+		dst.OperatorName = src.OperatorName
+	}
+	return dst
+}
@@ -0,0 +1,131 @@
+// Package events provides structured, typed-field event logging for the
+// peer and spot subsystems so operators can filter a running cluster's
+// output (e.g. just dedupe drops, or just peer reconnects) without
+// resorting to regexes over free-form log lines.
+package events
+
+import "time"
+
+// Level is an event's severity, ordered so a sink can filter by a minimum
+// threshold.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel maps a config string (as used by the `log_level` field) to a
+// Level, defaulting to LevelInfo for an empty or unrecognised value.
+func ParseLevel(s string) Level {
+	switch s {
+	case "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindFloat
+	kindTime
+	kindDuration
+	kindError
+)
+
+// Field is a single typed key/value pair attached to an event.
+type Field struct {
+	Key  string
+	kind fieldKind
+	str  string
+	i    int64
+	f    float64
+	t    time.Time
+	d    time.Duration
+	err  error
+}
+
+func String(key, value string) Field         { return Field{Key: key, kind: kindString, str: value} }
+func Int(key string, value int) Field        { return Field{Key: key, kind: kindInt, i: int64(value)} }
+func Float(key string, value float64) Field  { return Field{Key: key, kind: kindFloat, f: value} }
+func Time(key string, value time.Time) Field { return Field{Key: key, kind: kindTime, t: value} }
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: kindDuration, d: value}
+}
+
+// Err attaches an error under the conventional "error" key; it is a no-op
+// (omitted by sinks) when err is nil.
+func Err(err error) Field { return Field{Key: "error", kind: kindError, err: err} }
+
+// Value unboxes the field for machine-readable sinks (e.g. JSON), rendering
+// time.Time as RFC3339 and duration as its Go string form.
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case kindString:
+		return f.str
+	case kindInt:
+		return f.i
+	case kindFloat:
+		return f.f
+	case kindTime:
+		return f.t.Format(time.RFC3339)
+	case kindDuration:
+		return f.d.String()
+	case kindError:
+		if f.err == nil {
+			return nil
+		}
+		return f.err.Error()
+	default:
+		return nil
+	}
+}
+
+// IsZero reports whether the field carries no value, which is true for a
+// nil error field - sinks should omit these rather than printing "error=".
+func (f Field) IsZero() bool {
+	return f.kind == kindError && f.err == nil
+}
+
+// Logger is implemented by every event sink. Debug/Info/Warn/Error are
+// convenience wrappers over Event for the corresponding level.
+type Logger interface {
+	Event(level Level, name string, fields ...Field)
+}
+
+func Debug(l Logger, name string, fields ...Field) { emit(l, LevelDebug, name, fields) }
+func Info(l Logger, name string, fields ...Field)  { emit(l, LevelInfo, name, fields) }
+func Warn(l Logger, name string, fields ...Field)  { emit(l, LevelWarn, name, fields) }
+func Error(l Logger, name string, fields ...Field) { emit(l, LevelError, name, fields) }
+
+func emit(l Logger, level Level, name string, fields []Field) {
+	if l == nil {
+		return
+	}
+	l.Event(level, name, fields...)
+}
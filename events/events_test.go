@@ -0,0 +1,61 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMemorySinkCapturesEvents(t *testing.T) {
+	sink := NewMemorySink()
+	Info(sink, "peer.connect", String("peer", "W1AW"))
+	Debug(sink, "peer.dedupe_drop", String("key", "PC11:K1ABC"))
+
+	got := sink.Events()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 captured events, got %d", len(got))
+	}
+	if got[0].Level != LevelInfo || got[0].Name != "peer.connect" {
+		t.Fatalf("unexpected first event: %+v", got[0])
+	}
+}
+
+func TestJSONSinkRespectsMinLevel(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(&buf, LevelWarn)
+	Info(sink, "ignored")
+	Warn(sink, "kept", Int("n", 3))
+
+	if buf.Len() == 0 {
+		t.Fatal("expected one line to be written")
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if decoded["event"] != "kept" {
+		t.Fatalf("expected only the warn event to be written, got %v", decoded["event"])
+	}
+}
+
+func TestConsoleSinkQuotesValuesWithSpaces(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewConsoleSink(&buf, LevelDebug, false)
+	Info(sink, "call_correction.rejected", String("reason", "cty miss"))
+
+	line := buf.String()
+	if !strings.Contains(line, `reason="cty miss"`) {
+		t.Fatalf("expected quoted value with spaces, got %q", line)
+	}
+}
+
+func TestConsoleSinkOmitsNilError(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewConsoleSink(&buf, LevelDebug, false)
+	Info(sink, "dedup.enabled", Err(nil), Duration("window", 0))
+
+	if strings.Contains(buf.String(), "error=") {
+		t.Fatalf("expected nil error field to be omitted, got %q", buf.String())
+	}
+}
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSONSink writes one JSON object per event, suitable for `tail -f | jq`.
+// Writes are serialized so multiple goroutines can share a sink safely.
+type JSONSink struct {
+	mu       sync.Mutex
+	w        io.Writer
+	minLevel Level
+}
+
+// NewJSONSink builds a JSONSink writing to w, dropping events below
+// minLevel.
+func NewJSONSink(w io.Writer, minLevel Level) *JSONSink {
+	return &JSONSink{w: w, minLevel: minLevel}
+}
+
+func (s *JSONSink) Event(level Level, name string, fields ...Field) {
+	if level < s.minLevel {
+		return
+	}
+	obj := make(map[string]interface{}, len(fields)+2)
+	obj["ts"] = time.Now().Format(time.RFC3339)
+	obj["level"] = level.String()
+	obj["event"] = name
+	for _, f := range fields {
+		if f.IsZero() {
+			continue
+		}
+		obj[f.Key] = f.Value()
+	}
+	line, err := json.Marshal(obj)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.w.Write(line)
+}
+
+// levelColors holds the ANSI escape used to colourise each level's name in
+// the human formatter; empty strings disable colour.
+var levelColors = map[Level]string{
+	LevelDebug: "\x1b[90m",
+	LevelInfo:  "\x1b[36m",
+	LevelWarn:  "\x1b[33m",
+	LevelError: "\x1b[31m",
+}
+
+const colorReset = "\x1b[0m"
+
+// ConsoleSink renders events as colourised `key=value` pairs, one event per
+// line. It writes to a plain io.Writer - pass consoleLayout.LogWriter() to
+// interleave cleanly with the pinned stats header, since that writer
+// already serializes writes against the layout's own mutex.
+type ConsoleSink struct {
+	w        io.Writer
+	minLevel Level
+	color    bool
+}
+
+// NewConsoleSink builds a ConsoleSink writing to w. Set color to false for
+// redirected output (e.g. piped to a file) where ANSI codes are unwanted.
+func NewConsoleSink(w io.Writer, minLevel Level, color bool) *ConsoleSink {
+	return &ConsoleSink{w: w, minLevel: minLevel, color: color}
+}
+
+func (s *ConsoleSink) Event(level Level, name string, fields ...Field) {
+	if level < s.minLevel {
+		return
+	}
+	var b strings.Builder
+	levelName := level.String()
+	if s.color {
+		b.WriteString(levelColors[level])
+		b.WriteString(levelName)
+		b.WriteString(colorReset)
+	} else {
+		b.WriteString(levelName)
+	}
+	b.WriteByte(' ')
+	b.WriteString(name)
+	for _, f := range fields {
+		if f.IsZero() {
+			continue
+		}
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(formatValue(f))
+	}
+	b.WriteByte('\n')
+	_, _ = io.WriteString(s.w, b.String())
+}
+
+// formatValue renders a field's value for the human formatter, quoting
+// strings (and error messages) that contain whitespace.
+func formatValue(f Field) string {
+	switch f.kind {
+	case kindString:
+		return quoteIfNeeded(f.str)
+	case kindInt:
+		return strconv.FormatInt(f.i, 10)
+	case kindFloat:
+		return strconv.FormatFloat(f.f, 'g', -1, 64)
+	case kindTime:
+		return f.t.Format(time.RFC3339)
+	case kindDuration:
+		return f.d.String()
+	case kindError:
+		if f.err == nil {
+			return ""
+		}
+		return quoteIfNeeded(f.err.Error())
+	default:
+		return ""
+	}
+}
+
+func quoteIfNeeded(s string) string {
+	if strings.ContainsAny(s, " \t\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// MemoryEvent is a single captured call recorded by a MemorySink.
+type MemoryEvent struct {
+	Level  Level
+	Name   string
+	Fields []Field
+}
+
+// MemorySink captures events in-process so tests can assert on logging
+// side-effects without parsing a writer's output.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []MemoryEvent
+}
+
+// NewMemorySink builds an empty MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Event(level Level, name string, fields ...Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, MemoryEvent{Level: level, Name: name, Fields: fields})
+}
+
+// Events returns a snapshot of every captured event in order.
+func (s *MemorySink) Events() []MemoryEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]MemoryEvent, len(s.events))
+	copy(out, s.events)
+	return out
+}
+
+// Reset discards all captured events.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+}
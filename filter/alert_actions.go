@@ -0,0 +1,178 @@
+package filter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"time"
+
+	"dxcluster/spot"
+)
+
+// Action runs the side effect a matched AlertRule's then-clause names.
+// Apply returns keep=false to tell Engine.Apply to drop the spot instead
+// of forwarding it downstream; every built-in action besides "drop" keeps
+// the spot flowing.
+type Action interface {
+	Apply(s *spot.Spot) (keep bool, err error)
+}
+
+// ActionDeps wires in whatever a built-in action needs beyond its DSL
+// arguments. No field is required: a nil Forward makes "forward" a no-op,
+// and HTTPClient and NotifyCommand fall back to the defaults described
+// below, so a zero ActionDeps never panics on a live spot.
+type ActionDeps struct {
+	// Forward receives a copy of every spot the "forward" action runs on.
+	// Non-blocking: a full channel drops the forward rather than stalling
+	// the parse loop.
+	Forward chan<- *spot.Spot
+
+	// HTTPClient is used by the "webhook" action. Nil defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+
+	// NotifyCommand is the executable "notify"/"alert" runs to raise a
+	// desktop notification, argv-style: NotifyCommand[0] is the binary,
+	// remaining entries are fixed leading arguments, with the rule's
+	// message appended as the final argument. Empty defaults to
+	// []string{"notify-send", "GoCluster"}.
+	NotifyCommand []string
+}
+
+func (d ActionDeps) notifyCommand() []string {
+	if len(d.NotifyCommand) > 0 {
+		return d.NotifyCommand
+	}
+	return []string{"notify-send", "GoCluster"}
+}
+
+func (d ActionDeps) httpClient() *http.Client {
+	if d.HTTPClient != nil {
+		return d.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// newAction resolves one DSL action call (name plus its string arguments)
+// into an Action. Adding a new action kind means adding a case here and,
+// if it needs external state, a field on ActionDeps.
+func newAction(name string, args []string, deps ActionDeps) (Action, error) {
+	switch name {
+	case "drop":
+		return dropAction{}, nil
+	case "tag":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("tag(...) takes exactly one argument")
+		}
+		return tagAction{tag: args[0]}, nil
+	case "forward":
+		return forwardAction{ch: deps.Forward}, nil
+	case "webhook":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("webhook(...) takes exactly one argument (the URL)")
+		}
+		return webhookAction{url: args[0], client: deps.httpClient()}, nil
+	case "notify", "alert":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s(...) takes exactly one argument (the message)", name)
+		}
+		return notifyAction{message: args[0], command: deps.notifyCommand()}, nil
+	default:
+		return nil, fmt.Errorf("unknown action %q", name)
+	}
+}
+
+// dropAction removes the spot from the downstream feed entirely - the
+// generalized form of the old hard-coded dispatchUnlicensed special case.
+type dropAction struct{}
+
+func (dropAction) Apply(*spot.Spot) (bool, error) { return false, nil }
+
+// tagAction appends tag to Spot.Tags, deduplicating so re-evaluating the
+// same rule set (e.g. against a replayed spot) doesn't pile up repeats.
+type tagAction struct{ tag string }
+
+func (a tagAction) Apply(s *spot.Spot) (bool, error) {
+	for _, existing := range s.Tags {
+		if existing == a.tag {
+			return true, nil
+		}
+	}
+	s.Tags = append(s.Tags, a.tag)
+	return true, nil
+}
+
+// forwardAction sends a copy of the spot to a secondary channel - e.g. one
+// feeding a dedicated "wanted DXCC" alert window - without removing it from
+// the normal feed.
+type forwardAction struct{ ch chan<- *spot.Spot }
+
+func (a forwardAction) Apply(s *spot.Spot) (bool, error) {
+	if a.ch == nil {
+		return true, nil
+	}
+	cp := *s
+	select {
+	case a.ch <- &cp:
+	default:
+	}
+	return true, nil
+}
+
+// webhookAction POSTs the spot as JSON to url. Best-effort: a failed POST
+// is logged, not returned as an error that would stop the spot shipping.
+type webhookAction struct {
+	url    string
+	client *http.Client
+}
+
+func (a webhookAction) Apply(s *spot.Spot) (bool, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return true, fmt.Errorf("filter: marshal webhook payload: %w", err)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(payload))
+		if err != nil {
+			log.Printf("filter: build webhook request to %s: %v", a.url, err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			log.Printf("filter: webhook post to %s failed: %v", a.url, err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	return true, nil
+}
+
+// notifyAction raises a best-effort desktop notification via an external
+// command (notify-send by default). A missing binary or failed exec is
+// logged, never fatal to the spot being forwarded.
+type notifyAction struct {
+	message string
+	command []string
+}
+
+func (a notifyAction) Apply(*spot.Spot) (bool, error) {
+	if len(a.command) == 0 {
+		return true, nil
+	}
+	argv := append(append([]string{}, a.command[1:]...), a.message)
+	go func() {
+		if err := exec.Command(a.command[0], argv...).Run(); err != nil {
+			log.Printf("filter: desktop notification failed: %v", err)
+		}
+	}()
+	return true, nil
+}
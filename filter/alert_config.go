@@ -0,0 +1,17 @@
+package filter
+
+// AlertConfig mirrors the YAML schema for a set of alert rules: a flat list
+// of "when <expr> then <action>(<args>)" statements, same shape regardless
+// of whether the surrounding config file is YAML or TOML since it's just
+// an array of strings.
+type AlertConfig struct {
+	Rules []string `yaml:"alert_rules,omitempty"`
+}
+
+// NewAlertEngineFromConfig compiles cfg.Rules against deps. An empty
+// cfg.Rules yields a non-nil *AlertEngine with zero rules (Apply always
+// keeps), so callers can wire it in unconditionally without a special
+// case for "no alert rules configured".
+func NewAlertEngineFromConfig(cfg AlertConfig, deps ActionDeps) (*AlertEngine, error) {
+	return NewAlertEngine(cfg.Rules, deps)
+}
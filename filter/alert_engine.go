@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"fmt"
+	"log"
+
+	"dxcluster/spot"
+)
+
+// AlertEngine runs a compiled set of AlertRules against every spot between
+// EnsureNormalized and the point it's handed to callers, evaluating rules
+// in file order and running every matching rule's action - not just the
+// first - so a "tag" rule and a later "drop" rule can both fire for the
+// same spot. Apply keeps running matching rules' actions even after one
+// returns keep=false.
+type AlertEngine struct {
+	rules []*AlertRule
+}
+
+// NewAlertEngine compiles each rule source line (one "when ... then ..."
+// statement per entry) against deps. It returns an error naming the first
+// rule that fails to compile, so a config typo is caught at startup rather
+// than silently never firing.
+func NewAlertEngine(rules []string, deps ActionDeps) (*AlertEngine, error) {
+	e := &AlertEngine{}
+	for _, src := range rules {
+		rule, err := CompileAlertRule(src, deps)
+		if err != nil {
+			return nil, fmt.Errorf("filter: compile alert rule: %w", err)
+		}
+		e.rules = append(e.rules, rule)
+	}
+	return e, nil
+}
+
+// Apply evaluates every rule against s in order, running the action of
+// each one that matches - it does not stop at the first drop, so a rule
+// after a "drop" can still tag/forward/notify on the same spot. It reports
+// whether s should still be shipped downstream (false if any matching
+// rule's action dropped it).
+func (e *AlertEngine) Apply(s *spot.Spot) bool {
+	if e == nil {
+		return true
+	}
+	keep := true
+	for _, rule := range e.rules {
+		if !rule.Matches(s) {
+			continue
+		}
+		ok, err := rule.action.Apply(s)
+		if err != nil {
+			log.Printf("filter: alert rule %q action failed: %v", rule.Raw, err)
+		}
+		if !ok {
+			keep = false
+		}
+	}
+	return keep
+}
@@ -0,0 +1,528 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"dxcluster/spot"
+)
+
+// AlertRule is one compiled "when <expr> then <action>(<args>)" statement:
+// a small expression tree evaluated against a Spot, plus the action to run
+// when it matches. Raw keeps the original source around for logging/UI
+// display so an operator doesn't have to mentally decompile the tree.
+type AlertRule struct {
+	Raw    string
+	expr   exprNode
+	action Action
+}
+
+// Matches reports whether s satisfies the rule's when-clause.
+func (r *AlertRule) Matches(s *spot.Spot) bool {
+	v, err := r.expr.eval(s)
+	if err != nil {
+		return false
+	}
+	b, ok := v.(bool)
+	return ok && b
+}
+
+// exprNode is one node of a compiled when-clause's expression tree.
+type exprNode interface {
+	eval(s *spot.Spot) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) eval(*spot.Spot) (interface{}, error) { return n.value, nil }
+
+// identNode resolves a dotted field path (e.g. "dxMeta", "ADIF") against a
+// Spot.
+type identNode struct{ path []string }
+
+func (n identNode) eval(s *spot.Spot) (interface{}, error) {
+	return resolveIdent(s, n.path)
+}
+
+type unaryNode struct {
+	op   string // "not"
+	expr exprNode
+}
+
+func (n unaryNode) eval(s *spot.Spot) (interface{}, error) {
+	v, err := n.expr.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("filter: not operand is not boolean")
+	}
+	return !b, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n binaryNode) eval(s *spot.Spot) (interface{}, error) {
+	switch n.op {
+	case "and":
+		l, err := n.left.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		lb, _ := l.(bool)
+		if !lb {
+			return false, nil
+		}
+		r, err := n.right.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		rb, _ := r.(bool)
+		return rb, nil
+	case "or":
+		l, err := n.left.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		lb, _ := l.(bool)
+		if lb {
+			return true, nil
+		}
+		r, err := n.right.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		rb, _ := r.(bool)
+		return rb, nil
+	}
+
+	l, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return compare(n.op, l, r)
+}
+
+func compare(op string, l, r interface{}) (interface{}, error) {
+	if op == "==" || op == "!=" {
+		eq := valuesEqual(l, r)
+		if op == "!=" {
+			return !eq, nil
+		}
+		return eq, nil
+	}
+
+	lf, lok := asFloat(l)
+	rf, rok := asFloat(r)
+	if !lok || !rok {
+		return false, fmt.Errorf("filter: %s requires numeric operands", op)
+	}
+	switch op {
+	case ">":
+		return lf > rf, nil
+	case ">=":
+		return lf >= rf, nil
+	case "<":
+		return lf < rf, nil
+	case "<=":
+		return lf <= rf, nil
+	default:
+		return nil, fmt.Errorf("filter: unknown operator %q", op)
+	}
+}
+
+func valuesEqual(l, r interface{}) bool {
+	if lf, lok := asFloat(l); lok {
+		if rf, rok := asFloat(r); rok {
+			return lf == rf
+		}
+	}
+	ls, lok := l.(string)
+	rs, rok := r.(string)
+	if lok && rok {
+		return strings.EqualFold(ls, rs)
+	}
+	lb, lok := l.(bool)
+	rb, rok := r.(bool)
+	if lok && rok {
+		return lb == rb
+	}
+	return fmt.Sprint(l) == fmt.Sprint(r)
+}
+
+func asFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// resolveIdent resolves a dotted field path against s. Supported roots:
+// dxCall, deCall, freq, mode, band, report, comment, isHuman, sourceType,
+// dxMeta.*, deMeta.* (ADIF, CQZone, ITUZone, Continent, Country, Grid,
+// State, County, OperatorName).
+func resolveIdent(s *spot.Spot, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("filter: empty identifier")
+	}
+	switch path[0] {
+	case "dxCall":
+		return s.DXCall, nil
+	case "deCall":
+		return s.DECall, nil
+	case "freq":
+		return s.Frequency, nil
+	case "mode":
+		return s.Mode, nil
+	case "band":
+		return s.Band, nil
+	case "report":
+		return float64(s.Report), nil
+	case "comment":
+		return s.Comment, nil
+	case "isHuman":
+		return s.IsHuman, nil
+	case "sourceType":
+		return string(s.SourceType), nil
+	case "dxMeta":
+		return resolveMetadata(s.DXMetadata, path[1:])
+	case "deMeta":
+		return resolveMetadata(s.DEMetadata, path[1:])
+	default:
+		return nil, fmt.Errorf("filter: unknown identifier %q", path[0])
+	}
+}
+
+func resolveMetadata(m spot.CallMetadata, path []string) (interface{}, error) {
+	if len(path) != 1 {
+		return nil, fmt.Errorf("filter: metadata field required (e.g. dxMeta.ADIF)")
+	}
+	switch path[0] {
+	case "ADIF":
+		return float64(m.ADIF), nil
+	case "CQZone":
+		return float64(m.CQZone), nil
+	case "ITUZone":
+		return float64(m.ITUZone), nil
+	case "Continent":
+		return m.Continent, nil
+	case "Country":
+		return m.Country, nil
+	case "Grid":
+		return m.Grid, nil
+	case "State":
+		return m.State, nil
+	case "County":
+		return m.County, nil
+	case "OperatorName":
+		return m.OperatorName, nil
+	default:
+		return nil, fmt.Errorf("filter: unknown metadata field %q", path[0])
+	}
+}
+
+// CompileAlertRule parses one "when <expr> then <action>(<args>)" statement
+// and resolves its action via newAction. actionDeps wires in whatever the
+// named action needs (a forward channel, an HTTP client, ...).
+func CompileAlertRule(src string, deps ActionDeps) (*AlertRule, error) {
+	toks, err := lexAlertRule(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &alertParser{tokens: toks}
+
+	if !p.consumeKeyword("when") {
+		return nil, fmt.Errorf("filter: rule must start with %q: %s", "when", src)
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w: %s", err, src)
+	}
+	if !p.consumeKeyword("then") {
+		return nil, fmt.Errorf("filter: rule missing %q clause: %s", "then", src)
+	}
+	name, args, err := p.parseCall()
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w: %s", err, src)
+	}
+	if !p.atEnd() {
+		return nil, fmt.Errorf("filter: unexpected trailing input: %s", src)
+	}
+
+	action, err := newAction(name, args, deps)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w: %s", err, src)
+	}
+
+	return &AlertRule{Raw: src, expr: expr, action: action}, nil
+}
+
+// --- lexer ---
+
+type alertToken struct {
+	kind string // "ident", "number", "string", "op", "keyword", "punct"
+	text string
+	num  float64
+}
+
+func lexAlertRule(src string) ([]alertToken, error) {
+	var toks []alertToken
+	runes := []rune(src)
+	i := 0
+	for i < len(runes) {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '.':
+			toks = append(toks, alertToken{kind: "punct", text: string(c)})
+			i++
+		case c == '"':
+			j := i + 1
+			var sb strings.Builder
+			for j < len(runes) && runes[j] != '"' {
+				sb.WriteRune(runes[j])
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("filter: unterminated string literal in %q", src)
+			}
+			toks = append(toks, alertToken{kind: "string", text: sb.String()})
+			i = j + 1
+		case c == '=' && i+1 < len(runes) && runes[i+1] == '=':
+			toks = append(toks, alertToken{kind: "op", text: "=="})
+			i += 2
+		case c == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			toks = append(toks, alertToken{kind: "op", text: "!="})
+			i += 2
+		case c == '>' && i+1 < len(runes) && runes[i+1] == '=':
+			toks = append(toks, alertToken{kind: "op", text: ">="})
+			i += 2
+		case c == '<' && i+1 < len(runes) && runes[i+1] == '=':
+			toks = append(toks, alertToken{kind: "op", text: "<="})
+			i += 2
+		case c == '>' || c == '<':
+			toks = append(toks, alertToken{kind: "op", text: string(c)})
+			i++
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			n, err := strconv.ParseFloat(string(runes[i:j]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("filter: bad number %q in %q", string(runes[i:j]), src)
+			}
+			toks = append(toks, alertToken{kind: "number", num: n})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			word := string(runes[i:j])
+			switch strings.ToLower(word) {
+			case "when", "then", "and", "or", "not":
+				toks = append(toks, alertToken{kind: "keyword", text: strings.ToLower(word)})
+			default:
+				toks = append(toks, alertToken{kind: "ident", text: word})
+			}
+			i = j
+		default:
+			return nil, fmt.Errorf("filter: unexpected character %q in %q", c, src)
+		}
+	}
+	return toks, nil
+}
+
+// --- recursive-descent parser ---
+//
+// Grammar:
+//   rule   := "when" or "then" call
+//   or     := and ("or" and)*
+//   and    := unary ("and" unary)*
+//   unary  := "not" unary | cmp
+//   cmp    := primary (("==" | "!=" | ">" | ">=" | "<" | "<=") primary)?
+//   primary := ident ("." ident)* | number | string | "(" or ")"
+//   call   := ident "(" (string ("," string)*)? ")"
+
+type alertParser struct {
+	tokens []alertToken
+	pos    int
+}
+
+func (p *alertParser) atEnd() bool { return p.pos >= len(p.tokens) }
+
+func (p *alertParser) peek() (alertToken, bool) {
+	if p.atEnd() {
+		return alertToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *alertParser) consumeKeyword(kw string) bool {
+	t, ok := p.peek()
+	if !ok || t.kind != "keyword" || t.text != kw {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *alertParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *alertParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeKeyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *alertParser) parseUnary() (exprNode, error) {
+	if p.consumeKeyword("not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: "not", expr: inner}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *alertParser) parseComparison() (exprNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := p.peek()
+	if ok && t.kind == "op" {
+		p.pos++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{op: t.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *alertParser) parsePrimary() (exprNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	switch t.kind {
+	case "number":
+		p.pos++
+		return literalNode{value: t.num}, nil
+	case "string":
+		p.pos++
+		return literalNode{value: t.text}, nil
+	case "punct":
+		if t.text == "(" {
+			p.pos++
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if t, ok := p.peek(); !ok || t.kind != "punct" || t.text != ")" {
+				return nil, fmt.Errorf("expected closing paren")
+			}
+			p.pos++
+			return inner, nil
+		}
+		return nil, fmt.Errorf("unexpected token %q", t.text)
+	case "ident":
+		path := []string{t.text}
+		p.pos++
+		for {
+			if t, ok := p.peek(); ok && t.kind == "punct" && t.text == "." {
+				p.pos++
+				next, ok := p.peek()
+				if !ok || next.kind != "ident" {
+					return nil, fmt.Errorf("expected field name after '.'")
+				}
+				path = append(path, next.text)
+				p.pos++
+				continue
+			}
+			break
+		}
+		return identNode{path: path}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %q", t.text)
+	}
+}
+
+// parseCall parses `ident "(" (string ("," string)*)? ")"`, the shape
+// every built-in action takes (e.g. alert("new US on 20m")).
+func (p *alertParser) parseCall() (name string, args []string, err error) {
+	t, ok := p.peek()
+	if !ok || t.kind != "ident" {
+		return "", nil, fmt.Errorf("expected action name")
+	}
+	name = t.text
+	p.pos++
+
+	if t, ok := p.peek(); !ok || t.kind != "punct" || t.text != "(" {
+		return "", nil, fmt.Errorf("expected '(' after action name %q", name)
+	}
+	p.pos++
+
+	for {
+		t, ok := p.peek()
+		if !ok {
+			return "", nil, fmt.Errorf("unterminated argument list for %q", name)
+		}
+		if t.kind == "punct" && t.text == ")" {
+			p.pos++
+			break
+		}
+		if t.kind != "string" {
+			return "", nil, fmt.Errorf("expected string argument in %q(...)", name)
+		}
+		args = append(args, t.text)
+		p.pos++
+
+		t, ok = p.peek()
+		if ok && t.kind == "punct" && t.text == "," {
+			p.pos++
+			continue
+		}
+	}
+	return name, args, nil
+}
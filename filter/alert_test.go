@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"testing"
+
+	"dxcluster/spot"
+)
+
+func TestCompileAlertRuleTagMatch(t *testing.T) {
+	rule, err := CompileAlertRule(`when band == "20m" and mode == "CW" then tag("watch")`, ActionDeps{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	s := &spot.Spot{Band: "20m", Mode: "CW"}
+	if !rule.Matches(s) {
+		t.Fatalf("expected 20m CW spot to match")
+	}
+	keep, err := rule.action.Apply(s)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !keep {
+		t.Fatalf("expected tag action to keep the spot")
+	}
+	if len(s.Tags) != 1 || s.Tags[0] != "watch" {
+		t.Fatalf("expected Tags to contain \"watch\", got %v", s.Tags)
+	}
+}
+
+func TestCompileAlertRuleNoMatch(t *testing.T) {
+	rule, err := CompileAlertRule(`when band == "160m" then drop()`, ActionDeps{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	s := &spot.Spot{Band: "20m"}
+	if rule.Matches(s) {
+		t.Fatalf("expected 20m spot not to match a 160m-only rule")
+	}
+}
+
+func TestCompileAlertRuleDottedMetadataField(t *testing.T) {
+	rule, err := CompileAlertRule(`when dxMeta.Continent == "EU" then drop()`, ActionDeps{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	eu := &spot.Spot{DXMetadata: spot.CallMetadata{Continent: "EU"}}
+	if !rule.Matches(eu) {
+		t.Fatalf("expected EU dxMeta.Continent to match")
+	}
+	na := &spot.Spot{DXMetadata: spot.CallMetadata{Continent: "NA"}}
+	if rule.Matches(na) {
+		t.Fatalf("expected NA dxMeta.Continent not to match")
+	}
+}
+
+func TestCompileAlertRuleNumericComparisonAndOr(t *testing.T) {
+	rule, err := CompileAlertRule(`when freq > 14000 and freq < 14350 or band == "40m" then tag("hf")`, ActionDeps{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	in20m := &spot.Spot{Frequency: 14200}
+	if !rule.Matches(in20m) {
+		t.Fatalf("expected 14200 kHz to match the 20m range")
+	}
+	on40m := &spot.Spot{Frequency: 7030, Band: "40m"}
+	if !rule.Matches(on40m) {
+		t.Fatalf("expected 40m band fallback to match")
+	}
+	neither := &spot.Spot{Frequency: 21000, Band: "15m"}
+	if rule.Matches(neither) {
+		t.Fatalf("expected neither condition to match")
+	}
+}
+
+func TestCompileAlertRuleNotAndParens(t *testing.T) {
+	rule, err := CompileAlertRule(`when not (mode == "RTTY") then tag("nondigital")`, ActionDeps{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if !rule.Matches(&spot.Spot{Mode: "CW"}) {
+		t.Fatalf("expected CW to match \"not RTTY\"")
+	}
+	if rule.Matches(&spot.Spot{Mode: "RTTY"}) {
+		t.Fatalf("expected RTTY not to match \"not RTTY\"")
+	}
+}
+
+func TestCompileAlertRuleRejectsMalformedSource(t *testing.T) {
+	cases := []string{
+		`band == "20m" then tag("x")`,
+		`when band == "20m" tag("x")`,
+		`when band == then tag("x")`,
+		`when band == "20m" then tag()`,
+		`when band == "20m" then unknownaction("x")`,
+	}
+	for _, src := range cases {
+		if _, err := CompileAlertRule(src, ActionDeps{}); err == nil {
+			t.Fatalf("expected compile error for %q", src)
+		}
+	}
+}
+
+func TestAlertEngineAppliesEveryMatchingRule(t *testing.T) {
+	e, err := NewAlertEngine([]string{
+		`when band == "160m" then drop()`,
+		`when band == "160m" then tag("suppressed")`,
+	}, ActionDeps{})
+	if err != nil {
+		t.Fatalf("compile engine: %v", err)
+	}
+	s := &spot.Spot{Band: "160m"}
+	if e.Apply(s) {
+		t.Fatalf("expected the drop rule to report the spot should not ship")
+	}
+	if len(s.Tags) != 1 || s.Tags[0] != "suppressed" {
+		t.Fatalf("expected the tag rule to still run after the drop rule, got %v", s.Tags)
+	}
+}
+
+func TestAlertEngineNilIsNoop(t *testing.T) {
+	var e *AlertEngine
+	if !e.Apply(&spot.Spot{}) {
+		t.Fatalf("expected a nil AlertEngine to keep every spot")
+	}
+}
+
+func TestNewAlertEngineFromConfigEmptyRulesKeepsEverything(t *testing.T) {
+	e, err := NewAlertEngineFromConfig(AlertConfig{}, ActionDeps{})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if !e.Apply(&spot.Spot{}) {
+		t.Fatalf("expected an empty rule set to keep every spot")
+	}
+}
@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleConfig mirrors the YAML schema for one Rule.
+type RuleConfig struct {
+	Role        string                  `yaml:"role"`
+	Priority    int                     `yaml:"priority"`
+	Match       string                  `yaml:"match,omitempty"`
+	Constraints []LabelConstraintConfig `yaml:"constraints"`
+}
+
+// LabelConstraintConfig mirrors the YAML schema for one LabelConstraint.
+type LabelConstraintConfig struct {
+	Key    string   `yaml:"key"`
+	Op     string   `yaml:"op"`
+	Values []string `yaml:"values"`
+}
+
+// Config mirrors the YAML schema for a Filter: the legacy per-dimension
+// whitelists plus the newer priority-ordered Rules.
+type Config struct {
+	DXContinents []string     `yaml:"dx_continents,omitempty"`
+	DEContinents []string     `yaml:"de_continents,omitempty"`
+	DXZones      []int        `yaml:"dx_zones,omitempty"`
+	DEZones      []int        `yaml:"de_zones,omitempty"`
+	DXGrid2      []string     `yaml:"dx_grid2,omitempty"`
+	DEGrid2      []string     `yaml:"de_grid2,omitempty"`
+	Rules        []RuleConfig `yaml:"rules,omitempty"`
+}
+
+// ToConfig serializes f into its YAML-friendly form.
+func (f *Filter) ToConfig() Config {
+	cfg := Config{
+		DXContinents: sortedStringKeys(f.DXContinents),
+		DEContinents: sortedStringKeys(f.DEContinents),
+		DXZones:      sortedIntKeys(f.DXZones),
+		DEZones:      sortedIntKeys(f.DEZones),
+		DXGrid2:      sortedStringKeys(f.DXGrid2),
+		DEGrid2:      sortedStringKeys(f.DEGrid2),
+	}
+	for _, r := range f.Rules {
+		cfg.Rules = append(cfg.Rules, ruleToConfig(r))
+	}
+	return cfg
+}
+
+// FromConfig builds a Filter from its YAML-friendly form, restoring
+// permissive defaults for any dimension the config leaves empty.
+func FromConfig(cfg Config) *Filter {
+	f := NewFilter()
+	for _, c := range cfg.DXContinents {
+		f.SetDXContinent(c, true)
+	}
+	for _, c := range cfg.DEContinents {
+		f.SetDEContinent(c, true)
+	}
+	for _, z := range cfg.DXZones {
+		f.SetDXZone(z, true)
+	}
+	for _, z := range cfg.DEZones {
+		f.SetDEZone(z, true)
+	}
+	for _, g := range cfg.DXGrid2 {
+		f.SetDXGrid2Prefix(g, true)
+	}
+	for _, g := range cfg.DEGrid2 {
+		f.SetDEGrid2Prefix(g, true)
+	}
+	for _, rc := range cfg.Rules {
+		f.Rules = append(f.Rules, ruleFromConfig(rc))
+	}
+	return f
+}
+
+// MarshalYAML lets a Filter serialize directly via yaml.Marshal.
+func (f *Filter) MarshalYAML() (interface{}, error) {
+	return f.ToConfig(), nil
+}
+
+// UnmarshalYAML lets a Filter populate directly via yaml.Unmarshal.
+func (f *Filter) UnmarshalYAML(value *yaml.Node) error {
+	var cfg Config
+	if err := value.Decode(&cfg); err != nil {
+		return err
+	}
+	*f = *FromConfig(cfg)
+	return nil
+}
+
+func ruleToConfig(r Rule) RuleConfig {
+	rc := RuleConfig{
+		Role:     string(r.Role),
+		Priority: r.Priority,
+		Match:    string(r.Match),
+	}
+	for _, c := range r.Constraints {
+		rc.Constraints = append(rc.Constraints, LabelConstraintConfig{
+			Key:    c.Key,
+			Op:     string(c.Op),
+			Values: c.Values,
+		})
+	}
+	return rc
+}
+
+func ruleFromConfig(rc RuleConfig) Rule {
+	r := Rule{
+		Role:     Role(rc.Role),
+		Priority: rc.Priority,
+		Match:    MatchMode(rc.Match),
+	}
+	for _, c := range rc.Constraints {
+		r.Constraints = append(r.Constraints, LabelConstraint{
+			Key:    c.Key,
+			Op:     ConstraintOp(c.Op),
+			Values: c.Values,
+		})
+	}
+	return r
+}
+
+func sortedStringKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedIntKeys(m map[int]bool) []int {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]int, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,212 @@
+// Package filter decides which spots get forwarded downstream based on
+// operator-configured DX/DE continent, CQ zone, and grid-square
+// preferences, plus an optional priority-ordered rule list for expressing
+// constraints the flat per-dimension whitelists can't (e.g. "accept EU->NA
+// on CW but reject EU->EU except from zone 14").
+package filter
+
+import (
+	"strconv"
+	"strings"
+
+	"dxcluster/spot"
+)
+
+// Filter holds the active whitelist for each spot dimension. A dimension
+// with its All* flag set matches every spot; otherwise only values present
+// in the corresponding map match.
+type Filter struct {
+	AllDXContinents bool
+	AllDEContinents bool
+	DXContinents    map[string]bool
+	DEContinents    map[string]bool
+
+	AllDXZones bool
+	AllDEZones bool
+	DXZones    map[int]bool
+	DEZones    map[int]bool
+
+	AllDXGrid2 bool
+	AllDEGrid2 bool
+	DXGrid2    map[string]bool
+	DEGrid2    map[string]bool
+
+	// Rules, if non-empty, are evaluated by descending Priority before the
+	// legacy per-dimension whitelists above. The first rule whose
+	// constraints match decides the outcome; if none match, Matches falls
+	// back to the legacy whitelists.
+	Rules []Rule
+}
+
+// NewFilter returns a Filter that allows every spot until dimensions are
+// restricted via the Set* methods.
+func NewFilter() *Filter {
+	f := &Filter{
+		DXContinents: make(map[string]bool),
+		DEContinents: make(map[string]bool),
+		DXZones:      make(map[int]bool),
+		DEZones:      make(map[int]bool),
+		DXGrid2:      make(map[string]bool),
+		DEGrid2:      make(map[string]bool),
+	}
+	f.normalizeDefaults()
+	return f
+}
+
+// normalizeDefaults restores the permissive All* flags for any dimension
+// whose whitelist is empty. It makes a zero-value Filter (e.g. one
+// populated by unmarshaling a config that omits the filter section) behave
+// the same as NewFilter.
+func (f *Filter) normalizeDefaults() {
+	if len(f.DXContinents) == 0 {
+		f.AllDXContinents = true
+	}
+	if len(f.DEContinents) == 0 {
+		f.AllDEContinents = true
+	}
+	if len(f.DXZones) == 0 {
+		f.AllDXZones = true
+	}
+	if len(f.DEZones) == 0 {
+		f.AllDEZones = true
+	}
+	if len(f.DXGrid2) == 0 {
+		f.AllDXGrid2 = true
+	}
+	if len(f.DEGrid2) == 0 {
+		f.AllDEGrid2 = true
+	}
+}
+
+// SetDXContinent adds or removes continent from the DX continent
+// whitelist, switching AllDXContinents off while the whitelist is non-empty
+// and back on once it's emptied.
+func (f *Filter) SetDXContinent(continent string, enabled bool) {
+	setStringEntry(f.DXContinents, &f.AllDXContinents, continent, enabled)
+}
+
+// SetDEContinent mirrors SetDXContinent for the DE (spotter) continent.
+func (f *Filter) SetDEContinent(continent string, enabled bool) {
+	setStringEntry(f.DEContinents, &f.AllDEContinents, continent, enabled)
+}
+
+// SetDXZone adds or removes zone from the DX CQ zone whitelist.
+func (f *Filter) SetDXZone(zone int, enabled bool) {
+	setIntEntry(f.DXZones, &f.AllDXZones, zone, enabled)
+}
+
+// SetDEZone mirrors SetDXZone for the DE (spotter) CQ zone.
+func (f *Filter) SetDEZone(zone int, enabled bool) {
+	setIntEntry(f.DEZones, &f.AllDEZones, zone, enabled)
+}
+
+// SetDXGrid2Prefix adds or removes a 2-character grid-square prefix from
+// the DX grid whitelist. Longer grids are truncated to their first 2
+// characters since that's the resolution the whitelist operates at.
+func (f *Filter) SetDXGrid2Prefix(grid string, enabled bool) {
+	setStringEntry(f.DXGrid2, &f.AllDXGrid2, grid2Prefix(grid), enabled)
+}
+
+// SetDEGrid2Prefix mirrors SetDXGrid2Prefix for the DE (spotter) grid.
+func (f *Filter) SetDEGrid2Prefix(grid string, enabled bool) {
+	setStringEntry(f.DEGrid2, &f.AllDEGrid2, grid2Prefix(grid), enabled)
+}
+
+func setStringEntry(m map[string]bool, all *bool, key string, enabled bool) {
+	if enabled {
+		m[key] = true
+		*all = false
+		return
+	}
+	delete(m, key)
+	if len(m) == 0 {
+		*all = true
+	}
+}
+
+func setIntEntry(m map[int]bool, all *bool, key int, enabled bool) {
+	if enabled {
+		m[key] = true
+		*all = false
+		return
+	}
+	delete(m, key)
+	if len(m) == 0 {
+		*all = true
+	}
+}
+
+func grid2Prefix(grid string) string {
+	grid = strings.ToUpper(grid)
+	if len(grid) > 2 {
+		grid = grid[:2]
+	}
+	return grid
+}
+
+// Matches reports whether s should be forwarded. If Rules is non-empty,
+// the highest-priority matching rule decides the outcome; otherwise (or if
+// no rule matches) the legacy per-dimension whitelists apply.
+func (f *Filter) Matches(s *spot.Spot) bool {
+	if len(f.Rules) > 0 {
+		if rule, ok := firstMatchingRule(f.Rules, s); ok {
+			return rule.Role == RoleInclude
+		}
+	}
+	return f.matchesLegacy(s)
+}
+
+func (f *Filter) matchesLegacy(s *spot.Spot) bool {
+	if !f.AllDXContinents && !f.DXContinents[s.DXMetadata.Continent] {
+		return false
+	}
+	if !f.AllDEContinents && !f.DEContinents[s.DEMetadata.Continent] {
+		return false
+	}
+	if !f.AllDXZones && !f.DXZones[s.DXMetadata.CQZone] {
+		return false
+	}
+	if !f.AllDEZones && !f.DEZones[s.DEMetadata.CQZone] {
+		return false
+	}
+	if !f.AllDXGrid2 && !f.DXGrid2[grid2Prefix(s.DXMetadata.Grid)] {
+		return false
+	}
+	if !f.AllDEGrid2 && !f.DEGrid2[grid2Prefix(s.DEMetadata.Grid)] {
+		return false
+	}
+	return true
+}
+
+// labelValue resolves a LabelConstraint.Key against s. ok is false when the
+// spot has no value for that key, in which case In/Equals/PrefixIn
+// constraints never match and NotIn always does.
+func labelValue(s *spot.Spot, key string) (value string, ok bool) {
+	switch key {
+	case "dx.continent":
+		return s.DXMetadata.Continent, s.DXMetadata.Continent != ""
+	case "de.continent":
+		return s.DEMetadata.Continent, s.DEMetadata.Continent != ""
+	case "dx.cqzone":
+		return strconv.Itoa(s.DXMetadata.CQZone), s.DXMetadata.CQZone != 0
+	case "de.cqzone":
+		return strconv.Itoa(s.DEMetadata.CQZone), s.DEMetadata.CQZone != 0
+	case "dx.grid2":
+		v := grid2Prefix(s.DXMetadata.Grid)
+		return v, v != ""
+	case "de.grid2":
+		v := grid2Prefix(s.DEMetadata.Grid)
+		return v, v != ""
+	case "mode":
+		return s.Mode, s.Mode != ""
+	case "band":
+		return s.Band, s.Band != ""
+	case "dx.dxcc":
+		// ADIF country code doubles as the DXCC entity id in this codebase.
+		return strconv.Itoa(s.DXMetadata.ADIF), s.DXMetadata.ADIF != 0
+	case "de.dxcc":
+		return strconv.Itoa(s.DEMetadata.ADIF), s.DEMetadata.ADIF != 0
+	default:
+		return "", false
+	}
+}
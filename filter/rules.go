@@ -0,0 +1,145 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"dxcluster/spot"
+)
+
+// Role is the outcome a Rule applies once its constraints match.
+type Role string
+
+const (
+	RoleInclude Role = "include"
+	RoleExclude Role = "exclude"
+)
+
+// ConstraintOp is the comparison a LabelConstraint applies between a
+// spot's resolved label value and its Values list.
+type ConstraintOp string
+
+const (
+	OpIn       ConstraintOp = "in"
+	OpNotIn    ConstraintOp = "not_in"
+	OpEquals   ConstraintOp = "equals"
+	OpPrefixIn ConstraintOp = "prefix_in"
+)
+
+// MatchMode controls how a Rule's Constraints combine.
+type MatchMode string
+
+const (
+	MatchAllOf MatchMode = "all_of"
+	MatchAnyOf MatchMode = "any_of"
+)
+
+// LabelConstraint compares one spot-derived label against a set of values.
+// Key is one of: dx.continent, de.continent, dx.cqzone, de.cqzone,
+// dx.grid2, de.grid2, mode, band, dx.dxcc, de.dxcc.
+type LabelConstraint struct {
+	Key    string
+	Op     ConstraintOp
+	Values []string
+}
+
+// Rule is one entry in a Filter's priority-ordered constraint list. Rules
+// are evaluated highest Priority first; the first whose Constraints match
+// decides Matches' outcome via Role.
+type Rule struct {
+	Role        Role
+	Priority    int
+	Constraints []LabelConstraint
+	Match       MatchMode
+}
+
+// Matches reports whether every (Match == MatchAllOf, the default) or any
+// (Match == MatchAnyOf) of r's constraints match s. A rule with no
+// constraints never matches.
+func (r Rule) Matches(s *spot.Spot) bool {
+	if len(r.Constraints) == 0 {
+		return false
+	}
+	if r.Match == MatchAnyOf {
+		for _, c := range r.Constraints {
+			if c.matches(s) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range r.Constraints {
+		if !c.matches(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Explain returns a human-readable description of the first constraint in
+// r that matches s, or "" if r doesn't match. It's meant for surfacing why
+// a rule fired in the decision log, not as a full audit of every
+// constraint.
+func (r Rule) Explain(s *spot.Spot) string {
+	if !r.Matches(s) {
+		return ""
+	}
+	for _, c := range r.Constraints {
+		if c.matches(s) {
+			value, _ := labelValue(s, c.Key)
+			return fmt.Sprintf("%s %s %s (spot value %q)", c.Key, c.Op, strings.Join(c.Values, ","), value)
+		}
+	}
+	return ""
+}
+
+func (c LabelConstraint) matches(s *spot.Spot) bool {
+	value, ok := labelValue(s, c.Key)
+	switch c.Op {
+	case OpIn:
+		return ok && containsFold(c.Values, value)
+	case OpNotIn:
+		return !ok || !containsFold(c.Values, value)
+	case OpEquals:
+		return ok && len(c.Values) == 1 && strings.EqualFold(c.Values[0], value)
+	case OpPrefixIn:
+		return ok && prefixInFold(c.Values, value)
+	default:
+		return false
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func prefixInFold(prefixes []string, value string) bool {
+	for _, p := range prefixes {
+		if len(value) >= len(p) && strings.EqualFold(value[:len(p)], p) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstMatchingRule walks rules by descending Priority and returns the
+// first one matching s.
+func firstMatchingRule(rules []Rule, s *spot.Spot) (Rule, bool) {
+	sorted := make([]Rule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	for _, r := range sorted {
+		if r.Matches(s) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
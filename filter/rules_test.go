@@ -0,0 +1,169 @@
+package filter
+
+import (
+	"testing"
+
+	"dxcluster/spot"
+)
+
+func euToNaCW() *spot.Spot {
+	return &spot.Spot{
+		Mode: "CW",
+		Band: "20m",
+		DXMetadata: spot.CallMetadata{
+			Continent: "EU",
+			CQZone:    14,
+		},
+		DEMetadata: spot.CallMetadata{
+			Continent: "NA",
+			CQZone:    5,
+		},
+	}
+}
+
+func euToEuZone14() *spot.Spot {
+	return &spot.Spot{
+		Mode: "CW",
+		Band: "20m",
+		DXMetadata: spot.CallMetadata{
+			Continent: "EU",
+			CQZone:    14,
+		},
+		DEMetadata: spot.CallMetadata{
+			Continent: "EU",
+			CQZone:    14,
+		},
+	}
+}
+
+func euToEuZone20() *spot.Spot {
+	return &spot.Spot{
+		Mode: "CW",
+		Band: "20m",
+		DXMetadata: spot.CallMetadata{
+			Continent: "EU",
+			CQZone:    20,
+		},
+		DEMetadata: spot.CallMetadata{
+			Continent: "EU",
+			CQZone:    20,
+		},
+	}
+}
+
+// TestRulesOverrideLegacyWhitelist exercises the example from the request:
+// accept EU->NA on CW but reject EU->EU except from zone 14.
+func TestRulesOverrideLegacyWhitelist(t *testing.T) {
+	f := NewFilter()
+	f.Rules = []Rule{
+		{
+			Role:     RoleInclude,
+			Priority: 20,
+			Match:    MatchAllOf,
+			Constraints: []LabelConstraint{
+				{Key: "de.cqzone", Op: OpIn, Values: []string{"14"}},
+			},
+		},
+		{
+			Role:     RoleExclude,
+			Priority: 10,
+			Match:    MatchAllOf,
+			Constraints: []LabelConstraint{
+				{Key: "dx.continent", Op: OpEquals, Values: []string{"EU"}},
+				{Key: "de.continent", Op: OpEquals, Values: []string{"EU"}},
+			},
+		},
+	}
+
+	if !f.Matches(euToNaCW()) {
+		t.Fatalf("expected EU->NA to fall through to the permissive legacy default")
+	}
+	if !f.Matches(euToEuZone14()) {
+		t.Fatalf("expected EU->EU from zone 14 to be included by the higher-priority rule")
+	}
+	if f.Matches(euToEuZone20()) {
+		t.Fatalf("expected EU->EU from zone 20 to be excluded")
+	}
+}
+
+func TestRuleMatchAnyOf(t *testing.T) {
+	r := Rule{
+		Role:  RoleExclude,
+		Match: MatchAnyOf,
+		Constraints: []LabelConstraint{
+			{Key: "mode", Op: OpEquals, Values: []string{"RTTY"}},
+			{Key: "band", Op: OpEquals, Values: []string{"160m"}},
+		},
+	}
+	rtty := &spot.Spot{Mode: "RTTY", Band: "20m"}
+	if !r.Matches(rtty) {
+		t.Fatalf("expected RTTY to match an AnyOf rule on mode")
+	}
+	topband := &spot.Spot{Mode: "CW", Band: "160m"}
+	if !r.Matches(topband) {
+		t.Fatalf("expected 160m to match an AnyOf rule on band")
+	}
+	neither := &spot.Spot{Mode: "CW", Band: "20m"}
+	if r.Matches(neither) {
+		t.Fatalf("expected no match when neither AnyOf constraint is satisfied")
+	}
+}
+
+func TestLabelConstraintNotInAllowsMissingValue(t *testing.T) {
+	c := LabelConstraint{Key: "dx.grid2", Op: OpNotIn, Values: []string{"FN"}}
+	s := &spot.Spot{}
+	if !c.matches(s) {
+		t.Fatalf("expected not_in to match when the spot has no grid at all")
+	}
+}
+
+func TestLabelConstraintPrefixIn(t *testing.T) {
+	c := LabelConstraint{Key: "dx.grid2", Op: OpPrefixIn, Values: []string{"F"}}
+	s := &spot.Spot{DXMetadata: spot.CallMetadata{Grid: "FN"}}
+	if !c.matches(s) {
+		t.Fatalf("expected FN grid to match prefix_in [F]")
+	}
+}
+
+func TestRuleExplainDescribesMatchingConstraint(t *testing.T) {
+	r := Rule{
+		Role: RoleExclude,
+		Constraints: []LabelConstraint{
+			{Key: "dx.continent", Op: OpEquals, Values: []string{"EU"}},
+		},
+	}
+	explanation := r.Explain(euToEuZone20())
+	if explanation == "" {
+		t.Fatalf("expected a non-empty explanation for a matching rule")
+	}
+}
+
+func TestFilterConfigRoundTrip(t *testing.T) {
+	f := NewFilter()
+	f.SetDXContinent("EU", true)
+	f.SetDXZone(14, true)
+	f.Rules = []Rule{
+		{
+			Role:     RoleInclude,
+			Priority: 5,
+			Match:    MatchAllOf,
+			Constraints: []LabelConstraint{
+				{Key: "mode", Op: OpIn, Values: []string{"FT8", "FT4"}},
+			},
+		},
+	}
+
+	restored := FromConfig(f.ToConfig())
+	if restored.AllDXContinents {
+		t.Fatalf("expected the DX continent whitelist to survive the round trip")
+	}
+	if !restored.DXContinents["EU"] {
+		t.Fatalf("expected EU to remain whitelisted after the round trip")
+	}
+	if !restored.DXZones[14] {
+		t.Fatalf("expected zone 14 to remain whitelisted after the round trip")
+	}
+	if len(restored.Rules) != 1 || restored.Rules[0].Priority != 5 {
+		t.Fatalf("expected the rule list to survive the round trip, got %+v", restored.Rules)
+	}
+}
@@ -0,0 +1,58 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// collector surfaces this package's own counters: what it has ingested and
+// how its SSE subscribers are keeping up. It deliberately doesn't duplicate
+// the daemon's per-line parse/reject/skew counters (see Config.MetricsHandler) -
+// a spot rejected upstream (unlicensed DE, etc.) never reaches this
+// package's input channel, so there's nothing here to count it against.
+type collector struct {
+	s *Server
+
+	receivedDesc   *prometheus.Desc
+	bufferDesc     *prometheus.Desc
+	subscriberDesc *prometheus.Desc
+	sseDroppedDesc *prometheus.Desc
+}
+
+func newCollector(s *Server) *collector {
+	return &collector{
+		s:              s,
+		receivedDesc:   prometheus.NewDesc("dxcluster_httpserver_spots_received_total", "Spots ingested from the upstream spot channel.", nil, nil),
+		bufferDesc:     prometheus.NewDesc("dxcluster_httpserver_buffer_spots", "Spots currently held in the ring buffer.", nil, nil),
+		subscriberDesc: prometheus.NewDesc("dxcluster_httpserver_sse_subscribers", "Currently connected /spots/stream clients.", nil, nil),
+		sseDroppedDesc: prometheus.NewDesc("dxcluster_httpserver_sse_dropped_total", "Spots dropped for a slow SSE subscriber instead of blocking ingestion.", nil, nil),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.receivedDesc
+	ch <- c.bufferDesc
+	ch <- c.subscriberDesc
+	ch <- c.sseDroppedDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.s.subMu.RLock()
+	subscribers := len(c.s.subs)
+	c.s.subMu.RUnlock()
+
+	ch <- prometheus.MustNewConstMetric(c.receivedDesc, prometheus.CounterValue, float64(c.s.received.Load()))
+	ch <- prometheus.MustNewConstMetric(c.bufferDesc, prometheus.GaugeValue, float64(c.s.buf.Len()))
+	ch <- prometheus.MustNewConstMetric(c.subscriberDesc, prometheus.GaugeValue, float64(subscribers))
+	ch <- prometheus.MustNewConstMetric(c.sseDroppedDesc, prometheus.CounterValue, float64(c.s.sseDropped.Load()))
+}
+
+// defaultMetricsHandler builds the /metrics handler used when
+// Config.MetricsHandler isn't set.
+func defaultMetricsHandler(s *Server) http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(newCollector(s))
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,119 @@
+package httpserver
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+
+	"dxcluster/spot"
+)
+
+// dashboardHTML is the homepage: a recent-spots table, a top-spotters table,
+// and a per-band activity table (counts rather than a rendered graphic -
+// this package has no charting dependency, and a sortable count table gets
+// an operator the same "where's the activity" answer at a glance).
+const dashboardHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>GoCluster</title>
+<style>
+body { font-family: monospace; margin: 2em; background: #111; color: #ddd; }
+h2 { margin-top: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.25em 0.75em; border-bottom: 1px solid #333; }
+th { color: #8cf; }
+</style>
+</head>
+<body>
+<h1>GoCluster</h1>
+<p>{{.SpotCount}} spots in buffer</p>
+
+<h2>Recent spots</h2>
+<table>
+<tr><th>Time</th><th>DX</th><th>Freq</th><th>Mode</th><th>Band</th><th>De</th></tr>
+{{range .RecentSpots}}
+<tr><td>{{.Time.Format "15:04:05"}}</td><td>{{.DXCall}}</td><td>{{printf "%.1f" .Frequency}}</td><td>{{.Mode}}</td><td>{{.Band}}</td><td>{{.DECall}}</td></tr>
+{{end}}
+</table>
+
+<h2>Top spotters</h2>
+<table>
+<tr><th>Call</th><th>Spots</th></tr>
+{{range .TopSpotters}}
+<tr><td>{{.Call}}</td><td>{{.Spots}}</td></tr>
+{{end}}
+</table>
+
+<h2>Band activity</h2>
+<table>
+<tr><th>Band</th><th>Spots</th></tr>
+{{range .BandActivity}}
+<tr><td>{{.Band}}</td><td>{{.Spots}}</td></tr>
+{{end}}
+</table>
+</body>
+</html>
+`
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(dashboardHTML))
+
+// bandActivity is one row of the band-activity table.
+type bandActivity struct {
+	Band  string
+	Spots int
+}
+
+type dashboardData struct {
+	SpotCount    int
+	RecentSpots  []*spot.Spot
+	TopSpotters  []spotterCount
+	BandActivity []bandActivity
+}
+
+const dashboardRecentLimit = 50
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	all := s.buf.Snapshot()
+
+	bandCounts := make(map[string]int)
+	for _, sp := range all {
+		bandCounts[sp.Band]++
+	}
+
+	topSpotters := spotterLeaderboard(all)
+	if len(topSpotters) > 20 {
+		topSpotters = topSpotters[:20]
+	}
+
+	bandRows := make([]bandActivity, 0, len(bandCounts))
+	for band, n := range bandCounts {
+		bandRows = append(bandRows, bandActivity{Band: band, Spots: n})
+	}
+	sort.Slice(bandRows, func(i, j int) bool {
+		if bandRows[i].Spots != bandRows[j].Spots {
+			return bandRows[i].Spots > bandRows[j].Spots
+		}
+		return bandRows[i].Band < bandRows[j].Band
+	})
+
+	recent := all
+	if len(recent) > dashboardRecentLimit {
+		recent = recent[:dashboardRecentLimit]
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, dashboardData{
+		SpotCount:    len(all),
+		RecentSpots:  recent,
+		TopSpotters:  topSpotters,
+		BandActivity: bandRows,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
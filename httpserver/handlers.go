@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dxcluster/spot"
+)
+
+func (s *Server) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/", s.handleDashboard)
+	mux.HandleFunc("/spots", s.handleSpots)
+	mux.HandleFunc("/spots/count", s.handleSpotsCount)
+	mux.HandleFunc("/spotters", s.handleSpotters)
+	mux.HandleFunc("/spots/stream", s.handleStream)
+	mux.HandleFunc("/skew", s.handleSkew)
+
+	metricsHandler := s.cfg.MetricsHandler
+	if metricsHandler == nil {
+		metricsHandler = defaultMetricsHandler(s)
+	}
+	mux.Handle("/metrics", metricsHandler)
+}
+
+// spotFilter narrows the ring buffer down to what a caller asked for via
+// query params. A zero-value field (empty string, zero zone, nil pointers)
+// means "don't filter on this".
+type spotFilter struct {
+	band       string
+	mode       string
+	continent  string
+	cqZone     int
+	isHuman    *bool
+	sourceType string
+}
+
+func parseSpotFilter(r *http.Request) spotFilter {
+	q := r.URL.Query()
+	f := spotFilter{
+		band:       strings.ToUpper(strings.TrimSpace(q.Get("band"))),
+		mode:       strings.ToUpper(strings.TrimSpace(q.Get("mode"))),
+		continent:  strings.ToUpper(strings.TrimSpace(q.Get("continent"))),
+		sourceType: strings.ToUpper(strings.TrimSpace(q.Get("source_type"))),
+	}
+	if zone, err := strconv.Atoi(q.Get("cq_zone")); err == nil {
+		f.cqZone = zone
+	}
+	if raw := q.Get("is_human"); raw != "" {
+		if parsed, err := strconv.ParseBool(raw); err == nil {
+			f.isHuman = &parsed
+		}
+	}
+	return f
+}
+
+func (f spotFilter) matches(sp *spot.Spot) bool {
+	if f.band != "" && strings.ToUpper(sp.Band) != f.band {
+		return false
+	}
+	if f.mode != "" && strings.ToUpper(sp.Mode) != f.mode {
+		return false
+	}
+	if f.continent != "" && strings.ToUpper(sp.DXMetadata.Continent) != f.continent {
+		return false
+	}
+	if f.cqZone != 0 && sp.DXMetadata.CQZone != f.cqZone {
+		return false
+	}
+	if f.isHuman != nil && sp.IsHuman != *f.isHuman {
+		return false
+	}
+	if f.sourceType != "" && strings.ToUpper(string(sp.SourceType)) != f.sourceType {
+		return false
+	}
+	return true
+}
+
+func (s *Server) filteredSpots(r *http.Request) []*spot.Spot {
+	f := parseSpotFilter(r)
+	all := s.buf.Snapshot()
+	out := make([]*spot.Spot, 0, len(all))
+	for _, sp := range all {
+		if f.matches(sp) {
+			out = append(out, sp)
+		}
+	}
+	return out
+}
+
+func (s *Server) handleSpots(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.filteredSpots(r))
+}
+
+func (s *Server) handleSpotsCount(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, struct {
+		Count int `json:"count"`
+	}{Count: len(s.filteredSpots(r))})
+}
+
+// spotterCount is one row of the /spotters leaderboard.
+type spotterCount struct {
+	Call  string `json:"call"`
+	Spots int    `json:"spots"`
+}
+
+func (s *Server) handleSpotters(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, spotterLeaderboard(s.filteredSpots(r)))
+}
+
+// spotterLeaderboard counts spots per DE call and sorts the result by spot
+// count descending, breaking ties by call sign so the order is stable
+// across calls instead of following Go's randomized map iteration.
+func spotterLeaderboard(spots []*spot.Spot) []spotterCount {
+	counts := make(map[string]int)
+	for _, sp := range spots {
+		counts[sp.DECall]++
+	}
+	rows := make([]spotterCount, 0, len(counts))
+	for call, n := range counts {
+		rows = append(rows, spotterCount{Call: call, Spots: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Spots != rows[j].Spots {
+			return rows[i].Spots > rows[j].Spots
+		}
+		return rows[i].Call < rows[j].Call
+	})
+	return rows
+}
+
+// handleSkew serves the latest online per-skimmer skew state published to
+// s.cfg.SkewStore (see skew.Estimator.PublishTo) - an empty array when no
+// store is configured or none has been published yet.
+func (s *Server) handleSkew(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.cfg.SkewStore.OnlineSnapshot())
+}
+
+// handleStream serves /spots/stream as Server-Sent Events: one "data: {...}"
+// line per spot, forwarded as it's ingested. SSE rather than a websocket
+// because every browser and curl already speaks it with no handshake, and
+// this is a one-way feed - there's nothing a dashboard client needs to send
+// back.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	f := parseSpotFilter(r)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan *spot.Spot, 16)
+	s.subscribe(ch)
+	defer s.unsubscribe(ch)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		case sp := <-ch:
+			if !f.matches(sp) {
+				continue
+			}
+			body, err := json.Marshal(sp)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", body)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
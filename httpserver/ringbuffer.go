@@ -0,0 +1,73 @@
+package httpserver
+
+import (
+	"sync"
+
+	"dxcluster/spot"
+)
+
+// ringBuffer holds the most recent spots added, up to capacity, overwriting
+// the oldest once full - the bounded "last N spots" store the /spots,
+// /spotters, and dashboard handlers all read from.
+//
+// This is deliberately its own type rather than a reuse of
+// dxcluster/buffer.RingBuffer (the byte-oriented buffer main.go already
+// feeds via buf.Add for the telnet SH/DX backlog, tracked by
+// GetPosition/GetCount/GetSizeKB): that buffer's visible API has no way to
+// get back a filterable slice of structured *spot.Spot values, which is
+// exactly what every handler in this package needs.
+type ringBuffer struct {
+	mu       sync.RWMutex
+	entries  []*spot.Spot
+	next     int
+	full     bool
+	capacity int
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{
+		entries:  make([]*spot.Spot, capacity),
+		capacity: capacity,
+	}
+}
+
+func (r *ringBuffer) Add(s *spot.Spot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = s
+	r.next = (r.next + 1) % r.capacity
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Len returns how many spots are currently held, without the copy
+// Snapshot's full slice build does.
+func (r *ringBuffer) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.full {
+		return r.capacity
+	}
+	return r.next
+}
+
+// Snapshot returns every held spot, most recently added first.
+func (r *ringBuffer) Snapshot() []*spot.Spot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := r.next
+	if r.full {
+		n = r.capacity
+	}
+	out := make([]*spot.Spot, 0, n)
+	for i := 0; i < n; i++ {
+		idx := r.next - 1 - i
+		if idx < 0 {
+			idx += r.capacity
+		}
+		out = append(out, r.entries[idx])
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"testing"
+	"time"
+
+	"dxcluster/spot"
+)
+
+func testSpot(dxCall string) *spot.Spot {
+	return &spot.Spot{DXCall: dxCall, DECall: "W1AW", Frequency: 14074.0, Band: "20m", Mode: "FT8", Time: time.Now()}
+}
+
+func TestRingBufferSnapshotMostRecentFirst(t *testing.T) {
+	rb := newRingBuffer(3)
+	rb.Add(testSpot("K1ABC"))
+	rb.Add(testSpot("K2DEF"))
+	rb.Add(testSpot("K3GHI"))
+
+	got := rb.Snapshot()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 spots, got %d", len(got))
+	}
+	if got[0].DXCall != "K3GHI" || got[2].DXCall != "K1ABC" {
+		t.Fatalf("expected most-recent-first order, got %v, %v, %v", got[0].DXCall, got[1].DXCall, got[2].DXCall)
+	}
+}
+
+func TestRingBufferWrapsAtCapacity(t *testing.T) {
+	rb := newRingBuffer(2)
+	rb.Add(testSpot("K1ABC"))
+	rb.Add(testSpot("K2DEF"))
+	rb.Add(testSpot("K3GHI"))
+
+	got := rb.Snapshot()
+	if len(got) != 2 {
+		t.Fatalf("expected buffer capped at 2, got %d", len(got))
+	}
+	if got[0].DXCall != "K3GHI" || got[1].DXCall != "K2DEF" {
+		t.Fatalf("expected oldest entry evicted, got %v, %v", got[0].DXCall, got[1].DXCall)
+	}
+}
+
+func TestSpotFilterMatchesBandAndMode(t *testing.T) {
+	f := spotFilter{band: "20M", mode: "FT8"}
+	if !f.matches(testSpot("K1ABC")) {
+		t.Fatalf("expected matching band/mode spot to pass filter")
+	}
+
+	other := testSpot("K1ABC")
+	other.Band = "40m"
+	if f.matches(other) {
+		t.Fatalf("expected mismatched band to be filtered out")
+	}
+}
@@ -0,0 +1,181 @@
+// Package httpserver serves a live spot dashboard and JSON/SSE API over a
+// <-chan *spot.Spot GoCluster is already producing, typically
+// rbn.Client.GetSpotChannel(). cluster.Aggregator.Spots() returns
+// <-chan *AggregatedSpot instead, so feeding an Aggregator in requires a
+// small adapter goroutine that unwraps each AggregatedSpot.Spot onto its own
+// channel first. This lets an operator running the daemon headless - on a
+// Pi, in a closet, over SSH - still get a modern browser view without
+// needing telnet or an external client.
+package httpserver
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dxcluster/skew"
+	"dxcluster/spot"
+)
+
+// defaultBufferSize is how many recent spots the ring buffer holds when
+// Config.BufferSize is zero.
+const defaultBufferSize = 500
+
+// Config controls the server's listen address and ring buffer sizing.
+type Config struct {
+	// Addr is the listen address, e.g. ":8080". Empty defaults to ":8080".
+	Addr string
+
+	// BufferSize caps how many recent spots are kept for /spots, /spotters,
+	// and the dashboard homepage. Zero falls back to defaultBufferSize.
+	BufferSize int
+
+	// MetricsHandler, if set, is mounted at /metrics instead of this
+	// package's own ring-buffer counters - typically metrics.NewHandler's
+	// output, so the daemon's existing per-line parse/reject/skew counters
+	// are reachable from the same port as the dashboard rather than
+	// duplicated here. This package has no visibility into spots rejected
+	// before they reach its input channel (unlicensed DE, skew correction,
+	// etc.), so when MetricsHandler is nil it serves only what it can
+	// actually observe: spots ingested, current buffer occupancy, and SSE
+	// subscriber counts.
+	MetricsHandler http.Handler
+
+	// SkewStore, if set, backs /skew with the store's current online
+	// per-skimmer skew state (see skew.Estimator.PublishTo). Nil leaves
+	// /skew serving an empty list rather than being unmounted, so a
+	// dashboard client doesn't need to special-case a 404.
+	SkewStore *skew.Store
+}
+
+// Server ingests spots from a channel into a bounded ring buffer and serves
+// them over HTTP: JSON endpoints, an SSE stream, and an html/template
+// dashboard. Build one with NewServer, then call Start.
+type Server struct {
+	cfg   Config
+	spots <-chan *spot.Spot
+	buf   *ringBuffer
+
+	subMu sync.RWMutex
+	subs  map[chan *spot.Spot]struct{}
+
+	received   atomic.Uint64
+	sseDropped atomic.Uint64
+
+	httpServer *http.Server
+	shutdown   chan struct{}
+	stopOnce   sync.Once
+	wg         sync.WaitGroup
+}
+
+// NewServer builds a Server that will ingest from spots once Start is
+// called.
+func NewServer(cfg Config, spots <-chan *spot.Spot) *Server {
+	bufSize := cfg.BufferSize
+	if bufSize <= 0 {
+		bufSize = defaultBufferSize
+	}
+	return &Server{
+		cfg:      cfg,
+		spots:    spots,
+		buf:      newRingBuffer(bufSize),
+		subs:     make(map[chan *spot.Spot]struct{}),
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Start begins ingesting spots and serving HTTP in background goroutines. It
+// returns once the listener is up; a failure after that point (e.g. the
+// listener dying) is logged rather than returned, matching how main.go
+// already runs the Prometheus metrics server.
+func (s *Server) Start() error {
+	addr := s.cfg.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	mux := http.NewServeMux()
+	s.registerRoutes(mux)
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.httpServer = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.ingest()
+	}()
+	go func() {
+		defer s.wg.Done()
+		if err := s.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("httpserver: server stopped: %v", err)
+		}
+	}()
+	log.Printf("httpserver: dashboard available at http://%s/", addr)
+	return nil
+}
+
+// Stop shuts down the HTTP server and stops ingesting. It's safe to call
+// more than once and safe to call without a prior Start.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.shutdown)
+		if s.httpServer != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = s.httpServer.Shutdown(ctx)
+		}
+	})
+	s.wg.Wait()
+}
+
+func (s *Server) ingest() {
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case sp, ok := <-s.spots:
+			if !ok {
+				return
+			}
+			s.received.Add(1)
+			s.buf.Add(sp)
+			s.broadcast(sp)
+		}
+	}
+}
+
+// subscribe registers ch to receive every spot ingested from now on, until
+// unsubscribe is called. Sends are non-blocking: a slow SSE client drops
+// spots (counted in sseDropped) rather than stalling ingestion for everyone
+// else.
+func (s *Server) subscribe(ch chan *spot.Spot) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	s.subs[ch] = struct{}{}
+}
+
+func (s *Server) unsubscribe(ch chan *spot.Spot) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	delete(s.subs, ch)
+}
+
+func (s *Server) broadcast(sp *spot.Spot) {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	for ch := range s.subs {
+		select {
+		case ch <- sp:
+		default:
+			s.sseDropped.Add(1)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
@@ -11,18 +14,45 @@ import (
 	"time"
 
 	"dxcluster/buffer"
+	"dxcluster/callcorr"
 	"dxcluster/commands"
 	"dxcluster/config"
 	"dxcluster/cty"
 	"dxcluster/dedup"
+	"dxcluster/dxclusterclient"
+	"dxcluster/events"
 	"dxcluster/filter"
+	"dxcluster/metrics"
 	"dxcluster/pskreporter"
 	"dxcluster/rbn"
+	"dxcluster/sinks"
+	"dxcluster/skew"
 	"dxcluster/spot"
 	"dxcluster/stats"
 	"dxcluster/telnet"
 )
 
+// eventLogger is the process-wide structured event sink, wired up in main()
+// once the config and console layout are available. It is nil-safe: every
+// events.* helper treats a nil Logger as a no-op, so packages initialized
+// before main() finishes configuring it (or unit tests) can call through
+// without a guard.
+var eventLogger events.Logger
+
+// newEventLogger builds the configured sink (json or console, default
+// console) at the configured minimum level (default info).
+func newEventLogger(cfg config.LoggingConfig, layout *consoleLayout) events.Logger {
+	level := events.ParseLevel(cfg.Level)
+	if strings.EqualFold(cfg.Sink, "json") {
+		return events.NewJSONSink(os.Stdout, level)
+	}
+	var w io.Writer = os.Stdout
+	if layout != nil {
+		w = layout.LogWriter()
+	}
+	return events.NewConsoleSink(w, level, true)
+}
+
 // Version will be set at build time
 var Version = "dev"
 
@@ -39,14 +69,22 @@ func main() {
 		log.Printf("Warning: unable to initialize filter directory: %v", err)
 	}
 
+	layout := newConsoleLayout(os.Stdout, true, int(os.Stdout.Fd()))
+	defer layout.Close()
+	eventLogger = newEventLogger(cfg.Logging, layout)
+
 	// Print the configuration
 	cfg.Print()
 
-	// Load CTY database for callsign validation
-	ctyDB, err := cty.LoadCTYDatabase(cfg.CTY.File)
-	if err != nil {
+	// Load CTY database for callsign validation. ctyMgr owns the live,
+	// hot-reloaded snapshot; ctyDB is a point-in-time read of it handed to
+	// the consumers below that were written against the concrete
+	// *cty.CTYDatabase type rather than ctyMgr directly.
+	ctyMgr := cty.NewManager(cty.NewPlistFileSource(cfg.CTY.File), time.Duration(cfg.CTY.RefreshSeconds)*time.Second)
+	if err := ctyMgr.Start(context.Background()); err != nil {
 		log.Printf("Warning: failed to load CTY database: %v", err)
 	}
+	ctyDB := ctyMgr.Snapshot()
 
 	// Create stats tracker
 	statsTracker := stats.NewTracker()
@@ -80,7 +118,55 @@ func main() {
 			MaxHarmonicMultiple:  cfg.Harmonics.MaxHarmonicMultiple,
 			FrequencyToleranceHz: cfg.Harmonics.FrequencyToleranceHz,
 			MinReportDelta:       cfg.Harmonics.MinReportDelta,
+			PersistDBPath:        cfg.Harmonics.PersistDBPath,
+			DecayHalfLife:        time.Duration(cfg.Harmonics.DecayHalfLifeSeconds) * time.Second,
+			MinPersistedWeight:   cfg.Harmonics.MinPersistedWeight,
+			CompactionInterval:   time.Duration(cfg.Harmonics.CompactionIntervalSeconds) * time.Second,
 		})
+		if err := harmonicDetector.Load(context.Background()); err != nil {
+			log.Printf("Warning: failed to load persisted harmonic fundamentals: %v", err)
+		}
+		defer harmonicDetector.Close()
+	}
+
+	var callCorrector callcorr.Corrector
+	if cfg.CallCorrector.Enabled {
+		callCorrector = callcorr.NewConsensusCorrector(callcorr.Settings{
+			FreqBucketKHz:     cfg.CallCorrector.FreqBucketKHz,
+			RecencyWindow:     time.Duration(cfg.CallCorrector.RecencySeconds) * time.Second,
+			MaxEditDistance:   cfg.CallCorrector.MaxEditDistance,
+			MinConsensusVotes: cfg.CallCorrector.MinConsensusVotes,
+			MinAdvantage:      cfg.CallCorrector.MinAdvantage,
+			HumanWeight:       cfg.CallCorrector.HumanWeight,
+			SkimmerWeight:     cfg.CallCorrector.SkimmerWeight,
+			CooldownWindow:    time.Duration(cfg.CallCorrector.CooldownSeconds) * time.Second,
+		}, nil)
+	}
+
+	// Optional downstream spot sinks (FlexRadio, N1MM+/DXLog) that receive
+	// every deduplicated spot alongside the ring buffer and telnet broadcast.
+	var spotSinks []sinks.SpotSink
+	if cfg.FlexSink.Enabled {
+		flexSink := sinks.NewFlexSink(sinks.FlexConfig{
+			Host:            cfg.FlexSink.Host,
+			Port:            cfg.FlexSink.Port,
+			QueueSize:       cfg.FlexSink.QueueSize,
+			LifetimeSeconds: cfg.FlexSink.LifetimeSeconds,
+		})
+		flexSink.SetLogger(eventLogger)
+		flexSink.Start()
+		spotSinks = append(spotSinks, flexSink)
+		log.Printf("FlexRadio spot sink enabled (%s:%d)", cfg.FlexSink.Host, cfg.FlexSink.Port)
+	}
+	if cfg.N1MMSink.Enabled {
+		n1mmSink := sinks.NewN1MMSink(sinks.N1MMConfig{
+			Address:   cfg.N1MMSink.Address,
+			QueueSize: cfg.N1MMSink.QueueSize,
+		})
+		n1mmSink.SetLogger(eventLogger)
+		n1mmSink.Start()
+		spotSinks = append(spotSinks, n1mmSink)
+		log.Printf("N1MM+/DXLog spot sink enabled (%s)", cfg.N1MMSink.Address)
 	}
 
 	// Create deduplicator if enabled
@@ -90,11 +176,11 @@ func main() {
 		window := time.Duration(cfg.Dedup.ClusterWindowSeconds) * time.Second
 		deduplicator = dedup.NewDeduplicator(window)
 		deduplicator.Start() // Start the processing loop
-		log.Printf("Deduplication enabled with %v window", window)
+		events.Info(eventLogger, "dedup.enabled", events.Duration("window", window))
 
 		// Wire up dedup output to ring buffer and telnet broadcast
 		// Deduplicated spots → Ring Buffer → Broadcast to clients
-		go processOutputSpots(deduplicator, spotBuffer, nil, statsTracker, nil, cfg.CallCorrection, ctyDB, harmonicDetector, cfg.Harmonics, knownCalls, freqAverager, cfg.SpotPolicy) // We'll pass telnet server later
+		go processOutputSpots(deduplicator, spotBuffer, nil, statsTracker, nil, cfg.CallCorrection, ctyDB, harmonicDetector, cfg.Harmonics, knownCalls, freqAverager, cfg.SpotPolicy, spotSinks, callCorrector) // We'll pass telnet server later
 	}
 
 	// Create command processor
@@ -117,7 +203,7 @@ func main() {
 	// Now wire up the telnet server to the output processor
 	if cfg.Dedup.Enabled {
 		// Restart the output processor with telnet server
-		go processOutputSpots(deduplicator, spotBuffer, telnetServer, statsTracker, correctionIndex, cfg.CallCorrection, ctyDB, harmonicDetector, cfg.Harmonics, knownCalls, freqAverager, cfg.SpotPolicy)
+		go processOutputSpots(deduplicator, spotBuffer, telnetServer, statsTracker, correctionIndex, cfg.CallCorrection, ctyDB, harmonicDetector, cfg.Harmonics, knownCalls, freqAverager, cfg.SpotPolicy, spotSinks, callCorrector)
 	}
 
 	// Connect to RBN CW/RTTY feed if enabled (port 7000)
@@ -160,6 +246,29 @@ func main() {
 		}
 	}
 
+	// Connect to any configured generic upstream DX cluster nodes (e.g.
+	// VE7CC, DXSummit mirrors). Each node gets its own client and goroutine;
+	// all of them feed the same deduplicator input channel as RBN/PSKReporter.
+	var dxClusterClients []*dxclusterclient.Client
+	for _, node := range cfg.DXClusters {
+		if !node.Enabled {
+			continue
+		}
+		client := dxclusterclient.NewClient(node.Host, node.Port, node.Callsign, node.Password, node.Name, node.InitCommands, ctyDB, 0)
+		client.SetLogger(eventLogger)
+		if err := client.Connect(); err != nil {
+			log.Printf("Warning: Failed to connect to %s: %v", node.Name, err)
+			continue
+		}
+		dxClusterClients = append(dxClusterClients, client)
+		if cfg.Dedup.Enabled {
+			go processDXClusterSpots(client, deduplicator, node.Name)
+			log.Printf("%s client feeding spots into unified dedup engine", node.Name)
+		} else {
+			go processDXClusterSpotsNoDedupe(client, spotBuffer, telnetServer, statsTracker)
+		}
+	}
+
 	// Connect to PSKReporter if enabled
 	// PSKReporter spots go INTO the deduplicator input channel
 	var (
@@ -186,7 +295,27 @@ func main() {
 
 	// Start stats display goroutine
 	statsInterval := time.Duration(cfg.Stats.DisplayIntervalSeconds) * time.Second
-	go displayStats(statsInterval, statsTracker, deduplicator, spotBuffer, telnetServer, pskrClient, ctyDB)
+	go displayStats(statsInterval, statsTracker, deduplicator, spotBuffer, telnetServer, pskrClient, ctyDB, ctyMgr, spotSinks)
+
+	// Serve Prometheus metrics. skewStore starts empty and adaptive refresh
+	// is reported as nil (disabled) until those subsystems are wired into
+	// main; the handler treats both cases as "no data yet" rather than
+	// erroring.
+	skewStore := skew.NewStore()
+	if cfg.Metrics.Enabled {
+		metricsAddr := cfg.Metrics.Addr
+		if metricsAddr == "" {
+			metricsAddr = ":9090"
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.NewHandler(statsTracker, skewStore, nil, nil))
+		go func() {
+			if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+				log.Printf("Warning: metrics server stopped: %v", err)
+			}
+		}()
+		log.Printf("Prometheus metrics available at http://%s/metrics", metricsAddr)
+	}
 
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -239,6 +368,16 @@ func main() {
 		pskrClient.Stop()
 	}
 
+	// Stop generic upstream DX cluster clients
+	for _, client := range dxClusterClients {
+		client.Stop()
+	}
+
+	// Stop downstream spot sinks
+	for _, sink := range spotSinks {
+		sink.Stop()
+	}
+
 	// Stop the telnet server
 	telnetServer.Stop()
 
@@ -246,7 +385,7 @@ func main() {
 }
 
 // displayStats prints statistics at the configured interval
-func displayStats(interval time.Duration, tracker *stats.Tracker, dedup *dedup.Deduplicator, buf *buffer.RingBuffer, telnetServer *telnet.Server, pskr *pskreporter.Client, ctyDB *cty.CTYDatabase) {
+func displayStats(interval time.Duration, tracker *stats.Tracker, dedup *dedup.Deduplicator, buf *buffer.RingBuffer, telnetServer *telnet.Server, pskr *pskreporter.Client, ctyDB *cty.CTYDatabase, ctyMgr *cty.Manager, spotSinks []sinks.SpotSink) {
 	if interval <= 0 {
 		interval = 30 * time.Second
 	}
@@ -278,6 +417,11 @@ func displayStats(interval time.Duration, tracker *stats.Tracker, dedup *dedup.D
 			fmt.Printf("PSKReporter stats: workers=%d, queue_len=%d, drops=%d\n", workers, queueLen, drops)
 		}
 
+		for _, sink := range spotSinks {
+			queueLen, drops := sink.Stats()
+			fmt.Printf("%s sink stats: queue_len=%d, drops=%d\n", sink.Name(), queueLen, drops)
+		}
+
 		if ctyDB != nil {
 			metrics := ctyDB.Metrics()
 			cacheHitPercent := 0.0
@@ -292,6 +436,16 @@ func displayStats(interval time.Duration, tracker *stats.Tracker, dedup *dedup.D
 				metrics.TotalLookups, metrics.CacheHits, cacheHitPercent, metrics.CacheEntries, metrics.Validated, metrics.ValidatedFromCache, cacheValidatedPercent)
 		}
 
+		if ctyMgr != nil {
+			health := ctyMgr.Health()
+			status := "ok"
+			if health.LastError != nil {
+				status = fmt.Sprintf("error: %v", health.LastError)
+			}
+			fmt.Printf("CTY reload health: status=%s refreshes=%d prefixes=%d last_refresh=%s\n",
+				status, health.RefreshCount, health.PrefixCount, health.LastRefresh.Format(time.RFC3339))
+		}
+
 		// Print ring buffer position and approximate memory usage
 		position := buf.GetPosition()
 		count := buf.GetCount()
@@ -344,6 +498,8 @@ func processOutputSpots(
 	knownCalls *spot.KnownCallsigns,
 	freqAvg *spot.FrequencyAverager,
 	spotPolicy config.SpotPolicy,
+	spotSinks []sinks.SpotSink,
+	callCorrector callcorr.Corrector,
 ) {
 	outputChan := deduplicator.GetOutputChannel()
 
@@ -353,6 +509,7 @@ func processOutputSpots(
 			modeKey = string(spot.SourceType)
 		}
 		tracker.IncrementMode(modeKey)
+		tracker.IncrementBand(spot.Band)
 
 		if spot.SourceNode != "" && spot.SourceNode != modeKey {
 			tracker.IncrementSource(spot.SourceNode)
@@ -367,15 +524,15 @@ func processOutputSpots(
 
 		var suppress bool
 		if telnet != nil {
-			suppress = maybeApplyCallCorrection(spot, correctionIdx, correctionCfg, ctyDB, knownCalls)
+			suppress = maybeApplyCallCorrection(spot, correctionIdx, correctionCfg, ctyDB, knownCalls, eventLogger)
 			if suppress {
 				continue
 			}
 		}
 
 		if harmonicDetector != nil && harmonicCfg.Enabled {
-			if drop, fundamental := harmonicDetector.ShouldDrop(spot, time.Now().UTC()); drop {
-				log.Printf("Harmonic suppressed: %s fundamental=%.1fkHz harmonic=%.1fkHz", spot.DXCall, fundamental, spot.Frequency)
+			if drop, parents := harmonicDetector.ShouldDrop(spot, time.Now().UTC()); drop {
+				log.Printf("Harmonic suppressed: %s harmonic_of=%v harmonic=%.1fkHz", spot.DXCall, parents, spot.Frequency)
 				continue
 			}
 		}
@@ -391,11 +548,23 @@ func processOutputSpots(
 			}
 		}
 
+		if callCorrector != nil {
+			corrected, decision := callCorrector.Apply(spot, time.Now().UTC())
+			if decision.Decision == "applied" {
+				log.Printf("Call corrected: %s -> %s (%.1fkHz, confidence=%d%%)", spot.DXCall, corrected.DXCall, spot.Frequency, decision.WinnerConfidence)
+			}
+			spot = corrected
+		}
+
 		buf.Add(spot)
 
 		if telnet != nil {
 			telnet.BroadcastSpot(spot)
 		}
+
+		for _, sink := range spotSinks {
+			sink.Submit(spot)
+		}
 	}
 }
 
@@ -411,6 +580,49 @@ func processRBNSpotsNoDedupe(client *rbn.Client, buf *buffer.RingBuffer, telnet
 			modeKey = string(spot.SourceType)
 		}
 		tracker.IncrementMode(modeKey)
+		tracker.IncrementBand(spot.Band)
+
+		// Track spot by source node
+		if spot.SourceNode != "" {
+			tracker.IncrementSource(spot.SourceNode)
+		}
+
+		// Add directly to buffer (no dedup)
+		buf.Add(spot)
+
+		// Broadcast to all connected telnet clients
+		telnet.BroadcastSpot(spot)
+	}
+}
+
+// processDXClusterSpots receives spots from a generic upstream DX cluster
+// node and sends them to the deduplicator. This is the UNIFIED ARCHITECTURE
+// path: generic DX cluster → Deduplicator Input Channel
+func processDXClusterSpots(client *dxclusterclient.Client, deduplicator *dedup.Deduplicator, source string) {
+	spotChan := client.GetSpotChannel()
+	dedupInput := deduplicator.GetInputChannel()
+
+	for spot := range spotChan {
+		// Send spot to deduplicator input channel
+		// All sources send here!
+		dedupInput <- spot
+	}
+	log.Printf("%s: Spot processing stopped", source)
+}
+
+// processDXClusterSpotsNoDedupe is the legacy path when deduplication is
+// disabled: generic DX cluster → Ring Buffer → Clients (no deduplication)
+func processDXClusterSpotsNoDedupe(client *dxclusterclient.Client, buf *buffer.RingBuffer, telnet *telnet.Server, tracker *stats.Tracker) {
+	spotChan := client.GetSpotChannel()
+
+	for spot := range spotChan {
+		// Track spot by mode
+		modeKey := strings.ToUpper(strings.TrimSpace(spot.Mode))
+		if modeKey == "" {
+			modeKey = string(spot.SourceType)
+		}
+		tracker.IncrementMode(modeKey)
+		tracker.IncrementBand(spot.Band)
 
 		// Track spot by source node
 		if spot.SourceNode != "" {
@@ -436,6 +648,7 @@ func processPSKRSpotsNoDedupe(client *pskreporter.Client, buf *buffer.RingBuffer
 			modeKey = string(spot.SourceType)
 		}
 		tracker.IncrementMode(modeKey)
+		tracker.IncrementBand(spot.Band)
 
 		// Track spot by source node
 		if spot.SourceNode != "" {
@@ -447,7 +660,7 @@ func processPSKRSpotsNoDedupe(client *pskreporter.Client, buf *buffer.RingBuffer
 	}
 }
 
-func maybeApplyCallCorrection(spotEntry *spot.Spot, idx *spot.CorrectionIndex, cfg config.CallCorrectionConfig, ctyDB *cty.CTYDatabase, known *spot.KnownCallsigns) bool {
+func maybeApplyCallCorrection(spotEntry *spot.Spot, idx *spot.CorrectionIndex, cfg config.CallCorrectionConfig, ctyDB *cty.CTYDatabase, known *spot.KnownCallsigns, logger events.Logger) bool {
 	if spotEntry == nil {
 		return false
 	}
@@ -479,21 +692,34 @@ func maybeApplyCallCorrection(spotEntry *spot.Spot, idx *spot.CorrectionIndex, c
 
 	if ok && ctyDB != nil {
 		if _, valid := ctyDB.LookupCallsign(corrected); valid {
-			log.Printf("Call correction applied: %s -> %s at %.1f kHz (%d corroborators, %d%% confidence)",
-				spotEntry.DXCall, corrected, spotEntry.Frequency, supporters, correctedConfidence)
+			events.Info(logger, "call_correction.applied",
+				events.String("from", spotEntry.DXCall),
+				events.String("to", corrected),
+				events.Float("freq_khz", spotEntry.Frequency),
+				events.Int("supporters", supporters),
+				events.Int("confidence", correctedConfidence))
 			spotEntry.DXCall = corrected
 			spotEntry.Confidence = "C"
 		} else {
-			log.Printf("Call correction rejected (CTY miss): suggested %s at %.1f kHz", corrected, spotEntry.Frequency)
+			events.Warn(logger, "call_correction.rejected",
+				events.String("suggested", corrected),
+				events.Float("freq_khz", spotEntry.Frequency),
+				events.String("reason", "cty_miss"))
 			if strings.EqualFold(cfg.InvalidAction, "suppress") {
-				log.Printf("Call correction suppression engaged: dropping spot from %s at %.1f kHz", spotEntry.DXCall, spotEntry.Frequency)
+				events.Warn(logger, "call_correction.suppressed",
+					events.String("call", spotEntry.DXCall),
+					events.Float("freq_khz", spotEntry.Frequency))
 				return true
 			}
 			spotEntry.Confidence = "B"
 		}
 	} else if ok && ctyDB == nil {
-		log.Printf("Call correction suggestion ignored (no CTY database): %s -> %s (%d corroborators, %d%% confidence)",
-			spotEntry.DXCall, corrected, supporters, correctedConfidence)
+		events.Info(logger, "call_correction.ignored",
+			events.String("from", spotEntry.DXCall),
+			events.String("to", corrected),
+			events.Int("supporters", supporters),
+			events.Int("confidence", correctedConfidence),
+			events.String("reason", "no_cty_database"))
 		spotEntry.Confidence = "C"
 	}
 
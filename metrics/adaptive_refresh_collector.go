@@ -0,0 +1,38 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// adaptiveRefreshCollector surfaces the main daemon's adaptive-refresh
+// state. state is nil when adaptive refresh is disabled, in which case
+// Collect emits nothing.
+type adaptiveRefreshCollector struct {
+	state AdaptiveRefreshState
+
+	highestStateDesc *prometheus.Desc
+	spotsSinceDesc   *prometheus.Desc
+	lastRunAgeDesc   *prometheus.Desc
+}
+
+func newAdaptiveRefreshCollector(state AdaptiveRefreshState) *adaptiveRefreshCollector {
+	return &adaptiveRefreshCollector{
+		state:            state,
+		highestStateDesc: prometheus.NewDesc("dxcluster_adaptive_refresh_state", "Currently active adaptive-refresh state; the active state's series is 1.", []string{"state"}, nil),
+		spotsSinceDesc:   prometheus.NewDesc("dxcluster_adaptive_refresh_spots_since_last", "Spots received since the last adaptive refresh run.", nil, nil),
+		lastRunAgeDesc:   prometheus.NewDesc("dxcluster_adaptive_refresh_last_run_age_seconds", "Seconds since the last adaptive refresh run.", nil, nil),
+	}
+}
+
+func (c *adaptiveRefreshCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.highestStateDesc
+	ch <- c.spotsSinceDesc
+	ch <- c.lastRunAgeDesc
+}
+
+func (c *adaptiveRefreshCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.state == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.highestStateDesc, prometheus.GaugeValue, 1, c.state.HighestState())
+	ch <- prometheus.MustNewConstMetric(c.spotsSinceDesc, prometheus.GaugeValue, float64(c.state.SpotsSinceLastRefresh()))
+	ch <- prometheus.MustNewConstMetric(c.lastRunAgeDesc, prometheus.GaugeValue, c.state.LastRunAge().Seconds())
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// fetchRefreshCollector surfaces skew.FetchRefresher's conditional-GET
+// polling health. state is nil when no FetchRefresher is running, in which
+// case Collect emits nothing.
+type fetchRefreshCollector struct {
+	state FetchRefreshState
+
+	bytesDesc       *prometheus.Desc
+	notModifiedDesc *prometheus.Desc
+	modifiedDesc    *prometheus.Desc
+	lastSuccessDesc *prometheus.Desc
+}
+
+func newFetchRefreshCollector(state FetchRefreshState) *fetchRefreshCollector {
+	return &fetchRefreshCollector{
+		state:           state,
+		bytesDesc:       prometheus.NewDesc("dxcluster_skew_fetch_bytes_total", "Total response-body bytes read from the skew CSV endpoint.", nil, nil),
+		notModifiedDesc: prometheus.NewDesc("dxcluster_skew_fetch_not_modified_total", "Number of skew CSV polls that received a 304 Not Modified response.", nil, nil),
+		modifiedDesc:    prometheus.NewDesc("dxcluster_skew_fetch_modified_total", "Number of skew CSV polls that received a fresh 200 response.", nil, nil),
+		lastSuccessDesc: prometheus.NewDesc("dxcluster_skew_fetch_last_success_timestamp_seconds", "Unix timestamp of the last successful skew CSV poll (200 or 304), or 0 if never.", nil, nil),
+	}
+}
+
+func (c *fetchRefreshCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytesDesc
+	ch <- c.notModifiedDesc
+	ch <- c.modifiedDesc
+	ch <- c.lastSuccessDesc
+}
+
+func (c *fetchRefreshCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.state == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue, float64(c.state.BytesTransferred()))
+	ch <- prometheus.MustNewConstMetric(c.notModifiedDesc, prometheus.CounterValue, float64(c.state.NotModifiedCount()))
+	ch <- prometheus.MustNewConstMetric(c.modifiedDesc, prometheus.CounterValue, float64(c.state.ModifiedCount()))
+
+	var lastSuccess float64
+	if ts := c.state.LastSuccess(); !ts.IsZero() {
+		lastSuccess = float64(ts.Unix())
+	}
+	ch <- prometheus.MustNewConstMetric(c.lastSuccessDesc, prometheus.GaugeValue, lastSuccess)
+}
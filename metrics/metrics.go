@@ -0,0 +1,47 @@
+// Package metrics assembles the Prometheus /metrics endpoint for the
+// daemon: the spot tracker's counters (which implement prometheus.Collector
+// directly), skew-table coverage, and adaptive-refresh state.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"dxcluster/skew"
+	"dxcluster/stats"
+)
+
+// AdaptiveRefreshState exposes the read-only adaptive-refresh counters the
+// main daemon's refresher tracks, without this package needing to import
+// package main. A nil AdaptiveRefreshState (adaptive refresh disabled)
+// simply contributes no metrics.
+type AdaptiveRefreshState interface {
+	HighestState() string
+	SpotsSinceLastRefresh() int64
+	LastRunAge() time.Duration
+}
+
+// FetchRefreshState exposes skew.FetchRefresher's conditional-GET counters.
+// A nil FetchRefreshState (no FetchRefresher running) simply contributes no
+// metrics.
+type FetchRefreshState interface {
+	BytesTransferred() uint64
+	NotModifiedCount() uint64
+	ModifiedCount() uint64
+	LastSuccess() time.Time
+}
+
+// NewHandler returns an http.Handler serving Prometheus text-format
+// metrics for tracker, skewStore, refresh, and fetchRefresh. refresh and
+// fetchRefresh may be nil when those subsystems aren't running.
+func NewHandler(tracker *stats.Tracker, skewStore *skew.Store, refresh AdaptiveRefreshState, fetchRefresh FetchRefreshState) http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(tracker)
+	reg.MustRegister(newSkewCollector(skewStore))
+	reg.MustRegister(newAdaptiveRefreshCollector(refresh))
+	reg.MustRegister(newFetchRefreshCollector(fetchRefresh))
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"dxcluster/skew"
+)
+
+// skewCollector surfaces skew.Store's coverage and refresh health.
+type skewCollector struct {
+	store *skew.Store
+
+	entriesDesc     *prometheus.Desc
+	lastSuccessDesc *prometheus.Desc
+	errorsDesc      *prometheus.Desc
+}
+
+func newSkewCollector(store *skew.Store) *skewCollector {
+	return &skewCollector{
+		store:           store,
+		entriesDesc:     prometheus.NewDesc("dxcluster_skew_entries", "Number of skimmers with published skew corrections.", nil, nil),
+		lastSuccessDesc: prometheus.NewDesc("dxcluster_skew_last_refresh_timestamp_seconds", "Unix timestamp of the last successful skew table refresh, or 0 if never refreshed.", nil, nil),
+		errorsDesc:      prometheus.NewDesc("dxcluster_skew_refresh_errors_total", "Number of failed skew table refresh attempts.", nil, nil),
+	}
+}
+
+func (c *skewCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entriesDesc
+	ch <- c.lastSuccessDesc
+	ch <- c.errorsDesc
+}
+
+func (c *skewCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.entriesDesc, prometheus.GaugeValue, float64(c.store.Count()))
+
+	var lastSuccess float64
+	if ts := c.store.LastSuccess(); !ts.IsZero() {
+		lastSuccess = float64(ts.Unix())
+	}
+	ch <- prometheus.MustNewConstMetric(c.lastSuccessDesc, prometheus.GaugeValue, lastSuccess)
+	ch <- prometheus.MustNewConstMetric(c.errorsDesc, prometheus.CounterValue, float64(c.store.ErrorCount()))
+}
@@ -1,34 +1,106 @@
 package peer
 
-import "time"
+import (
+	"math/rand"
+	"time"
 
-type backoff struct {
-	cur time.Duration
-	max time.Duration
+	"dxcluster/backoff"
+	"dxcluster/events"
+)
+
+// Policy, and the concrete strategies below, are aliases for the shared
+// dxcluster/backoff policies so peer's reconnect loop and anything else
+// retrying a flaky connection (e.g. rbn's) stay on the same jitter math.
+type Policy = backoff.Policy
+
+// PolicyExponential is the historical naked-doubling strategy: the delay
+// doubles every attempt up to max, with no jitter. It is prone to
+// synchronised reconnect storms when many peers retry in lockstep.
+type PolicyExponential = backoff.PolicyExponential
+
+// PolicyFullJitter spreads retries uniformly over [0, min(max, base<<attempt)),
+// tracking the attempt implicitly via the carried prev/next ceiling.
+type PolicyFullJitter = backoff.PolicyFullJitter
+
+// PolicyDecorrelatedJitter implements the AWS-style decorrelated jitter
+// strategy: Next() = min(max, rand(base, 3*prev)), which tends to space out
+// synchronised retries better than full jitter while still growing the
+// delay over time.
+type PolicyDecorrelatedJitter = backoff.PolicyDecorrelatedJitter
+
+// reconnectBackoff tracks reconnect delay state for a single peer
+// connection. The delay sequence is produced by a pluggable Policy so
+// operators can trade off reconnect speed against thundering-herd risk per
+// connection.
+type reconnectBackoff struct {
+	base   time.Duration
+	max    time.Duration
+	prev   time.Duration
+	policy Policy
+	rng    *rand.Rand
+	logger events.Logger
+}
+
+// SetLogger wires a structured event sink so each scheduled reconnect delay
+// is observable, independent of whatever reconnect loop owns this backoff.
+func (b *reconnectBackoff) SetLogger(l events.Logger) {
+	if b == nil {
+		return
+	}
+	b.logger = l
+}
+
+// newBackoff preserves the historical constructor: plain exponential
+// doubling with no jitter and the package-global RNG.
+func newBackoff(base, max time.Duration) *reconnectBackoff {
+	return newBackoffWithPolicy(base, max, PolicyExponential{}, nil)
 }
 
-func newBackoff(base, max time.Duration) *backoff {
+// newBackoffWithPolicy builds a backoff driven by the given Policy. Passing
+// a non-nil rng makes the sequence deterministic, which tests rely on;
+// production callers can pass nil to use the global math/rand source.
+func newBackoffWithPolicy(base, max time.Duration, p Policy, rng *rand.Rand) *reconnectBackoff {
 	if base <= 0 {
 		base = time.Second
 	}
 	if max < base {
 		max = base
 	}
-	return &backoff{cur: base, max: max}
+	if p == nil {
+		p = PolicyExponential{}
+	}
+	return &reconnectBackoff{base: base, max: max, policy: p, rng: rng}
 }
 
-func (b *backoff) Next() time.Duration {
-	if b.cur >= b.max {
-		return b.max
-	}
-	d := b.cur
-	b.cur *= 2
-	if b.cur > b.max {
-		b.cur = b.max
-	}
+func (b *reconnectBackoff) Next() time.Duration {
+	d, next := b.policy.Next(b.base, b.max, b.prev, b.rng)
+	b.prev = next
+	events.Debug(b.logger, "peer.backoff_schedule", events.Duration("delay", d))
 	return d
 }
 
-func (b *backoff) Reset() {
-	b.cur = 0
+func (b *reconnectBackoff) Reset() {
+	b.prev = 0
+}
+
+// NewReconnectBackoff builds a reconnect backoff for the named policy, for
+// callers outside this package that drive their own peer reconnect loop
+// (e.g. cmd/peerprobe). name is the peer config's `backoff_policy` value;
+// see backoffPolicyFromName for the accepted strings and the default.
+func NewReconnectBackoff(base, max time.Duration, name string) *reconnectBackoff {
+	return newBackoffWithPolicy(base, max, backoffPolicyFromName(name), nil)
+}
+
+// backoffPolicyFromName maps the `backoff_policy` config string to a Policy,
+// defaulting to the historical exponential behaviour when unset or
+// unrecognised.
+func backoffPolicyFromName(name string) Policy {
+	switch name {
+	case "full_jitter":
+		return PolicyFullJitter{}
+	case "decorrelated_jitter":
+		return PolicyDecorrelatedJitter{}
+	default:
+		return PolicyExponential{}
+	}
 }
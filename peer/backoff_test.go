@@ -0,0 +1,87 @@
+package peer
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestBackoffExponentialDoublesAndCaps(t *testing.T) {
+	b := newBackoff(time.Second, 8*time.Second)
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Fatalf("attempt %d: got %v, want %v", i, got, w)
+		}
+	}
+	b.Reset()
+	if got := b.Next(); got != time.Second {
+		t.Fatalf("after Reset: got %v, want %v", got, time.Second)
+	}
+}
+
+func TestBackoffFullJitterBounds(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	b := newBackoffWithPolicy(time.Second, 10*time.Second, PolicyFullJitter{}, rng)
+	ceil := time.Second
+	for i := 0; i < 20; i++ {
+		d := b.Next()
+		if d < 0 || d > ceil {
+			t.Fatalf("attempt %d: delay %v outside [0, %v]", i, d, ceil)
+		}
+		ceil *= 2
+		if ceil > 10*time.Second {
+			ceil = 10 * time.Second
+		}
+	}
+}
+
+func TestBackoffDecorrelatedJitterMonotoneCap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	b := newBackoffWithPolicy(time.Second, 10*time.Second, PolicyDecorrelatedJitter{}, rng)
+	for i := 0; i < 50; i++ {
+		d := b.Next()
+		if d < time.Second || d > 10*time.Second {
+			t.Fatalf("attempt %d: delay %v outside [base, max]", i, d)
+		}
+	}
+}
+
+func TestBackoffDecorrelatedJitterResetClearsPrev(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	b := newBackoffWithPolicy(time.Second, 10*time.Second, PolicyDecorrelatedJitter{}, rng)
+	b.Next()
+	b.Next()
+	b.Reset()
+	if b.prev != 0 {
+		t.Fatalf("expected Reset to clear carried prev, got %v", b.prev)
+	}
+}
+
+func TestNewReconnectBackoffUsesNamedPolicy(t *testing.T) {
+	b := NewReconnectBackoff(time.Second, 8*time.Second, "")
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second}
+	for i, w := range want {
+		if got := b.Next(); got != w {
+			t.Fatalf("attempt %d: got %v, want %v", i, got, w)
+		}
+	}
+	b.Reset()
+	if got := b.Next(); got != time.Second {
+		t.Fatalf("after Reset: got %v, want %v", got, time.Second)
+	}
+}
+
+func TestBackoffPolicyFromName(t *testing.T) {
+	cases := map[string]Policy{
+		"":                     PolicyExponential{},
+		"full_jitter":          PolicyFullJitter{},
+		"decorrelated_jitter":  PolicyDecorrelatedJitter{},
+		"unknown_garbage_name": PolicyExponential{},
+	}
+	for name, want := range cases {
+		if got := backoffPolicyFromName(name); got != want {
+			t.Fatalf("backoffPolicyFromName(%q) = %T, want %T", name, got, want)
+		}
+	}
+}
@@ -1,50 +1,286 @@
 package peer
 
 import (
+	"container/list"
+	"hash/fnv"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"dxcluster/events"
+)
+
+// Tuning constants for the sharded dedupe cache. dedupeShardCount trades
+// lock contention (more shards = less contention) against per-shard
+// bookkeeping overhead; 16 is a reasonable default for the peer counts this
+// cluster runs with.
+const (
+	dedupeShardCount        = 16
+	dedupeDefaultMaxEntries = 200_000
+	dedupeBloomCountersPerK = 8 // bloom filter size is maxEntries * this, per shard
 )
 
-// dedupeCache is a time-bounded set for seen keys.
+// dedupeEntry is a single tracked key and the absolute time it expires.
+// Entries within a shard are pushed in insertion order and, because every
+// entry in a given cache shares the same TTL, that insertion order is also
+// ascending expiry order - prune can therefore pop from the front until it
+// finds an unexpired entry instead of scanning the whole shard.
+type dedupeEntry struct {
+	key    string
+	expiry time.Time
+}
+
+// dedupeShard is one lock-striped partition of the cache: its own map, its
+// own FIFO eviction order, and its own counting-bloom prefilter so hot
+// negative lookups (the common case - most spots are new) never need to
+// touch the map or compete for its mutex with other shards.
+type dedupeShard struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	bloom   *countingBloom
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newDedupeShard(maxEntries int) *dedupeShard {
+	return &dedupeShard{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		bloom:   newCountingBloom(maxEntries * dedupeBloomCountersPerK),
+	}
+}
+
+// markSeen records key if it hasn't been seen before (or has expired and
+// been pruned), evicting the oldest entry first if the shard is full.
+func (s *dedupeShard) markSeen(key string, now time.Time, ttl time.Duration, maxEntries int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bloom.mayContain(key) {
+		if _, ok := s.entries[key]; ok {
+			atomic.AddUint64(&s.hits, 1)
+			return false
+		}
+	}
+	atomic.AddUint64(&s.misses, 1)
+
+	if len(s.entries) >= maxEntries {
+		s.evictOldestLocked()
+	}
+
+	el := s.order.PushBack(&dedupeEntry{key: key, expiry: now.Add(ttl)})
+	s.entries[key] = el
+	s.bloom.add(key)
+	return true
+}
+
+func (s *dedupeShard) evictOldestLocked() {
+	front := s.order.Front()
+	if front == nil {
+		return
+	}
+	e := front.Value.(*dedupeEntry)
+	s.order.Remove(front)
+	delete(s.entries, e.key)
+	s.bloom.remove(e.key)
+	atomic.AddUint64(&s.evictions, 1)
+}
+
+func (s *dedupeShard) prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		front := s.order.Front()
+		if front == nil {
+			return
+		}
+		e := front.Value.(*dedupeEntry)
+		if !now.After(e.expiry) {
+			return
+		}
+		s.order.Remove(front)
+		delete(s.entries, e.key)
+		s.bloom.remove(e.key)
+	}
+}
+
+func (s *dedupeShard) stats() (hits, misses, evictions uint64) {
+	return atomic.LoadUint64(&s.hits), atomic.LoadUint64(&s.misses), atomic.LoadUint64(&s.evictions)
+}
+
+// DedupeStats reports cumulative counters across all shards of a
+// dedupeCache, suitable for display in the console header.
+type DedupeStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// dedupeCache is a time-bounded set for seen keys, sharded by FNV-1a hash of
+// the key to spread lock contention across dedupeShardCount partitions. Each
+// shard enforces its own MaxEntries cap, evicting its oldest entry on insert
+// once full, and is fronted by a counting-bloom filter so negative lookups
+// for brand-new keys skip the map entirely.
 type dedupeCache struct {
-	mu    sync.Mutex
-	items map[string]time.Time
-	ttl   time.Duration
+	shards     [dedupeShardCount]*dedupeShard
+	ttl        time.Duration
+	maxEntries int // per shard
+	logger     events.Logger
+}
+
+// SetLogger wires a structured event sink so duplicate-key drops are
+// observable (e.g. `events: tail -f | jq 'select(.event=="peer.dedupe_drop")'`)
+// instead of only showing up as a Stats() counter.
+func (c *dedupeCache) SetLogger(l events.Logger) {
+	if c == nil {
+		return
+	}
+	c.logger = l
 }
 
 func newDedupeCache(ttl time.Duration) *dedupeCache {
-	return &dedupeCache{
-		items: make(map[string]time.Time),
-		ttl:   ttl,
+	return newDedupeCacheWithCapacity(ttl, dedupeDefaultMaxEntries)
+}
+
+// newDedupeCacheWithCapacity builds a dedupeCache with an explicit total
+// entry budget, split evenly across shards.
+func newDedupeCacheWithCapacity(ttl time.Duration, maxEntries int) *dedupeCache {
+	if maxEntries < dedupeShardCount {
+		maxEntries = dedupeShardCount
 	}
+	perShard := maxEntries / dedupeShardCount
+	if perShard < 1 {
+		perShard = 1
+	}
+	c := &dedupeCache{ttl: ttl, maxEntries: perShard}
+	for i := range c.shards {
+		c.shards[i] = newDedupeShard(perShard)
+	}
+	return c
+}
+
+func (c *dedupeCache) shardFor(key string) *dedupeShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return c.shards[h.Sum32()%dedupeShardCount]
 }
 
 func (c *dedupeCache) markSeen(key string, now time.Time) bool {
 	if c == nil || key == "" {
 		return false
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if _, ok := c.items[key]; ok {
-		return false
+	seen := c.shardFor(key).markSeen(key, now, c.ttl, c.maxEntries)
+	if !seen {
+		events.Debug(c.logger, "peer.dedupe_drop", events.String("key", key))
 	}
-	c.items[key] = now
-	return true
+	return seen
 }
 
 func (c *dedupeCache) prune(now time.Time) {
 	if c == nil {
 		return
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if len(c.items) == 0 {
-		return
+	for _, s := range c.shards {
+		s.prune(now)
+	}
+}
+
+// Stats returns cumulative hit/miss/eviction counters summed across every
+// shard.
+func (c *dedupeCache) Stats() DedupeStats {
+	var out DedupeStats
+	for _, s := range c.shards {
+		hits, misses, evictions := s.stats()
+		out.Hits += hits
+		out.Misses += misses
+		out.Evictions += evictions
+	}
+	return out
+}
+
+// countingBloom is a fixed-size counting bloom filter with 4-bit saturating
+// counters packed two per byte. It uses double hashing (Kirsch-Mitzenmacher)
+// derived from two independent 64-bit FNV hashes to synthesize k=4 index
+// functions without computing four separate hashes.
+type countingBloom struct {
+	counters []byte
+	m        uint32
+}
+
+const countingBloomK = 4
+
+func newCountingBloom(numCounters int) *countingBloom {
+	if numCounters < 64 {
+		numCounters = 64
+	}
+	return &countingBloom{
+		counters: make([]byte, (numCounters+1)/2),
+		m:        uint32(numCounters),
+	}
+}
+
+func (b *countingBloom) indices(key string) [countingBloomK]uint32 {
+	h1 := fnv.New64a()
+	_, _ = h1.Write([]byte(key))
+	sum1 := h1.Sum64()
+
+	h2 := fnv.New64()
+	_, _ = h2.Write([]byte(key))
+	sum2 := h2.Sum64()
+	if sum2 == 0 {
+		sum2 = 1 // avoid degenerating to a single index when sum2 is 0
+	}
+
+	var idx [countingBloomK]uint32
+	for i := 0; i < countingBloomK; i++ {
+		combined := sum1 + uint64(i)*sum2
+		idx[i] = uint32(combined % uint64(b.m))
+	}
+	return idx
+}
+
+func (b *countingBloom) counter(i uint32) byte {
+	v := b.counters[i/2]
+	if i%2 == 0 {
+		return v & 0x0F
+	}
+	return (v >> 4) & 0x0F
+}
+
+func (b *countingBloom) setCounter(i uint32, c byte) {
+	idx := i / 2
+	v := b.counters[idx]
+	if i%2 == 0 {
+		b.counters[idx] = (v & 0xF0) | (c & 0x0F)
+	} else {
+		b.counters[idx] = (v & 0x0F) | ((c & 0x0F) << 4)
+	}
+}
+
+func (b *countingBloom) add(key string) {
+	for _, i := range b.indices(key) {
+		if c := b.counter(i); c < 0x0F {
+			b.setCounter(i, c+1)
+		}
+	}
+}
+
+func (b *countingBloom) remove(key string) {
+	for _, i := range b.indices(key) {
+		if c := b.counter(i); c > 0 {
+			b.setCounter(i, c-1)
+		}
 	}
-	ttl := c.ttl
-	for k, ts := range c.items {
-		if now.Sub(ts) > ttl {
-			delete(c.items, k)
+}
+
+func (b *countingBloom) mayContain(key string) bool {
+	for _, i := range b.indices(key) {
+		if b.counter(i) == 0 {
+			return false
 		}
 	}
+	return true
 }
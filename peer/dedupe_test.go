@@ -0,0 +1,84 @@
+package peer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDedupeCacheMarkSeenDeduplicates(t *testing.T) {
+	c := newDedupeCache(time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	if !c.markSeen("PC11:K1ABC:W1AW:14074", now) {
+		t.Fatal("expected first sighting to be new")
+	}
+	if c.markSeen("PC11:K1ABC:W1AW:14074", now) {
+		t.Fatal("expected repeat key to be suppressed")
+	}
+}
+
+func TestDedupeCacheEvictsOldestOnceFull(t *testing.T) {
+	c := newDedupeCacheWithCapacity(time.Hour, dedupeShardCount) // 1 entry per shard
+	shard := c.shards[0]
+	now := time.Unix(1_700_000_000, 0)
+
+	// Force several keys into the same shard by brute-forcing suffixes.
+	var keys []string
+	for i := 0; len(keys) < 3; i++ {
+		k := fmt.Sprintf("k%d", i)
+		if c.shardFor(k) == shard {
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+		c.markSeen(k, now)
+	}
+	_, _, evictions := shard.stats()
+	if evictions == 0 {
+		t.Fatalf("expected at least one eviction once the shard exceeded its cap, stats=%+v", c.Stats())
+	}
+}
+
+func TestDedupeCachePruneRemovesExpiredEntries(t *testing.T) {
+	c := newDedupeCache(time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	c.markSeen("stale", now)
+
+	c.prune(now.Add(2 * time.Minute))
+
+	if !c.markSeen("stale", now.Add(2*time.Minute)) {
+		t.Fatal("expected expired key to be forgotten after prune")
+	}
+}
+
+func TestCountingBloomFalsePositiveRateIsBounded(t *testing.T) {
+	b := newCountingBloom(10_000 * dedupeBloomCountersPerK)
+	for i := 0; i < 5000; i++ {
+		b.add(fmt.Sprintf("present-%d", i))
+	}
+
+	falsePositives := 0
+	const trials = 5000
+	for i := 0; i < trials; i++ {
+		if b.mayContain(fmt.Sprintf("absent-%d", i)) {
+			falsePositives++
+		}
+	}
+	rate := float64(falsePositives) / float64(trials)
+	if rate > 0.05 {
+		t.Fatalf("false positive rate too high: %.4f", rate)
+	}
+}
+
+func TestCountingBloomRemoveDecrementsCounters(t *testing.T) {
+	b := newCountingBloom(64 * dedupeBloomCountersPerK)
+	b.add("only-key")
+	if !b.mayContain("only-key") {
+		t.Fatal("expected key to be present immediately after add")
+	}
+	b.remove("only-key")
+	if b.mayContain("only-key") {
+		t.Fatal("expected key to be absent after remove")
+	}
+}
@@ -0,0 +1,69 @@
+package peer
+
+import "fmt"
+
+// DisconnectReason classifies why a peer session ended, modeled on the
+// Ethereum p2p DiscReason enum: a small, stable set of reasons a reconnect
+// loop can switch on instead of pattern-matching error strings.
+type DisconnectReason int
+
+const (
+	DisconnectUnknown DisconnectReason = iota
+	DisconnectHandshakeTimeout
+	DisconnectAuthRejected
+	DisconnectProtocolError
+	DisconnectIdleTimeout
+	DisconnectMalformedFrame
+	DisconnectRemoteClosed
+	DisconnectNetworkError
+	DisconnectRateLimited
+)
+
+func (r DisconnectReason) String() string {
+	switch r {
+	case DisconnectHandshakeTimeout:
+		return "handshake_timeout"
+	case DisconnectAuthRejected:
+		return "auth_rejected"
+	case DisconnectProtocolError:
+		return "protocol_error"
+	case DisconnectIdleTimeout:
+		return "idle_timeout"
+	case DisconnectMalformedFrame:
+		return "malformed_frame"
+	case DisconnectRemoteClosed:
+		return "remote_closed"
+	case DisconnectNetworkError:
+		return "network_error"
+	case DisconnectRateLimited:
+		return "rate_limited"
+	default:
+		return "unknown"
+	}
+}
+
+// PeerError wraps a lower-level error with a DisconnectReason so a reconnect
+// loop can branch on why a session ended rather than matching error
+// strings. Fatal marks a reason worth giving up on outright (e.g. rejected
+// credentials are not going to start working on the next retry).
+type PeerError struct {
+	Reason     DisconnectReason
+	Underlying error
+	Fatal      bool
+}
+
+func (e *PeerError) Error() string {
+	if e.Underlying != nil {
+		return fmt.Sprintf("%s: %v", e.Reason, e.Underlying)
+	}
+	return e.Reason.String()
+}
+
+func (e *PeerError) Unwrap() error { return e.Underlying }
+
+// NewPeerError builds a PeerError for reason wrapping err. AuthRejected is
+// the only reason currently treated as fatal - every other reason describes
+// a condition a later retry might recover from.
+func NewPeerError(reason DisconnectReason, err error) *PeerError {
+	return &PeerError{Reason: reason, Underlying: err, Fatal: reason == DisconnectAuthRejected}
+}
@@ -1,25 +1,31 @@
 package peer
 
-import "dxcluster/config"
+import (
+	"encoding/json"
+
+	"dxcluster/config"
+)
 
 // PeerEndpoint wraps a configured peer.
 type PeerEndpoint struct {
-	host       string
-	port       int
-	loginCall  string
-	remoteCall string
-	password   string
-	preferPC9x bool
+	host          string
+	port          int
+	loginCall     string
+	remoteCall    string
+	password      string
+	preferPC9x    bool
+	backoffPolicy string
 }
 
 func newPeerEndpoint(p config.PeeringPeer) PeerEndpoint {
 	return PeerEndpoint{
-		host:       p.Host,
-		port:       p.Port,
-		loginCall:  p.LoginCallsign,
-		remoteCall: p.RemoteCallsign,
-		password:   p.Password,
-		preferPC9x: p.PreferPC9x,
+		host:          p.Host,
+		port:          p.Port,
+		loginCall:     p.LoginCallsign,
+		remoteCall:    p.RemoteCallsign,
+		password:      p.Password,
+		preferPC9x:    p.PreferPC9x,
+		backoffPolicy: p.BackoffPolicy,
 	}
 }
 
@@ -29,3 +35,61 @@ func (p PeerEndpoint) ID() string {
 	}
 	return p.host
 }
+
+// ToPeeringPeer converts a PeerEndpoint back into a config.PeeringPeer, for
+// callers that dial from an endpoint yielded by a dynamic PeerRegistry
+// (etcd/consul) rather than directly from the configured peer list.
+func (p PeerEndpoint) ToPeeringPeer() config.PeeringPeer {
+	return config.PeeringPeer{
+		Host:           p.host,
+		Port:           p.port,
+		LoginCallsign:  p.loginCall,
+		RemoteCallsign: p.remoteCall,
+		Password:       p.password,
+		PreferPC9x:     p.preferPC9x,
+		BackoffPolicy:  p.backoffPolicy,
+		Enabled:        true,
+	}
+}
+
+// peerEndpointWire is the JSON form a PeerEndpoint is advertised under in a
+// dynamic registry (etcd/consul); PeerEndpoint's own fields are unexported
+// so encoding/json can't see them directly.
+type peerEndpointWire struct {
+	Host          string `json:"host"`
+	Port          int    `json:"port"`
+	LoginCall     string `json:"login_call"`
+	RemoteCall    string `json:"remote_call"`
+	Password      string `json:"password"`
+	PreferPC9x    bool   `json:"prefer_pc9x"`
+	BackoffPolicy string `json:"backoff_policy"`
+}
+
+// MarshalJSON renders a PeerEndpoint for storage in a dynamic registry.
+func (p PeerEndpoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(peerEndpointWire{
+		Host:          p.host,
+		Port:          p.port,
+		LoginCall:     p.loginCall,
+		RemoteCall:    p.remoteCall,
+		Password:      p.password,
+		PreferPC9x:    p.preferPC9x,
+		BackoffPolicy: p.backoffPolicy,
+	})
+}
+
+// UnmarshalJSON restores a PeerEndpoint from a dynamic registry entry.
+func (p *PeerEndpoint) UnmarshalJSON(data []byte) error {
+	var w peerEndpointWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	p.host = w.Host
+	p.port = w.Port
+	p.loginCall = w.LoginCall
+	p.remoteCall = w.RemoteCall
+	p.password = w.Password
+	p.preferPC9x = w.PreferPC9x
+	p.backoffPolicy = w.BackoffPolicy
+	return nil
+}
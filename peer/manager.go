@@ -0,0 +1,43 @@
+package peer
+
+import (
+	"context"
+	"log"
+)
+
+// PeerManager drives a PeerRegistry's membership stream, dialing
+// newly-appearing peers and dropping removed ones without requiring a
+// process restart.
+type PeerManager struct {
+	registry PeerRegistry
+	dial     func(PeerEndpoint)
+	drop     func(PeerEndpoint)
+}
+
+// NewPeerManager builds a manager over registry; dial is invoked for every
+// PeerAdded event and drop for every PeerRemoved event. Callers typically
+// pass closures that start/stop a peerLoop goroutine per endpoint.
+func NewPeerManager(registry PeerRegistry, dial, drop func(PeerEndpoint)) *PeerManager {
+	return &PeerManager{registry: registry, dial: dial, drop: drop}
+}
+
+// Run watches the registry until ctx is canceled, invoking dial/drop as
+// membership changes arrive. It blocks, so callers run it in its own
+// goroutine.
+func (m *PeerManager) Run(ctx context.Context) error {
+	events, err := m.registry.Watch(ctx)
+	if err != nil {
+		return err
+	}
+	for ev := range events {
+		switch ev.Type {
+		case PeerAdded:
+			log.Printf("peer manager: %s added", ev.Endpoint.ID())
+			m.dial(ev.Endpoint)
+		case PeerRemoved:
+			log.Printf("peer manager: %s removed", ev.Endpoint.ID())
+			m.drop(ev.Endpoint)
+		}
+	}
+	return ctx.Err()
+}
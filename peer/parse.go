@@ -18,7 +18,7 @@ func parseSpotFromFrame(frame *Frame, fallbackOrigin string) (*spot.Spot, error)
 	if frame == nil {
 		return nil, fmt.Errorf("nil frame")
 	}
-	fields := frame.payloadFields()
+	fields := frame.PayloadFields()
 	switch frame.Type {
 	case "PC11":
 		return parsePC11(fields, frame.Hop, fallbackOrigin)
@@ -81,7 +81,7 @@ func parsePC61(fields []string, hop int, fallbackOrigin string) (*spot.Spot, err
 	if origin == "" {
 		origin = fallbackOrigin
 	}
-	// fields[7] user IP present but not stored in Spot; could be logged later.
+	spotterIP := strings.TrimSpace(fields[7])
 	ts := parsePCDateTime(date, timeStr)
 	mode, report, hasReport, cleaned := parseCommentModeReport(comment, freq)
 	s := spot.NewSpot(dx, spotter, freq, mode)
@@ -92,6 +92,7 @@ func parsePC61(fields []string, hop int, fallbackOrigin string) (*spot.Spot, err
 	s.Report = report
 	s.HasReport = hasReport
 	s.IsHuman = !hasReport
+	s.SpotterIP = spotterIP
 	if hop > 0 {
 		s.TTL = uint8(hop)
 	}
@@ -0,0 +1,45 @@
+package peer
+
+import "testing"
+
+func TestParsePC61WithSpotterIP(t *testing.T) {
+	f, err := ParseFrame("PC61^14074.0^W1AW^25-Nov-2024^2200Z^FT8 +05 DB^K1ABC^ARCLUSTER^203.0.113.7^H1")
+	if err != nil {
+		t.Fatalf("ParseFrame: %v", err)
+	}
+	s, err := parseSpotFromFrame(f, "fallback")
+	if err != nil {
+		t.Fatalf("parseSpotFromFrame: %v", err)
+	}
+	if s.SpotterIP != "203.0.113.7" {
+		t.Fatalf("expected SpotterIP %q, got %q", "203.0.113.7", s.SpotterIP)
+	}
+}
+
+func TestParsePC61WithoutSpotterIP(t *testing.T) {
+	f, err := ParseFrame("PC61^14074.0^W1AW^25-Nov-2024^2200Z^FT8 +05 DB^K1ABC^ARCLUSTER^^H1")
+	if err != nil {
+		t.Fatalf("ParseFrame: %v", err)
+	}
+	s, err := parseSpotFromFrame(f, "fallback")
+	if err != nil {
+		t.Fatalf("parseSpotFromFrame: %v", err)
+	}
+	if s.SpotterIP != "" {
+		t.Fatalf("expected empty SpotterIP, got %q", s.SpotterIP)
+	}
+}
+
+func TestParsePC11HasNoSpotterIP(t *testing.T) {
+	f, err := ParseFrame("PC11^14074.0^W1AW^25-Nov-2024^2200Z^FT8 +05 DB^K1ABC^ARCLUSTER^H1")
+	if err != nil {
+		t.Fatalf("ParseFrame: %v", err)
+	}
+	s, err := parseSpotFromFrame(f, "fallback")
+	if err != nil {
+		t.Fatalf("parseSpotFromFrame: %v", err)
+	}
+	if s.SpotterIP != "" {
+		t.Fatalf("expected empty SpotterIP on a PC11 spot, got %q", s.SpotterIP)
+	}
+}
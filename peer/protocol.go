@@ -116,14 +116,19 @@ func (f *Frame) Encode(hop int) string {
 	return out
 }
 
-// payloadFields returns non-hop payload fields with trailing empties preserved.
-func (f *Frame) payloadFields() []string {
+// PayloadFields returns non-hop payload fields with trailing empties preserved.
+func (f *Frame) PayloadFields() []string {
 	if f == nil {
 		return nil
 	}
 	fields := make([]string, len(f.Fields))
 	copy(fields, f.Fields)
-	// strip hop marker if present at end
+	// Wire frames end in a trailing "^", which Split turns into a trailing
+	// empty field after the hop marker; strip that before looking for the
+	// marker itself, or it's never recognized as being "at end".
+	for len(fields) > 0 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
 	if len(fields) > 0 {
 		last := strings.TrimSpace(fields[len(fields)-1])
 		if strings.HasPrefix(last, "H") || strings.HasPrefix(last, "h") {
@@ -132,3 +137,102 @@ func (f *Frame) payloadFields() []string {
 	}
 	return fields
 }
+
+// PC92NodeEntry is one call's bitmap/version/build/ip tuple within a PC92
+// announcement's entry chain.
+type PC92NodeEntry struct {
+	Bitmap  int
+	Call    string
+	Version string
+	Build   string
+	IP      string
+}
+
+// PC92Announcement is a parsed PC92 frame: the sending node, its subtype
+// (A=add, C=config, K=keepalive), and the chain of node entries describing
+// every hop between the origin and this peer. NodeCount/UserCount are only
+// populated for K frames, which carry them instead of a multi-hop chain.
+type PC92Announcement struct {
+	Sender    string
+	Subtype   string
+	Timestamp string
+	Entries   []PC92NodeEntry
+	NodeCount int
+	UserCount int
+	Hop       int
+}
+
+// ParsePC92 decodes a PC92 frame into its sender, subtype, and full entry
+// chain, so callers that need the whole path - topology tracking, loop
+// suppression, dup filters - don't each have to re-split the caret fields
+// themselves. It returns nil for anything that isn't a well-formed PC92
+// frame.
+func ParsePC92(frame *Frame) *PC92Announcement {
+	if frame == nil || frame.Type != "PC92" {
+		return nil
+	}
+	fields := frame.PayloadFields()
+	if len(fields) < 3 {
+		return nil
+	}
+	ann := &PC92Announcement{
+		Sender:    strings.ToUpper(strings.TrimSpace(fields[0])),
+		Timestamp: strings.TrimSpace(fields[1]),
+		Subtype:   strings.ToUpper(strings.TrimSpace(fields[2])),
+		Hop:       frame.Hop,
+	}
+	rest := fields[3:]
+
+	if ann.Subtype == "K" {
+		// Keepalive: a single entry for the sender itself, followed by node
+		// and user counts rather than further chain hops.
+		if len(rest) > 0 {
+			if entry := strings.TrimSpace(rest[0]); entry != "" {
+				ann.Entries = append(ann.Entries, parsePC92Entry(entry))
+			}
+		}
+		if len(rest) > 1 {
+			ann.NodeCount, _ = strconv.Atoi(strings.TrimSpace(rest[1]))
+		}
+		if len(rest) > 2 {
+			ann.UserCount, _ = strconv.Atoi(strings.TrimSpace(rest[2]))
+		}
+		return ann
+	}
+
+	// A (add) and C (config), and anything else we don't specifically
+	// recognize: every remaining non-empty field is one more hop in the
+	// chain between the origin and us.
+	for _, raw := range rest {
+		entry := strings.TrimSpace(raw)
+		if entry == "" {
+			continue
+		}
+		ann.Entries = append(ann.Entries, parsePC92Entry(entry))
+	}
+	return ann
+}
+
+// parsePC92Entry splits one PC92 chain entry of the form
+// <bitmap><call>:<version>[:<build>[:<ip>]] into its parts.
+func parsePC92Entry(entry string) PC92NodeEntry {
+	parts := strings.Split(entry, ":")
+	var e PC92NodeEntry
+	head := parts[0]
+	if len(head) > 0 {
+		e.Bitmap = int(head[0] - '0')
+		if len(head) > 1 {
+			e.Call = head[1:]
+		}
+	}
+	if len(parts) > 1 {
+		e.Version = parts[1]
+	}
+	if len(parts) > 2 {
+		e.Build = parts[2]
+	}
+	if len(parts) > 3 {
+		e.IP = parts[3]
+	}
+	return e
+}
@@ -0,0 +1,82 @@
+package peer
+
+import (
+	"context"
+
+	"dxcluster/config"
+)
+
+// PeerEventType classifies a PeerEvent as an arrival or departure.
+type PeerEventType int
+
+const (
+	PeerAdded PeerEventType = iota
+	PeerRemoved
+)
+
+func (t PeerEventType) String() string {
+	if t == PeerRemoved {
+		return "removed"
+	}
+	return "added"
+}
+
+// PeerEvent is one change in a PeerRegistry's membership: a peer appearing
+// or disappearing, keyed by Endpoint.ID() (the remote callsign).
+type PeerEvent struct {
+	Type     PeerEventType
+	Endpoint PeerEndpoint
+}
+
+// PeerRegistry is a source of peer membership that can change while the
+// process runs, so the peer manager can dial newly-appearing peers and drop
+// removed ones without a restart. Implementations: staticPeerRegistry (the
+// fixed YAML peer list), etcdPeerRegistry, and consulPeerRegistry.
+type PeerRegistry interface {
+	// Watch streams membership changes until ctx is canceled, at which
+	// point it closes the returned channel. Implementations that can
+	// enumerate an initial membership (etcd/consul) emit a PeerAdded event
+	// per existing entry before any subsequent change.
+	Watch(ctx context.Context) (<-chan PeerEvent, error)
+
+	// Register advertises self in the registry under a lease/TTL, renewing
+	// it for as long as ctx remains live, and removes the advertisement
+	// when ctx is done. It returns once the initial registration succeeds;
+	// renewal continues in a background goroutine. Backends with no
+	// concept of self-registration (static) treat this as a no-op.
+	Register(ctx context.Context, self PeerEndpoint) error
+}
+
+// staticPeerRegistry adapts the fixed config.PeeringPeer list to
+// PeerRegistry: membership never changes after the single initial batch of
+// PeerAdded events, and self-registration is a no-op since there's no
+// external store to advertise into.
+type staticPeerRegistry struct {
+	peers []config.PeeringPeer
+}
+
+// NewStaticPeerRegistry wraps a fixed, YAML-configured peer list as a
+// PeerRegistry so it can be driven by the same PeerManager as the dynamic
+// backends.
+func NewStaticPeerRegistry(peers []config.PeeringPeer) PeerRegistry {
+	return &staticPeerRegistry{peers: peers}
+}
+
+func (r *staticPeerRegistry) Watch(ctx context.Context) (<-chan PeerEvent, error) {
+	ch := make(chan PeerEvent, len(r.peers))
+	for _, p := range r.peers {
+		if !p.Enabled {
+			continue
+		}
+		ch <- PeerEvent{Type: PeerAdded, Endpoint: newPeerEndpoint(p)}
+	}
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (r *staticPeerRegistry) Register(ctx context.Context, self PeerEndpoint) error {
+	return nil
+}
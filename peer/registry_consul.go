@@ -0,0 +1,178 @@
+package peer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"dxcluster/config"
+)
+
+// consulPeerRegistry backs PeerRegistry with a Consul service: every node
+// registers itself as a service instance named cfg.BasePath, carrying a
+// JSON-encoded PeerEndpoint in its tags' sibling metadata so peers can be
+// reconstructed from the catalog without a separate KV fetch.
+type consulPeerRegistry struct {
+	client *api.Client
+	cfg    config.ConsulRegistryConfig
+}
+
+// NewConsulPeerRegistry builds a client for the Consul agent described by
+// cfg; no network call happens until Watch/Register are used.
+func NewConsulPeerRegistry(cfg config.ConsulRegistryConfig) (*consulPeerRegistry, error) {
+	apiCfg := api.DefaultConfig()
+	if cfg.Address != "" {
+		apiCfg.Address = cfg.Address
+	}
+	if cfg.Token != "" {
+		apiCfg.Token = cfg.Token
+	}
+	client, err := api.NewClient(apiCfg)
+	if err != nil {
+		return nil, fmt.Errorf("consul registry: new client: %w", err)
+	}
+	return &consulPeerRegistry{client: client, cfg: cfg}, nil
+}
+
+func (r *consulPeerRegistry) serviceName() string {
+	if r.cfg.BasePath != "" {
+		return r.cfg.BasePath
+	}
+	return "dxcluster-peer"
+}
+
+func (r *consulPeerRegistry) timeout() time.Duration {
+	if r.cfg.TimeoutSeconds > 0 {
+		return time.Duration(r.cfg.TimeoutSeconds) * time.Second
+	}
+	return 5 * time.Second
+}
+
+// Watch polls Consul's blocking-query catalog endpoint for the peer service
+// and diffs each response against the last-known membership, translating
+// additions and removals into PeerEvents.
+func (r *consulPeerRegistry) Watch(ctx context.Context) (<-chan PeerEvent, error) {
+	ch := make(chan PeerEvent, 16)
+	go func() {
+		defer close(ch)
+		known := make(map[string]PeerEndpoint)
+		var waitIndex uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			services, meta, err := r.client.Health().Service(r.serviceName(), "", true, (&api.QueryOptions{
+				WaitIndex: waitIndex,
+				WaitTime:  r.timeout(),
+			}).WithContext(ctx))
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				time.Sleep(time.Second)
+				continue
+			}
+			waitIndex = meta.LastIndex
+
+			current := make(map[string]PeerEndpoint, len(services))
+			for _, svc := range services {
+				ep, ok := peerEndpointFromConsulMeta(svc.Service.Meta)
+				if !ok {
+					continue
+				}
+				current[ep.ID()] = ep
+			}
+
+			for id, ep := range current {
+				if prev, ok := known[id]; !ok || !reflect.DeepEqual(prev, ep) {
+					ch <- PeerEvent{Type: PeerAdded, Endpoint: ep}
+				}
+			}
+			for id, ep := range known {
+				if _, ok := current[id]; !ok {
+					ch <- PeerEvent{Type: PeerRemoved, Endpoint: ep}
+				}
+			}
+			known = current
+		}
+	}()
+	return ch, nil
+}
+
+// Register advertises self as a Consul service instance with a TTL health
+// check, renewing it on a third-of-TTL cadence for as long as ctx is live,
+// then deregisters on the way out.
+func (r *consulPeerRegistry) Register(ctx context.Context, self PeerEndpoint) error {
+	const ttl = 30 * time.Second
+	checkID := "dxcluster-peer-" + self.ID()
+
+	meta, err := peerEndpointToConsulMeta(self)
+	if err != nil {
+		return fmt.Errorf("consul registry: encode self: %w", err)
+	}
+
+	reg := &api.AgentServiceRegistration{
+		ID:      "dxcluster-peer-" + self.ID(),
+		Name:    r.serviceName(),
+		Address: self.host,
+		Port:    self.port,
+		Meta:    meta,
+		Check: &api.AgentServiceCheck{
+			CheckID:                        checkID,
+			TTL:                            ttl.String(),
+			DeregisterCriticalServiceAfter: (3 * ttl).String(),
+		},
+	}
+	if err := r.client.Agent().ServiceRegister(reg); err != nil {
+		return fmt.Errorf("consul registry: register: %w", err)
+	}
+	if err := r.client.Agent().UpdateTTL(checkID, "", api.HealthPassing); err != nil {
+		return fmt.Errorf("consul registry: initial TTL pass: %w", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = r.client.Agent().UpdateTTL(checkID, "", api.HealthPassing)
+			case <-ctx.Done():
+				_ = r.client.Agent().ServiceDeregister(reg.ID)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+const consulMetaEndpointKey = "peer_endpoint"
+
+// peerEndpointToConsulMeta packs a PeerEndpoint into the single metadata
+// field Consul allows us to diff a service instance on.
+func peerEndpointToConsulMeta(ep PeerEndpoint) (map[string]string, error) {
+	raw, err := ep.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{consulMetaEndpointKey: string(raw)}, nil
+}
+
+func peerEndpointFromConsulMeta(meta map[string]string) (PeerEndpoint, bool) {
+	raw, ok := meta[consulMetaEndpointKey]
+	if !ok {
+		return PeerEndpoint{}, false
+	}
+	var ep PeerEndpoint
+	if err := json.Unmarshal([]byte(raw), &ep); err != nil {
+		return PeerEndpoint{}, false
+	}
+	return ep, true
+}
@@ -0,0 +1,121 @@
+package peer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"dxcluster/config"
+)
+
+// etcdPeerRegistry backs PeerRegistry with an etcd v3 keyspace: every node
+// is a key under basePath/serverPath keyed by remote callsign, holding a
+// JSON-encoded PeerEndpoint as its value.
+type etcdPeerRegistry struct {
+	client *clientv3.Client
+	cfg    config.EtcdRegistryConfig
+}
+
+// NewEtcdPeerRegistry dials the etcd cluster described by cfg. The
+// connection is lazy (etcd v3 clients dial on first use), so this only
+// fails on malformed config.
+func NewEtcdPeerRegistry(cfg config.EtcdRegistryConfig) (*etcdPeerRegistry, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("etcd registry: no endpoints configured")
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		DialTimeout: timeout,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("etcd registry: dial: %w", err)
+	}
+	return &etcdPeerRegistry{client: client, cfg: cfg}, nil
+}
+
+// prefix is the etcd key prefix every advertised node lives under.
+func (r *etcdPeerRegistry) prefix() string {
+	return path.Join(r.cfg.BasePath, r.cfg.ServerPath) + "/"
+}
+
+func (r *etcdPeerRegistry) Watch(ctx context.Context) (<-chan PeerEvent, error) {
+	prefix := r.prefix()
+	initial, err := r.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("etcd registry: initial get: %w", err)
+	}
+
+	ch := make(chan PeerEvent, len(initial.Kvs)+16)
+	for _, kv := range initial.Kvs {
+		var ep PeerEndpoint
+		if err := ep.UnmarshalJSON(kv.Value); err != nil {
+			continue
+		}
+		ch <- PeerEvent{Type: PeerAdded, Endpoint: ep}
+	}
+
+	watchCh := r.client.Watch(ctx, prefix, clientv3.WithPrefix(), clientv3.WithRev(initial.Header.Revision+1))
+	go func() {
+		defer close(ch)
+		for resp := range watchCh {
+			for _, ev := range resp.Events {
+				switch ev.Type {
+				case clientv3.EventTypePut:
+					var ep PeerEndpoint
+					if err := ep.UnmarshalJSON(ev.Kv.Value); err != nil {
+						continue
+					}
+					ch <- PeerEvent{Type: PeerAdded, Endpoint: ep}
+				case clientv3.EventTypeDelete:
+					// The value is gone by the time a delete is observed;
+					// the remote callsign baked into the key is all we have.
+					call := strings.TrimPrefix(string(ev.Kv.Key), prefix)
+					ch <- PeerEvent{Type: PeerRemoved, Endpoint: PeerEndpoint{remoteCall: call}}
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (r *etcdPeerRegistry) Register(ctx context.Context, self PeerEndpoint) error {
+	const leaseTTLSeconds = 30
+
+	lease, err := r.client.Grant(ctx, leaseTTLSeconds)
+	if err != nil {
+		return fmt.Errorf("etcd registry: grant lease: %w", err)
+	}
+
+	value, err := self.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("etcd registry: encode self: %w", err)
+	}
+	key := r.prefix() + self.ID()
+	if _, err := r.client.Put(ctx, key, string(value), clientv3.WithLease(lease.ID)); err != nil {
+		return fmt.Errorf("etcd registry: put self: %w", err)
+	}
+
+	keepAlive, err := r.client.KeepAlive(ctx, lease.ID)
+	if err != nil {
+		return fmt.Errorf("etcd registry: keepalive: %w", err)
+	}
+	go func() {
+		for range keepAlive {
+			// Drain renewal acks; the lease stays alive as long as ctx does.
+		}
+		revokeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = r.client.Revoke(revokeCtx, lease.ID)
+	}()
+	return nil
+}
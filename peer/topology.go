@@ -11,12 +11,21 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-type topologyStore struct {
+// TopologyStore persists the mesh topology learned from PC92 entry chains:
+// a flat log of peer_nodes sightings plus the peer_edges parent/child
+// relationships derived from them, queryable via Neighbors/Path/Snapshot.
+// Call Ingest for every frame read off a peer connection to populate it -
+// cmd/peerprobe does this when started with -topology_db set; cmd/topology
+// then opens the resulting DB through OpenTopologyStore (read-write, not in
+// read-only mode) to query it.
+type TopologyStore struct {
 	db        *sql.DB
 	retention time.Duration
 }
 
-func openTopologyStore(path string, retention time.Duration) (*topologyStore, error) {
+// OpenTopologyStore opens (creating if needed) the SQLite-backed topology
+// store at path.
+func OpenTopologyStore(path string, retention time.Duration) (*TopologyStore, error) {
 	if dir := filepath.Dir(path); dir != "" && dir != "." {
 		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return nil, err
@@ -32,7 +41,10 @@ func openTopologyStore(path string, retention time.Duration) (*topologyStore, er
 	if err := ensurePeerNodesSchema(db); err != nil {
 		return nil, err
 	}
-	return &topologyStore{db: db, retention: retention}, nil
+	if err := ensurePeerEdgesSchema(db); err != nil {
+		return nil, err
+	}
+	return &TopologyStore{db: db, retention: retention}, nil
 }
 
 func ensurePeerNodesSchema(db *sql.DB) error {
@@ -75,6 +87,25 @@ func ensurePeerNodesSchema(db *sql.DB) error {
 	return nil
 }
 
+// ensurePeerEdgesSchema creates the parent/child table applyPC92 populates
+// alongside the flat peer_nodes rows. Unlike peer_nodes it's keyed on the
+// relationship itself, so a repeated sighting of the same edge updates
+// last_seen in place rather than growing the table.
+func ensurePeerEdgesSchema(db *sql.DB) error {
+	schema := `
+	create table if not exists peer_edges (
+		parent_call text not null,
+		child_call text not null,
+		first_seen integer,
+		last_seen integer,
+		primary key (parent_call, child_call)
+	);
+	create index if not exists idx_peer_edges_child on peer_edges(child_call);
+	`
+	_, err := db.Exec(schema)
+	return err
+}
+
 func fetchColumns(db *sql.DB, table string) (map[string]struct{}, error) {
 	rows, err := db.Query(fmt.Sprintf("pragma table_info(%s);", table))
 	if err != nil {
@@ -95,24 +126,57 @@ func fetchColumns(db *sql.DB, table string) (map[string]struct{}, error) {
 	return cols, rows.Err()
 }
 
-func (t *topologyStore) applyPC92(frame *Frame, now time.Time) {
+// Ingest records one frame's sighting into the store: a PC92 frame expands
+// into its full entry chain and the edges between hops (applyPC92), and
+// anything else is recorded as a single bare sighting of its origin
+// (applyLegacy). now is the frame's arrival time, used for the sighting's
+// updated_at/first_seen/last_seen columns and later honoured by prune.
+func (t *TopologyStore) Ingest(frame *Frame, now time.Time) {
+	if t == nil || frame == nil {
+		return
+	}
+	if frame.Type == "PC92" {
+		t.applyPC92(frame, now)
+		return
+	}
+	t.applyLegacy(frame, now)
+}
+
+// applyPC92 records every node in the PC92 entry chain and the parent->child
+// edges linking them: ann.Sender is the start of the chain, and each
+// subsequent entry's call becomes the parent of the next, so a frame that
+// has crossed several hops before reaching us still yields one edge per hop
+// rather than just a single sender->last-entry edge.
+func (t *TopologyStore) applyPC92(frame *Frame, now time.Time) {
 	if t == nil || frame == nil {
 		return
 	}
-	fields := frame.payloadFields()
-	if len(fields) < 3 {
+	ann := ParsePC92(frame)
+	if ann == nil || len(ann.Entries) == 0 {
 		return
 	}
-	entry := strings.TrimSpace(fields[1])
-	if entry == "" {
-		entry = strings.TrimSpace(fields[2])
+	parent := ann.Sender
+	if parent != "" && strings.ToUpper(strings.TrimSpace(ann.Entries[0].Call)) != parent {
+		// The sender heads the chain without being one of its entries:
+		// record a bare sighting so lookups can resolve it like any node.
+		_, _ = t.db.Exec(`insert into peer_nodes(origin, bitmap, call, version, build, ip, updated_at) values(?,?,?,?,?,?,?)`,
+			frame.Type, 0, parent, "", "", "", now.Unix())
+	}
+	for _, entry := range ann.Entries {
+		call := strings.ToUpper(strings.TrimSpace(entry.Call))
+		if call == "" {
+			continue
+		}
+		_, _ = t.db.Exec(`insert into peer_nodes(origin, bitmap, call, version, build, ip, updated_at) values(?,?,?,?,?,?,?)`,
+			frame.Type, entry.Bitmap, call, entry.Version, entry.Build, entry.IP, now.Unix())
+		if parent != "" && parent != call {
+			t.recordEdge(parent, call, now)
+		}
+		parent = call
 	}
-	bitmap, call, version, build, ip := parsePC92Entry(entry)
-	_, _ = t.db.Exec(`insert into peer_nodes(origin, bitmap, call, version, build, ip, updated_at) values(?,?,?,?,?,?,?)`,
-		frame.Type, bitmap, call, version, build, ip, now.Unix())
 }
 
-func (t *topologyStore) applyLegacy(frame *Frame, now time.Time) {
+func (t *TopologyStore) applyLegacy(frame *Frame, now time.Time) {
 	if t == nil {
 		return
 	}
@@ -120,39 +184,193 @@ func (t *topologyStore) applyLegacy(frame *Frame, now time.Time) {
 		frame.Type, 0, "", "", "", "", now.Unix())
 }
 
-func (t *topologyStore) prune(now time.Time) {
+// recordEdge upserts one parent->child sighting: first_seen is set once,
+// last_seen advances on every repeat sighting of the same edge.
+func (t *TopologyStore) recordEdge(parent, child string, now time.Time) {
+	if t == nil {
+		return
+	}
+	ts := now.Unix()
+	_, _ = t.db.Exec(`
+		insert into peer_edges(parent_call, child_call, first_seen, last_seen)
+		values(?, ?, ?, ?)
+		on conflict(parent_call, child_call) do update set last_seen = excluded.last_seen
+	`, parent, child, ts, ts)
+}
+
+// Prune deletes peer_nodes/peer_edges rows older than the store's retention,
+// relative to now. Callers populating the store in production (cmd/peerprobe)
+// should call this on a timer - Ingest never prunes on its own, so without a
+// periodic Prune call the database grows without bound.
+func (t *TopologyStore) Prune(now time.Time) {
 	if t == nil {
 		return
 	}
 	cutoff := now.Add(-t.retention).Unix()
 	_, _ = t.db.Exec(`delete from peer_nodes where updated_at < ?`, cutoff)
+	_, _ = t.db.Exec(`delete from peer_edges where last_seen < ?`, cutoff)
 }
 
-func (t *topologyStore) Close() error {
+func (t *TopologyStore) Close() error {
 	if t == nil {
 		return nil
 	}
 	return t.db.Close()
 }
 
-func parsePC92Entry(entry string) (bitmap int, call, version, build, ip string) {
-	// entry format: <bitmap><call>:<version>[:<build>[:<ip>]]
-	parts := strings.Split(entry, ":")
-	head := parts[0]
-	if len(head) > 0 {
-		bitmap = int(head[0] - '0')
-		if len(head) > 1 {
-			call = head[1:]
+// NodeInfo is the most recently observed state of one mesh node.
+type NodeInfo struct {
+	Call     string
+	Version  string
+	Build    string
+	IP       string
+	LastSeen time.Time
+}
+
+// Edge is one parent/child relationship the topology store has observed,
+// derived from a PC92 entry chain.
+type Edge struct {
+	Parent    string
+	Child     string
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Neighbors returns the nodes directly connected to call in the learned
+// mesh graph, in either direction (call as parent or as child).
+func (t *TopologyStore) Neighbors(call string) []NodeInfo {
+	if t == nil {
+		return nil
+	}
+	call = strings.ToUpper(strings.TrimSpace(call))
+	if call == "" {
+		return nil
+	}
+	rows, err := t.db.Query(`
+		select case when parent_call = ? then child_call else parent_call end
+		from peer_edges
+		where parent_call = ? or child_call = ?
+	`, call, call, call)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var calls []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			continue
+		}
+		calls = append(calls, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil
+	}
+	return t.lookupNodes(calls)
+}
+
+// lookupNodes resolves each call to its most recently seen peer_nodes row,
+// skipping duplicates and calls with no known sighting.
+func (t *TopologyStore) lookupNodes(calls []string) []NodeInfo {
+	var infos []NodeInfo
+	seen := make(map[string]bool, len(calls))
+	for _, c := range calls {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		var info NodeInfo
+		var updatedAt int64
+		err := t.db.QueryRow(`
+			select call, version, build, ip, updated_at
+			from peer_nodes
+			where call = ?
+			order by updated_at desc
+			limit 1
+		`, c).Scan(&info.Call, &info.Version, &info.Build, &info.IP, &updatedAt)
+		if err != nil {
+			continue
+		}
+		info.LastSeen = time.Unix(updatedAt, 0).UTC()
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Snapshot returns every currently-known parent/child edge, i.e. the DAG
+// of nodes built up from PC92 entry chains so far.
+func (t *TopologyStore) Snapshot() []Edge {
+	if t == nil {
+		return nil
+	}
+	rows, err := t.db.Query(`select parent_call, child_call, first_seen, last_seen from peer_edges order by parent_call, child_call`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var edges []Edge
+	for rows.Next() {
+		var e Edge
+		var first, last int64
+		if err := rows.Scan(&e.Parent, &e.Child, &first, &last); err != nil {
+			continue
 		}
+		e.FirstSeen = time.Unix(first, 0).UTC()
+		e.LastSeen = time.Unix(last, 0).UTC()
+		edges = append(edges, e)
 	}
-	if len(parts) > 1 {
-		version = parts[1]
+	return edges
+}
+
+// Path finds a route from from to to over the learned mesh graph via BFS,
+// treating edges as undirected since a spot can propagate either way along
+// a peering link. It tracks visited nodes so a cycle in the underlying
+// graph (two nodes that both peer with each other, or a longer loop) can
+// never be walked twice - an edge back into an already-visited node is
+// simply skipped rather than followed. Returns nil if no route exists.
+func (t *TopologyStore) Path(from, to string) []string {
+	if t == nil {
+		return nil
 	}
-	if len(parts) > 2 {
-		build = parts[2]
+	from = strings.ToUpper(strings.TrimSpace(from))
+	to = strings.ToUpper(strings.TrimSpace(to))
+	if from == "" || to == "" {
+		return nil
+	}
+	if from == to {
+		return []string{from}
 	}
-	if len(parts) > 3 {
-		ip = parts[3]
+
+	adjacency := make(map[string][]string)
+	for _, e := range t.Snapshot() {
+		adjacency[e.Parent] = append(adjacency[e.Parent], e.Child)
+		adjacency[e.Child] = append(adjacency[e.Child], e.Parent)
+	}
+
+	type frontier struct {
+		call string
+		path []string
 	}
-	return
+	visited := map[string]bool{from: true}
+	queue := []frontier{{call: from, path: []string{from}}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[cur.call] {
+			if visited[next] {
+				continue
+			}
+			path := append(append([]string{}, cur.path...), next)
+			if next == to {
+				return path
+			}
+			visited[next] = true
+			queue = append(queue, frontier{call: next, path: path})
+		}
+	}
+	return nil
 }
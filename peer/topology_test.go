@@ -0,0 +1,85 @@
+package peer
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestTopologyStore(t *testing.T) *TopologyStore {
+	t.Helper()
+	store, err := OpenTopologyStore(filepath.Join(t.TempDir(), "topology.db"), 0)
+	if err != nil {
+		t.Fatalf("OpenTopologyStore: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func mustPC92Frame(t *testing.T, line string) *Frame {
+	t.Helper()
+	f, err := ParseFrame(line)
+	if err != nil {
+		t.Fatalf("ParseFrame(%q): %v", line, err)
+	}
+	return f
+}
+
+func TestIngestPC92RecordsChainAndEdges(t *testing.T) {
+	store := newTestTopologyStore(t)
+	now := time.Unix(1_700_000_000, 0)
+
+	frame := mustPC92Frame(t, "PC92^N2WQ^12345^A^1W1AW:1.0:BUILD1^2K1ABC:2.0^H2")
+	store.Ingest(frame, now)
+
+	edges := store.Snapshot()
+	if len(edges) != 2 {
+		t.Fatalf("expected 2 edges (N2WQ->W1AW, W1AW->K1ABC), got %d: %+v", len(edges), edges)
+	}
+	if edges[0].Parent != "N2WQ" || edges[0].Child != "W1AW" {
+		t.Fatalf("unexpected first edge: %+v", edges[0])
+	}
+	if edges[1].Parent != "W1AW" || edges[1].Child != "K1ABC" {
+		t.Fatalf("unexpected second edge: %+v", edges[1])
+	}
+
+	neighbors := store.Neighbors("W1AW")
+	if len(neighbors) != 2 {
+		t.Fatalf("expected W1AW to have 2 neighbors, got %d: %+v", len(neighbors), neighbors)
+	}
+}
+
+func TestIngestPC92PathFindsRouteAcrossHops(t *testing.T) {
+	store := newTestTopologyStore(t)
+	now := time.Unix(1_700_000_000, 0)
+
+	frame := mustPC92Frame(t, "PC92^N2WQ^12345^A^1W1AW:1.0^2K1ABC:2.0^H2")
+	store.Ingest(frame, now)
+
+	path := store.Path("N2WQ", "K1ABC")
+	want := []string{"N2WQ", "W1AW", "K1ABC"}
+	if len(path) != len(want) {
+		t.Fatalf("expected path %v, got %v", want, path)
+	}
+	for i, call := range want {
+		if path[i] != call {
+			t.Fatalf("expected path %v, got %v", want, path)
+		}
+	}
+}
+
+func TestIngestLegacyFrameRecordsBareSighting(t *testing.T) {
+	store := newTestTopologyStore(t)
+	now := time.Unix(1_700_000_000, 0)
+
+	frame := mustPC92Frame(t, "PC51^N2WQ^1^H1")
+	store.Ingest(frame, now)
+
+	var count int
+	if err := store.db.QueryRow(`select count(*) from peer_nodes where origin = ?`, "PC51").Scan(&count); err != nil {
+		t.Fatalf("count peer_nodes: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected applyLegacy to record 1 bare sighting for PC51, got %d", count)
+	}
+}
@@ -0,0 +1,117 @@
+package rbn
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"dxcluster/backoff"
+)
+
+// BackoffPolicy decides how long connectionSupervisor should wait before its
+// next reconnect attempt, and when to stop trying altogether, so tests and
+// operators can substitute constant, decorrelated-jitter, or no-op policies
+// in place of the default full-jitter one.
+type BackoffPolicy interface {
+	// Next returns the delay before reconnect attempt n (n starts at 1 for the
+	// first retry after a disconnect). ok is false once the policy has given
+	// up, at which point the caller stops retrying instead of sleeping.
+	Next(n int) (delay time.Duration, ok bool)
+
+	// Reset is called after a successful (re)connection, so the next
+	// disconnect starts backing off from the beginning again.
+	Reset()
+}
+
+// JitteredBackoff is a BackoffPolicy built on the same jitter math
+// peer.Policy uses for gossip reconnects (dxcluster/backoff), plus an
+// optional MaxElapsed give-up deadline peer reconnects don't need. The zero
+// value's Policy is nil, so construct one with NewFullJitterBackoff or
+// NewDecorrelatedJitterBackoff rather than &JitteredBackoff{}.
+type JitteredBackoff struct {
+	Base   time.Duration
+	Max    time.Duration
+	Policy backoff.Policy
+
+	// MaxElapsed caps how long Next keeps returning ok=true, measured from
+	// the first Next call after construction or the last Reset. Zero means
+	// retry forever.
+	MaxElapsed time.Duration
+
+	mu    sync.Mutex
+	start time.Time
+	prev  time.Duration
+	rng   *rand.Rand
+}
+
+// NewFullJitterBackoff returns the default BackoffPolicy: AWS-style
+// full-jitter exponential backoff, the kth Next call since construction or
+// the last Reset (k starting at 1) sleeping a duration chosen uniformly at
+// random from [0, min(maxDelay, initialDelay*2^(k-1))); the n argument is
+// not consulted. maxElapsed of zero means never give up.
+func NewFullJitterBackoff(initialDelay, maxDelay, maxElapsed time.Duration) *JitteredBackoff {
+	return &JitteredBackoff{
+		Base:       initialDelay,
+		Max:        maxDelay,
+		MaxElapsed: maxElapsed,
+		Policy:     backoff.PolicyFullJitter{},
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// NewDecorrelatedJitterBackoff returns a BackoffPolicy using AWS's
+// "decorrelated jitter" variant, which spreads retries out more than full
+// jitter while still growing attempt over attempt. maxElapsed of zero means
+// never give up.
+func NewDecorrelatedJitterBackoff(base, maxDelay, maxElapsed time.Duration) *JitteredBackoff {
+	return &JitteredBackoff{
+		Base:       base,
+		Max:        maxDelay,
+		MaxElapsed: maxElapsed,
+		Policy:     backoff.PolicyDecorrelatedJitter{},
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (b *JitteredBackoff) Next(n int) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.start.IsZero() {
+		b.start = time.Now()
+	}
+	if b.MaxElapsed > 0 && time.Since(b.start) >= b.MaxElapsed {
+		return 0, false
+	}
+
+	policy := b.Policy
+	if policy == nil {
+		policy = backoff.PolicyFullJitter{}
+	}
+	delay, next := policy.Next(b.Base, b.Max, b.prev, b.rng)
+	b.prev = next
+	return delay, true
+}
+
+func (b *JitteredBackoff) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.start = time.Time{}
+	b.prev = 0
+}
+
+// ConstantBackoff always waits the same Delay and never gives up. Useful in
+// tests that want deterministic, non-jittered reconnect timing.
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+func (b ConstantBackoff) Next(int) (time.Duration, bool) { return b.Delay, true }
+func (b ConstantBackoff) Reset()                         {}
+
+// NoBackoff retries immediately and never gives up. Useful in tests that
+// want reconnects to happen as fast as the supervisor loop can run.
+type NoBackoff struct{}
+
+func (NoBackoff) Next(int) (time.Duration, bool) { return 0, true }
+func (NoBackoff) Reset()                         {}
@@ -5,6 +5,7 @@ package rbn
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
@@ -14,11 +15,15 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"os"
 
 	"dxcluster/cty"
+	"dxcluster/dialer"
+	"dxcluster/enrich"
+	"dxcluster/filter"
 	"dxcluster/skew"
 	"dxcluster/spot"
 	"dxcluster/uls"
@@ -73,6 +78,23 @@ type Client struct {
 	unlicensedQueue    chan unlicensedEvent
 
 	minimalParse bool
+
+	otlp *otlpExporter
+
+	backoff           BackoffPolicy
+	spotChanCloseOnce sync.Once
+
+	tlsConfig *tls.Config
+
+	filterProfile FilterProfile
+
+	serverInfoMu   sync.RWMutex
+	serverSoftware string
+	serverVersion  string
+	serverCaps     map[string]bool
+
+	enricher    *enrich.Manager
+	alertEngine *filter.AlertEngine
 }
 
 type modeAllocation struct {
@@ -126,6 +148,8 @@ type acNode struct {
 type acScanner struct {
 	patterns []acPattern
 	nodes    []acNode
+
+	matchPool sync.Pool
 }
 
 func newACScanner(patterns []acPattern) *acScanner {
@@ -133,6 +157,7 @@ func newACScanner(patterns []acPattern) *acScanner {
 		patterns: patterns,
 		nodes:    []acNode{{next: make(map[byte]int)}},
 	}
+	sc.matchPool.New = func() any { return make([]acMatch, 0, 8) }
 	for idx, p := range patterns {
 		state := 0
 		for i := 0; i < len(p.word); i++ {
@@ -178,7 +203,7 @@ func (sc *acScanner) FindAll(text string) []acMatch {
 		return nil
 	}
 	state := 0
-	matches := make([]acMatch, 0, 8)
+	matches := sc.matchPool.Get().([]acMatch)[:0]
 	for i := 0; i < len(text); i++ {
 		ch := text[i]
 		next, ok := sc.nodes[state].next[ch]
@@ -204,6 +229,18 @@ func (sc *acScanner) FindAll(text string) []acMatch {
 	return matches
 }
 
+// Release returns matches, as returned by FindAll, to sc's internal pool so
+// the next FindAll call can reuse its backing array instead of allocating a
+// new one. Callers should call Release once they're done reading matches
+// (buildMatchIndex, for instance, copies each acMatch by value into its map,
+// so the slice is safe to release right after).
+func (sc *acScanner) Release(matches []acMatch) {
+	if sc == nil || matches == nil {
+		return
+	}
+	sc.matchPool.Put(matches[:0])
+}
+
 func buildMatchIndex(matches []acMatch) map[int][]acMatch {
 	if len(matches) == 0 {
 		return nil
@@ -233,7 +270,10 @@ func classifyTokenWithFallback(matchIndex map[int][]acMatch, tok spotToken) (acP
 	}
 	// Fallback: scan the token itself to tolerate any positional drift from the
 	// global match index (e.g., doubled spaces or trimmed punctuation).
-	for _, m := range getKeywordScanner().FindAll(tok.upper) {
+	scanner := getKeywordScanner()
+	matches := scanner.FindAll(tok.upper)
+	defer scanner.Release(matches)
+	for _, m := range matches {
 		if m.start == 0 && m.end == len(tok.upper) {
 			return m.pattern, true
 		}
@@ -241,7 +281,23 @@ func classifyTokenWithFallback(matchIndex map[int][]acMatch, tok spotToken) (acP
 	return acPattern{}, false
 }
 
-var keywordPatterns = []acPattern{
+// KeywordPattern is the YAML/API form of a single Aho-Corasick keyword the
+// line parser matches against - a mode name, or a structural token like
+// "DX"/"DE"/"dB"/"WPM". Word is matched case-insensitively (patterns are
+// uppercased on load and compared against the uppercased token stream); Kind
+// selects the acTokenKind the match is classified as ("dx", "de", "db",
+// "wpm", or "mode"); Mode is only meaningful when Kind is "mode".
+type KeywordPattern struct {
+	Word string `yaml:"word"`
+	Kind string `yaml:"kind"`
+	Mode string `yaml:"mode"`
+}
+
+// defaultKeywordPatterns is used whenever config/rbn_keywords.yaml (or its
+// parent-directory fallback, see loadKeywordPatterns) can't be found or
+// parsed, so a missing config file degrades to the client's historical
+// behavior instead of leaving it unable to classify any token at all.
+var defaultKeywordPatterns = []acPattern{
 	{word: "DX", kind: acTokenDX},
 	{word: "DE", kind: acTokenDE},
 	{word: "DB", kind: acTokenDB},
@@ -261,14 +317,120 @@ var keywordPatterns = []acPattern{
 	{word: "SSB", kind: acTokenMode, mode: "SSB"},
 }
 
-var keywordScannerOnce sync.Once
-var keywordScanner *acScanner
+const keywordConfigPath = "config/rbn_keywords.yaml"
+
+type keywordDictionary struct {
+	Keywords []KeywordPattern `yaml:"keywords"`
+}
+
+func parseTokenKind(kind string) acTokenKind {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "dx":
+		return acTokenDX
+	case "de":
+		return acTokenDE
+	case "db":
+		return acTokenDB
+	case "wpm":
+		return acTokenWPM
+	case "mode":
+		return acTokenMode
+	default:
+		return acTokenUnknown
+	}
+}
+
+func acPatternsFromKeywords(entries []KeywordPattern) []acPattern {
+	patterns := make([]acPattern, 0, len(entries))
+	for _, e := range entries {
+		word := strings.ToUpper(strings.TrimSpace(e.Word))
+		if word == "" {
+			continue
+		}
+		kind := parseTokenKind(e.Kind)
+		if kind == acTokenUnknown {
+			// A keyword the parser can't classify still matches in FindAll,
+			// but parseSpot's switch over acTokenKind has no case for it, so
+			// it's silently treated as if it never matched - warn here since
+			// that's almost always a typo'd Kind, not an intentional entry.
+			log.Printf("Warning: keyword %q has unrecognized kind %q; it will match but won't be classified", e.Word, e.Kind)
+		}
+		patterns = append(patterns, acPattern{
+			word: word,
+			kind: kind,
+			mode: strings.ToUpper(strings.TrimSpace(e.Mode)),
+		})
+	}
+	return patterns
+}
 
+// loadKeywordPatterns loads the keyword dictionary from keywordConfigPath (or
+// its parent-directory fallback, matching loadModeAllocations), falling back
+// to defaultKeywordPatterns if the file is missing, unparsable, or empty -
+// this is what lets non-RBN telnet feeds run UseMinimalParser without ever
+// needing the file to exist.
+func loadKeywordPatterns() []acPattern {
+	paths := []string{keywordConfigPath, filepath.Join("..", keywordConfigPath)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var dict keywordDictionary
+		if err := yaml.Unmarshal(data, &dict); err != nil {
+			log.Printf("Warning: unable to parse keyword dictionary (%s): %v", path, err)
+			return defaultKeywordPatterns
+		}
+		if len(dict.Keywords) == 0 {
+			return defaultKeywordPatterns
+		}
+		return acPatternsFromKeywords(dict.Keywords)
+	}
+	return defaultKeywordPatterns
+}
+
+var (
+	keywordScannerMu sync.Mutex
+	keywordScanner   atomic.Pointer[acScanner]
+)
+
+// getKeywordScanner returns the shared scanner, lazily loading it from
+// config/rbn_keywords.yaml (or the hardcoded defaults) on first use. It
+// never returns nil: the fast path checks the pointer without locking, but
+// the slow path re-checks under keywordScannerMu before loading, so it can't
+// race with a concurrent RegisterKeywords and observe neither the old nor
+// the new scanner.
 func getKeywordScanner() *acScanner {
-	keywordScannerOnce.Do(func() {
-		keywordScanner = newACScanner(keywordPatterns)
-	})
-	return keywordScanner
+	if sc := keywordScanner.Load(); sc != nil {
+		return sc
+	}
+	keywordScannerMu.Lock()
+	defer keywordScannerMu.Unlock()
+	if sc := keywordScanner.Load(); sc != nil {
+		return sc
+	}
+	sc := newACScanner(loadKeywordPatterns())
+	keywordScanner.Store(sc)
+	return sc
+}
+
+// RegisterKeywords rebuilds the Aho-Corasick keyword automaton from entries
+// and atomically swaps it into the scanner every Client shares, so modes
+// added at runtime (Q65, JS8, VarAC, ROS, contest-specific tokens, ...) take
+// effect on the very next line any connected client parses, with no
+// reconnect required. It's a method, like the client's other SetX/UseX
+// configuration calls, but the automaton it swaps is process-wide - matching
+// getKeywordScanner's existing singleton - rather than per-Client, since the
+// line parser that reads it is a package-level function, not tied to any one
+// Client instance.
+//
+// RegisterKeywords takes the same keywordScannerMu getKeywordScanner's lazy
+// load uses, so the two can't race and leave the pointer transiently nil or
+// have the lazy default load clobber a registration that happened first.
+func (c *Client) RegisterKeywords(entries []KeywordPattern) {
+	keywordScannerMu.Lock()
+	defer keywordScannerMu.Unlock()
+	keywordScanner.Store(newACScanner(acPatternsFromKeywords(entries)))
 }
 
 type spotToken struct {
@@ -355,6 +517,12 @@ func ConfigureCallCache(size int, ttl time.Duration) {
 // NewClient creates a new RBN client. bufferSize controls how many parsed spots
 // can queue between the telnet reader and the downstream pipeline; it should be
 // sized to absorb RBN burstiness (especially FT8/FT4 decode cycles).
+//
+// host is usually a bare hostname or IP dialed as host:port over dual-stack
+// TCP, but it may instead carry a dialer scheme - "tcp4://host", "tcp6://host",
+// or "tls://host" - to pin an address family or require TLS (configure the
+// TLS side with SetTLSConfig); port is ignored when host's scheme already
+// includes one.
 func NewClient(host string, port int, callsign string, name string, lookup *cty.CTYDatabase, skewStore *skew.Store, keepSSID bool, bufferSize int) *Client {
 	if bufferSize <= 0 {
 		bufferSize = 100 // legacy default; callers should override via config
@@ -371,9 +539,34 @@ func NewClient(host string, port int, callsign string, name string, lookup *cty.
 		reconnect:  make(chan struct{}, 1),
 		keepSSID:   keepSSID,
 		bufferSize: bufferSize,
+		backoff:    NewFullJitterBackoff(5*time.Second, 60*time.Second, 0),
 	}
 }
 
+// SetBackoff replaces the reconnect backoff policy used by
+// connectionSupervisor. The default is a FullJitterBackoff with a 5s initial
+// delay, a 60s cap, and no elapsed-time limit (retries forever); pass a
+// ConstantBackoff, NoBackoff, or DecorrelatedJitterBackoff to change that, or
+// a FullJitterBackoff with MaxElapsed set to give up after a bounded outage.
+// Like the other SetX options, call this before Connect; it isn't safe to
+// swap policies while connectionSupervisor is actively retrying.
+func (c *Client) SetBackoff(policy BackoffPolicy) {
+	if policy == nil {
+		return
+	}
+	c.backoff = policy
+}
+
+// SetTLSConfig supplies the *tls.Config used when host is a tls://
+// endpoint (see NewClient); it's ignored otherwise. cfg may leave ServerName
+// empty to let dialer.Dial default it to the dialed host, or set RootCAs
+// (dialer.TLSConfigWithCABundle builds one from a PEM file) to pin a private
+// cluster's CA instead of trusting the system roots. Call this before
+// Connect, like the other SetX options.
+func (c *Client) SetTLSConfig(cfg *tls.Config) {
+	c.tlsConfig = cfg
+}
+
 // UseMinimalParser switches this client into a permissive parser intended for
 // human/upstream telnet feeds (not strict RBN formats).
 //
@@ -519,6 +712,22 @@ func extractCallAndFreq(tok spotToken) (string, float64, bool) {
 	return callPart, freq, ok
 }
 
+// SetEnricher installs an optional enrich.Manager used to fill in grid
+// square, lat/lon, state/county, and operator name beyond what cty's
+// prefix-level lookup provides. A nil enricher (the default) leaves
+// metadata exactly as metadataFromPrefix built it.
+func (c *Client) SetEnricher(m *enrich.Manager) {
+	c.enricher = m
+}
+
+// SetAlertEngine installs an optional filter.AlertEngine run between
+// EnsureNormalized and the spot being handed to spotChan. A rule whose
+// action drops the spot (see filter.Action) stops it here, before it ever
+// reaches a downstream consumer.
+func (c *Client) SetAlertEngine(e *filter.AlertEngine) {
+	c.alertEngine = e
+}
+
 // SetUnlicensedReporter installs a best-effort reporter for unlicensed US drops.
 // Reporting is fire-and-forget; when the queue is full we fallback to an async call.
 func (c *Client) SetUnlicensedReporter(rep UnlicensedReporter) {
@@ -564,6 +773,7 @@ func (c *Client) Connect() error {
 }
 
 func (c *Client) dispatchUnlicensed(role, call, mode string, freq float64) {
+	c.recordUnlicensed()
 	rep := c.unlicensedReporter
 	if rep == nil {
 		return
@@ -579,13 +789,19 @@ func (c *Client) dispatchUnlicensed(role, call, mode string, freq float64) {
 	go rep(c.sourceKey(), role, call, mode, freq)
 }
 
-// establishConnection dials the remote RBN feed and spins up the login and read
-// goroutines. It is used for the initial connection and each subsequent reconnect.
+// establishConnection dials the remote RBN feed, runs the login handshake,
+// and starts the read goroutine. It is used for the initial connection and
+// each subsequent reconnect; running the handshake synchronously (rather
+// than handing it to a goroutine and returning early) means a server that
+// accepts TCP connections but never completes login reports back as an
+// establishConnection failure like any other, so connectionSupervisor's
+// backoff applies to it instead of spinning connectionSupervisor's retry
+// loop at full speed forever.
 func (c *Client) establishConnection() error {
-	addr := net.JoinHostPort(c.host, fmt.Sprintf("%d", c.port))
+	addr := c.dialAddr()
 	log.Printf("%s: connecting to %s...", c.displayName(), addr)
 
-	conn, err := net.DialTimeout("tcp", addr, 30*time.Second)
+	conn, err := dialer.Dial(addr, 30*time.Second, c.tlsConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to %s: %w", c.displayName(), err)
 	}
@@ -597,20 +813,25 @@ func (c *Client) establishConnection() error {
 
 	log.Printf("%s: connection established", c.displayName())
 
-	// Start login sequence and stream reader for this connection.
-	go c.handleLogin()
+	if err := c.performHandshake(); err != nil {
+		c.connected = false
+		conn.Close()
+		return fmt.Errorf("login handshake with %s failed: %w", c.displayName(), err)
+	}
+
+	// The completed handshake, not just the TCP connect, is what resets the
+	// backoff: that's the point at which we know the server is actually
+	// usable, not merely reachable.
+	c.backoff.Reset()
+
 	go c.readLoop()
 	return nil
 }
 
-// connectionSupervisor waits for disconnect notifications and orchestrates the
-// exponential backoff / reconnect attempts while honoring shutdown signals.
+// connectionSupervisor waits for disconnect notifications and orchestrates
+// reconnect attempts, sleeping between them per c.backoff, while honoring
+// shutdown signals.
 func (c *Client) connectionSupervisor() {
-	const (
-		initialDelay = 5 * time.Second
-		maxDelay     = 60 * time.Second
-	)
-
 	for {
 		select {
 		case <-c.shutdown:
@@ -619,14 +840,21 @@ func (c *Client) connectionSupervisor() {
 			if c.isShutdown() {
 				return
 			}
-			delay := initialDelay
+			attempt := 0
 
 			for {
 				if c.isShutdown() {
 					return
 				}
-				log.Printf("%s: attempting reconnect...", c.displayName())
+				attempt++
+				log.Printf("%s: attempting reconnect (attempt %d)...", c.displayName(), attempt)
 				if err := c.establishConnection(); err != nil {
+					delay, ok := c.backoff.Next(attempt)
+					if !ok {
+						log.Printf("%s: giving up reconnecting after %d attempts: %v", c.displayName(), attempt, err)
+						c.closeSpotChan()
+						return
+					}
 					log.Printf("%s: reconnect failed: %v (retry in %s)", c.displayName(), err, delay)
 					timer := time.NewTimer(delay)
 					select {
@@ -635,10 +863,6 @@ func (c *Client) connectionSupervisor() {
 						timer.Stop()
 						return
 					}
-					delay *= 2
-					if delay > maxDelay {
-						delay = maxDelay
-					}
 					continue
 				}
 				break
@@ -647,19 +871,12 @@ func (c *Client) connectionSupervisor() {
 	}
 }
 
-// handleLogin performs the RBN login sequence
-func (c *Client) handleLogin() {
-	// Wait for login prompt and respond with callsign
-	time.Sleep(2 * time.Second)
-
-	if c.name != "" {
-		log.Printf("Logging in to %s as %s", c.name, c.callsign)
-	} else {
-		log.Printf("Logging in to RBN as %s", c.callsign)
-	}
-	// Use CRLF for telnet-style compatibility with RBN servers.
-	c.writer.WriteString(c.callsign + "\r\n")
-	c.writer.Flush()
+// closeSpotChan closes the spot channel once, signaling GetSpotChannel's
+// consumer that this client has given up reconnecting for good.
+func (c *Client) closeSpotChan() {
+	c.spotChanCloseOnce.Do(func() {
+		close(c.spotChan)
+	})
 }
 
 // readLoop reads lines from RBN
@@ -855,7 +1072,10 @@ func (c *Client) parseSpot(line string) {
 	if strings.ToUpper(tokens[0].clean) != "DX" || strings.ToUpper(tokens[1].clean) != "DE" {
 		return
 	}
-	matchIndex := buildMatchIndex(getKeywordScanner().FindAll(strings.ToUpper(line)))
+	scanner := getKeywordScanner()
+	lineMatches := scanner.FindAll(strings.ToUpper(line))
+	matchIndex := buildMatchIndex(lineMatches)
+	scanner.Release(lineMatches)
 	consumed := make([]bool, len(tokens))
 	consumed[0], consumed[1] = true, true
 
@@ -1011,6 +1231,8 @@ func (c *Client) parseSpot(line string) {
 		dxMeta = metadataFromPrefix(dxInfo)
 		deMeta = metadataFromPrefix(deInfo)
 	}
+	dxMeta = c.enricher.Enrich(dxMeta, dxCall)
+	deMeta = c.enricher.Enrich(deMeta, deCall)
 
 	comment := buildComment(tokens, consumed)
 	if !hasReport && comment != "" {
@@ -1079,10 +1301,17 @@ func (c *Client) parseSpot(line string) {
 	s.RefreshBeaconFlag()
 	s.EnsureNormalized()
 
+	if c.alertEngine != nil && !c.alertEngine.Apply(s) {
+		return
+	}
+
+	c.emitSpotLog(s, wpmStr)
+
 	select {
 	case c.spotChan <- s:
 	default:
 		log.Printf("%s: Spot channel full (capacity=%d), dropping spot", c.displayName(), cap(c.spotChan))
+		c.recordSpotDropped()
 	}
 }
 
@@ -1129,6 +1358,7 @@ func (c *Client) Stop() {
 	if c.conn != nil {
 		c.conn.Close()
 	}
+	c.shutdownOTLP()
 }
 
 func (c *Client) isShutdown() bool {
@@ -1144,6 +1374,7 @@ func (c *Client) requestReconnect(reason error) {
 	if c.isShutdown() {
 		return
 	}
+	c.recordReconnect()
 	if reason != nil {
 		log.Printf("%s: scheduling reconnect after error: %v", c.displayName(), reason)
 	}
@@ -1153,6 +1384,14 @@ func (c *Client) requestReconnect(reason error) {
 	}
 }
 
+// dialAddr returns the address passed to dialer.Dial: the classic host:port
+// pair dialed as dual-stack TCP, or - if host already carries a tcp4://,
+// tcp6://, or tls:// scheme - that scheme with c.port appended when the host
+// didn't already specify one.
+func (c *Client) dialAddr() string {
+	return dialer.WithDefaultPort(c.host, c.port)
+}
+
 func (c *Client) displayName() string {
 	if c.name != "" {
 		return c.name
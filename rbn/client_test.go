@@ -1,7 +1,9 @@
 package rbn
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -64,3 +66,146 @@ func TestSplitSpotterTokenStandard(t *testing.T) {
 		t.Fatalf("expected slice length unchanged, got %d vs %d", len(updated), len(parts))
 	}
 }
+
+func TestParseServerBannerVersionToken(t *testing.T) {
+	software, version := parseServerBanner([]string{"CC Cluster v2.5b3 de W3LPL"})
+	if software != "CC Cluster v2.5b3 de W3LPL" {
+		t.Fatalf("expected software to be the full banner line, got %q", software)
+	}
+	if version != "2.5b3" {
+		t.Fatalf("expected version 2.5b3, got %q", version)
+	}
+}
+
+func TestParseServerBannerNumericToken(t *testing.T) {
+	_, version := parseServerBanner([]string{"AR-Cluster 6.3 de W3LPL"})
+	if version != "6.3" {
+		t.Fatalf("expected version 6.3, got %q", version)
+	}
+}
+
+func TestParseServerBannerNoVersion(t *testing.T) {
+	software, version := parseServerBanner([]string{"Welcome to the cluster"})
+	if software != "Welcome to the cluster" {
+		t.Fatalf("expected software to be the banner line, got %q", software)
+	}
+	if version != "" {
+		t.Fatalf("expected no version, got %q", version)
+	}
+}
+
+func TestFilterProfileCommandsSkipsUnsupportedCaps(t *testing.T) {
+	ft8 := true
+	skimmer := false
+	profile := FilterProfile{
+		ShowDXBacklog: "50",
+		FT8:           &ft8,
+		Skimmer:       &skimmer,
+		AcceptSpots:   "14000-14350",
+		RejectSpots:   "7000-7100",
+	}
+
+	cmds := profile.commands(map[string]bool{"FT8": true})
+	want := []string{"SH/DX 50", "SET/FT8", "ACCEPT/SPOTS 14000-14350", "REJECT/SPOTS 7000-7100"}
+	if len(cmds) != len(want) {
+		t.Fatalf("expected %d commands (skimmer unsupported), got %v", len(want), cmds)
+	}
+	for i, w := range want {
+		if cmds[i] != w {
+			t.Fatalf("command %d: expected %q, got %q", i, w, cmds[i])
+		}
+	}
+}
+
+func TestFilterProfileCommandsEmptyProfile(t *testing.T) {
+	var profile FilterProfile
+	if cmds := profile.commands(map[string]bool{"FT8": true, "SKIMMER": true}); len(cmds) != 0 {
+		t.Fatalf("expected no commands for zero-value profile, got %v", cmds)
+	}
+}
+
+func TestFilterProfileCommandsNilCapsIsPermissive(t *testing.T) {
+	ft8 := true
+	profile := FilterProfile{FT8: &ft8}
+
+	cmds := profile.commands(nil)
+	if len(cmds) != 1 || cmds[0] != "SET/FT8" {
+		t.Fatalf("expected SET/FT8 to be sent when no banner was seen, got %v", cmds)
+	}
+}
+
+func TestAcPatternsFromKeywordsParsesKindAndUppercases(t *testing.T) {
+	entries := []KeywordPattern{
+		{Word: "q65", Kind: "mode", Mode: "q65"},
+		{Word: " ", Kind: "mode"}, // blank word is dropped
+	}
+	patterns := acPatternsFromKeywords(entries)
+	if len(patterns) != 1 {
+		t.Fatalf("expected blank-word entry to be dropped, got %v", patterns)
+	}
+	if patterns[0].word != "Q65" || patterns[0].kind != acTokenMode || patterns[0].mode != "Q65" {
+		t.Fatalf("expected uppercased Q65 mode pattern, got %+v", patterns[0])
+	}
+}
+
+func TestRegisterKeywordsPicksUpNewMode(t *testing.T) {
+	prev := keywordScanner.Load()
+	defer keywordScanner.Store(prev)
+
+	var c *Client
+	c.RegisterKeywords([]KeywordPattern{{Word: "Q65", Kind: "mode", Mode: "Q65"}})
+
+	scanner := getKeywordScanner()
+	matches := scanner.FindAll("Q65")
+	defer scanner.Release(matches)
+	if len(matches) != 1 || matches[0].pattern.mode != "Q65" {
+		t.Fatalf("expected RegisterKeywords to make Q65 matchable, got %v", matches)
+	}
+}
+
+// TestRegisterKeywordsSurvivesLazyInit guards against getKeywordScanner's
+// lazy default load clobbering a RegisterKeywords call that happened before
+// any line was ever parsed.
+func TestRegisterKeywordsSurvivesLazyInit(t *testing.T) {
+	prev := keywordScanner.Load()
+	keywordScanner.Store(nil) // simulate "never initialized"
+	defer keywordScanner.Store(prev)
+
+	var c *Client
+	c.RegisterKeywords([]KeywordPattern{{Word: "Q65", Kind: "mode", Mode: "Q65"}})
+
+	// Simulate the first line ever parsed, which is what triggers the lazy
+	// default load if nothing has registered a scanner yet.
+	scanner := getKeywordScanner()
+	matches := scanner.FindAll("Q65")
+	defer scanner.Release(matches)
+	if len(matches) != 1 || matches[0].pattern.mode != "Q65" {
+		t.Fatalf("expected registered Q65 keyword to survive getKeywordScanner's lazy init, got %v", matches)
+	}
+}
+
+// BenchmarkKeywordScannerFindAll exercises FindAll/Release against a
+// synthetic burst of FT8-shaped spot lines to demonstrate the sync.Pool of
+// match slices keeps the keyword scan itself allocation-free after warmup -
+// the property a fresh make([]acMatch, ...) per call didn't have. It doesn't
+// cover parseSpot's other per-line work (e.g. the *spot.Spot it ultimately
+// allocates), only the scan step the pool targets.
+func BenchmarkKeywordScannerFindAll(b *testing.B) {
+	lines := make([]string, 10000)
+	for i := range lines {
+		lines[i] = strings.ToUpper(fmt.Sprintf(
+			"DX de W%dABC-#:   14074.0  K%dXYZ       FT8   -12 dB   23 WPM  1234Z", i%10, i%10))
+	}
+	scanner := getKeywordScanner()
+
+	for _, line := range lines {
+		scanner.Release(scanner.FindAll(line))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matches := scanner.FindAll(lines[i%len(lines)])
+		scanner.Release(matches)
+	}
+}
@@ -0,0 +1,282 @@
+package rbn
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loginHandshakeTimeout bounds the whole login handshake, including the
+// banner peek below. It is deliberately much shorter than readLoop's
+// steady-state read deadline: a connection that's actually dead during
+// login is a faster, more specific failure than an idle established
+// connection, and should be reconnected on quickly rather than left to the
+// 5-minute idle timeout.
+const loginHandshakeTimeout = 15 * time.Second
+
+// bannerPeekTimeout bounds how long performHandshake waits to see a banner
+// before sending the callsign anyway. Real RBN and most other cluster
+// software (see dxclusterclient.handleLogin) accept the callsign blind,
+// printing no explicit login prompt at all, so the callsign can't be made
+// to wait on banner text actually arriving - only a genuinely broken
+// connection (a hard read error, not just "nothing yet") should fail the
+// handshake outright.
+const bannerPeekTimeout = 3 * time.Second
+
+// knownServerCaps are the capability keywords performHandshake looks for,
+// case-insensitively, in the login banner. A keyword's absence doesn't
+// prove the server lacks that capability - cluster software banners vary
+// widely and this is a best-effort hint, not a negotiated feature list -
+// but its presence is enough to justify sending the matching FilterProfile
+// command.
+var knownServerCaps = []string{"FT8", "FT4", "SKIMMER", "CW", "SSB"}
+
+// FilterProfile configures the server-side filtering commands performHandshake
+// sends once the server has accepted our callsign, so RBN does the
+// filtering upstream instead of relying solely on dropping spots
+// client-side. A zero-value FilterProfile sends nothing beyond the
+// callsign, matching this client's historical behavior.
+type FilterProfile struct {
+	// ShowDXBacklog, when non-empty, is sent as "SH/DX <ShowDXBacklog>" to
+	// replay that many recent spots on login, e.g. "50".
+	ShowDXBacklog string
+
+	// FT8 and Skimmer, when non-nil, send "SET/FT8"/"UNSET/FT8" and
+	// "SET/SKIMMER"/"UNSET/SKIMMER" depending on their value. Both are
+	// skipped - regardless of value - on a server whose banner doesn't
+	// mention the corresponding capability, so a strict profile doesn't
+	// waste a command on a server that won't recognize it.
+	FT8     *bool
+	Skimmer *bool
+
+	// AcceptSpots and RejectSpots, when non-empty, are sent verbatim as
+	// "ACCEPT/SPOTS <AcceptSpots>" / "REJECT/SPOTS <RejectSpots>".
+	AcceptSpots string
+	RejectSpots string
+}
+
+// commands renders p as the ordered command list performHandshake sends.
+// caps is nil when no banner was ever seen (the common case for real RBN,
+// which prints no banner at all - see bannerPeekTimeout) and FT8/Skimmer are
+// sent unconditionally in that case, since there's no signal to weigh
+// against the operator's explicit configuration. Once a banner has been
+// parsed, caps reflects it, and a keyword's absence there does suppress the
+// matching command.
+func (p FilterProfile) commands(caps map[string]bool) []string {
+	supports := func(name string) bool {
+		if caps == nil {
+			return true
+		}
+		return caps[name]
+	}
+
+	var cmds []string
+	if p.ShowDXBacklog != "" {
+		cmds = append(cmds, "SH/DX "+p.ShowDXBacklog)
+	}
+	if p.FT8 != nil && supports("FT8") {
+		cmds = append(cmds, setUnsetCommand("FT8", *p.FT8))
+	}
+	if p.Skimmer != nil && supports("SKIMMER") {
+		cmds = append(cmds, setUnsetCommand("SKIMMER", *p.Skimmer))
+	}
+	if p.AcceptSpots != "" {
+		cmds = append(cmds, "ACCEPT/SPOTS "+p.AcceptSpots)
+	}
+	if p.RejectSpots != "" {
+		cmds = append(cmds, "REJECT/SPOTS "+p.RejectSpots)
+	}
+	return cmds
+}
+
+func setUnsetCommand(name string, enabled bool) string {
+	if enabled {
+		return "SET/" + name
+	}
+	return "UNSET/" + name
+}
+
+// SetFilterProfile configures the SH/DX, SET/FT8, SET/SKIMMER,
+// ACCEPT/SPOTS, and REJECT/SPOTS commands sent after login (see
+// FilterProfile). Like the other SetX options, call this before Connect.
+func (c *Client) SetFilterProfile(profile FilterProfile) {
+	c.filterProfile = profile
+}
+
+// ServerInfo returns the software name and version parsed from the login
+// banner, plus the best-effort capability map FilterProfile gates its
+// commands on, so the supervisor can log what it connected to and
+// downstream code can avoid relying on a capability the server never
+// advertised (e.g. requesting an FT8-only filter on a server that doesn't
+// understand SET/FT8). All three are zero values until a banner is seen;
+// caps specifically stays nil rather than an empty map, distinguishing "no
+// banner observed" from "a banner was parsed and named no known caps".
+func (c *Client) ServerInfo() (software, version string, caps map[string]bool) {
+	c.serverInfoMu.RLock()
+	defer c.serverInfoMu.RUnlock()
+	if c.serverCaps == nil {
+		return c.serverSoftware, c.serverVersion, nil
+	}
+	capsCopy := make(map[string]bool, len(c.serverCaps))
+	for k, v := range c.serverCaps {
+		capsCopy[k] = v
+	}
+	return c.serverSoftware, c.serverVersion, capsCopy
+}
+
+// performHandshake peeks for the server's banner, sends the callsign, and
+// issues c.filterProfile's commands. It doesn't block the callsign on a
+// banner actually showing up - see bannerPeekTimeout - but it does fail
+// cleanly into the reconnect path, rather than writing to a dead socket,
+// if the peek or the login write hits a genuine connection error.
+func (c *Client) performHandshake() error {
+	hardDeadline := time.Now().Add(loginHandshakeTimeout)
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	peekDeadline := time.Now().Add(bannerPeekTimeout)
+	if peekDeadline.After(hardDeadline) {
+		peekDeadline = hardDeadline
+	}
+	banner, spotLine, err := c.readBanner(peekDeadline, hardDeadline)
+	if err != nil {
+		return fmt.Errorf("connection to %s failed before login: %w", c.displayName(), err)
+	}
+	if banner != nil {
+		c.recordServerBanner(banner)
+	}
+
+	if c.name != "" {
+		log.Printf("Logging in to %s as %s", c.name, c.callsign)
+	} else {
+		log.Printf("Logging in to RBN as %s", c.callsign)
+	}
+	// Use CRLF for telnet-style compatibility with RBN servers.
+	c.writer.WriteString(c.callsign + "\r\n")
+
+	_, _, caps := c.ServerInfo()
+	for _, cmd := range c.filterProfile.commands(caps) {
+		c.writer.WriteString(cmd + "\r\n")
+	}
+	if err := c.writer.Flush(); err != nil {
+		return fmt.Errorf("sending login sequence to %s: %w", c.displayName(), err)
+	}
+
+	if spotLine != "" {
+		c.parseSpot(spotLine)
+	}
+	return nil
+}
+
+// maxBannerLines caps how many lines readBanner accumulates before giving
+// up on finding a blank-line/prompt boundary, so a server that streams
+// non-blank output for the entire handshake window can't grow banner
+// unbounded.
+const maxBannerLines = 50
+
+// readBanner reads whatever the server sends before we've said anything,
+// stopping at the first blank line following banner text (the conventional
+// telnet banner-then-blank-then-prompt shape), the first live "DX de" spot
+// line (some servers start streaming immediately with no real prompt, in
+// which case spotLine is returned so performHandshake can forward it
+// instead of dropping it), maxBannerLines, or peekDeadline. Running out of
+// time at a line boundary with nothing pending is not an error - plenty of
+// cluster servers never print a banner at all - but a read error other
+// than a timeout means the connection itself is broken, and is returned as
+// err so performHandshake doesn't try to log in over it.
+//
+// A timeout that lands mid-line (a partial read with no '\n' yet) is a
+// special case: those bytes are already consumed from the underlying
+// conn and would be lost if readBanner gave up on them, corrupting
+// whatever readLoop reads next. Rather than discard them, readBanner keeps
+// accumulating that one line past peekDeadline, up to hardDeadline, so it
+// either completes the line or fails the handshake outright - it does not
+// silently drop back to "no banner seen".
+func (c *Client) readBanner(peekDeadline, hardDeadline time.Time) (banner []string, spotLine string, err error) {
+	var pending strings.Builder
+	deadline := peekDeadline
+
+	for time.Now().Before(deadline) && len(banner) < maxBannerLines {
+		if err := c.conn.SetReadDeadline(deadline); err != nil {
+			return nil, "", fmt.Errorf("setting banner read deadline: %w", err)
+		}
+
+		chunk, rerr := c.reader.ReadString('\n')
+		pending.WriteString(chunk)
+
+		if rerr != nil {
+			ne, isTimeout := rerr.(net.Error)
+			if !isTimeout || !ne.Timeout() {
+				return nil, "", rerr
+			}
+			if pending.Len() == 0 {
+				return banner, "", nil
+			}
+			if deadline.Equal(hardDeadline) {
+				return nil, "", fmt.Errorf("line never completed within %s", loginHandshakeTimeout)
+			}
+			// A line is in flight; extend to hardDeadline just long enough to
+			// finish it, rather than abandon bytes readLoop would otherwise
+			// have to make sense of.
+			deadline = hardDeadline
+			continue
+		}
+
+		// A line completed; any extension above was only to finish that one
+		// line, so drop back to the peek window for whatever comes next.
+		deadline = peekDeadline
+
+		trimmed := strings.TrimSpace(pending.String())
+		pending.Reset()
+		switch {
+		case strings.HasPrefix(trimmed, "DX de"):
+			return banner, trimmed, nil
+		case trimmed != "":
+			banner = append(banner, trimmed)
+		case trimmed == "" && len(banner) > 0:
+			return banner, "", nil
+		}
+	}
+	return banner, "", nil
+}
+
+// recordServerBanner parses banner for a software/version string and a
+// best-effort capability map, and stores both for ServerInfo.
+func (c *Client) recordServerBanner(banner []string) {
+	software, version := parseServerBanner(banner)
+	joined := strings.ToUpper(strings.Join(banner, " "))
+
+	caps := make(map[string]bool, len(knownServerCaps))
+	for _, kw := range knownServerCaps {
+		caps[kw] = strings.Contains(joined, kw)
+	}
+
+	c.serverInfoMu.Lock()
+	c.serverSoftware = software
+	c.serverVersion = version
+	c.serverCaps = caps
+	c.serverInfoMu.Unlock()
+}
+
+// parseServerBanner pulls a software name and version out of a banner like
+// "CC Cluster v2.5b3 ..." or "AR-Cluster 6.3 de W3LPL". It's a heuristic,
+// not a real grammar - cluster software banners vary widely - so it takes
+// the first banner line as the software name and, within it, the first
+// "vX..." or bare numeric token as the version.
+func parseServerBanner(banner []string) (software, version string) {
+	if len(banner) == 0 {
+		return "", ""
+	}
+	software = banner[0]
+	for _, field := range strings.Fields(software) {
+		if len(field) > 1 && (field[0] == 'v' || field[0] == 'V') && field[1] >= '0' && field[1] <= '9' {
+			return software, field[1:]
+		}
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			return software, field
+		}
+	}
+	return software, ""
+}
@@ -0,0 +1,349 @@
+package rbn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"dxcluster/spot"
+
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	"gopkg.in/yaml.v3"
+)
+
+// OTLPConfig is the YAML shape for SetOTLPExporter, so an operator can point
+// every RBN client at the same collector from one config block instead of
+// calling SetOTLPExporter from Go:
+//
+//	otlp:
+//	  endpoint: grpc://collector.internal:4317
+//	  compression: zstd
+//	  headers:
+//	    x-api-key: secret
+type OTLPConfig struct {
+	Endpoint    string            `yaml:"endpoint"`
+	Compression string            `yaml:"compression"`
+	Headers     map[string]string `yaml:"headers"`
+}
+
+// ConfigureOTLPFromFile reads an OTLPConfig block from path and applies it via
+// SetOTLPExporter. It's the YAML-driven counterpart to calling
+// SetOTLPExporter directly, for callers that already load the rest of their
+// config from disk.
+func (c *Client) ConfigureOTLPFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("rbn: read OTLP config %s: %w", path, err)
+	}
+	var wrapper struct {
+		OTLP OTLPConfig `yaml:"otlp"`
+	}
+	if err := yaml.Unmarshal(data, &wrapper); err != nil {
+		return fmt.Errorf("rbn: parse OTLP config %s: %w", path, err)
+	}
+	return c.SetOTLPExporter(wrapper.OTLP.Endpoint, wrapper.OTLP.Headers, wrapper.OTLP.Compression)
+}
+
+// otlpExporter bundles the SDK providers, logger, and counters backing
+// Client.SetOTLPExporter. A nil *Client.otlp (the default before
+// SetOTLPExporter is called) means every recordX/emitSpotLog call below is a
+// no-op, so the rest of the client never has to check whether export is on.
+type otlpExporter struct {
+	loggerProvider *sdklog.LoggerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	logger         otellog.Logger
+
+	accepted   metric.Int64Counter
+	dropped    metric.Int64Counter
+	unlicensed metric.Int64Counter
+	reconnects metric.Int64Counter
+}
+
+// SetOTLPExporter publishes this client's parsed spots (as structured log
+// records) and connection-health counters (accepted/dropped/unlicensed/
+// reconnects) to an OTLP collector at endpoint, so every RBN client can be
+// watched from one collector instead of scraping each process's stdout.
+//
+// endpoint's scheme picks the transport: "grpc://host:port" dials OTLP/gRPC,
+// anything else ("http://" or "https://") uses OTLP/HTTP. headers are
+// attached to every export request (e.g. for collector auth) and may be nil.
+// compression is "none", "gzip", or "zstd"; zstd is gRPC-only, since the
+// upstream OTLP/HTTP exporters don't ship a zstd codec.
+func (c *Client) SetOTLPExporter(endpoint string, headers map[string]string, compression string) error {
+	exp, err := newOTLPExporter(endpoint, headers, compression)
+	if err != nil {
+		return err
+	}
+	c.otlp = exp
+	return nil
+}
+
+func newOTLPExporter(endpoint string, headers map[string]string, compression string) (*otlpExporter, error) {
+	scheme, host, hasScheme := strings.Cut(endpoint, "://")
+	if !hasScheme {
+		scheme, host = "", endpoint
+	}
+	if host == "" {
+		return nil, fmt.Errorf("rbn: invalid OTLP endpoint %q: no host", endpoint)
+	}
+	useGRPC := strings.EqualFold(scheme, "grpc")
+	insecure := !strings.EqualFold(scheme, "https")
+
+	compression = strings.ToLower(strings.TrimSpace(compression))
+	switch compression {
+	case "", "none", "gzip":
+	case "zstd":
+		if !useGRPC {
+			return nil, fmt.Errorf("rbn: zstd OTLP compression requires the grpc:// transport, not %q", endpoint)
+		}
+		registerZstdCompressor()
+	default:
+		return nil, fmt.Errorf("rbn: unsupported OTLP compression %q (want none, gzip, or zstd)", compression)
+	}
+
+	logExp, metricExp, err := dialOTLP(host, headers, compression, useGRPC, insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(logExp)))
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExp)))
+	meter := mp.Meter("dxcluster/rbn")
+
+	accepted, err := meter.Int64Counter("rbn_spots_accepted_total",
+		metric.WithDescription("Spots parsed and canonicalized, before the internal spot channel"))
+	if err != nil {
+		return nil, fmt.Errorf("rbn: create accepted counter: %w", err)
+	}
+	dropped, err := meter.Int64Counter("rbn_spots_dropped_total",
+		metric.WithDescription("Spots discarded because the internal spot channel was full"))
+	if err != nil {
+		return nil, fmt.Errorf("rbn: create dropped counter: %w", err)
+	}
+	unlicensed, err := meter.Int64Counter("rbn_unlicensed_total",
+		metric.WithDescription("Spots dropped because the US station failed an FCC license check"))
+	if err != nil {
+		return nil, fmt.Errorf("rbn: create unlicensed counter: %w", err)
+	}
+	reconnects, err := meter.Int64Counter("rbn_reconnects_total",
+		metric.WithDescription("Times this client's connection supervisor scheduled a reconnect"))
+	if err != nil {
+		return nil, fmt.Errorf("rbn: create reconnects counter: %w", err)
+	}
+
+	return &otlpExporter{
+		loggerProvider: lp,
+		meterProvider:  mp,
+		logger:         lp.Logger("dxcluster/rbn"),
+		accepted:       accepted,
+		dropped:        dropped,
+		unlicensed:     unlicensed,
+		reconnects:     reconnects,
+	}, nil
+}
+
+// dialOTLP builds the log and metric exporters for one transport, applying
+// headers and compression the same way to both.
+// otlpDialTimeout bounds each exporter dial independently, so a slow logs
+// handshake can't starve the metrics exporter's (or vice versa) out of the
+// time it would otherwise have had on its own.
+const otlpDialTimeout = 10 * time.Second
+
+func dialOTLP(host string, headers map[string]string, compression string, useGRPC, insecure bool) (sdklog.Exporter, sdkmetric.Exporter, error) {
+	if useGRPC {
+		logOpts := []otlploggrpc.Option{otlploggrpc.WithEndpoint(host)}
+		metricOpts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(host)}
+		if insecure {
+			logOpts = append(logOpts, otlploggrpc.WithInsecure())
+			metricOpts = append(metricOpts, otlpmetricgrpc.WithInsecure())
+		}
+		if len(headers) > 0 {
+			logOpts = append(logOpts, otlploggrpc.WithHeaders(headers))
+			metricOpts = append(metricOpts, otlpmetricgrpc.WithHeaders(headers))
+		}
+		if compression != "" && compression != "none" {
+			logOpts = append(logOpts, otlploggrpc.WithCompressor(compression))
+			metricOpts = append(metricOpts, otlpmetricgrpc.WithCompressor(compression))
+		}
+
+		logCtx, logCancel := context.WithTimeout(context.Background(), otlpDialTimeout)
+		defer logCancel()
+		logExp, err := otlploggrpc.New(logCtx, logOpts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rbn: dial OTLP/gRPC logs exporter: %w", err)
+		}
+
+		metricCtx, metricCancel := context.WithTimeout(context.Background(), otlpDialTimeout)
+		defer metricCancel()
+		metricExp, err := otlpmetricgrpc.New(metricCtx, metricOpts...)
+		if err != nil {
+			shutdownExporter(logExp)
+			return nil, nil, fmt.Errorf("rbn: dial OTLP/gRPC metrics exporter: %w", err)
+		}
+		return logExp, metricExp, nil
+	}
+
+	logOpts := []otlploghttp.Option{otlploghttp.WithEndpoint(host)}
+	metricOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(host)}
+	if insecure {
+		logOpts = append(logOpts, otlploghttp.WithInsecure())
+		metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
+	}
+	if len(headers) > 0 {
+		logOpts = append(logOpts, otlploghttp.WithHeaders(headers))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithHeaders(headers))
+	}
+	if compression == "gzip" {
+		logOpts = append(logOpts, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+
+	logCtx, logCancel := context.WithTimeout(context.Background(), otlpDialTimeout)
+	defer logCancel()
+	logExp, err := otlploghttp.New(logCtx, logOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rbn: dial OTLP/HTTP logs exporter: %w", err)
+	}
+
+	metricCtx, metricCancel := context.WithTimeout(context.Background(), otlpDialTimeout)
+	defer metricCancel()
+	metricExp, err := otlpmetrichttp.New(metricCtx, metricOpts...)
+	if err != nil {
+		shutdownExporter(logExp)
+		return nil, nil, fmt.Errorf("rbn: dial OTLP/HTTP metrics exporter: %w", err)
+	}
+	return logExp, metricExp, nil
+}
+
+// shutdownExporter releases a successfully-dialed logs exporter that's being
+// discarded because its paired metrics exporter failed to dial.
+func shutdownExporter(exp sdklog.Exporter) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := exp.Shutdown(ctx); err != nil {
+		log.Printf("rbn: shutting down OTLP logs exporter after failed setup: %v", err)
+	}
+}
+
+var registerZstdCompressorOnce sync.Once
+
+// registerZstdCompressor installs a grpc/encoding.Compressor named "zstd",
+// backed by the same klauspost/compress/zstd dependency archive's roller
+// already uses for cold storage, so otlploggrpc/otlpmetricgrpc's
+// WithCompressor("zstd") has a codec to find - the core OTLP gRPC exporters
+// only register "gzip" out of the box.
+func registerZstdCompressor() {
+	registerZstdCompressorOnce.Do(func() {
+		encoding.RegisterCompressor(zstdCompressor{})
+	})
+}
+
+type zstdCompressor struct{}
+
+func (zstdCompressor) Name() string { return "zstd" }
+
+func (zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+func (zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return zstd.NewReader(r)
+}
+
+// emitSpotLog publishes s as a structured OTLP log record (fields de, dx,
+// freq_khz, mode, snr_db, wpm, spot_time, source) and counts it as accepted,
+// so downstream analytics don't have to scrape stdout. wpm is the raw WPM
+// token parseSpot folded into the comment, kept separate here since *spot.Spot
+// has no dedicated field for it. A no-op until SetOTLPExporter is called.
+func (c *Client) emitSpotLog(s *spot.Spot, wpm string) {
+	if c.otlp == nil {
+		return
+	}
+	c.otlp.accepted.Add(context.Background(), 1, metric.WithAttributes(attribute.String("source", c.sourceKey())))
+
+	var rec otellog.Record
+	now := time.Now()
+	rec.SetTimestamp(now)
+	rec.SetObservedTimestamp(now)
+	rec.SetSeverity(otellog.SeverityInfo)
+	rec.SetBody(otellog.StringValue(fmt.Sprintf("%s de %s on %.1f kHz (%s)", s.DXCall, s.DECall, s.Frequency, s.Mode)))
+
+	attrs := []otellog.KeyValue{
+		otellog.String("de", s.DECall),
+		otellog.String("dx", s.DXCall),
+		otellog.Float64("freq_khz", s.Frequency),
+		otellog.String("mode", s.Mode),
+		otellog.String("spot_time", s.Time.UTC().Format(time.RFC3339)),
+		otellog.String("source", string(s.SourceType)),
+	}
+	if s.HasReport {
+		attrs = append(attrs, otellog.Int("snr_db", s.Report))
+	}
+	if strings.TrimSpace(wpm) != "" {
+		attrs = append(attrs, otellog.String("wpm", wpm))
+	}
+	rec.AddAttributes(attrs...)
+
+	c.otlp.logger.Emit(context.Background(), rec)
+}
+
+// recordSpotDropped counts a spot discarded because the internal spot
+// channel was full. A no-op until SetOTLPExporter is called.
+func (c *Client) recordSpotDropped() {
+	if c.otlp == nil {
+		return
+	}
+	c.otlp.dropped.Add(context.Background(), 1, metric.WithAttributes(attribute.String("source", c.sourceKey())))
+}
+
+// recordUnlicensed counts a spot dropped by the FCC license check,
+// regardless of whether an UnlicensedReporter is registered to also receive
+// it. A no-op until SetOTLPExporter is called.
+func (c *Client) recordUnlicensed() {
+	if c.otlp == nil {
+		return
+	}
+	c.otlp.unlicensed.Add(context.Background(), 1, metric.WithAttributes(attribute.String("source", c.sourceKey())))
+}
+
+// recordReconnect counts a reconnect scheduled by requestReconnect. A no-op
+// until SetOTLPExporter is called.
+func (c *Client) recordReconnect() {
+	if c.otlp == nil {
+		return
+	}
+	c.otlp.reconnects.Add(context.Background(), 1, metric.WithAttributes(attribute.String("source", c.sourceKey())))
+}
+
+// shutdownOTLP flushes and closes the OTLP providers, if any were ever
+// configured. Called from Client.Stop.
+func (c *Client) shutdownOTLP() {
+	if c.otlp == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.otlp.loggerProvider.Shutdown(ctx); err != nil {
+		log.Printf("%s: OTLP logger shutdown: %v", c.displayName(), err)
+	}
+	if err := c.otlp.meterProvider.Shutdown(ctx); err != nil {
+		log.Printf("%s: OTLP meter shutdown: %v", c.displayName(), err)
+	}
+}
@@ -0,0 +1,235 @@
+package sinks
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dxcluster/events"
+	"dxcluster/spot"
+)
+
+// FlexConfig configures the FlexRadio spot sink.
+type FlexConfig struct {
+	Host            string
+	Port            int
+	QueueSize       int
+	LifetimeSeconds int
+}
+
+// flexModeNames translates our internal mode strings to the radio's SmartSDR
+// API mode tokens. Modes we don't recognise fall back to "CW" so the spot is
+// still visible rather than silently dropped by the radio.
+var flexModeNames = map[string]string{
+	"CW":    "CW",
+	"SSB":   "LSB",
+	"USB":   "USB",
+	"LSB":   "LSB",
+	"FT8":   "DIGU",
+	"FT4":   "DIGU",
+	"RTTY":  "RTTY",
+	"PSK31": "DIGU",
+}
+
+// flexModeColors assigns a distinct SmartSDR spot color per mode so the
+// panadapter overlay is visually grouped by mode.
+var flexModeColors = map[string]string{
+	"CW":   "0xFFFF0000",
+	"LSB":  "0xFF00FF00",
+	"USB":  "0xFF00FF00",
+	"RTTY": "0xFFFFFF00",
+	"DIGU": "0xFF00FFFF",
+}
+
+const flexDefaultColor = "0xFFFFFFFF"
+
+// flexModeString maps a spot mode to the radio's mode token.
+func flexModeString(mode string) string {
+	if m, ok := flexModeNames[normalizeModeKey(mode)]; ok {
+		return m
+	}
+	return "CW"
+}
+
+// flexColorForMode returns the panadapter color for the radio mode token
+// (as returned by flexModeString, not the raw spot mode).
+func flexColorForMode(radioMode string) string {
+	if c, ok := flexModeColors[radioMode]; ok {
+		return c
+	}
+	return flexDefaultColor
+}
+
+func normalizeModeKey(mode string) string {
+	switch mode {
+	case "":
+		return "CW"
+	default:
+		return mode
+	}
+}
+
+// buildSpotAddCommand renders the SmartSDR "spot add" command for one spot.
+func buildSpotAddCommand(s *spot.Spot, lifetimeSeconds int) string {
+	radioMode := flexModeString(s.Mode)
+	freqMHz := s.Frequency / 1000.0
+	source := s.SourceNode
+	if source == "" {
+		source = string(s.SourceType)
+	}
+	return fmt.Sprintf(
+		"spot add rx_freq=%.6f callsign=%s mode=%s color=%s source=%s timestamp=%d lifetime_seconds=%d",
+		freqMHz, s.DXCall, radioMode, flexColorForMode(radioMode), source, s.Time.Unix(), lifetimeSeconds,
+	)
+}
+
+// FlexSink forwards deduplicated spots to a FlexRadio 6000-series radio over
+// its TCP command API, subscribing to spot updates once connected.
+type FlexSink struct {
+	cfg    FlexConfig
+	logger events.Logger
+
+	queue    chan *spot.Spot
+	shutdown chan struct{}
+	stopOnce sync.Once
+	drops    atomic.Uint64
+
+	connBox connBox
+}
+
+// NewFlexSink builds a FlexSink for the given radio. Call Start to begin
+// connecting and delivering queued spots.
+func NewFlexSink(cfg FlexConfig) *FlexSink {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 200
+	}
+	if cfg.LifetimeSeconds <= 0 {
+		cfg.LifetimeSeconds = 1800
+	}
+	return &FlexSink{
+		cfg:      cfg,
+		queue:    make(chan *spot.Spot, cfg.QueueSize),
+		shutdown: make(chan struct{}),
+	}
+}
+
+// SetLogger wires a structured event sink for connect/disconnect events.
+func (s *FlexSink) SetLogger(l events.Logger) {
+	if s != nil {
+		s.logger = l
+	}
+}
+
+// Start launches the delivery worker; it reconnects with backoff whenever
+// the radio connection drops.
+func (s *FlexSink) Start() {
+	go s.run()
+}
+
+func (s *FlexSink) Name() string { return "flex" }
+
+// Submit enqueues a spot for delivery; it never blocks. If the queue is
+// full the spot is dropped and counted.
+func (s *FlexSink) Submit(sp *spot.Spot) bool {
+	select {
+	case s.queue <- sp:
+		return true
+	default:
+		s.drops.Add(1)
+		return false
+	}
+}
+
+// Stats reports the current queue depth and cumulative drop count.
+func (s *FlexSink) Stats() (queueLen int, drops uint64) {
+	return len(s.queue), s.drops.Load()
+}
+
+// Stop stops the delivery worker and closes the radio connection.
+func (s *FlexSink) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.shutdown)
+	})
+	if conn := s.connBox.get(); conn != nil {
+		conn.Close()
+	}
+}
+
+func (s *FlexSink) run() {
+	const (
+		initialDelay = 5 * time.Second
+		maxDelay     = 60 * time.Second
+	)
+	delay := initialDelay
+
+	for {
+		if s.isShutdown() {
+			return
+		}
+		conn, err := s.connect()
+		if err != nil {
+			log.Printf("flex sink: connect to %s:%d failed: %v (retry in %s)", s.cfg.Host, s.cfg.Port, err, delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-s.shutdown:
+				timer.Stop()
+				return
+			}
+			delay *= 2
+			if delay > maxDelay {
+				delay = maxDelay
+			}
+			continue
+		}
+		delay = initialDelay
+		s.deliverUntilDisconnect(conn)
+	}
+}
+
+func (s *FlexSink) connect() (net.Conn, error) {
+	addr := net.JoinHostPort(s.cfg.Host, fmt.Sprintf("%d", s.cfg.Port))
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	s.connBox.set(conn)
+	if _, err := fmt.Fprintf(conn, "sub spot all\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	log.Printf("flex sink: connected to %s", addr)
+	events.Info(s.logger, "sink.flex.connect", events.String("addr", addr))
+	return conn, nil
+}
+
+// deliverUntilDisconnect writes queued spots to conn until a write fails or
+// shutdown is requested, at which point it returns so run() can reconnect.
+func (s *FlexSink) deliverUntilDisconnect(conn net.Conn) {
+	defer conn.Close()
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case sp := <-s.queue:
+			cmd := buildSpotAddCommand(sp, s.cfg.LifetimeSeconds)
+			if _, err := fmt.Fprintf(conn, "%s\n", cmd); err != nil {
+				log.Printf("flex sink: write failed: %v", err)
+				events.Warn(s.logger, "sink.flex.disconnect", events.Err(err))
+				return
+			}
+		}
+	}
+}
+
+func (s *FlexSink) isShutdown() bool {
+	select {
+	case <-s.shutdown:
+		return true
+	default:
+		return false
+	}
+}
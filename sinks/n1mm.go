@@ -0,0 +1,176 @@
+package sinks
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dxcluster/events"
+	"dxcluster/spot"
+)
+
+// N1MMConfig configures the N1MM+/DXLog UDP spot sink.
+type N1MMConfig struct {
+	// Address is a host:port pair; it may be a multicast group address
+	// (e.g. "239.192.1.1:12060", N1MM+'s default) or a unicast address.
+	Address   string
+	QueueSize int
+}
+
+// buildSpotXML renders the N1MM+/DXLog `<spot>` UDP datagram for one spot.
+// The schema mirrors what N1MM+ itself broadcasts on its spot multicast
+// group, so DXLog and other loggers that already consume N1MM+ spots can
+// consume ours unmodified.
+func buildSpotXML(s *spot.Spot) string {
+	var b strings.Builder
+	b.WriteString("<spot>\r\n")
+	fmt.Fprintf(&b, "<call>%s</call>\r\n", escapeXML(s.DXCall))
+	fmt.Fprintf(&b, "<freq>%.1f</freq>\r\n", s.Frequency)
+	fmt.Fprintf(&b, "<mode>%s</mode>\r\n", escapeXML(s.Mode))
+	fmt.Fprintf(&b, "<spotter>%s</spotter>\r\n", escapeXML(s.DECall))
+	fmt.Fprintf(&b, "<timestamp>%s</timestamp>\r\n", s.Time.UTC().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "<comment>%s</comment>\r\n", escapeXML(s.Comment))
+	b.WriteString("</spot>\r\n")
+	return b.String()
+}
+
+func escapeXML(v string) string {
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return replacer.Replace(v)
+}
+
+// N1MMSink forwards deduplicated spots as N1MM+/DXLog-style UDP `<spot>`
+// datagrams to a configurable multicast or unicast address.
+type N1MMSink struct {
+	cfg    N1MMConfig
+	logger events.Logger
+
+	queue    chan *spot.Spot
+	shutdown chan struct{}
+	stopOnce sync.Once
+	drops    atomic.Uint64
+
+	connBox connBox
+}
+
+// NewN1MMSink builds an N1MMSink for the given destination. Call Start to
+// begin delivering queued spots.
+func NewN1MMSink(cfg N1MMConfig) *N1MMSink {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 200
+	}
+	return &N1MMSink{
+		cfg:      cfg,
+		queue:    make(chan *spot.Spot, cfg.QueueSize),
+		shutdown: make(chan struct{}),
+	}
+}
+
+// SetLogger wires a structured event sink for connect/disconnect events.
+func (s *N1MMSink) SetLogger(l events.Logger) {
+	if s != nil {
+		s.logger = l
+	}
+}
+
+// Start launches the delivery worker; it reconnects with backoff whenever
+// the UDP socket needs to be re-dialed.
+func (s *N1MMSink) Start() {
+	go s.run()
+}
+
+func (s *N1MMSink) Name() string { return "n1mm" }
+
+// Submit enqueues a spot for delivery; it never blocks. If the queue is
+// full the spot is dropped and counted.
+func (s *N1MMSink) Submit(sp *spot.Spot) bool {
+	select {
+	case s.queue <- sp:
+		return true
+	default:
+		s.drops.Add(1)
+		return false
+	}
+}
+
+// Stats reports the current queue depth and cumulative drop count.
+func (s *N1MMSink) Stats() (queueLen int, drops uint64) {
+	return len(s.queue), s.drops.Load()
+}
+
+// Stop stops the delivery worker and closes the UDP socket.
+func (s *N1MMSink) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.shutdown)
+	})
+	if conn := s.connBox.get(); conn != nil {
+		conn.Close()
+	}
+}
+
+func (s *N1MMSink) run() {
+	const (
+		initialDelay = 2 * time.Second
+		maxDelay     = 30 * time.Second
+	)
+	delay := initialDelay
+
+	for {
+		if s.isShutdown() {
+			return
+		}
+		conn, err := net.Dial("udp", s.cfg.Address)
+		if err != nil {
+			log.Printf("n1mm sink: dial %s failed: %v (retry in %s)", s.cfg.Address, err, delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-s.shutdown:
+				timer.Stop()
+				return
+			}
+			delay *= 2
+			if delay > maxDelay {
+				delay = maxDelay
+			}
+			continue
+		}
+		s.connBox.set(conn)
+		delay = initialDelay
+		log.Printf("n1mm sink: sending spots to %s", s.cfg.Address)
+		events.Info(s.logger, "sink.n1mm.connect", events.String("addr", s.cfg.Address))
+		s.deliverUntilDisconnect(conn)
+	}
+}
+
+// deliverUntilDisconnect writes queued spots to conn until a write fails or
+// shutdown is requested, at which point it returns so run() can redial.
+func (s *N1MMSink) deliverUntilDisconnect(conn net.Conn) {
+	defer conn.Close()
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case sp := <-s.queue:
+			datagram := buildSpotXML(sp)
+			if _, err := conn.Write([]byte(datagram)); err != nil {
+				log.Printf("n1mm sink: write failed: %v", err)
+				events.Warn(s.logger, "sink.n1mm.disconnect", events.Err(err))
+				return
+			}
+		}
+	}
+}
+
+func (s *N1MMSink) isShutdown() bool {
+	select {
+	case <-s.shutdown:
+		return true
+	default:
+		return false
+	}
+}
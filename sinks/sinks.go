@@ -0,0 +1,49 @@
+// Package sinks implements optional downstream forwarders for deduplicated
+// spots - radios and contest loggers that want the unified, deduplicated
+// stream rather than talking to each upstream source themselves. Each sink
+// owns a bounded queue and its own network connection so a slow or
+// unreachable downstream never blocks processOutputSpots.
+package sinks
+
+import (
+	"net"
+	"sync"
+
+	"dxcluster/spot"
+)
+
+// SpotSink receives every spot that survives deduplication. Submit must
+// never block: a full queue should be dropped and counted, not backed up
+// into the output loop.
+type SpotSink interface {
+	// Submit enqueues a spot for delivery, returning false if the sink's
+	// queue was full and the spot was dropped.
+	Submit(s *spot.Spot) bool
+	// Stats reports the current queue depth and the cumulative number of
+	// spots dropped because the queue was full.
+	Stats() (queueLen int, drops uint64)
+	// Name identifies the sink for logging and stats display.
+	Name() string
+	// Stop drains and closes the sink, releasing its connection.
+	Stop()
+}
+
+// connBox holds a sink's live connection behind a mutex, since it's written
+// by the sink's reconnect loop and read/closed by Stop() from whatever
+// goroutine calls that - a plain field would race under go test -race.
+type connBox struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (b *connBox) set(conn net.Conn) {
+	b.mu.Lock()
+	b.conn = conn
+	b.mu.Unlock()
+}
+
+func (b *connBox) get() net.Conn {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.conn
+}
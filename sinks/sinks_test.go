@@ -0,0 +1,96 @@
+package sinks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dxcluster/spot"
+)
+
+func TestFlexModeStringTranslatesKnownModes(t *testing.T) {
+	cases := map[string]string{
+		"CW":  "CW",
+		"FT8": "DIGU",
+		"FT4": "DIGU",
+		"SSB": "LSB",
+		"":    "CW",
+		"XYZ": "CW",
+	}
+	for in, want := range cases {
+		if got := flexModeString(in); got != want {
+			t.Errorf("flexModeString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFlexColorForModeIsDistinctPerMode(t *testing.T) {
+	cw := flexColorForMode("CW")
+	digu := flexColorForMode("DIGU")
+	if cw == digu {
+		t.Fatalf("expected distinct colors for CW and DIGU, both got %q", cw)
+	}
+	if flexColorForMode("UNKNOWN") != flexDefaultColor {
+		t.Fatalf("expected default color for an unrecognised radio mode")
+	}
+}
+
+func TestBuildSpotAddCommandFormatsFields(t *testing.T) {
+	s := spot.NewSpot("DX1ABC", "W1AW", 14025.5, "CW")
+	s.SourceNode = "RBN"
+	s.Time = time.Unix(1700000000, 0).UTC()
+
+	cmd := buildSpotAddCommand(s, 1800)
+	for _, want := range []string{"rx_freq=14.025500", "callsign=DX1ABC", "mode=CW", "source=RBN", "lifetime_seconds=1800"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command %q missing %q", cmd, want)
+		}
+	}
+}
+
+func TestBuildSpotXMLEscapesAndFormats(t *testing.T) {
+	s := spot.NewSpot("DX1ABC", "W1AW", 14025.5, "CW")
+	s.Comment = "5 & 9 <loud>"
+	s.Time = time.Unix(1700000000, 0).UTC()
+
+	xmlDoc := buildSpotXML(s)
+	if !strings.Contains(xmlDoc, "<call>DX1ABC</call>") {
+		t.Fatalf("missing call element: %q", xmlDoc)
+	}
+	if strings.Contains(xmlDoc, "<comment>5 & 9") {
+		t.Fatalf("expected comment to be XML-escaped: %q", xmlDoc)
+	}
+	if !strings.Contains(xmlDoc, "5 &amp; 9 &lt;loud&gt;") {
+		t.Fatalf("expected escaped comment, got: %q", xmlDoc)
+	}
+}
+
+func TestFlexSinkSubmitDropsWhenQueueFull(t *testing.T) {
+	sink := NewFlexSink(FlexConfig{Host: "127.0.0.1", Port: 4992, QueueSize: 1})
+	s := spot.NewSpot("DX1ABC", "W1AW", 14025.5, "CW")
+
+	if !sink.Submit(s) {
+		t.Fatal("expected first submit to succeed")
+	}
+	if sink.Submit(s) {
+		t.Fatal("expected second submit to be dropped once the queue is full")
+	}
+	if _, drops := sink.Stats(); drops != 1 {
+		t.Fatalf("expected 1 drop recorded, got %d", drops)
+	}
+}
+
+func TestN1MMSinkSubmitDropsWhenQueueFull(t *testing.T) {
+	sink := NewN1MMSink(N1MMConfig{Address: "239.192.1.1:12060", QueueSize: 1})
+	s := spot.NewSpot("DX1ABC", "W1AW", 14025.5, "CW")
+
+	if !sink.Submit(s) {
+		t.Fatal("expected first submit to succeed")
+	}
+	if sink.Submit(s) {
+		t.Fatal("expected second submit to be dropped once the queue is full")
+	}
+	if _, drops := sink.Stats(); drops != 1 {
+		t.Fatalf("expected 1 drop recorded, got %d", drops)
+	}
+}
@@ -0,0 +1,348 @@
+package skew
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EstimatorConfig controls how Estimator aggregates spot observations into
+// skew corrections.
+type EstimatorConfig struct {
+	// Window is how far apart in time two reports of the same (dx, band)
+	// pair can be and still be compared against the same consensus
+	// frequency.
+	Window time.Duration
+	// MinSpots is the minimum number of accepted offset samples a skimmer
+	// must have accumulated before it appears in a Snapshot. This is
+	// independent of ApplyCorrection's minSamplesForCorrection: Snapshot
+	// feeds the CSV-compatible Table export, while ApplyCorrection trusts
+	// the online EWMA state published via PublishTo/Store.SetOnline - the
+	// two call sites can reasonably want different confidence bars.
+	MinSpots int
+	// ConsensusQuorum is the minimum number of distinct reports of a (dx,
+	// band) pair within Window required before it's treated as a
+	// consensus frequency worth comparing against.
+	ConsensusQuorum int
+	// EWMAAlpha is the smoothing factor applied to each skimmer's running
+	// offset and variance estimate; higher values track recent drift
+	// faster at the cost of more noise.
+	EWMAAlpha float64
+	// DriftWindow is how often a skimmer's EWMA offset is re-baselined for
+	// drift comparison; see DriftThresholdHz.
+	DriftWindow time.Duration
+	// DriftThresholdHz is how far (in Hz) a skimmer's offset can move
+	// between re-baselinings before it's logged as a possible
+	// mis-calibration worth an operator's attention.
+	DriftThresholdHz float64
+}
+
+// normalize fills in sane defaults for zero-valued fields.
+func (c EstimatorConfig) normalize() EstimatorConfig {
+	if c.Window <= 0 {
+		c.Window = 10 * time.Second
+	}
+	if c.MinSpots <= 0 {
+		c.MinSpots = 20
+	}
+	if c.ConsensusQuorum <= 0 {
+		c.ConsensusQuorum = 3
+	}
+	if c.EWMAAlpha <= 0 {
+		c.EWMAAlpha = 0.1
+	}
+	if c.DriftWindow <= 0 {
+		c.DriftWindow = 30 * time.Minute
+	}
+	if c.DriftThresholdHz <= 0 {
+		c.DriftThresholdHz = 15.0
+	}
+	return c
+}
+
+// Observation is one skimmer's report of a DX station, as fed to Estimator
+// from the live spot stream.
+type Observation struct {
+	SkimmerCall string
+	DXCall      string
+	Band        string
+	FreqHz      float64
+	Time        time.Time
+}
+
+// groupReport is one pooled report within a dxBandGroup. counted tracks
+// whether it has already contributed an offset to its skimmer's history,
+// so reaching quorum later doesn't double-count it.
+type groupReport struct {
+	obs     Observation
+	skimmer string
+	counted bool
+}
+
+// dxBandGroup accumulates the reports seen for one (dx, band) pair within
+// the current window, so a consensus frequency can be formed once enough
+// independent skimmers have weighed in.
+type dxBandGroup struct {
+	reports []groupReport
+}
+
+// skimmerSkew is one skimmer's online-learned skew estimate: an EWMA of its
+// residual against the rolling consensus frequency, the matching EWMA
+// variance, and a periodically re-baselined offset used to detect drift.
+type skimmerSkew struct {
+	offsetHz    float64
+	varianceHz2 float64
+	freqHzEWMA  float64
+	sampleCount int
+	lastUpdated time.Time
+
+	baselineHz float64
+	baselineAt time.Time
+}
+
+// SkimmerSkew is the public, persistable view of a skimmer's online skew
+// state: {offsetHz, varianceHz2, sampleCount, lastUpdated}, keyed by
+// callsign elsewhere (OnlineSnapshot, Store.SetOnline).
+type SkimmerSkew struct {
+	Callsign    string    `json:"callsign"`
+	OffsetHz    float64   `json:"offset_hz"`
+	VarianceHz2 float64   `json:"variance_hz2"`
+	SampleCount int       `json:"sample_count"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// Estimator aggregates a live stream of skimmer spot observations into
+// per-skimmer frequency-skew corrections, as a local alternative to
+// downloading a precomputed skew table. For each (dx, band) pair it forms
+// a consensus frequency from independent skimmers' reports within a short
+// window, then folds every report's deviation from that consensus into the
+// skimmer's running EWMA offset and variance - an online learner that
+// adapts as a skimmer's receiver drifts, rather than a static correction
+// computed once from a CSV snapshot.
+type Estimator struct {
+	cfg EstimatorConfig
+
+	mu     sync.Mutex
+	groups map[string]*dxBandGroup // "DX|BAND" -> in-window reports
+	skews  map[string]*skimmerSkew // skimmer call -> online skew state
+}
+
+// NewEstimator creates an Estimator with the given config; zero-valued
+// fields fall back to sane defaults (see EstimatorConfig.normalize).
+func NewEstimator(cfg EstimatorConfig) *Estimator {
+	return &Estimator{
+		cfg:    cfg.normalize(),
+		groups: make(map[string]*dxBandGroup),
+		skews:  make(map[string]*skimmerSkew),
+	}
+}
+
+// Observe records one skimmer's report of dx on freqHz. If ConsensusQuorum
+// or more independent reports of the same (dx, band) pair already exist
+// within Window, obs's deviation from their consensus frequency is folded
+// into the skimmer's running EWMA offset and variance.
+func (e *Estimator) Observe(obs Observation) {
+	if e == nil {
+		return
+	}
+	skimmer := strings.ToUpper(strings.TrimSpace(obs.SkimmerCall))
+	dx := strings.ToUpper(strings.TrimSpace(obs.DXCall))
+	band := strings.ToUpper(strings.TrimSpace(obs.Band))
+	if skimmer == "" || dx == "" || band == "" {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := dx + "|" + band
+	g := e.groups[key]
+	if g == nil {
+		g = &dxBandGroup{}
+		e.groups[key] = g
+	}
+
+	// Keep only in-window reports from other skimmers: obs replaces this
+	// skimmer's earlier report, so quorum and consensus count independent
+	// skimmers rather than one skimmer's repeats.
+	kept := g.reports[:0]
+	for _, r := range g.reports {
+		delta := obs.Time.Sub(r.obs.Time)
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta <= e.cfg.Window && r.skimmer != skimmer {
+			kept = append(kept, r)
+		}
+	}
+	g.reports = append(kept, groupReport{obs: obs, skimmer: skimmer})
+
+	if len(g.reports) < e.cfg.ConsensusQuorum {
+		return
+	}
+
+	// Quorum reached (possibly only just now): form a consensus from every
+	// pooled report and back-fill an offset for any that hasn't
+	// contributed one yet, so a peer that arrived before quorum was
+	// reached isn't skipped just because it wasn't the triggering report.
+	freqs := make([]float64, len(g.reports))
+	for i, r := range g.reports {
+		freqs[i] = r.obs.FreqHz
+	}
+	consensus := median(freqs)
+	for i, r := range g.reports {
+		if r.counted {
+			continue
+		}
+		e.updateSkew(r.skimmer, r.obs.FreqHz-consensus, r.obs.FreqHz, r.obs.Time)
+		g.reports[i].counted = true
+	}
+}
+
+// updateSkew folds one residual observation into skimmer's running EWMA
+// offset and variance (the standard exponential-moving-variance update: the
+// same smoothing factor is applied to both the mean and the mean's own
+// squared deviation), and logs when the offset has drifted more than
+// DriftThresholdHz since the last re-baselining. The baseline is always
+// taken from the EWMA offset itself, never a raw single-sample residual -
+// otherwise a noisy first observation would pin a bad baseline and the
+// first drift check would compare a converged estimate against it.
+func (e *Estimator) updateSkew(skimmer string, residualHz, freqHz float64, at time.Time) {
+	st := e.skews[skimmer]
+	if st == nil {
+		st = &skimmerSkew{freqHzEWMA: freqHz}
+		e.skews[skimmer] = st
+	}
+
+	diff := residualHz - st.offsetHz
+	incr := e.cfg.EWMAAlpha * diff
+	st.offsetHz += incr
+	st.varianceHz2 = (1 - e.cfg.EWMAAlpha) * (st.varianceHz2 + diff*incr)
+	st.freqHzEWMA += e.cfg.EWMAAlpha * (freqHz - st.freqHzEWMA)
+	st.sampleCount++
+	st.lastUpdated = at
+
+	if st.baselineAt.IsZero() {
+		st.baselineHz = st.offsetHz
+		st.baselineAt = at
+		return
+	}
+
+	if at.Sub(st.baselineAt) >= e.cfg.DriftWindow {
+		if drift := st.offsetHz - st.baselineHz; math.Abs(drift) > e.cfg.DriftThresholdHz {
+			log.Printf("skew: %s offset drifted %.1f Hz over %s (now %.1f Hz from %d samples) - possible mis-calibration",
+				skimmer, drift, e.cfg.DriftWindow, st.offsetHz, st.sampleCount)
+		}
+		st.baselineHz = st.offsetHz
+		st.baselineAt = at
+	}
+}
+
+// Snapshot summarizes every skimmer with at least MinSpots accumulated
+// samples into an []Entry, suitable for WriteJSON or installing into a
+// Store's CSV-compatible Table via Store.Set.
+func (e *Estimator) Snapshot() []Entry {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entries := make([]Entry, 0, len(e.skews))
+	for skimmer, st := range e.skews {
+		if st.sampleCount < e.cfg.MinSpots {
+			continue
+		}
+		factor := 1.0
+		if st.freqHzEWMA != 0 {
+			factor = 1 - st.offsetHz/st.freqHzEWMA
+		}
+		entries = append(entries, Entry{
+			Callsign:         skimmer,
+			SkewHz:           st.offsetHz,
+			Spots:            st.sampleCount,
+			CorrectionFactor: factor,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Callsign < entries[j].Callsign })
+	return entries
+}
+
+// OnlineSnapshot exports every skimmer's current online skew state
+// regardless of MinSpots, suitable for persistence (SaveOnlineState) or
+// publishing into a Store (Store.SetOnline) for ApplyCorrection and the
+// /skew HTTP endpoint to read live.
+func (e *Estimator) OnlineSnapshot() []SkimmerSkew {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make([]SkimmerSkew, 0, len(e.skews))
+	for skimmer, st := range e.skews {
+		out = append(out, SkimmerSkew{
+			Callsign:    skimmer,
+			OffsetHz:    st.offsetHz,
+			VarianceHz2: st.varianceHz2,
+			SampleCount: st.sampleCount,
+			LastUpdated: st.lastUpdated,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Callsign < out[j].Callsign })
+	return out
+}
+
+// PublishTo installs the estimator's current online skew state into store,
+// so ApplyCorrection sees it on the hot path without Store having to know
+// anything about how the estimate was derived. Callers typically call this
+// on a timer (e.g. every few seconds) from the same goroutine that feeds
+// Observe.
+func (e *Estimator) PublishTo(store *Store) {
+	store.SetOnline(e.OnlineSnapshot())
+}
+
+// RestoreOnlineState seeds the estimator with previously persisted skew
+// state (see LoadOnlineState), so a restart doesn't throw away calibration
+// that took a long rolling window to build up. States with a callsign
+// already tracked, or with SampleCount <= 0, are ignored.
+func (e *Estimator) RestoreOnlineState(states []SkimmerSkew) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, s := range states {
+		call := strings.ToUpper(strings.TrimSpace(s.Callsign))
+		if call == "" || s.SampleCount <= 0 {
+			continue
+		}
+		if _, exists := e.skews[call]; exists {
+			continue
+		}
+		e.skews[call] = &skimmerSkew{
+			offsetHz:    s.OffsetHz,
+			varianceHz2: s.VarianceHz2,
+			sampleCount: s.SampleCount,
+			lastUpdated: s.LastUpdated,
+			baselineHz:  s.OffsetHz,
+			baselineAt:  s.LastUpdated,
+		}
+	}
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
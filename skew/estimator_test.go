@@ -0,0 +1,125 @@
+package skew
+
+import (
+	"testing"
+	"time"
+)
+
+func obs(skimmer, dx, band string, freqHz float64, at time.Time) Observation {
+	return Observation{SkimmerCall: skimmer, DXCall: dx, Band: band, FreqHz: freqHz, Time: at}
+}
+
+func TestEstimatorRecordsOffsetOnceConsensusQuorumReached(t *testing.T) {
+	e := NewEstimator(EstimatorConfig{Window: time.Second, MinSpots: 1, ConsensusQuorum: 3})
+	base := time.Unix(1_700_000_000, 0).UTC()
+
+	// Two reports: no consensus yet, no offset recorded for either.
+	e.Observe(obs("K1ABC-1", "W1AW", "20M", 14074000, base))
+	e.Observe(obs("K2XYZ-2", "W1AW", "20M", 14074000, base))
+	if len(e.OnlineSnapshot()) != 0 {
+		t.Fatalf("expected no online state before quorum is reached, got %+v", e.OnlineSnapshot())
+	}
+
+	// Third independent report reaches quorum=3; all three get an offset.
+	e.Observe(obs("K3DEF-3", "W1AW", "20M", 14074020, base))
+
+	counts := map[string]int{}
+	for _, st := range e.OnlineSnapshot() {
+		counts[st.Callsign] = st.SampleCount
+	}
+
+	for _, call := range []string{"K1ABC-1", "K2XYZ-2", "K3DEF-3"} {
+		if counts[call] != 1 {
+			t.Fatalf("expected %s to have exactly 1 recorded sample, got %d", call, counts[call])
+		}
+	}
+}
+
+func TestEstimatorSnapshotRequiresMinSpots(t *testing.T) {
+	e := NewEstimator(EstimatorConfig{Window: time.Second, MinSpots: 2, ConsensusQuorum: 2})
+	base := time.Unix(1_700_000_000, 0).UTC()
+
+	e.Observe(obs("K1ABC-1", "W1AW", "20M", 14074000, base))
+	e.Observe(obs("K2XYZ-2", "W1AW", "20M", 14074000, base))
+	if entries := e.Snapshot(); len(entries) != 0 {
+		t.Fatalf("expected no entries with only 1 sample recorded (MinSpots=2), got %+v", entries)
+	}
+
+	e.Observe(obs("K1ABC-1", "W9ZZZ", "40M", 7074000, base.Add(time.Millisecond)))
+	e.Observe(obs("K2XYZ-2", "W9ZZZ", "40M", 7074000, base.Add(time.Millisecond)))
+	entries := e.Snapshot()
+	if len(entries) != 2 {
+		t.Fatalf("expected both skimmers to now meet MinSpots=2, got %+v", entries)
+	}
+}
+
+// TestEstimatorEWMAConverges feeds a skimmer a steady 10 Hz-high residual
+// over many consensus rounds and checks its online offset converges toward
+// 10 Hz with a low variance, the case ApplyCorrection should trust.
+func TestEstimatorEWMAConverges(t *testing.T) {
+	e := NewEstimator(EstimatorConfig{Window: time.Second, MinSpots: 1, ConsensusQuorum: 2})
+	base := time.Unix(1_700_000_000, 0).UTC()
+
+	for i := 0; i < 200; i++ {
+		at := base.Add(time.Duration(i) * time.Millisecond)
+		e.Observe(obs("K1ABC-1", "W1AW", "20M", 14074010, at))
+		e.Observe(obs("K2XYZ-2", "W1AW", "20M", 14074000, at))
+	}
+
+	var skewed SkimmerSkew
+	for _, st := range e.OnlineSnapshot() {
+		if st.Callsign == "K1ABC-1" {
+			skewed = st
+		}
+	}
+	if skewed.SampleCount == 0 {
+		t.Fatalf("expected K1ABC-1 to have accumulated samples")
+	}
+	if skewed.OffsetHz < 4 || skewed.OffsetHz > 6 {
+		t.Fatalf("expected K1ABC-1's EWMA offset to converge near 5 Hz (half the 10 Hz gap to the 2-way consensus), got %.2f", skewed.OffsetHz)
+	}
+	if skewed.VarianceHz2 >= maxVarianceHz2ForCorrection {
+		t.Fatalf("expected a steady residual to settle into low variance, got %.2f", skewed.VarianceHz2)
+	}
+}
+
+// TestEstimatorEWMANoisyOffsetStaysVariant checks that a skimmer whose
+// residual alternates wildly keeps a high EWMA variance, the signal
+// ApplyCorrection uses to avoid trusting an unstable estimate.
+func TestEstimatorEWMANoisyOffsetStaysVariant(t *testing.T) {
+	e := NewEstimator(EstimatorConfig{Window: time.Second, MinSpots: 1, ConsensusQuorum: 2, EWMAAlpha: 0.3})
+	base := time.Unix(1_700_000_000, 0).UTC()
+
+	for i := 0; i < 50; i++ {
+		at := base.Add(time.Duration(i) * time.Millisecond)
+		freq := 14074000.0
+		if i%2 == 0 {
+			freq = 14074200
+		}
+		e.Observe(obs("K1ABC-1", "W1AW", "20M", freq, at))
+		e.Observe(obs("K2XYZ-2", "W1AW", "20M", 14074000, at))
+	}
+
+	var noisy SkimmerSkew
+	for _, st := range e.OnlineSnapshot() {
+		if st.Callsign == "K1ABC-1" {
+			noisy = st
+		}
+	}
+	if noisy.VarianceHz2 < maxVarianceHz2ForCorrection {
+		t.Fatalf("expected an alternating 0/100 Hz residual to keep variance above the correction threshold, got %.2f", noisy.VarianceHz2)
+	}
+}
+
+func TestEstimatorIgnoresObservationsOutsideWindow(t *testing.T) {
+	e := NewEstimator(EstimatorConfig{Window: time.Second, MinSpots: 1, ConsensusQuorum: 2})
+	base := time.Unix(1_700_000_000, 0).UTC()
+
+	e.Observe(obs("K1ABC-1", "W1AW", "20M", 14074000, base))
+	// Well outside the ±1s window: shouldn't count toward consensus with the first.
+	e.Observe(obs("K2XYZ-2", "W1AW", "20M", 14074000, base.Add(time.Hour)))
+
+	if entries := e.Snapshot(); len(entries) != 0 {
+		t.Fatalf("expected no consensus to form across a stale report, got %+v", entries)
+	}
+}
@@ -0,0 +1,392 @@
+package skew
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultQuietInterval  = 30 * time.Minute
+	defaultNormalInterval = 10 * time.Minute
+	defaultBusyInterval   = 2 * time.Minute
+
+	minBackoff = 5 * time.Second
+	maxBackoff = 10 * time.Minute
+)
+
+// AdaptiveState reports the busiest adaptive-refresh state currently in
+// effect ("quiet", "normal", "busy"), used to scale how often FetchRefresher
+// polls for a new skew table. Defined locally (rather than depending on the
+// main daemon's adaptiveRefresher type) so this package has no upward
+// dependency; *adaptiveRefresher already satisfies this structurally.
+type AdaptiveState interface {
+	HighestState() string
+}
+
+// FetchRefresherConfig controls FetchRefresher's polling cadence and where
+// it persists its table and HTTP caching metadata.
+type FetchRefresherConfig struct {
+	// URL is the CSV endpoint FetchRefresher polls, same as Fetch's rawURL.
+	URL string
+	// JSONPath is where the parsed table is written on every 200 response,
+	// in the same format WriteJSON/LoadFile use. A sidecar file alongside
+	// it (JSONPath + ".meta.json") persists the ETag/Last-Modified seen on
+	// the last response, so a restart can still send a conditional GET.
+	JSONPath string
+	// Adaptive, if set, scales the poll interval with the busiest current
+	// adaptive-refresh state. Nil always uses NormalInterval.
+	Adaptive AdaptiveState
+
+	QuietInterval  time.Duration
+	NormalInterval time.Duration
+	BusyInterval   time.Duration
+}
+
+func (c FetchRefresherConfig) normalize() FetchRefresherConfig {
+	if c.QuietInterval <= 0 {
+		c.QuietInterval = defaultQuietInterval
+	}
+	if c.NormalInterval <= 0 {
+		c.NormalInterval = defaultNormalInterval
+	}
+	if c.BusyInterval <= 0 {
+		c.BusyInterval = defaultBusyInterval
+	}
+	return c
+}
+
+// FetchRefresher periodically polls a skew CSV endpoint with conditional
+// GETs, writes a freshly parsed table to disk via write-temp-then-rename,
+// and installs it into a Store. It backs off exponentially with jitter on
+// repeated failures, and exposes counters suitable for a Prometheus
+// collector (bytes transferred, 304-vs-200 ratio, time since last success).
+type FetchRefresher struct {
+	cfg   FetchRefresherConfig
+	store *Store
+
+	mu           sync.Mutex
+	currentETag  string
+	lastModified string
+
+	bytesTransferred atomic.Uint64
+	notModifiedCount atomic.Uint64
+	modifiedCount    atomic.Uint64
+	lastSuccess      atomic.Int64 // unix nanos; 0 = never succeeded
+
+	quit chan struct{}
+}
+
+// NewFetchRefresher constructs a FetchRefresher, seeding its conditional-GET
+// state from JSONPath's sidecar file if one exists. It returns nil if store,
+// cfg.URL, or cfg.JSONPath is missing, matching the nil-safe
+// optional-subsystem pattern used elsewhere in this package.
+func NewFetchRefresher(store *Store, cfg FetchRefresherConfig) *FetchRefresher {
+	if store == nil || strings.TrimSpace(cfg.URL) == "" || strings.TrimSpace(cfg.JSONPath) == "" {
+		return nil
+	}
+	cfg = cfg.normalize()
+	sc := loadFetchSidecar(cfg.JSONPath)
+	return &FetchRefresher{
+		cfg:          cfg,
+		store:        store,
+		currentETag:  sc.ETag,
+		lastModified: sc.LastModified,
+		quit:         make(chan struct{}),
+	}
+}
+
+// Start begins the polling loop in a background goroutine.
+func (r *FetchRefresher) Start() {
+	if r == nil {
+		return
+	}
+	go r.loop()
+}
+
+// Stop ends the polling loop.
+func (r *FetchRefresher) Stop() {
+	if r == nil {
+		return
+	}
+	close(r.quit)
+}
+
+func (r *FetchRefresher) loop() {
+	attempt := 0
+	for {
+		select {
+		case <-r.quit:
+			return
+		case <-time.After(r.currentInterval()):
+		}
+
+		if err := r.refresh(); err != nil {
+			attempt++
+			backoff := backoffWithJitter(attempt)
+			log.Printf("skew: fetch refresh failed (attempt %d), backing off %s: %v", attempt, backoff, err)
+			select {
+			case <-r.quit:
+				return
+			case <-time.After(backoff):
+			}
+			continue
+		}
+		attempt = 0
+	}
+}
+
+func (r *FetchRefresher) currentInterval() time.Duration {
+	state := ""
+	if r.cfg.Adaptive != nil {
+		state = r.cfg.Adaptive.HighestState()
+	}
+	switch state {
+	case "quiet":
+		return r.cfg.QuietInterval
+	case "busy":
+		return r.cfg.BusyInterval
+	default:
+		return r.cfg.NormalInterval
+	}
+}
+
+// refresh runs one conditional-GET poll: a 304 is treated as success without
+// touching JSONPath; a 200 atomically rewrites it and installs the new
+// table.
+func (r *FetchRefresher) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	r.mu.Lock()
+	etag, lastModified := r.currentETag, r.lastModified
+	r.mu.Unlock()
+
+	entries, notModified, newETag, newLastModified, bytesRead, err := fetchConditional(ctx, r.cfg.URL, etag, lastModified)
+	if err != nil {
+		return err
+	}
+
+	r.bytesTransferred.Add(uint64(bytesRead))
+
+	r.mu.Lock()
+	r.currentETag, r.lastModified = newETag, newLastModified
+	r.mu.Unlock()
+	r.writeSidecar()
+
+	if notModified {
+		r.notModifiedCount.Add(1)
+		r.lastSuccess.Store(time.Now().UnixNano())
+		return nil
+	}
+
+	if err := writeJSONAtomic(entries, r.cfg.JSONPath); err != nil {
+		return err
+	}
+	table, err := NewTable(entries)
+	if err != nil {
+		return err
+	}
+	r.store.Set(table)
+
+	r.modifiedCount.Add(1)
+	r.lastSuccess.Store(time.Now().UnixNano())
+	return nil
+}
+
+// BytesTransferred returns the total response-body bytes read across every
+// 200 response so far.
+func (r *FetchRefresher) BytesTransferred() uint64 {
+	if r == nil {
+		return 0
+	}
+	return r.bytesTransferred.Load()
+}
+
+// NotModifiedCount returns how many polls got a 304 Not Modified response.
+func (r *FetchRefresher) NotModifiedCount() uint64 {
+	if r == nil {
+		return 0
+	}
+	return r.notModifiedCount.Load()
+}
+
+// ModifiedCount returns how many polls got a fresh 200 response.
+func (r *FetchRefresher) ModifiedCount() uint64 {
+	if r == nil {
+		return 0
+	}
+	return r.modifiedCount.Load()
+}
+
+// LastSuccess returns when a poll (200 or 304) last completed successfully,
+// or the zero time if none has yet.
+func (r *FetchRefresher) LastSuccess() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	nanos := r.lastSuccess.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+func (r *FetchRefresher) writeSidecar() {
+	r.mu.Lock()
+	sc := fetchSidecar{ETag: r.currentETag, LastModified: r.lastModified}
+	r.mu.Unlock()
+	if err := sc.write(r.cfg.JSONPath); err != nil {
+		log.Printf("skew: writing fetch sidecar: %v", err)
+	}
+}
+
+// backoffWithJitter returns an exponential backoff duration for the given
+// attempt (1-indexed), capped at maxBackoff and randomized by up to 50% to
+// avoid a thundering herd after a shared outage.
+func backoffWithJitter(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	shift := attempt - 1
+	if shift > 10 {
+		shift = 10
+	}
+	d := minBackoff * time.Duration(1<<uint(shift))
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
+
+// fetchSidecar persists the ETag/Last-Modified seen on the last response to
+// JSONPath's endpoint, so a restart can still send a conditional GET instead
+// of always re-downloading the full CSV.
+type fetchSidecar struct {
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+}
+
+func sidecarPath(jsonPath string) string {
+	return jsonPath + ".meta.json"
+}
+
+func loadFetchSidecar(jsonPath string) fetchSidecar {
+	payload, err := os.ReadFile(sidecarPath(jsonPath))
+	if err != nil {
+		return fetchSidecar{}
+	}
+	var sc fetchSidecar
+	if err := json.Unmarshal(payload, &sc); err != nil {
+		return fetchSidecar{}
+	}
+	return sc
+}
+
+func (sc fetchSidecar) write(jsonPath string) error {
+	payload, err := json.Marshal(sc)
+	if err != nil {
+		return fmt.Errorf("skew: marshal fetch sidecar: %w", err)
+	}
+	return os.WriteFile(sidecarPath(jsonPath), payload, 0o644)
+}
+
+// fetchConditional sends a conditional GET for rawURL, returning the parsed
+// entries (nil on a 304), whether the response was a 304, the response's
+// ETag/Last-Modified for the next call, and the number of response-body
+// bytes read.
+func fetchConditional(ctx context.Context, rawURL, etag, lastModified string) (entries []Entry, notModified bool, newETag, newLastModified string, bytesRead int, err error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil, false, "", "", 0, errors.New("skew: url is required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, false, "", "", 0, fmt.Errorf("skew: build request: %w", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, false, "", "", 0, fmt.Errorf("skew: download csv: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, true, etag, lastModified, 0, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, "", "", 0, fmt.Errorf("skew: unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, "", "", 0, fmt.Errorf("skew: read response: %w", err)
+	}
+
+	parsed, err := parseCSV(body)
+	if err != nil {
+		return nil, false, "", "", 0, err
+	}
+
+	return parsed, false, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), len(body), nil
+}
+
+// writeJSONAtomic is like WriteJSON but swaps the file via write-to-temp +
+// rename, so a concurrent reader (e.g. LoadFile on the next daemon start)
+// never observes a partially written file mid-refresh.
+func writeJSONAtomic(entries []Entry, path string) error {
+	if len(entries) == 0 {
+		return errors.New("skew: no entries to write")
+	}
+	payload, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("skew: marshal json: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("skew: mkdir %s: %w", dir, err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, ".skew-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("skew: create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(payload); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("skew: write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("skew: close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("skew: rename temp file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package skew
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchConditionalUsesETagAndHandlesNotModified(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("W1AW,10,5,1.0005\n"))
+	}))
+	defer srv.Close()
+
+	entries, notModified, etag, _, bytesRead, err := fetchConditional(context.Background(), srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	if notModified || len(entries) != 1 || bytesRead == 0 {
+		t.Fatalf("expected a fresh 200 with 1 entry, got entries=%+v notModified=%v bytesRead=%d", entries, notModified, bytesRead)
+	}
+	if etag != `"v1"` {
+		t.Fatalf("expected etag v1, got %q", etag)
+	}
+
+	entries, notModified, _, _, bytesRead, err = fetchConditional(context.Background(), srv.URL, etag, "")
+	if err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if !notModified || entries != nil || bytesRead != 0 {
+		t.Fatalf("expected a 304 with no entries, got entries=%+v notModified=%v bytesRead=%d", entries, notModified, bytesRead)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestFetchConditionalRejects5xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if _, _, _, _, _, err := fetchConditional(context.Background(), srv.URL, "", ""); err == nil {
+		t.Fatal("expected an error on a 503 response")
+	}
+}
+
+func TestFetchSidecarRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "skew.json")
+
+	sc := fetchSidecar{ETag: `"abc"`, LastModified: "Tue, 01 Jan 2030 00:00:00 GMT"}
+	if err := sc.write(jsonPath); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	got := loadFetchSidecar(jsonPath)
+	if got != sc {
+		t.Fatalf("expected %+v, got %+v", sc, got)
+	}
+}
+
+func TestLoadFetchSidecarMissingFileReturnsZeroValue(t *testing.T) {
+	got := loadFetchSidecar(filepath.Join(t.TempDir(), "missing.json"))
+	if got != (fetchSidecar{}) {
+		t.Fatalf("expected zero value for a missing sidecar, got %+v", got)
+	}
+}
+
+func TestWriteJSONAtomicLeavesNoTempFileBehind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "skew.json")
+
+	if err := writeJSONAtomic([]Entry{{Callsign: "W1AW", SkewHz: 1, Spots: 1, CorrectionFactor: 1}}, path); err != nil {
+		t.Fatalf("writeJSONAtomic: %v", err)
+	}
+
+	if _, err := LoadFile(path); err != nil {
+		t.Fatalf("expected the written file to parse back, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected exactly the final file and no leftover temp file, got %+v", entries)
+	}
+}
+
+func TestBackoffWithJitterIsBoundedAndGrows(t *testing.T) {
+	first := backoffWithJitter(1)
+	if first < minBackoff/2 || first > minBackoff {
+		t.Fatalf("expected attempt 1 backoff within [%s, %s], got %s", minBackoff/2, minBackoff, first)
+	}
+
+	large := backoffWithJitter(100)
+	if large > maxBackoff {
+		t.Fatalf("expected backoff capped at %s, got %s", maxBackoff, large)
+	}
+}
+
+type fakeAdaptiveState struct{ state string }
+
+func (f fakeAdaptiveState) HighestState() string { return f.state }
+
+func TestFetchRefresherCurrentIntervalFollowsAdaptiveState(t *testing.T) {
+	r := NewFetchRefresher(NewStore(), FetchRefresherConfig{
+		URL:      "http://example.invalid/skew.csv",
+		JSONPath: filepath.Join(t.TempDir(), "skew.json"),
+		Adaptive: fakeAdaptiveState{state: "busy"},
+	})
+	if got := r.currentInterval(); got != defaultBusyInterval {
+		t.Fatalf("expected busy interval %s, got %s", defaultBusyInterval, got)
+	}
+
+	r.cfg.Adaptive = fakeAdaptiveState{state: "quiet"}
+	if got := r.currentInterval(); got != defaultQuietInterval {
+		t.Fatalf("expected quiet interval %s, got %s", defaultQuietInterval, got)
+	}
+}
+
+func TestNewFetchRefresherRequiresStoreAndURL(t *testing.T) {
+	if r := NewFetchRefresher(nil, FetchRefresherConfig{URL: "http://x", JSONPath: "x.json"}); r != nil {
+		t.Fatal("expected nil with no store")
+	}
+	if r := NewFetchRefresher(NewStore(), FetchRefresherConfig{JSONPath: "x.json"}); r != nil {
+		t.Fatal("expected nil with no URL")
+	}
+}
+
+func TestFetchRefresherRefreshWritesFileAndStore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("W1AW,10,5,1.0005\n"))
+	}))
+	defer srv.Close()
+
+	store := NewStore()
+	jsonPath := filepath.Join(t.TempDir(), "skew.json")
+	r := NewFetchRefresher(store, FetchRefresherConfig{URL: srv.URL, JSONPath: jsonPath})
+
+	if err := r.refresh(); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if store.Count() != 1 {
+		t.Fatalf("expected the store to be populated, got count=%d", store.Count())
+	}
+	if r.ModifiedCount() != 1 || r.NotModifiedCount() != 0 {
+		t.Fatalf("expected modified=1 notModified=0, got modified=%d notModified=%d", r.ModifiedCount(), r.NotModifiedCount())
+	}
+	if r.LastSuccess().IsZero() {
+		t.Fatal("expected LastSuccess to be set after a successful refresh")
+	}
+	if _, err := os.Stat(sidecarPath(jsonPath)); err != nil {
+		t.Fatalf("expected a sidecar file to be written: %v", err)
+	}
+
+	if err := r.refresh(); err != nil {
+		t.Fatalf("second refresh: %v", err)
+	}
+	if r.NotModifiedCount() != 1 {
+		t.Fatalf("expected the second poll to be a 304, got notModified=%d", r.NotModifiedCount())
+	}
+}
+
+func TestFetchRefresherNilIsSafe(t *testing.T) {
+	var r *FetchRefresher
+	r.Start()
+	r.Stop()
+	if r.BytesTransferred() != 0 || r.NotModifiedCount() != 0 || r.ModifiedCount() != 0 || !r.LastSuccess().IsZero() {
+		t.Fatal("expected all nil-receiver accessors to return zero values")
+	}
+}
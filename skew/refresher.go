@@ -0,0 +1,79 @@
+package skew
+
+import "time"
+
+// defaultRefreshInterval is used when Refresher is constructed with a
+// non-positive interval.
+const defaultRefreshInterval = 10 * time.Minute
+
+// Refresher periodically builds a Table from an Estimator's accumulated
+// offsets and installs it into a Store, so a running node's own skew
+// corrections gradually take over from (or fill in alongside) whatever
+// Store was seeded with via Fetch/LoadFile at bootstrap.
+type Refresher struct {
+	estimator *Estimator
+	store     *Store
+	interval  time.Duration
+	quit      chan struct{}
+}
+
+// NewRefresher returns a Refresher that snapshots estimator into store
+// every interval. It returns nil if estimator or store is nil, matching
+// the nil-safe optional-subsystem pattern used elsewhere (e.g.
+// adaptiveRefresher).
+func NewRefresher(estimator *Estimator, store *Store, interval time.Duration) *Refresher {
+	if estimator == nil || store == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	return &Refresher{
+		estimator: estimator,
+		store:     store,
+		interval:  interval,
+		quit:      make(chan struct{}),
+	}
+}
+
+// Start begins the periodic refresh loop in a background goroutine.
+func (r *Refresher) Start() {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.refresh()
+			case <-r.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the refresh loop.
+func (r *Refresher) Stop() {
+	if r == nil {
+		return
+	}
+	close(r.quit)
+}
+
+func (r *Refresher) refresh() {
+	entries := r.estimator.Snapshot()
+	if len(entries) == 0 {
+		// Not enough accumulated data yet; leave the store's current
+		// table (from Fetch/LoadFile or an earlier refresh) in place.
+		return
+	}
+	table, err := NewTable(entries)
+	if err != nil {
+		r.store.RecordRefreshError()
+		return
+	}
+	r.store.Set(table)
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -86,9 +87,17 @@ func (t *Table) Lookup(call string) (float64, bool) {
 	return entry.CorrectionFactor, true
 }
 
-// Store provides atomic access to the latest skew table.
+// Store provides atomic access to the latest skew table and, separately,
+// the latest online per-skimmer skew state published by an Estimator (see
+// Estimator.PublishTo). The two are independent: Table holds the
+// multiplicative corrections downloaded from a CSV feed, while the online
+// state backs ApplyCorrection's EWMA-based additive correction.
 type Store struct {
-	ptr atomic.Pointer[Table]
+	ptr           atomic.Pointer[Table]
+	lastSuccess   atomic.Int64 // unix nanos of the last Set; 0 = never refreshed
+	refreshErrors atomic.Uint64
+
+	online atomic.Pointer[map[string]SkimmerSkew]
 }
 
 // NewStore constructs an empty store.
@@ -96,12 +105,46 @@ func NewStore() *Store {
 	return &Store{}
 }
 
-// Set replaces the currently stored table.
+// Set replaces the currently stored table and marks the refresh as
+// successful.
 func (s *Store) Set(table *Table) {
 	if s == nil {
 		return
 	}
 	s.ptr.Store(table)
+	s.lastSuccess.Store(time.Now().UnixNano())
+}
+
+// RecordRefreshError notes a failed refresh attempt (e.g. Fetch returned an
+// error), so the last good table can stay in place while /metrics still
+// surfaces that refreshes are failing.
+func (s *Store) RecordRefreshError() {
+	if s == nil {
+		return
+	}
+	s.refreshErrors.Add(1)
+}
+
+// LastSuccess returns when the store's table was last successfully
+// replaced via Set, or the zero time if it never has been.
+func (s *Store) LastSuccess() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	nanos := s.lastSuccess.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// ErrorCount returns the number of failed refresh attempts recorded via
+// RecordRefreshError.
+func (s *Store) ErrorCount() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.refreshErrors.Load()
 }
 
 // Lookup retrieves the correction factor for the raw skimmer callsign.
@@ -128,6 +171,123 @@ func (s *Store) Count() int {
 	return table.Count()
 }
 
+// SetOnline replaces the store's published online skew states, keyed by
+// uppercased callsign. Called from an Estimator via PublishTo; states with
+// an empty callsign are skipped.
+func (s *Store) SetOnline(states []SkimmerSkew) {
+	if s == nil {
+		return
+	}
+	m := make(map[string]SkimmerSkew, len(states))
+	for _, st := range states {
+		call := strings.ToUpper(strings.TrimSpace(st.Callsign))
+		if call == "" {
+			continue
+		}
+		m[call] = st
+	}
+	s.online.Store(&m)
+}
+
+// OnlineLookup returns the published online skew state for the raw DE
+// call, if any.
+func (s *Store) OnlineLookup(call string) (SkimmerSkew, bool) {
+	if s == nil {
+		return SkimmerSkew{}, false
+	}
+	m := s.online.Load()
+	if m == nil {
+		return SkimmerSkew{}, false
+	}
+	key := strings.ToUpper(strings.TrimSpace(call))
+	if key == "" {
+		return SkimmerSkew{}, false
+	}
+	st, ok := (*m)[key]
+	return st, ok
+}
+
+// OnlineSnapshot returns every published online skew state, sorted by
+// callsign, for the /skew HTTP endpoint and for persistence.
+func (s *Store) OnlineSnapshot() []SkimmerSkew {
+	if s == nil {
+		return []SkimmerSkew{}
+	}
+	m := s.online.Load()
+	if m == nil {
+		return []SkimmerSkew{}
+	}
+	out := make([]SkimmerSkew, 0, len(*m))
+	for _, st := range *m {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Callsign < out[j].Callsign })
+	return out
+}
+
+const (
+	// minSamplesForCorrection is the least number of online-learned
+	// samples a skimmer must have before ApplyCorrection trusts its
+	// offset over the skimmer's raw reported frequency.
+	minSamplesForCorrection = 20
+	// maxVarianceHz2ForCorrection caps how noisy (EWMA variance, Hz^2) a
+	// skimmer's offset can be and still be applied; a skimmer whose
+	// residuals bounce around (a loose VFO, intermittent GPS lock) is left
+	// uncorrected rather than "corrected" toward a number that isn't
+	// actually stable. 400 Hz^2 is a 20 Hz standard deviation.
+	maxVarianceHz2ForCorrection = 400.0
+)
+
+// ApplyCorrection returns freqHz adjusted by call's online-learned skew
+// offset, if store has enough confidence in it (at least
+// minSamplesForCorrection samples with variance under
+// maxVarianceHz2ForCorrection); otherwise it passes freqHz through
+// unchanged; a thin or still-noisy estimate isn't worth trusting over the
+// skimmer's raw report.
+func ApplyCorrection(store *Store, call string, freqHz float64) float64 {
+	st, ok := store.OnlineLookup(call)
+	if !ok || st.SampleCount < minSamplesForCorrection || st.VarianceHz2 >= maxVarianceHz2ForCorrection {
+		return freqHz
+	}
+	return freqHz - st.OffsetHz
+}
+
+// SaveOnlineState writes store's current online skew states to path as
+// JSON, in the same shape LoadOnlineState expects - so a restart can
+// restore an Estimator's calibration via Estimator.RestoreOnlineState
+// instead of re-accumulating it from scratch.
+func SaveOnlineState(store *Store, path string) error {
+	states := store.OnlineSnapshot()
+	payload, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("skew: marshal online state: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("skew: mkdir %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		return fmt.Errorf("skew: write file: %w", err)
+	}
+	return nil
+}
+
+// LoadOnlineState reads online skew state previously written by
+// SaveOnlineState.
+func LoadOnlineState(path string) ([]SkimmerSkew, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("skew: read %s: %w", path, err)
+	}
+	var states []SkimmerSkew
+	if err := json.Unmarshal(payload, &states); err != nil {
+		return nil, fmt.Errorf("skew: parse %s: %w", path, err)
+	}
+	return states, nil
+}
+
 // Fetch downloads the CSV table and returns parsed skew entries.
 func Fetch(ctx context.Context, rawURL string) ([]Entry, error) {
 	rawURL = strings.TrimSpace(rawURL)
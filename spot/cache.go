@@ -0,0 +1,70 @@
+package spot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cachedLoad is the on-disk representation of one loader's most recent
+// successful result, so a restart doesn't have to immediately re-hit a
+// remote source before serving any known callsigns at all.
+type cachedLoad struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Calls     []string  `json:"calls"`
+}
+
+// cacheFilePath returns the cache file used for one loader's results within dir.
+func cacheFilePath(dir string, name Source) string {
+	safe := strings.NewReplacer("/", "_", " ", "_").Replace(string(name))
+	return filepath.Join(dir, "known_callsigns_"+safe+".json")
+}
+
+// readCache returns a loader's cached entries if dir is set, a cache file
+// exists, and (when ttl > 0) it was written within ttl.
+func readCache(dir string, name Source, ttl time.Duration) (map[string]struct{}, bool) {
+	if dir == "" {
+		return nil, false
+	}
+	payload, err := os.ReadFile(cacheFilePath(dir, name))
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedLoad
+	if err := json.Unmarshal(payload, &cached); err != nil {
+		return nil, false
+	}
+	if ttl > 0 && time.Since(cached.FetchedAt) > ttl {
+		return nil, false
+	}
+	entries := make(map[string]struct{}, len(cached.Calls))
+	for _, call := range cached.Calls {
+		entries[call] = struct{}{}
+	}
+	return entries, true
+}
+
+// writeCache persists a loader's latest entries to dir; a no-op if dir is unset.
+func writeCache(dir string, name Source, entries map[string]struct{}) error {
+	if dir == "" {
+		return nil
+	}
+	calls := make([]string, 0, len(entries))
+	for call := range entries {
+		calls = append(calls, call)
+	}
+	sort.Strings(calls)
+
+	payload, err := json.MarshalIndent(cachedLoad{FetchedAt: time.Now(), Calls: calls}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("spot: marshal known callsigns cache: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("spot: mkdir %s: %w", dir, err)
+	}
+	return os.WriteFile(cacheFilePath(dir, name), payload, 0o644)
+}
@@ -0,0 +1,417 @@
+package spot
+
+import (
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// callDistanceScale is the integer scaling applied to every edit operation so
+// fractional substitution costs (e.g. 0.3 for a close CW confusable) can still
+// be represented as integers. A MaxEditDistance of N therefore corresponds to
+// a scaled threshold of N*callDistanceScale.
+const callDistanceScale = 10
+
+// CorrectionSettings controls the parameters used by SuggestCallCorrection.
+type CorrectionSettings struct {
+	MinConsensusReports  int
+	MinAdvantage         int
+	MinConfidencePercent int
+	MaxEditDistance      int
+	RecencyWindow        time.Duration
+}
+
+// correctionEligibleModes lists the modes where operator-copied callsigns are
+// prone to typos and thus worth running through correction/harmonic logic.
+// Automated digital modes (FT8, FT4, ...) decode callsigns algorithmically and
+// are excluded.
+var correctionEligibleModes = map[string]struct{}{
+	"CW":   {},
+	"SSB":  {},
+	"USB":  {},
+	"LSB":  {},
+	"FM":   {},
+	"AM":   {},
+	"RTTY": {},
+}
+
+// IsCallCorrectionCandidate reports whether spots in the given mode should be
+// considered for call-sign correction and harmonic suppression.
+func IsCallCorrectionCandidate(mode string) bool {
+	_, ok := correctionEligibleModes[strings.ToUpper(strings.TrimSpace(mode))]
+	return ok
+}
+
+// SuggestCallCorrection looks for a consensus among recently seen spots that
+// disagree with subject's call sign, and suggests a correction when enough
+// independent spotters agree on an alternate spelling that is cheap to reach
+// from the subject's call. It returns the suggested call, the number of
+// corroborating spotters, the confidence percentage behind that suggestion,
+// the confidence percentage behind the subject's own call, the total number
+// of reporters considered, and whether a correction should be applied.
+func SuggestCallCorrection(subject *Spot, others []*Spot, settings CorrectionSettings, now time.Time) (call string, supporters int, confidence int, subjectConfidence int, total int, ok bool) {
+	if subject == nil {
+		return "", 0, 0, 0, 0, false
+	}
+	subjectCall := strings.ToUpper(strings.TrimSpace(subject.DXCall))
+	if subjectCall == "" {
+		return "", 0, 0, 0, 0, false
+	}
+	subjectReporter := strings.ToUpper(strings.TrimSpace(subject.DECall))
+	maxDist := settings.MaxEditDistance * callDistanceScale
+
+	candidateReporters := make(map[string]map[string]struct{})
+	subjectReporters := make(map[string]struct{})
+
+	for _, o := range others {
+		if o == nil {
+			continue
+		}
+		reporter := strings.ToUpper(strings.TrimSpace(o.DECall))
+		if reporter == "" || reporter == subjectReporter {
+			continue
+		}
+		if settings.RecencyWindow > 0 {
+			delta := o.Time.Sub(subject.Time)
+			if delta < 0 {
+				delta = -delta
+			}
+			if delta > settings.RecencyWindow {
+				continue
+			}
+		}
+		otherCall := strings.ToUpper(strings.TrimSpace(o.DXCall))
+		if otherCall == "" {
+			continue
+		}
+		if otherCall == subjectCall {
+			subjectReporters[reporter] = struct{}{}
+			continue
+		}
+		if dist := callDistance(subjectCall, otherCall, subject.Mode, "morse", "baudot"); dist > maxDist {
+			continue
+		}
+		reporters := candidateReporters[otherCall]
+		if reporters == nil {
+			reporters = make(map[string]struct{})
+			candidateReporters[otherCall] = reporters
+		}
+		reporters[reporter] = struct{}{}
+	}
+
+	bestCall := ""
+	bestCount := 0
+	for candidate, reporters := range candidateReporters {
+		if count := len(reporters); count > bestCount || (count == bestCount && candidate < bestCall) {
+			bestCount = count
+			bestCall = candidate
+		}
+	}
+
+	subjectSupport := len(subjectReporters) + 1
+	total = subjectSupport + bestCount
+	if bestCount > 0 {
+		subjectConfidence = int(math.Round(float64(subjectSupport) / float64(total) * 100))
+	} else {
+		subjectConfidence = 100
+	}
+
+	if bestCall == "" || bestCount < settings.MinConsensusReports {
+		return "", 0, 0, subjectConfidence, total, false
+	}
+	if advantage := bestCount - subjectSupport; advantage < settings.MinAdvantage {
+		return "", 0, 0, subjectConfidence, total, false
+	}
+	confidencePercent := int(math.Round(float64(bestCount) / float64(total) * 100))
+	if confidencePercent < settings.MinConfidencePercent {
+		return "", 0, 0, subjectConfidence, total, false
+	}
+
+	return bestCall, bestCount, confidencePercent, subjectConfidence, total, true
+}
+
+// CallDistance returns the weighted Damerau-Levenshtein optimal-string-
+// alignment distance between two call signs for the given mode, in natural
+// edit-distance units (i.e. already divided back out of the internal
+// fixed-point scale callDistance uses to represent fractional confusable
+// costs). It's exposed for other packages, such as callcorr, that need the
+// same mode-aware confusable weighting SuggestCallCorrection uses
+// internally without duplicating it.
+func CallDistance(a, b, mode string) float64 {
+	return float64(callDistance(a, b, mode, "morse", "baudot")) / callDistanceScale
+}
+
+// callDistance computes a Damerau-Levenshtein optimal-string-alignment
+// distance between two call signs, scaled by callDistanceScale. Adjacent
+// transpositions (e.g. K1ABC <-> K1BAC) cost one scaled unit, the same as an
+// insert or delete. Substitution cost is drawn from a per-mode weighted
+// confusion matrix: cwVariant selects the CW/Morse cost table when mode is
+// "CW", rttyVariant selects the RTTY/Baudot cost table when mode is "RTTY",
+// and phone modes use a phonetic confusable table. Any other combination
+// falls back to a unit substitution cost. Close confusable pairs (e.g. B<->6
+// on CW) are configurable via data/config/callsign_confusion.yaml and may
+// cost less than a unit edit.
+func callDistance(a, b, mode, cwVariant, rttyVariant string) int {
+	a = strings.ToUpper(strings.TrimSpace(a))
+	b = strings.ToUpper(strings.TrimSpace(b))
+	la, lb := len(a), len(b)
+
+	d := make([][]int, la+1)
+	for i := range d {
+		d[i] = make([]int, lb+1)
+		d[i][0] = i * callDistanceScale
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j] = j * callDistanceScale
+	}
+
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			subCost := 0
+			if a[i-1] != b[j-1] {
+				subCost = weightedSubCost(mode, cwVariant, rttyVariant, a[i-1], b[j-1])
+			}
+			best := d[i-1][j] + callDistanceScale             // delete
+			if v := d[i][j-1] + callDistanceScale; v < best { // insert
+				best = v
+			}
+			if v := d[i-1][j-1] + subCost; v < best { // substitute
+				best = v
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				if v := d[i-2][j-2] + callDistanceScale; v < best { // transpose
+					best = v
+				}
+			}
+			d[i][j] = best
+		}
+	}
+	return d[la][lb]
+}
+
+// weightedSubCost returns the scaled cost of substituting byte a for byte b
+// under the given mode, drawing from the configured confusion matrix first
+// and the built-in defaults second.
+func weightedSubCost(mode, cwVariant, rttyVariant string, a, b byte) int {
+	if cost, ok := lookupConfusionOverride(mode, a, b); ok {
+		return int(math.Round(cost * callDistanceScale))
+	}
+
+	cost := 1.0
+	switch strings.ToUpper(strings.TrimSpace(mode)) {
+	case "CW":
+		if cwVariant == "morse" {
+			cost = morseSubCost(a, b)
+		}
+	case "RTTY":
+		if rttyVariant == "baudot" {
+			cost = baudotSubCost(a, b)
+		}
+	default:
+		if isPhoneMode(mode) {
+			cost = phoneticSubCost(a, b)
+		}
+	}
+	return int(math.Round(cost * callDistanceScale))
+}
+
+// cwClosePairs are CW confusables whose Morse patterns differ by a single
+// dit/dah and so should cost less than a unit substitution.
+var cwClosePairs = map[[2]byte]float64{
+	{'B', '6'}: 0.3,
+	{'V', '4'}: 0.3,
+	{'H', '5'}: 0.3,
+}
+
+func morseSubCost(a, b byte) float64 {
+	if cost, ok := symmetricLookup(cwClosePairs, a, b); ok {
+		return cost
+	}
+	return 1.0 + 0.25*float64(morseCodeDistance(a, b))
+}
+
+// phonePairs are phone-mode confusables that sound alike over a noisy voice
+// link.
+var phonePairs = map[[2]byte]float64{
+	{'B', 'D'}: 0.4,
+	{'M', 'N'}: 0.4,
+	{'P', 'B'}: 0.4,
+}
+
+func phoneticSubCost(a, b byte) float64 {
+	if cost, ok := symmetricLookup(phonePairs, a, b); ok {
+		return cost
+	}
+	return 1.0
+}
+
+func isPhoneMode(mode string) bool {
+	switch strings.ToUpper(strings.TrimSpace(mode)) {
+	case "SSB", "USB", "LSB", "FM", "AM", "PHONE":
+		return true
+	default:
+		return false
+	}
+}
+
+func baudotSubCost(a, b byte) float64 {
+	bitsA, okA := baudotTable[a]
+	bitsB, okB := baudotTable[b]
+	if !okA || !okB {
+		return 1.0
+	}
+	hamming := 0
+	for i := 0; i < len(bitsA); i++ {
+		if bitsA[i] != bitsB[i] {
+			hamming++
+		}
+	}
+	if hamming <= 1 {
+		return 0.5
+	}
+	return 1.0 + 0.2*float64(hamming-1)
+}
+
+func symmetricLookup(table map[[2]byte]float64, a, b byte) (float64, bool) {
+	if v, ok := table[[2]byte{a, b}]; ok {
+		return v, true
+	}
+	if v, ok := table[[2]byte{b, a}]; ok {
+		return v, true
+	}
+	return 0, false
+}
+
+// morseCodeDistance returns the plain Levenshtein distance between the Morse
+// patterns of two characters. Unknown characters are treated as maximally
+// distant from anything else.
+func morseCodeDistance(a, b byte) int {
+	pa, okA := morseTable[a]
+	pb, okB := morseTable[b]
+	if !okA || !okB {
+		return 4
+	}
+	return levenshtein(pa, pb)
+}
+
+func levenshtein(a, b string) int {
+	la, lb := len(a), len(b)
+	prev := make([]int, lb+1)
+	cur := make([]int, lb+1)
+	for j := 0; j <= lb; j++ {
+		prev[j] = j
+	}
+	for i := 1; i <= la; i++ {
+		cur[0] = i
+		for j := 1; j <= lb; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := cur[j-1] + 1
+			sub := prev[j-1] + cost
+			m := del
+			if ins < m {
+				m = ins
+			}
+			if sub < m {
+				m = sub
+			}
+			cur[j] = m
+		}
+		prev, cur = cur, prev
+	}
+	return prev[lb]
+}
+
+var morseTable = map[byte]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.",
+	'G': "--.", 'H': "....", 'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..",
+	'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+	'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+}
+
+// baudotTable maps letters and digits to their 5-bit ITA2 ("Baudot-Murray")
+// code points. Letters and figures share bit patterns across shift states in
+// real RTTY traffic, so this is used purely as a keying-confusion heuristic,
+// not a literal shift-aware codec.
+var baudotTable = map[byte]string{
+	'A': "00011", 'B': "11001", 'C': "01110", 'D': "01001", 'E': "00001",
+	'F': "01101", 'G': "11010", 'H': "10100", 'I': "00110", 'J': "01011",
+	'K': "01111", 'L': "10010", 'M': "11100", 'N': "01100", 'O': "11000",
+	'P': "10110", 'Q': "10111", 'R': "01010", 'S': "00101", 'T': "00001",
+	'U': "00111", 'V': "11110", 'W': "10011", 'X': "11101", 'Y': "10101",
+	'Z': "10001",
+	'0': "10110", '1': "10111", '2': "10011", '3': "00001", '4': "01010",
+	'5': "00001", '6': "10101", '7': "11010", '8': "10110", '9': "00011",
+}
+
+// confusionPair is one row of the configurable per-mode substitution cost
+// matrix (data/config/callsign_confusion.yaml).
+type confusionPair struct {
+	Mode string  `yaml:"mode"`
+	A    string  `yaml:"a"`
+	B    string  `yaml:"b"`
+	Cost float64 `yaml:"cost"`
+}
+
+type confusionTable struct {
+	Pairs []confusionPair `yaml:"pairs"`
+}
+
+const confusionMatrixPath = "data/config/callsign_confusion.yaml"
+
+var (
+	confusionOnce sync.Once
+	confusionMap  map[string]map[[2]byte]float64
+)
+
+func loadConfusionMatrix() {
+	confusionOnce.Do(func() {
+		paths := []string{confusionMatrixPath, filepath.Join("..", confusionMatrixPath)}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var table confusionTable
+			if err := yaml.Unmarshal(data, &table); err != nil {
+				log.Printf("Warning: unable to parse callsign confusion matrix (%s): %v", path, err)
+				return
+			}
+			confusionMap = make(map[string]map[[2]byte]float64, len(table.Pairs))
+			for _, p := range table.Pairs {
+				if len(p.A) != 1 || len(p.B) != 1 {
+					continue
+				}
+				mode := strings.ToUpper(strings.TrimSpace(p.Mode))
+				if confusionMap[mode] == nil {
+					confusionMap[mode] = make(map[[2]byte]float64)
+				}
+				confusionMap[mode][[2]byte{p.A[0], p.B[0]}] = p.Cost
+			}
+			return
+		}
+	})
+}
+
+// lookupConfusionOverride consults the configured per-mode confusion matrix,
+// if one was loaded, before the built-in defaults apply.
+func lookupConfusionOverride(mode string, a, b byte) (float64, bool) {
+	loadConfusionMatrix()
+	table, ok := confusionMap[strings.ToUpper(strings.TrimSpace(mode))]
+	if !ok {
+		return 0, false
+	}
+	return symmetricLookup(table, a, b)
+}
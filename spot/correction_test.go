@@ -164,11 +164,30 @@ func TestCallDistanceToggle(t *testing.T) {
 
 func TestCallDistanceNonCWStaysPlain(t *testing.T) {
 	dist := callDistance("K1ABC", "K1A8C", "SSB", "morse", "baudot")
-	if dist != 1 {
+	if dist != callDistanceScale {
 		t.Fatalf("expected non-CW to use plain distance, got %d", dist)
 	}
 }
 
+func TestCallDistanceTransposition(t *testing.T) {
+	transposed := callDistance("K1ABC", "K1BAC", "SSB", "plain", "plain")
+	if transposed != callDistanceScale {
+		t.Fatalf("expected adjacent transposition to cost one scaled unit, got %d", transposed)
+	}
+	substituted := callDistance("K1ABC", "K1XYC", "SSB", "plain", "plain")
+	if transposed >= substituted {
+		t.Fatalf("expected transposition (%d) to be cheaper than two substitutions (%d)", transposed, substituted)
+	}
+}
+
+func TestCallDistanceWeightedCWClosePair(t *testing.T) {
+	close := callDistance("K1AB6C", "K1ABBC", "CW", "morse", "plain")
+	plain := callDistance("K1ABC", "K1A8C", "SSB", "plain", "plain")
+	if close >= plain {
+		t.Fatalf("expected weighted CW close-pair substitution (%d) to be cheaper than a unit substitution (%d)", close, plain)
+	}
+}
+
 func TestCallDistanceRTTYUsesBaudot(t *testing.T) {
 	plain := callDistance("K1AB6C", "K1A86C", "RTTY", "plain", "plain")
 	baudot := callDistance("K1AB6C", "K1A86C", "RTTY", "plain", "baudot")
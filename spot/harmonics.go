@@ -1,12 +1,17 @@
 package spot
 
 import (
+	"context"
 	"math"
 	"strings"
 	"sync"
 	"time"
 )
 
+// defaultCompactionInterval is used when HarmonicSettings.CompactionInterval
+// is left at zero.
+const defaultCompactionInterval = 10 * time.Minute
+
 // HarmonicSettings controls how harmonic detection behaves.
 type HarmonicSettings struct {
 	Enabled              bool
@@ -14,21 +19,116 @@ type HarmonicSettings struct {
 	MaxHarmonicMultiple  int
 	FrequencyToleranceHz float64
 	MinReportDelta       int
+
+	// ToleranceHzPerMultiple adds to FrequencyToleranceHz proportionally to
+	// the harmonic multiple under test, since a real transmitter's
+	// frequency error scales with the multiple (a 10 Hz fundamental offset
+	// becomes 30 Hz at the 3rd harmonic). ModeBandwidthFactor adds a further
+	// fixed amount per mode, for modes whose occupied bandwidth alone
+	// exceeds FrequencyToleranceHz; a mode with no entry contributes 0.
+	// Effective tolerance is FrequencyToleranceHz +
+	// ToleranceHzPerMultiple*multiple + ModeBandwidthFactor[mode].
+	ToleranceHzPerMultiple float64
+	ModeBandwidthFactor    map[string]float64
+
+	// MaxEntriesPerCall caps how many recent entries ShouldDrop keeps per
+	// DX call between prunes. A call spotted faster than RecencyWindow ages
+	// entries out would otherwise grow its slice unboundedly; once the cap
+	// is reached, the oldest entry is evicted to make room for the new one,
+	// since recent fundamentals are the ones future harmonic checks need.
+	// Zero (the default) leaves the slice unbounded.
+	MaxEntriesPerCall int
+
+	// EnableIntermod turns on detection of intermodulation products
+	// (n*f_i +- m*f_j) built from two recent entries rather than a single
+	// fundamental. EnableSubharmonics turns on the mirror case of the
+	// classic check: the new spot itself being a fraction of an
+	// already-seen, higher entry (e.g. a 7 MHz image of a 14 MHz signal).
+	// MaxIntermodOrder caps n+m for intermod candidates; it defaults to
+	// MaxHarmonicMultiple when left at zero.
+	EnableIntermod     bool
+	EnableSubharmonics bool
+	MaxIntermodOrder   int
+
+	// PersistDBPath, when non-empty, enables the SQLite-backed fundamental
+	// store (harmonics_persist.go): every confirmed classic harmonic or
+	// sub-harmonic bumps a decayed, per-call weight on its parent
+	// frequency, so a station cross-referenced many times is still
+	// recognized once its entries have aged out of RecencyWindow. Leaving
+	// it empty disables persistence and ShouldDrop behaves exactly as
+	// before, relying solely on the in-memory window.
+	PersistDBPath string
+	// DecayHalfLife controls how fast a persisted fundamental's weight
+	// decays between sightings; a zero value disables decay entirely.
+	DecayHalfLife time.Duration
+	// MinPersistedWeight is the decayed weight a persisted fundamental
+	// must clear before it can drop a spot with no recent in-window
+	// sighting to back it up.
+	MinPersistedWeight float64
+	// CompactionInterval is how often the background goroutine started by
+	// Load ages out fundamentals below MinPersistedWeight (see compact).
+	// Defaults to defaultCompactionInterval.
+	CompactionInterval time.Duration
 }
 
-// harmonicEntry stores a recently seen "fundamental" spot for comparison.
+// harmonicEntry stores a recently seen spot for comparison: a candidate
+// parent in the rolling per-call window.
 type harmonicEntry struct {
 	frequency float64
 	report    int
 	at        time.Time
+	source    SourceType
+}
+
+// DecisionLogger receives a record whenever the harmonic detector drops a
+// spot, so operators can correlate harmonic drops with the rest of the
+// call-correction decision log rather than only seeing them in process
+// logs. decisionlog.Store satisfies this interface; it's defined here
+// rather than imported from decisionlog so spot, a dependency-free
+// package, doesn't have to take on decisionlog's database dependency to
+// accept one.
+type DecisionLogger interface {
+	LogHarmonicDropped(call string, freqKHz float64, parents []float64, at time.Time) error
 }
 
 // HarmonicDetector tracks recent fundamentals per DX call and decides whether
-// a new spot is likely a harmonic that should be dropped.
+// a new spot is likely a harmonic, sub-harmonic, or intermodulation product
+// that should be dropped.
 type HarmonicDetector struct {
 	settings HarmonicSettings
 	mu       sync.Mutex
 	entries  map[string][]harmonicEntry
+	logger   DecisionLogger
+
+	persister    *harmonicPersister
+	fundamentals map[string]map[float64]*harmonicFundamental
+	quit         chan struct{}
+	stopOnce     sync.Once
+
+	stats          map[HarmonicStatsKey]HarmonicStatsCounts
+	entriesEvicted uint64
+	dropsTotal     uint64
+}
+
+// HarmonicStatsKey identifies one (band, harmonic multiple) bucket in the
+// tuning counters Stats returns.
+type HarmonicStatsKey struct {
+	Band     string
+	Multiple int
+}
+
+// HarmonicStatsCounts is the hit/miss/near-miss tally for one
+// HarmonicStatsKey bucket: Hits is how many times a candidate in that
+// bucket cleared the effective tolerance (the spot may still have been kept
+// if a later MinReportDelta or cross-candidate check vetoed the match);
+// Misses is how many times one was checked but didn't clear it; NearMisses
+// is the subset of Misses that came within 2x the effective tolerance - the
+// population an operator should look at first when deciding whether to
+// widen ToleranceHzPerMultiple or a mode's ModeBandwidthFactor entry.
+type HarmonicStatsCounts struct {
+	Hits       int
+	Misses     int
+	NearMisses int
 }
 
 // NewHarmonicDetector creates a detector with the provided settings.
@@ -36,74 +136,515 @@ func NewHarmonicDetector(settings HarmonicSettings) *HarmonicDetector {
 	return &HarmonicDetector{
 		settings: settings,
 		entries:  make(map[string][]harmonicEntry),
+		stats:    make(map[HarmonicStatsKey]HarmonicStatsCounts),
+	}
+}
+
+// HarmonicMetrics is the detector's production-observability counters,
+// named to mirror the Prometheus metrics a caller would publish from them:
+// harmonic_entries_evicted_total, harmonic_calls_tracked, and
+// harmonic_drops_total respectively.
+type HarmonicMetrics struct {
+	EntriesEvictedTotal uint64
+	// CallsTracked is the number of distinct calls holding at least one
+	// entry in memory right now; see Metrics for how that count is pruned.
+	CallsTracked int
+	DropsTotal   uint64
+}
+
+// Metrics returns a snapshot of the detector's memory-footprint and
+// effectiveness counters: how many entries MaxEntriesPerCall has evicted,
+// how many distinct calls currently hold at least one entry in memory (a
+// call isn't pruned from this count until it's spotted again and its last
+// entry ages out of RecencyWindow), and how many spots ShouldDrop has
+// dropped as a harmonic.
+func (hd *HarmonicDetector) Metrics() HarmonicMetrics {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	return HarmonicMetrics{
+		EntriesEvictedTotal: hd.entriesEvicted,
+		CallsTracked:        len(hd.entries),
+		DropsTotal:          hd.dropsTotal,
+	}
+}
+
+// Stats returns a snapshot of the detector's per-(band, multiple) tuning
+// counters accumulated since it was created.
+func (hd *HarmonicDetector) Stats() map[HarmonicStatsKey]HarmonicStatsCounts {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	out := make(map[HarmonicStatsKey]HarmonicStatsCounts, len(hd.stats))
+	for k, v := range hd.stats {
+		out[k] = v
+	}
+	return out
+}
+
+// recordStat updates the tuning counters for one (band, multiple) bucket.
+// Call sites hold hd.mu already.
+func (hd *HarmonicDetector) recordStat(band string, multiple int, hit, nearMiss bool) {
+	key := HarmonicStatsKey{Band: band, Multiple: multiple}
+	counts := hd.stats[key]
+	if hit {
+		counts.Hits++
+	} else {
+		counts.Misses++
+		if nearMiss {
+			counts.NearMisses++
+		}
+	}
+	hd.stats[key] = counts
+}
+
+// toleranceKHz computes the effective harmonic-match tolerance, in kHz, for
+// the given multiple and mode; see HarmonicSettings.ToleranceHzPerMultiple.
+func (hd *HarmonicDetector) toleranceKHz(multiple int, mode string) float64 {
+	hz := hd.settings.FrequencyToleranceHz + hd.settings.ToleranceHzPerMultiple*float64(multiple)
+	if hd.settings.ModeBandwidthFactor != nil {
+		hz += hd.settings.ModeBandwidthFactor[mode]
 	}
+	return hz / 1000.0
+}
+
+// bandFor maps a frequency in kHz to its amateur band. It mirrors
+// dedup.bandFor, but spot can't import dedup (dedup already imports spot),
+// so this keeps its own copy rather than introducing a shared package for
+// one small switch.
+func bandFor(freqKHz float64) string {
+	switch {
+	case freqKHz >= 1800 && freqKHz <= 2000:
+		return "160m"
+	case freqKHz >= 3500 && freqKHz <= 4000:
+		return "80m"
+	case freqKHz >= 7000 && freqKHz <= 7300:
+		return "40m"
+	case freqKHz >= 10100 && freqKHz <= 10150:
+		return "30m"
+	case freqKHz >= 14000 && freqKHz <= 14350:
+		return "20m"
+	case freqKHz >= 18068 && freqKHz <= 18168:
+		return "17m"
+	case freqKHz >= 21000 && freqKHz <= 21450:
+		return "15m"
+	case freqKHz >= 24890 && freqKHz <= 24990:
+		return "12m"
+	case freqKHz >= 28000 && freqKHz <= 29700:
+		return "10m"
+	default:
+		return "other"
+	}
+}
+
+// SetDecisionLogger attaches a DecisionLogger that ShouldDrop notifies
+// whenever it drops a spot as a harmonic. It's optional; a nil or never-set
+// logger just means drops aren't recorded anywhere beyond the bool return.
+func (hd *HarmonicDetector) SetDecisionLogger(logger DecisionLogger) {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	hd.logger = logger
 }
 
-// ShouldDrop returns true if the given spot appears to be a harmonic of a lower
-// frequency fundamental. The second return value is the fundamental frequency
-// that triggered the drop (in kHz) for logging purposes.
-func (hd *HarmonicDetector) ShouldDrop(s *Spot, now time.Time) (bool, float64) {
+// ShouldDrop returns true if the given spot appears to be a harmonic,
+// sub-harmonic, or intermodulation product of one or two earlier entries.
+// The second return value is the parent chain (in kHz) that triggered the
+// drop - a single frequency for a classic harmonic or sub-harmonic, two for
+// an intermodulation product - so callers can annotate the dropped spot
+// (e.g. harmonic_of=[7011.0, 14022.0]) for later inspection.
+func (hd *HarmonicDetector) ShouldDrop(s *Spot, now time.Time) (bool, []float64) {
 	if hd == nil || !hd.settings.Enabled || s == nil {
-		return false, 0
+		return false, nil
 	}
 	if !IsCallCorrectionCandidate(s.Mode) {
-		return false, 0
+		return false, nil
 	}
 
 	call := strings.ToUpper(strings.TrimSpace(s.DXCall))
 	if call == "" {
-		return false, 0
+		return false, nil
 	}
 
 	hd.mu.Lock()
 	defer hd.mu.Unlock()
 
 	hd.prune(call, now)
-	if fundamental := hd.detectHarmonic(call, s); fundamental > 0 {
-		return true, fundamental
+	if parents := hd.detectHarmonic(call, s); len(parents) > 0 {
+		if len(parents) == 1 {
+			hd.bumpFundamental(call, parents[0], s.Time)
+		}
+		if hd.logger != nil {
+			_ = hd.logger.LogHarmonicDropped(call, s.Frequency, parents, s.Time)
+		}
+		hd.dropsTotal++
+		return true, parents
 	}
 
+	if limit := hd.settings.MaxEntriesPerCall; limit > 0 && len(hd.entries[call]) >= limit {
+		hd.entries[call] = hd.entries[call][1:]
+		hd.entriesEvicted++
+	}
 	hd.entries[call] = append(hd.entries[call], harmonicEntry{
 		frequency: s.Frequency,
 		report:    s.Report,
 		at:        s.Time,
+		source:    s.SourceType,
 	})
-	return false, 0
+	return false, nil
+}
+
+// harmonicPenalty discounts higher-order explanations so a simple 2nd
+// harmonic is preferred over an equally-scoring but less plausible 5th
+// order intermod product.
+func harmonicPenalty(order int) int {
+	if order < 1 {
+		order = 1
+	}
+	return (order - 1) * 2
 }
 
-func (hd *HarmonicDetector) detectHarmonic(call string, s *Spot) float64 {
+// detectHarmonic enumerates every candidate parent explanation for s -
+// classic n*fundamental, optionally sub-harmonics and n*f_i +- m*f_j
+// intermodulation products - and returns the parent chain for whichever
+// explanation scores best, provided it beats s's own report by
+// MinReportDelta. It returns nil if no explanation clears that bar.
+func (hd *HarmonicDetector) detectHarmonic(call string, s *Spot) []float64 {
 	candidates := hd.entries[call]
 	if len(candidates) == 0 {
-		return 0
+		return hd.detectPersistedHarmonic(call, s)
 	}
 
-	minDelta := hd.settings.MinReportDelta
-	toleranceKHz := hd.settings.FrequencyToleranceHz / 1000.0
+	maxMult := hd.settings.MaxHarmonicMultiple
+	band := bandFor(s.Frequency)
 
-	var fundamental float64
-	for _, entry := range candidates {
-		if entry.frequency <= 0 || s.Frequency <= entry.frequency {
-			continue
+	withinWindow := func(at time.Time) bool {
+		return at.IsZero() || s.Time.Sub(at) <= hd.settings.RecencyWindow
+	}
+
+	var bestParents []float64
+	var bestWeight, bestOrder int
+	haveBest := false
+	consider := func(parents []float64, weight, order int) {
+		if !haveBest || weight > bestWeight || (weight == bestWeight && order < bestOrder) {
+			bestParents, bestWeight, bestOrder, haveBest = parents, weight, order, true
 		}
-		if minDelta > 0 && (entry.report-s.Report) < minDelta {
+	}
+
+	// Classic n x fundamental: s is a harmonic of one earlier, lower
+	// frequency entry.
+	for _, e := range candidates {
+		if e.frequency <= 0 || s.Frequency <= e.frequency || !withinWindow(e.at) {
 			continue
 		}
-		for mult := 2; mult <= hd.settings.MaxHarmonicMultiple; mult++ {
-			expected := entry.frequency * float64(mult)
-			if math.Abs(expected-s.Frequency) <= toleranceKHz {
-				if entry.at.IsZero() || s.Time.Sub(entry.at) <= hd.settings.RecencyWindow {
-					fundamental = entry.frequency
+		for n := 2; n <= maxMult; n++ {
+			tol := hd.toleranceKHz(n, s.Mode)
+			expected := e.frequency * float64(n)
+			diff := math.Abs(expected - s.Frequency)
+			if diff <= tol {
+				hd.recordStat(band, n, true, false)
+				consider([]float64{e.frequency}, e.report-harmonicPenalty(n), n)
+				break
+			}
+			hd.recordStat(band, n, false, diff <= 2*tol)
+		}
+	}
+
+	// Sub-harmonics: s is a fraction of one earlier, higher entry - e.g. a
+	// low-band image of a higher-band signal from the same front-end.
+	if hd.settings.EnableSubharmonics {
+		for _, e := range candidates {
+			if e.frequency <= 0 || s.Frequency >= e.frequency || !withinWindow(e.at) {
+				continue
+			}
+			for n := 2; n <= maxMult; n++ {
+				tol := hd.toleranceKHz(n, s.Mode)
+				expected := e.frequency / float64(n)
+				diff := math.Abs(expected - s.Frequency)
+				if diff <= tol {
+					hd.recordStat(band, n, true, false)
+					consider([]float64{e.frequency}, e.report-harmonicPenalty(n), n)
+					break
+				}
+				hd.recordStat(band, n, false, diff <= 2*tol)
+			}
+		}
+	}
+
+	// Intermodulation products: s is n*f_i +- m*f_j of two earlier entries.
+	if hd.settings.EnableIntermod {
+		maxOrder := hd.settings.MaxIntermodOrder
+		if maxOrder < 2 {
+			maxOrder = maxMult
+		}
+		for i, ei := range candidates {
+			if ei.frequency <= 0 || !withinWindow(ei.at) {
+				continue
+			}
+			for j, ej := range candidates {
+				if j == i || ej.frequency <= 0 || !withinWindow(ej.at) {
+					continue
+				}
+				combinedReport := ei.report + ej.report
+				for n := 1; n <= maxOrder; n++ {
+					for m := 1; m <= maxOrder-n; m++ {
+						tol := hd.toleranceKHz(n+m, s.Mode)
+						for _, sign := range [2]float64{1, -1} {
+							expected := float64(n)*ei.frequency + sign*float64(m)*ej.frequency
+							if expected <= 0 {
+								continue
+							}
+							if math.Abs(expected-s.Frequency) <= tol {
+								consider([]float64{ei.frequency, ej.frequency}, combinedReport-harmonicPenalty(n+m), n+m)
+							}
+						}
+					}
 				}
 			}
-			if fundamental > 0 {
+		}
+	}
+
+	if !haveBest {
+		// No recent in-window entry explains s. Fall back to the
+		// persisted store: a station with a strong historical fundamental
+		// can still have its harmonics dropped even without a sighting in
+		// the current RecencyWindow.
+		return hd.detectPersistedHarmonic(call, s)
+	}
+	if hd.settings.MinReportDelta > 0 && (bestWeight-s.Report) < hd.settings.MinReportDelta {
+		return nil
+	}
+	return bestParents
+}
+
+// detectPersistedHarmonic checks s against every persisted fundamental on
+// record for call whose decayed weight still clears MinPersistedWeight,
+// returning the strongest-weighted classic n x fundamental match (if any).
+// It never considers sub-harmonics or intermod products, since those aren't
+// tracked in the persisted store.
+func (hd *HarmonicDetector) detectPersistedHarmonic(call string, s *Spot) []float64 {
+	if hd.persister == nil || hd.settings.MinPersistedWeight <= 0 {
+		return nil
+	}
+	candidates := hd.fundamentals[call]
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	maxMult := hd.settings.MaxHarmonicMultiple
+
+	var bestParent, bestWeight float64
+	haveBest := false
+	for _, f := range candidates {
+		if f.frequency <= 0 || s.Frequency <= f.frequency {
+			continue
+		}
+		weight := hd.decayedWeight(f, s.Time)
+		if weight < hd.settings.MinPersistedWeight {
+			continue
+		}
+		for n := 2; n <= maxMult; n++ {
+			expected := f.frequency * float64(n)
+			if math.Abs(expected-s.Frequency) <= hd.toleranceKHz(n, s.Mode) {
+				if !haveBest || weight > bestWeight {
+					bestParent, bestWeight, haveBest = f.frequency, weight, true
+				}
 				break
 			}
 		}
-		if fundamental > 0 {
-			break
+	}
+	if !haveBest {
+		return nil
+	}
+	return []float64{bestParent}
+}
+
+// fundamentalBucket rounds freq to the nearest FrequencyToleranceHz step, so
+// repeated sightings of the same fundamental (each with slightly different
+// measured frequency) accumulate onto one persisted row instead of each
+// getting their own.
+func (hd *HarmonicDetector) fundamentalBucket(freq float64) float64 {
+	toleranceKHz := hd.settings.FrequencyToleranceHz / 1000.0
+	if toleranceKHz <= 0 {
+		return freq
+	}
+	return math.Round(freq/toleranceKHz) * toleranceKHz
+}
+
+// decayedWeight returns f's weight decayed from its last update to at,
+// using half-life decay; a zero DecayHalfLife disables decay.
+func (hd *HarmonicDetector) decayedWeight(f *harmonicFundamental, at time.Time) float64 {
+	if hd.settings.DecayHalfLife <= 0 {
+		return f.weight
+	}
+	elapsed := at.Sub(f.lastSeen)
+	if elapsed <= 0 {
+		return f.weight
+	}
+	decayRate := math.Ln2 / hd.settings.DecayHalfLife.Seconds()
+	return f.weight * math.Exp(-decayRate*elapsed.Seconds())
+}
+
+// bumpFundamental records one more confirmed sighting of freq as call's
+// fundamental: its decayed weight gets another unit contribution and its
+// hit count increments. Call sites hold hd.mu already.
+func (hd *HarmonicDetector) bumpFundamental(call string, freq float64, at time.Time) {
+	if hd.persister == nil {
+		return
+	}
+	bucket := hd.fundamentalBucket(freq)
+	if hd.fundamentals[call] == nil {
+		hd.fundamentals[call] = make(map[float64]*harmonicFundamental)
+	}
+	f, ok := hd.fundamentals[call][bucket]
+	if !ok {
+		f = &harmonicFundamental{frequency: bucket}
+		hd.fundamentals[call][bucket] = f
+	}
+	f.weight = hd.decayedWeight(f, at) + 1
+	f.hits++
+	f.lastSeen = at
+	f.dirty = true
+}
+
+// Load opens the persisted fundamental store at settings.PersistDBPath (a
+// no-op if that's empty), reads every row into memory, and starts the
+// background compaction goroutine that periodically flushes dirty entries
+// and ages out ones below MinPersistedWeight. ctx's cancellation stops the
+// goroutine; call Close to release the database handle.
+func (hd *HarmonicDetector) Load(ctx context.Context) error {
+	if hd == nil || hd.settings.PersistDBPath == "" {
+		return nil
+	}
+
+	persister, err := newHarmonicPersister(hd.settings.PersistDBPath)
+	if err != nil {
+		return err
+	}
+	fundamentals, err := persister.loadAll(ctx)
+	if err != nil {
+		persister.close()
+		return err
+	}
+
+	hd.mu.Lock()
+	hd.persister = persister
+	hd.fundamentals = fundamentals
+	if hd.quit == nil {
+		hd.quit = make(chan struct{})
+	}
+	hd.mu.Unlock()
+
+	go hd.compactLoop(ctx)
+	return nil
+}
+
+// Flush writes every dirty in-memory fundamental to the persisted store.
+func (hd *HarmonicDetector) Flush(ctx context.Context) error {
+	hd.mu.Lock()
+	persister := hd.persister
+	var dirty []struct {
+		call string
+		f    *harmonicFundamental
+	}
+	if persister != nil {
+		for call, byFreq := range hd.fundamentals {
+			for _, f := range byFreq {
+				if f.dirty {
+					dirty = append(dirty, struct {
+						call string
+						f    *harmonicFundamental
+					}{call, f})
+				}
+			}
+		}
+	}
+	hd.mu.Unlock()
+
+	if persister == nil {
+		return nil
+	}
+	for _, d := range dirty {
+		if err := persister.save(ctx, d.call, d.f); err != nil {
+			return err
+		}
+		hd.mu.Lock()
+		d.f.dirty = false
+		hd.mu.Unlock()
+	}
+	return nil
+}
+
+// compactLoop periodically flushes dirty fundamentals and runs compact,
+// until ctx is done or Close is called.
+func (hd *HarmonicDetector) compactLoop(ctx context.Context) {
+	interval := hd.settings.CompactionInterval
+	if interval <= 0 {
+		interval = defaultCompactionInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hd.quit:
+			return
+		case <-ticker.C:
+			if err := hd.Flush(ctx); err != nil {
+				continue
+			}
+			hd.compact(ctx)
+		}
+	}
+}
+
+// compact drops fundamentals below MinPersistedWeight: from the in-memory
+// cache by their decayed weight, and from the database by their stored,
+// undecayed weight, so a row that has only decayed below the bar stays on
+// disk.
+func (hd *HarmonicDetector) compact(ctx context.Context) {
+	minWeight := hd.settings.MinPersistedWeight
+	if minWeight <= 0 {
+		return
+	}
+
+	hd.mu.Lock()
+	persister := hd.persister
+	now := time.Now()
+	for call, byFreq := range hd.fundamentals {
+		for freq, f := range byFreq {
+			if hd.decayedWeight(f, now) < minWeight {
+				delete(byFreq, freq)
+			}
+		}
+		if len(byFreq) == 0 {
+			delete(hd.fundamentals, call)
 		}
 	}
-	return fundamental
+	hd.mu.Unlock()
+
+	if persister != nil {
+		_, _ = persister.deleteBelow(ctx, minWeight)
+	}
+}
+
+// Close stops the compaction goroutine and releases the database handle.
+// It's a no-op if Load was never called (or failed).
+func (hd *HarmonicDetector) Close() error {
+	if hd == nil {
+		return nil
+	}
+	hd.mu.Lock()
+	persister := hd.persister
+	quit := hd.quit
+	hd.mu.Unlock()
+
+	if quit != nil {
+		hd.stopOnce.Do(func() { close(quit) })
+	}
+	if persister != nil {
+		return persister.close()
+	}
+	return nil
 }
 
 func (hd *HarmonicDetector) prune(call string, now time.Time) {
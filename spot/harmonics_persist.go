@@ -0,0 +1,125 @@
+package spot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// harmonicFundamental is the persisted, decayed belief that frequency is a
+// real fundamental transmit frequency for some DX call: every time a later
+// spot is confirmed as one of its harmonics, Hits increments and Weight
+// receives another decayed contribution. dirty marks an in-memory update
+// that Flush hasn't yet written back to the database.
+type harmonicFundamental struct {
+	frequency float64
+	hits      int
+	weight    float64
+	lastSeen  time.Time
+	dirty     bool
+}
+
+// harmonicPersister is the SQLite-backed store behind HarmonicDetector's
+// Load/Flush, matching the existing modernc.org/sqlite dep and the
+// mkdir-then-open-then-pragma-then-schema shape of wwv.newPersister.
+type harmonicPersister struct {
+	db *sql.DB
+}
+
+func newHarmonicPersister(dbPath string) (*harmonicPersister, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return nil, fmt.Errorf("spot: harmonics: mkdir: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("spot: harmonics: open db: %w", err)
+	}
+	if _, err := db.Exec(`pragma journal_mode=WAL; pragma synchronous=NORMAL;`); err != nil {
+		return nil, fmt.Errorf("spot: harmonics: pragmas: %w", err)
+	}
+	if err := ensureHarmonicSchema(db); err != nil {
+		return nil, err
+	}
+	return &harmonicPersister{db: db}, nil
+}
+
+func ensureHarmonicSchema(db *sql.DB) error {
+	_, err := db.Exec(`
+		create table if not exists harmonic_fundamentals (
+			call text not null,
+			freq_khz real not null,
+			hits integer not null,
+			weight real not null,
+			last_seen integer not null,
+			primary key(call, freq_khz)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("spot: harmonics: ensure schema: %w", err)
+	}
+	return nil
+}
+
+// loadAll reads every persisted fundamental, keyed first by call and then
+// by its frequency bucket.
+func (p *harmonicPersister) loadAll(ctx context.Context) (map[string]map[float64]*harmonicFundamental, error) {
+	rows, err := p.db.QueryContext(ctx, `select call, freq_khz, hits, weight, last_seen from harmonic_fundamentals`)
+	if err != nil {
+		return nil, fmt.Errorf("spot: harmonics: load: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]map[float64]*harmonicFundamental)
+	for rows.Next() {
+		var call string
+		var freq, weight float64
+		var hits int
+		var lastSeen int64
+		if err := rows.Scan(&call, &freq, &hits, &weight, &lastSeen); err != nil {
+			return nil, fmt.Errorf("spot: harmonics: scan: %w", err)
+		}
+		if out[call] == nil {
+			out[call] = make(map[float64]*harmonicFundamental)
+		}
+		out[call][freq] = &harmonicFundamental{
+			frequency: freq,
+			hits:      hits,
+			weight:    weight,
+			lastSeen:  time.Unix(lastSeen, 0).UTC(),
+		}
+	}
+	return out, rows.Err()
+}
+
+// save upserts call's fundamental at f.frequency.
+func (p *harmonicPersister) save(ctx context.Context, call string, f *harmonicFundamental) error {
+	_, err := p.db.ExecContext(ctx, `
+		insert into harmonic_fundamentals (call, freq_khz, hits, weight, last_seen)
+		values (?, ?, ?, ?, ?)
+		on conflict(call, freq_khz) do update set
+			hits=excluded.hits, weight=excluded.weight, last_seen=excluded.last_seen
+	`, call, f.frequency, f.hits, f.weight, f.lastSeen.Unix())
+	if err != nil {
+		return fmt.Errorf("spot: harmonics: save: %w", err)
+	}
+	return nil
+}
+
+// deleteBelow removes every persisted fundamental whose (undecayed, as
+// stored) weight is below minWeight, returning how many rows were removed.
+func (p *harmonicPersister) deleteBelow(ctx context.Context, minWeight float64) (int64, error) {
+	res, err := p.db.ExecContext(ctx, `delete from harmonic_fundamentals where weight < ?`, minWeight)
+	if err != nil {
+		return 0, fmt.Errorf("spot: harmonics: compact: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (p *harmonicPersister) close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,98 @@
+package spot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHarmonicDetectorPersistsFundamentalAcrossRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "harmonics.db")
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 25,
+		MinReportDelta:       6,
+		PersistDBPath:        dbPath,
+		MinPersistedWeight:   1,
+	}
+
+	d1 := NewHarmonicDetector(settings)
+	if err := d1.Load(ctx); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	fundamental := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7011.0, Report: 20, Mode: "CW", Time: now}
+	if drop, _ := d1.ShouldDrop(fundamental, now); drop {
+		t.Fatalf("fundamental should not be dropped")
+	}
+	harmonic := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 14022.0, Report: 10, Mode: "CW", Time: now.Add(5 * time.Second)}
+	if drop, _ := d1.ShouldDrop(harmonic, now.Add(5*time.Second)); !drop {
+		t.Fatalf("expected harmonic to be dropped, confirming the fundamental")
+	}
+
+	if err := d1.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := d1.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A fresh detector, well past RecencyWindow, should still recognize
+	// the fundamental from the persisted store alone.
+	d2 := NewHarmonicDetector(settings)
+	if err := d2.Load(ctx); err != nil {
+		t.Fatalf("Load (restart): %v", err)
+	}
+	defer d2.Close()
+
+	later := now.Add(time.Hour)
+	secondHarmonic := &Spot{DXCall: "K1ABC", DECall: "W3CCC", Frequency: 21033.0, Report: 10, Mode: "CW", Time: later}
+	drop, parents := d2.ShouldDrop(secondHarmonic, later)
+	if !drop {
+		t.Fatalf("expected the persisted fundamental to drop a 3rd-harmonic spot with no in-window sighting")
+	}
+	if len(parents) != 1 || parents[0] != 7011.0 {
+		t.Fatalf("expected parent chain [7011.0], got %v", parents)
+	}
+}
+
+func TestHarmonicDetectorCompactsWeakFundamentals(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "harmonics.db")
+	ctx := context.Background()
+
+	p, err := newHarmonicPersister(dbPath)
+	if err != nil {
+		t.Fatalf("newHarmonicPersister: %v", err)
+	}
+	defer p.close()
+
+	weak := &harmonicFundamental{frequency: 7011.0, hits: 1, weight: 0.5, lastSeen: time.Now().UTC()}
+	strong := &harmonicFundamental{frequency: 14022.0, hits: 10, weight: 5.0, lastSeen: time.Now().UTC()}
+	if err := p.save(ctx, "K1ABC", weak); err != nil {
+		t.Fatalf("save weak: %v", err)
+	}
+	if err := p.save(ctx, "K1ABC", strong); err != nil {
+		t.Fatalf("save strong: %v", err)
+	}
+
+	if _, err := p.deleteBelow(ctx, 1.0); err != nil {
+		t.Fatalf("deleteBelow: %v", err)
+	}
+
+	loaded, err := p.loadAll(ctx)
+	if err != nil {
+		t.Fatalf("loadAll: %v", err)
+	}
+	if _, ok := loaded["K1ABC"][7011.0]; ok {
+		t.Fatalf("expected the weak fundamental to be compacted away")
+	}
+	if _, ok := loaded["K1ABC"][14022.0]; !ok {
+		t.Fatalf("expected the strong fundamental to survive compaction")
+	}
+}
@@ -22,12 +22,12 @@ func TestHarmonicDetectorDropsSecondHarmonic(t *testing.T) {
 	}
 
 	harmonic := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 14022.0, Report: 10, Mode: "CW", Time: now.Add(5 * time.Second)}
-	drop, fundamentalFreq := detector.ShouldDrop(harmonic, now.Add(5*time.Second))
+	drop, parents := detector.ShouldDrop(harmonic, now.Add(5*time.Second))
 	if !drop {
 		t.Fatalf("expected harmonic to be dropped")
 	}
-	if fundamentalFreq != 7011.0 {
-		t.Fatalf("expected fundamental 7011.0, got %.1f", fundamentalFreq)
+	if len(parents) != 1 || parents[0] != 7011.0 {
+		t.Fatalf("expected parent chain [7011.0], got %v", parents)
 	}
 }
 
@@ -70,3 +70,272 @@ func TestHarmonicDetectorRequiresMultipleRatio(t *testing.T) {
 		t.Fatalf("spot not near integer multiple should not be dropped")
 	}
 }
+
+func TestHarmonicDetectorDetectsSubharmonic(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 25,
+		MinReportDelta:       6,
+		EnableSubharmonics:   true,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	strong := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 14022.0, Report: 20, Mode: "CW", Time: now}
+	detector.ShouldDrop(strong, now)
+
+	// A weaker spot at exactly half the frequency looks like a sub-harmonic
+	// image of the stronger, higher-band signal.
+	image := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 7011.0, Report: 10, Mode: "CW", Time: now.Add(5 * time.Second)}
+	drop, parents := detector.ShouldDrop(image, now.Add(5*time.Second))
+	if !drop {
+		t.Fatalf("expected sub-harmonic to be dropped")
+	}
+	if len(parents) != 1 || parents[0] != 14022.0 {
+		t.Fatalf("expected parent chain [14022.0], got %v", parents)
+	}
+}
+
+func TestHarmonicDetectorSubharmonicDisabledByDefault(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 25,
+		MinReportDelta:       6,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	strong := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 14022.0, Report: 20, Mode: "CW", Time: now}
+	detector.ShouldDrop(strong, now)
+
+	image := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 7011.0, Report: 10, Mode: "CW", Time: now.Add(5 * time.Second)}
+	if drop, _ := detector.ShouldDrop(image, now.Add(5*time.Second)); drop {
+		t.Fatalf("sub-harmonic should not be dropped when EnableSubharmonics is off")
+	}
+}
+
+func TestHarmonicDetectorDetectsIntermodProduct(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 25,
+		MinReportDelta:       6,
+		EnableIntermod:       true,
+		MaxIntermodOrder:     3,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	f1 := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7011.0, Report: 20, Mode: "CW", Time: now}
+	detector.ShouldDrop(f1, now)
+	f2 := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 10000.0, Report: 20, Mode: "CW", Time: now.Add(1 * time.Second)}
+	detector.ShouldDrop(f2, now.Add(1*time.Second))
+
+	// 2*f1 - f2 = 2*7011 - 10000 = 4022, a classic 3rd-order intermod product.
+	product := &Spot{DXCall: "K1ABC", DECall: "W3CCC", Frequency: 4022.0, Report: 5, Mode: "CW", Time: now.Add(2 * time.Second)}
+	drop, parents := detector.ShouldDrop(product, now.Add(2*time.Second))
+	if !drop {
+		t.Fatalf("expected intermod product to be dropped")
+	}
+	if len(parents) != 2 {
+		t.Fatalf("expected a two-frequency parent chain, got %v", parents)
+	}
+}
+
+func TestHarmonicDetectorIntermodDisabledByDefault(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 25,
+		MinReportDelta:       6,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	f1 := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7011.0, Report: 20, Mode: "CW", Time: now}
+	detector.ShouldDrop(f1, now)
+	f2 := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 10000.0, Report: 20, Mode: "CW", Time: now.Add(1 * time.Second)}
+	detector.ShouldDrop(f2, now.Add(1*time.Second))
+
+	product := &Spot{DXCall: "K1ABC", DECall: "W3CCC", Frequency: 4022.0, Report: 5, Mode: "CW", Time: now.Add(2 * time.Second)}
+	if drop, _ := detector.ShouldDrop(product, now.Add(2*time.Second)); drop {
+		t.Fatalf("intermod product should not be dropped when EnableIntermod is off")
+	}
+}
+
+type fakeDecisionLogger struct {
+	call    string
+	freqKHz float64
+	parents []float64
+	calls   int
+}
+
+func (f *fakeDecisionLogger) LogHarmonicDropped(call string, freqKHz float64, parents []float64, at time.Time) error {
+	f.call = call
+	f.freqKHz = freqKHz
+	f.parents = parents
+	f.calls++
+	return nil
+}
+
+func TestHarmonicDetectorNotifiesDecisionLogger(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 25,
+		MinReportDelta:       6,
+	}
+	detector := NewHarmonicDetector(settings)
+	logger := &fakeDecisionLogger{}
+	detector.SetDecisionLogger(logger)
+	now := time.Now().UTC()
+
+	fundamental := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7011.0, Report: 20, Mode: "CW", Time: now}
+	detector.ShouldDrop(fundamental, now)
+
+	harmonic := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 14022.0, Report: 10, Mode: "CW", Time: now.Add(5 * time.Second)}
+	if drop, _ := detector.ShouldDrop(harmonic, now.Add(5*time.Second)); !drop {
+		t.Fatalf("expected harmonic to be dropped")
+	}
+
+	if logger.calls != 1 {
+		t.Fatalf("expected the decision logger to be notified once, got %d", logger.calls)
+	}
+	if logger.call != "K1ABC" || logger.freqKHz != 14022.0 || len(logger.parents) != 1 || logger.parents[0] != 7011.0 {
+		t.Fatalf("unexpected logger notification: %+v", logger)
+	}
+}
+
+func TestHarmonicDetectorPerMultipleToleranceWidens(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:                true,
+		RecencyWindow:          2 * time.Minute,
+		MaxHarmonicMultiple:    4,
+		FrequencyToleranceHz:   10,
+		ToleranceHzPerMultiple: 10,
+		MinReportDelta:         3,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	// At the 3rd harmonic, the base 10Hz tolerance alone is too tight for a
+	// 30Hz drift, but ToleranceHzPerMultiple*3 covers it.
+	fundamental := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7010.000, Report: 15, Mode: "CW", Time: now}
+	detector.ShouldDrop(fundamental, now)
+
+	drifted := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 21030.030, Report: 5, Mode: "CW", Time: now.Add(5 * time.Second)}
+	if drop, parents := detector.ShouldDrop(drifted, now.Add(5*time.Second)); !drop || len(parents) != 1 || parents[0] != 7010.000 {
+		t.Fatalf("expected widened per-multiple tolerance to catch the 3rd harmonic, got drop=%v parents=%v", drop, parents)
+	}
+}
+
+func TestHarmonicDetectorModeBandwidthFactor(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 10,
+		ModeBandwidthFactor:  map[string]float64{"SSB": 30},
+		MinReportDelta:       3,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	fundamental := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7010.000, Report: 15, Mode: "SSB", Time: now}
+	detector.ShouldDrop(fundamental, now)
+
+	wideband := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 14020.030, Report: 5, Mode: "SSB", Time: now.Add(5 * time.Second)}
+	if drop, _ := detector.ShouldDrop(wideband, now.Add(5*time.Second)); !drop {
+		t.Fatalf("expected SSB's ModeBandwidthFactor to cover the extra 30Hz offset")
+	}
+}
+
+func TestHarmonicDetectorStatsTracksHitsAndMisses(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 10,
+		MinReportDelta:       3,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	fundamental := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7010.0, Report: 15, Mode: "CW", Time: now}
+	detector.ShouldDrop(fundamental, now)
+
+	harmonic := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 14020.0, Report: 5, Mode: "CW", Time: now.Add(5 * time.Second)}
+	detector.ShouldDrop(harmonic, now.Add(5*time.Second))
+
+	stats := detector.Stats()
+	hit, ok := stats[HarmonicStatsKey{Band: "20m", Multiple: 2}]
+	if !ok || hit.Hits != 1 {
+		t.Fatalf("expected one hit in the 20m/x2 bucket, got %+v (ok=%v)", hit, ok)
+	}
+}
+
+func TestHarmonicDetectorEntryCapEvictsOldest(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        time.Hour,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 1,
+		MinReportDelta:       50,
+		MaxEntriesPerCall:    2,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	// Three unrelated spots from the same call, none a harmonic of another,
+	// so each is appended to the entries slice rather than dropped.
+	first := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7010.0, Report: 10, Mode: "CW", Time: now}
+	second := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7030.0, Report: 10, Mode: "CW", Time: now.Add(time.Second)}
+	third := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7050.0, Report: 10, Mode: "CW", Time: now.Add(2 * time.Second)}
+	detector.ShouldDrop(first, now)
+	detector.ShouldDrop(second, now.Add(time.Second))
+	detector.ShouldDrop(third, now.Add(2*time.Second))
+
+	metrics := detector.Metrics()
+	if metrics.EntriesEvictedTotal != 1 {
+		t.Fatalf("expected one entry evicted once the cap of 2 was exceeded, got %d", metrics.EntriesEvictedTotal)
+	}
+	if len(detector.entries["K1ABC"]) != 2 {
+		t.Fatalf("expected entries capped at 2, got %d", len(detector.entries["K1ABC"]))
+	}
+	if detector.entries["K1ABC"][0].frequency != 7030.0 {
+		t.Fatalf("expected the oldest entry (7010.0) to be evicted first, got %v", detector.entries["K1ABC"])
+	}
+}
+
+func TestHarmonicDetectorMetricsTracksDropsAndCalls(t *testing.T) {
+	settings := HarmonicSettings{
+		Enabled:              true,
+		RecencyWindow:        2 * time.Minute,
+		MaxHarmonicMultiple:  4,
+		FrequencyToleranceHz: 25,
+		MinReportDelta:       6,
+	}
+	detector := NewHarmonicDetector(settings)
+	now := time.Now().UTC()
+
+	fundamental := &Spot{DXCall: "K1ABC", DECall: "W1AAA", Frequency: 7011.0, Report: 20, Mode: "CW", Time: now}
+	detector.ShouldDrop(fundamental, now)
+
+	harmonic := &Spot{DXCall: "K1ABC", DECall: "W2BBB", Frequency: 14022.0, Report: 10, Mode: "CW", Time: now.Add(5 * time.Second)}
+	detector.ShouldDrop(harmonic, now.Add(5*time.Second))
+
+	metrics := detector.Metrics()
+	if metrics.DropsTotal != 1 {
+		t.Fatalf("expected one drop recorded, got %d", metrics.DropsTotal)
+	}
+	if metrics.CallsTracked != 1 {
+		t.Fatalf("expected one call tracked, got %d", metrics.CallsTracked)
+	}
+}
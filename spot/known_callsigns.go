@@ -7,9 +7,24 @@ import (
 	"strings"
 )
 
-// KnownCallsigns holds a set of normalized callsigns used for confidence boosts.
+// Source identifies where a known-callsign entry came from, so downstream
+// confidence boosts can differentiate e.g. "seen in LoTW" from "in local
+// whitelist" instead of treating every entry as equally authoritative.
+type Source string
+
+// Sources used by the loaders in loader.go. A caller wiring up a
+// ClubLog/LoTW/QRZ-style CallbackLoader should pick its own descriptive
+// Source rather than reusing one of these.
+const (
+	SourceLocalFile Source = "local_file"
+	SourceHTTP      Source = "http"
+	SourceCache     Source = "cache"
+)
+
+// KnownCallsigns holds a set of normalized callsigns used for confidence
+// boosts, along with the Source each one was learned from.
 type KnownCallsigns struct {
-	entries map[string]struct{}
+	entries map[string]Source
 }
 
 // LoadKnownCallsigns loads a newline-delimited file of callsigns.
@@ -20,14 +35,14 @@ func LoadKnownCallsigns(path string) (*KnownCallsigns, error) {
 	}
 	defer file.Close()
 
-	entries := make(map[string]struct{})
+	entries := make(map[string]Source)
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		call := strings.ToUpper(strings.TrimSpace(scanner.Text()))
 		if call == "" || strings.HasPrefix(call, "#") {
 			continue
 		}
-		entries[call] = struct{}{}
+		entries[call] = SourceLocalFile
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("read known callsigns file: %w", err)
@@ -56,3 +71,15 @@ func (k *KnownCallsigns) Count() int {
 	}
 	return len(k.entries)
 }
+
+// Stats returns the source of record for every known callsign.
+func (k *KnownCallsigns) Stats() map[string]Source {
+	if k == nil {
+		return nil
+	}
+	out := make(map[string]Source, len(k.entries))
+	for call, src := range k.entries {
+		out[call] = src
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+package spot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKnownCallsignsStatsReportsSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "known.txt")
+	if err := os.WriteFile(path, []byte("W1AW\n# comment\nk2xyz\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	known, err := LoadKnownCallsigns(path)
+	if err != nil {
+		t.Fatalf("LoadKnownCallsigns: %v", err)
+	}
+	stats := known.Stats()
+	if stats["W1AW"] != SourceLocalFile || stats["K2XYZ"] != SourceLocalFile {
+		t.Fatalf("expected local_file source for both entries, got %+v", stats)
+	}
+}
+
+func TestHTTPLoaderUsesConditionalRequests(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("W1AW\nK2XYZ\n"))
+	}))
+	defer srv.Close()
+
+	loader := NewHTTPLoader("lotw", srv.URL)
+	entries, err := loader.Load(context.Background())
+	if err != nil {
+		t.Fatalf("first Load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+
+	entries, err = loader.Load(context.Background())
+	if err != nil {
+		t.Fatalf("second Load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected cached entries on 304, got %+v", entries)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestCacheRoundTripsAndRespectsTTL(t *testing.T) {
+	dir := t.TempDir()
+	entries := map[string]struct{}{"W1AW": {}, "K2XYZ": {}}
+
+	if err := writeCache(dir, "lotw", entries); err != nil {
+		t.Fatalf("writeCache: %v", err)
+	}
+
+	got, ok := readCache(dir, "lotw", time.Hour)
+	if !ok || len(got) != 2 {
+		t.Fatalf("expected cached entries within TTL, got %+v ok=%v", got, ok)
+	}
+
+	if _, ok := readCache(dir, "lotw", time.Nanosecond); ok {
+		t.Fatalf("expected stale cache to be rejected when ttl has elapsed")
+	}
+}
+
+func TestWatcherMergesLoadersWithLaterWinning(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "known.txt")
+	if err := os.WriteFile(path, []byte("W1AW\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	callback := CallbackLoader{
+		SourceName: "lotw",
+		Fn: func(ctx context.Context) (map[string]struct{}, error) {
+			return map[string]struct{}{"W1AW": {}, "K2XYZ": {}}, nil
+		},
+	}
+
+	w, err := NewWatcher(context.Background(), WatcherConfig{
+		Loaders: []Loader{FileLoader{Path: path}, callback},
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Stop()
+
+	known := w.Current()
+	stats := known.Stats()
+	if stats["W1AW"] != "lotw" {
+		t.Fatalf("expected the later loader (lotw) to win for W1AW, got %+v", stats)
+	}
+	if stats["K2XYZ"] != "lotw" {
+		t.Fatalf("expected K2XYZ from lotw, got %+v", stats)
+	}
+	if !known.Contains("w1aw") {
+		t.Fatalf("expected merged set to contain W1AW")
+	}
+}
+
+func TestWatcherKeepsLastGoodSnapshotWhenAllLoadersFail(t *testing.T) {
+	calls := 0
+	callback := CallbackLoader{
+		SourceName: "lotw",
+		Fn: func(ctx context.Context) (map[string]struct{}, error) {
+			calls++
+			if calls == 1 {
+				return map[string]struct{}{"W1AW": {}}, nil
+			}
+			return nil, context.DeadlineExceeded
+		},
+	}
+
+	w, err := NewWatcher(context.Background(), WatcherConfig{Loaders: []Loader{callback}})
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Stop()
+
+	if err := w.reload(context.Background(), false); err != nil {
+		t.Fatalf("expected reload to tolerate a failing loader once a snapshot exists, got %v", err)
+	}
+	if !w.Current().Contains("W1AW") {
+		t.Fatalf("expected the last good snapshot to still be served")
+	}
+}
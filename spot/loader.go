@@ -0,0 +1,151 @@
+package spot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Loader produces a set of known callsigns from one source (a local file, an
+// HTTP endpoint, a ClubLog/LoTW/QRZ-style API, ...). Load is called once per
+// refresh by Watcher; a Loader doesn't need to do its own polling or
+// scheduling.
+type Loader interface {
+	// Name identifies the loader for logging and as the Source tag applied
+	// to every callsign it contributes.
+	Name() Source
+	Load(ctx context.Context) (map[string]struct{}, error)
+}
+
+// FileLoader loads callsigns from a local newline-delimited file, the same
+// format LoadKnownCallsigns reads.
+type FileLoader struct {
+	Path string
+}
+
+// Name implements Loader.
+func (l FileLoader) Name() Source { return SourceLocalFile }
+
+// Load implements Loader.
+func (l FileLoader) Load(_ context.Context) (map[string]struct{}, error) {
+	file, err := os.Open(l.Path)
+	if err != nil {
+		return nil, fmt.Errorf("spot: open known callsigns file: %w", err)
+	}
+	defer file.Close()
+
+	entries := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		call := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if call == "" || strings.HasPrefix(call, "#") {
+			continue
+		}
+		entries[call] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("spot: read known callsigns file: %w", err)
+	}
+	return entries, nil
+}
+
+// HTTPLoader loads callsigns from a newline-delimited text endpoint,
+// conditionally re-fetching with If-None-Match/If-Modified-Since so an
+// unchanged remote list doesn't cost a full download on every refresh.
+type HTTPLoader struct {
+	SourceName Source
+	URL        string
+	Client     *http.Client
+
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	cached       map[string]struct{}
+}
+
+// NewHTTPLoader returns an HTTPLoader tagging its entries with source.
+func NewHTTPLoader(source Source, url string) *HTTPLoader {
+	return &HTTPLoader{SourceName: source, URL: url}
+}
+
+// Name implements Loader.
+func (l *HTTPLoader) Name() Source { return l.SourceName }
+
+// Load implements Loader. On a 304 Not Modified response it returns the
+// previously fetched entries rather than re-parsing an empty body.
+func (l *HTTPLoader) Load(ctx context.Context) (map[string]struct{}, error) {
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("spot: build known callsigns request: %w", err)
+	}
+
+	l.mu.Lock()
+	if l.etag != "" {
+		req.Header.Set("If-None-Match", l.etag)
+	}
+	if l.lastModified != "" {
+		req.Header.Set("If-Modified-Since", l.lastModified)
+	}
+	l.mu.Unlock()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("spot: fetch known callsigns: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.cached, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("spot: known callsigns endpoint returned %s", resp.Status)
+	}
+
+	entries := make(map[string]struct{})
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		call := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if call == "" || strings.HasPrefix(call, "#") {
+			continue
+		}
+		entries[call] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("spot: read known callsigns response: %w", err)
+	}
+
+	l.mu.Lock()
+	l.etag = resp.Header.Get("ETag")
+	l.lastModified = resp.Header.Get("Last-Modified")
+	l.cached = entries
+	l.mu.Unlock()
+
+	return entries, nil
+}
+
+// CallbackLoader wraps an arbitrary function as a Loader, for pulling
+// callsigns from a ClubLog/LoTW/QRZ-style API client that the caller
+// provides; this package has no opinion on those APIs' auth or shape.
+type CallbackLoader struct {
+	SourceName Source
+	Fn         func(ctx context.Context) (map[string]struct{}, error)
+}
+
+// Name implements Loader.
+func (l CallbackLoader) Name() Source { return l.SourceName }
+
+// Load implements Loader.
+func (l CallbackLoader) Load(ctx context.Context) (map[string]struct{}, error) {
+	return l.Fn(ctx)
+}
@@ -0,0 +1,230 @@
+package spot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	defaultWatcherCacheTTL     = time.Hour
+	defaultWatcherPollInterval = 15 * time.Minute
+)
+
+// WatcherConfig controls how Watcher merges and refreshes its Loaders.
+type WatcherConfig struct {
+	// Loaders are merged together on every refresh; later loaders win on a
+	// callsign collision, so list the most authoritative source last.
+	Loaders []Loader
+	// CacheDir, if set, stores each non-file loader's most recent result on
+	// disk so a restart can serve known callsigns immediately instead of
+	// waiting on a remote fetch.
+	CacheDir string
+	// CacheTTL is how long a cached loader result is considered fresh
+	// enough to use instead of calling Load again at startup. Defaults to
+	// 1 hour.
+	CacheTTL time.Duration
+	// PollInterval is how often every loader is re-run in the background.
+	// Defaults to 15 minutes. FileLoader paths are additionally reloaded on
+	// fsnotify change.
+	PollInterval time.Duration
+}
+
+func (c WatcherConfig) normalize() WatcherConfig {
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = defaultWatcherCacheTTL
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultWatcherPollInterval
+	}
+	return c
+}
+
+// Watcher hot-reloads a KnownCallsigns set by merging entries from one or
+// more Loaders and publishing the merged result via atomic.Pointer, similar
+// to skew.Store, so readers never block on (or see a half-applied) reload.
+// FileLoader paths are re-read on fsnotify change; every loader is also
+// re-run on a fixed PollInterval as a fallback for sources fsnotify can't
+// watch (HTTP endpoints, callback-based API clients).
+type Watcher struct {
+	cfg WatcherConfig
+
+	current atomic.Pointer[KnownCallsigns]
+
+	fsw  *fsnotify.Watcher
+	quit chan struct{}
+}
+
+// NewWatcher constructs a Watcher and performs an initial load before
+// returning, so Current is never nil on success.
+func NewWatcher(ctx context.Context, cfg WatcherConfig) (*Watcher, error) {
+	cfg = cfg.normalize()
+	if len(cfg.Loaders) == 0 {
+		return nil, errors.New("spot: watcher requires at least one loader")
+	}
+
+	w := &Watcher{cfg: cfg, quit: make(chan struct{})}
+	if err := w.reload(ctx, true); err != nil {
+		return nil, err
+	}
+
+	if fsw, err := fsnotify.NewWatcher(); err != nil {
+		log.Printf("spot: known callsigns fsnotify unavailable, falling back to polling only: %v", err)
+	} else {
+		for _, l := range cfg.Loaders {
+			fl, ok := l.(FileLoader)
+			if !ok {
+				continue
+			}
+			if err := fsw.Add(filepath.Dir(fl.Path)); err != nil {
+				log.Printf("spot: watch %s: %v", fl.Path, err)
+			}
+		}
+		w.fsw = fsw
+	}
+
+	go w.loop(ctx)
+	return w, nil
+}
+
+// Current returns the most recently published KnownCallsigns snapshot.
+func (w *Watcher) Current() *KnownCallsigns {
+	if w == nil {
+		return nil
+	}
+	return w.current.Load()
+}
+
+// Stop ends the watch/poll loop and releases the fsnotify watcher.
+func (w *Watcher) Stop() {
+	if w == nil {
+		return
+	}
+	close(w.quit)
+	if w.fsw != nil {
+		w.fsw.Close()
+	}
+}
+
+func (w *Watcher) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if w.fsw != nil {
+		events = w.fsw.Events
+		errs = w.fsw.Errors
+	}
+
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C:
+			if err := w.reload(ctx, false); err != nil {
+				log.Printf("spot: known callsigns refresh failed: %v", err)
+			}
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 || !w.watchesFile(ev.Name) {
+				continue
+			}
+			if err := w.reload(ctx, false); err != nil {
+				log.Printf("spot: known callsigns reload after %s: %v", ev.Name, err)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			log.Printf("spot: known callsigns fsnotify error: %v", err)
+		}
+	}
+}
+
+// watchesFile reports whether name is one of the configured FileLoader
+// paths, so a change to an unrelated file in the same directory is ignored.
+func (w *Watcher) watchesFile(name string) bool {
+	name = filepath.Clean(name)
+	for _, l := range w.cfg.Loaders {
+		if fl, ok := l.(FileLoader); ok && filepath.Clean(fl.Path) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// reload merges every loader's current entries into a new KnownCallsigns
+// snapshot and publishes it. When preferCache is true (the initial load), a
+// fresh on-disk cache is used in place of calling a non-file loader at all,
+// so a restart doesn't immediately hammer remote services.
+func (w *Watcher) reload(ctx context.Context, preferCache bool) error {
+	merged := make(map[string]Source)
+	loaded := 0
+	for _, l := range w.cfg.Loaders {
+		entries, err := w.loadOne(ctx, l, preferCache)
+		if err != nil {
+			log.Printf("spot: known callsigns loader %s failed: %v", l.Name(), err)
+			continue
+		}
+		loaded++
+		for call := range entries {
+			call = strings.ToUpper(strings.TrimSpace(call))
+			if call == "" {
+				continue
+			}
+			merged[call] = l.Name()
+		}
+	}
+
+	if loaded == 0 {
+		if w.current.Load() == nil {
+			return errors.New("spot: no known callsigns loaded from any source")
+		}
+		// Every loader failed this round; keep serving the last good snapshot.
+		return nil
+	}
+
+	w.current.Store(&KnownCallsigns{entries: merged})
+	return nil
+}
+
+// loadOne runs a single loader, consulting and updating the on-disk cache
+// for non-file loaders.
+func (w *Watcher) loadOne(ctx context.Context, l Loader, preferCache bool) (map[string]struct{}, error) {
+	_, isFile := l.(FileLoader)
+
+	if !isFile && preferCache {
+		if cached, ok := readCache(w.cfg.CacheDir, l.Name(), w.cfg.CacheTTL); ok {
+			return cached, nil
+		}
+	}
+
+	entries, err := l.Load(ctx)
+	if err != nil {
+		if !isFile {
+			if cached, ok := readCache(w.cfg.CacheDir, l.Name(), 0); ok {
+				log.Printf("spot: known callsigns loader %s failed, using stale cache: %v", l.Name(), err)
+				return cached, nil
+			}
+		}
+		return nil, err
+	}
+
+	if !isFile {
+		if err := writeCache(w.cfg.CacheDir, l.Name(), entries); err != nil {
+			log.Printf("spot: writing known callsigns cache for %s: %v", l.Name(), err)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"sync"
+
+	"github.com/beorn7/perks/quantile"
+)
+
+// quantileTargets are the quantiles every sketch tracks, each with its own
+// absolute error bound. Targeting a handful of known quantiles up front
+// (rather than the full distribution) is what keeps each sketch's memory
+// bounded to a few hundred samples regardless of how many values it sees.
+var quantileTargets = map[float64]float64{
+	0.5:  0.05,
+	0.95: 0.01,
+	0.99: 0.001,
+}
+
+// quantileKeys is quantileTargets' keys in a fixed order, so callers always
+// see p50/p95/p99 reported in the same order.
+var quantileKeys = []float64{0.5, 0.95, 0.99}
+
+// quantileSketch is a single compact, concurrency-safe quantile estimator.
+// perks/quantile.Stream isn't safe for concurrent use on its own, so each
+// sketch gets its own small mutex instead of sharing one global lock across
+// every stream being tracked, mirroring the sync.Map+atomic sharding already
+// used for Tracker's plain counters in tracker.go.
+type quantileSketch struct {
+	mu     sync.Mutex
+	stream *quantile.Stream
+}
+
+func newQuantileSketch() *quantileSketch {
+	return &quantileSketch{stream: quantile.NewTargeted(quantileTargets)}
+}
+
+func (s *quantileSketch) observe(v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stream.Insert(v)
+}
+
+// values returns the current p50/p95/p99 estimates.
+func (s *quantileSketch) values() map[float64]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[float64]float64, len(quantileKeys))
+	for _, q := range quantileKeys {
+		out[q] = s.stream.Query(q)
+	}
+	return out
+}
+
+// sketchFor returns the *quantileSketch for key, creating one on first use.
+func sketchFor(m *sync.Map, key any) *quantileSketch {
+	if existing, ok := m.Load(key); ok {
+		return existing.(*quantileSketch)
+	}
+	actual, _ := m.LoadOrStore(key, newQuantileSketch())
+	return actual.(*quantileSketch)
+}
@@ -6,16 +6,41 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// latencyKey identifies one (source, mode) processing-latency sketch.
+type latencyKey struct {
+	source string
+	mode   string
+}
+
 // Tracker tracks spot statistics by source
 type Tracker struct {
 	// counters live in sync.Map + atomic.Uint64 so per-spot increments don't fight over a mutex
 	modeCounts   sync.Map // string -> *atomic.Uint64
 	sourceCounts sync.Map // string -> *atomic.Uint64
+	bandCounts   sync.Map // string -> *atomic.Uint64
 	start        atomic.Int64
+
+	// latencySketches and interarrivalSketches hold one quantileSketch per
+	// key, each independently locked, so recording a sample never contends
+	// across sources/modes. lastArrival tracks the previous arrival time per
+	// source so RecordInterarrival can turn timestamps into intervals.
+	latencySketches      sync.Map // latencyKey -> *quantileSketch
+	interarrivalSketches sync.Map // string (source) -> *quantileSketch
+	lastArrival          sync.Map // string (source) -> *atomic.Int64 (unix nanos)
 }
 
+var (
+	modeCountDesc   = prometheus.NewDesc("dxcluster_spots_by_mode_total", "Total spots observed, by mode.", []string{"mode"}, nil)
+	sourceCountDesc = prometheus.NewDesc("dxcluster_spots_by_source_total", "Total spots observed, by source node.", []string{"source"}, nil)
+	bandCountDesc   = prometheus.NewDesc("dxcluster_spots_by_band_total", "Total spots observed, by band.", []string{"band"}, nil)
+	totalDesc       = prometheus.NewDesc("dxcluster_spots_total", "Total spots observed across all sources.", nil, nil)
+	uptimeDesc      = prometheus.NewDesc("dxcluster_uptime_seconds", "How long the tracker has been running.", nil, nil)
+)
+
 // NewTracker creates a new stats tracker
 func NewTracker() *Tracker {
 	t := &Tracker{}
@@ -33,6 +58,71 @@ func (t *Tracker) IncrementSource(source string) {
 	incrementCounter(&t.sourceCounts, source)
 }
 
+// IncrementBand increases the count for a band (20m, 40m, etc.)
+func (t *Tracker) IncrementBand(band string) {
+	incrementCounter(&t.bandCounts, band)
+}
+
+// RecordLatency records one end-to-end processing latency sample (parse ->
+// dedup -> publish) for source/mode, updating a compact quantile sketch.
+func (t *Tracker) RecordLatency(source, mode string, d time.Duration) {
+	if strings.TrimSpace(source) == "" || strings.TrimSpace(mode) == "" {
+		return
+	}
+	sketchFor(&t.latencySketches, latencyKey{source: source, mode: mode}).observe(float64(d))
+}
+
+// RecordInterarrival records one spot's arrival time for source, updating a
+// compact quantile sketch of inter-spot arrival intervals. The first
+// observation for a source only seeds the reference time; no interval can be
+// formed until a second one arrives.
+func (t *Tracker) RecordInterarrival(source string, at time.Time) {
+	if strings.TrimSpace(source) == "" {
+		return
+	}
+	last := lastArrivalFor(&t.lastArrival, source)
+	now := at.UnixNano()
+	if prev := last.Swap(now); prev != 0 {
+		sketchFor(&t.interarrivalSketches, source).observe(float64(now - prev))
+	}
+}
+
+// GetQuantiles returns the p50/p95/p99 inter-spot arrival interval for
+// source, or nil if no interval has been recorded yet.
+func (t *Tracker) GetQuantiles(source string) map[float64]time.Duration {
+	existing, ok := t.interarrivalSketches.Load(source)
+	if !ok {
+		return nil
+	}
+	return toDurations(existing.(*quantileSketch).values())
+}
+
+// GetLatencyQuantiles returns the p50/p95/p99 end-to-end processing latency
+// for source/mode, or nil if no sample has been recorded yet.
+func (t *Tracker) GetLatencyQuantiles(source, mode string) map[float64]time.Duration {
+	existing, ok := t.latencySketches.Load(latencyKey{source: source, mode: mode})
+	if !ok {
+		return nil
+	}
+	return toDurations(existing.(*quantileSketch).values())
+}
+
+func lastArrivalFor(m *sync.Map, key string) *atomic.Int64 {
+	if existing, ok := m.Load(key); ok {
+		return existing.(*atomic.Int64)
+	}
+	actual, _ := m.LoadOrStore(key, &atomic.Int64{})
+	return actual.(*atomic.Int64)
+}
+
+func toDurations(values map[float64]float64) map[float64]time.Duration {
+	out := make(map[float64]time.Duration, len(values))
+	for q, v := range values {
+		out[q] = time.Duration(v)
+	}
+	return out
+}
+
 // GetCounts returns a copy of all counts
 // GetModeCounts returns a copy of mode counts
 func (t *Tracker) GetModeCounts() map[string]uint64 {
@@ -81,9 +171,54 @@ func (t *Tracker) Reset() {
 		t.sourceCounts.Delete(key)
 		return true
 	})
+	t.bandCounts.Range(func(key, _ any) bool {
+		t.bandCounts.Delete(key)
+		return true
+	})
+	t.latencySketches.Range(func(key, _ any) bool {
+		t.latencySketches.Delete(key)
+		return true
+	})
+	t.interarrivalSketches.Range(func(key, _ any) bool {
+		t.interarrivalSketches.Delete(key)
+		return true
+	})
+	t.lastArrival.Range(func(key, _ any) bool {
+		t.lastArrival.Delete(key)
+		return true
+	})
 	t.start.Store(time.Now().UnixNano())
 }
 
+// Describe implements prometheus.Collector.
+func (t *Tracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- modeCountDesc
+	ch <- sourceCountDesc
+	ch <- bandCountDesc
+	ch <- totalDesc
+	ch <- uptimeDesc
+}
+
+// Collect implements prometheus.Collector, reading directly from
+// modeCounts/sourceCounts/bandCounts so there's no separate counter state
+// to keep in lock-step with IncrementMode/IncrementSource/IncrementBand.
+func (t *Tracker) Collect(ch chan<- prometheus.Metric) {
+	t.modeCounts.Range(func(key, value any) bool {
+		ch <- prometheus.MustNewConstMetric(modeCountDesc, prometheus.CounterValue, float64(value.(*atomic.Uint64).Load()), key.(string))
+		return true
+	})
+	t.sourceCounts.Range(func(key, value any) bool {
+		ch <- prometheus.MustNewConstMetric(sourceCountDesc, prometheus.CounterValue, float64(value.(*atomic.Uint64).Load()), key.(string))
+		return true
+	})
+	t.bandCounts.Range(func(key, value any) bool {
+		ch <- prometheus.MustNewConstMetric(bandCountDesc, prometheus.CounterValue, float64(value.(*atomic.Uint64).Load()), key.(string))
+		return true
+	})
+	ch <- prometheus.MustNewConstMetric(totalDesc, prometheus.CounterValue, float64(t.GetTotal()))
+	ch <- prometheus.MustNewConstMetric(uptimeDesc, prometheus.GaugeValue, t.GetUptime().Seconds())
+}
+
 // Print displays the current statistics
 func (t *Tracker) Print() {
 	// Print source counts (higher-level sources)
@@ -121,6 +256,36 @@ func (t *Tracker) Print() {
 		fmt.Printf("(none)")
 	}
 	fmt.Println()
+
+	// Print processing latency quantiles by source/mode
+	fmt.Printf("Processing latency (p50/p95/p99) by source/mode:\n")
+	first = true
+	t.latencySketches.Range(func(key, value any) bool {
+		k := key.(latencyKey)
+		qs := value.(*quantileSketch).values()
+		fmt.Printf("  %s/%s: %s/%s/%s\n", k.source, k.mode,
+			time.Duration(qs[0.5]), time.Duration(qs[0.95]), time.Duration(qs[0.99]))
+		first = false
+		return true
+	})
+	if first {
+		fmt.Printf("  (none)\n")
+	}
+
+	// Print inter-spot arrival interval quantiles by source
+	fmt.Printf("Inter-spot arrival interval (p50/p95/p99) by source:\n")
+	first = true
+	t.interarrivalSketches.Range(func(key, value any) bool {
+		source := key.(string)
+		qs := value.(*quantileSketch).values()
+		fmt.Printf("  %s: %s/%s/%s\n", source,
+			time.Duration(qs[0.5]), time.Duration(qs[0.95]), time.Duration(qs[0.99]))
+		first = false
+		return true
+	})
+	if first {
+		fmt.Printf("  (none)\n")
+	}
 }
 
 func incrementCounter(m *sync.Map, key string) {
@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordLatencyTracksQuantiles(t *testing.T) {
+	tr := NewTracker()
+
+	for _, ms := range []int{10, 20, 30, 40, 100} {
+		tr.RecordLatency("RBN", "FT8", time.Duration(ms)*time.Millisecond)
+	}
+
+	qs := tr.GetLatencyQuantiles("RBN", "FT8")
+	if qs == nil {
+		t.Fatal("expected latency quantiles after recording samples")
+	}
+	if qs[0.5] < 20*time.Millisecond || qs[0.5] > 40*time.Millisecond {
+		t.Fatalf("expected p50 roughly in the middle of the samples, got %s", qs[0.5])
+	}
+	if qs[0.99] < qs[0.5] {
+		t.Fatalf("expected p99 (%s) >= p50 (%s)", qs[0.99], qs[0.5])
+	}
+}
+
+func TestGetLatencyQuantilesNilBeforeAnySample(t *testing.T) {
+	tr := NewTracker()
+	if qs := tr.GetLatencyQuantiles("RBN", "FT8"); qs != nil {
+		t.Fatalf("expected nil quantiles before any sample is recorded, got %+v", qs)
+	}
+}
+
+func TestRecordInterarrivalNeedsTwoSamples(t *testing.T) {
+	tr := NewTracker()
+	base := time.Unix(1_700_000_000, 0).UTC()
+
+	tr.RecordInterarrival("RBN", base)
+	if qs := tr.GetQuantiles("RBN"); qs != nil {
+		t.Fatalf("expected no interval from a single arrival, got %+v", qs)
+	}
+
+	tr.RecordInterarrival("RBN", base.Add(2*time.Second))
+	qs := tr.GetQuantiles("RBN")
+	if qs == nil {
+		t.Fatal("expected an interval after a second arrival")
+	}
+	if qs[0.5] != 2*time.Second {
+		t.Fatalf("expected p50 interval of 2s, got %s", qs[0.5])
+	}
+}
+
+func TestResetClearsQuantileSketches(t *testing.T) {
+	tr := NewTracker()
+	tr.RecordLatency("RBN", "FT8", 10*time.Millisecond)
+	tr.RecordInterarrival("RBN", time.Unix(1_700_000_000, 0))
+	tr.RecordInterarrival("RBN", time.Unix(1_700_000_002, 0))
+
+	tr.Reset()
+
+	if qs := tr.GetLatencyQuantiles("RBN", "FT8"); qs != nil {
+		t.Fatalf("expected latency sketches cleared after Reset, got %+v", qs)
+	}
+	if qs := tr.GetQuantiles("RBN"); qs != nil {
+		t.Fatalf("expected interarrival sketches cleared after Reset, got %+v", qs)
+	}
+}
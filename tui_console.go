@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"dxcluster/config"
+)
+
+// tuiPaneScrollback caps how many lines each pane keeps beyond the visible
+// window; older lines fall off the front once a pane hits this size.
+const tuiPaneScrollback = 2000
+
+// tuiConsole is a full-screen ui.mode=tui backend built on tview/tcell. It
+// implements the same uiSurface contract as ansiConsole, so it's a drop-in
+// for callers, but replaces the ansiConsole's whole-screen repaint with
+// resizable, independently-scrollable panes, mouse click-to-focus, a "/"
+// filter prompt per pane, and a keybinding to dump the focused pane's
+// visible lines to a file.
+type tuiConsole struct {
+	app    *tview.Application
+	header *tview.TextView
+	status *tview.TextView
+	panes  map[string]*tuiPane
+	order  []string // focus-cycle order, also the Tab order
+
+	focusMu sync.Mutex
+	focused string
+
+	ready    chan struct{}
+	quit     chan struct{}
+	stopOnce sync.Once
+
+	writer *ansiWriter
+}
+
+// tuiPane is one scrollable, optionally-filtered log pane: a TextView for
+// display plus the full unfiltered scrollback it was rendered from.
+type tuiPane struct {
+	name   string
+	view   *tview.TextView
+	mu     sync.Mutex
+	lines  []string
+	filter string
+}
+
+func newTUIConsole(uiCfg config.UIConfig) *tuiConsole {
+	app := tview.NewApplication()
+	app.EnableMouse(true)
+
+	c := &tuiConsole{
+		app:    app,
+		header: tview.NewTextView().SetDynamicColors(true),
+		status: tview.NewTextView().SetDynamicColors(true),
+		panes:  make(map[string]*tuiPane),
+		order:  []string{"calls", "unlicensed", "harmonics", "system"},
+		ready:  make(chan struct{}),
+		quit:   make(chan struct{}),
+	}
+	c.writer = &ansiWriter{append: c.AppendSystem, color: uiCfg.Color}
+
+	for _, name := range c.order {
+		c.panes[name] = newTUIPane(name)
+	}
+	c.focused = "system"
+
+	headerHeight := uiCfg.PaneLines.Stats
+	if headerHeight <= 0 {
+		headerHeight = 1
+	}
+
+	paneRow := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(c.panes["calls"].view, 0, 1, false).
+		AddItem(c.panes["unlicensed"].view, 0, 1, false).
+		AddItem(c.panes["harmonics"].view, 0, 1, false)
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(c.header, headerHeight, 1, false).
+		AddItem(paneRow, 0, 3, false).
+		AddItem(c.panes["system"].view, 0, 2, false).
+		AddItem(c.status, 1, 1, false)
+
+	c.installKeybindings(root)
+	c.installMouseFocus()
+
+	app.SetRoot(root, true).SetFocus(c.panes["system"].view)
+	close(c.ready)
+
+	go func() {
+		if err := app.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "TUI console exited: %v\n", err)
+		}
+	}()
+
+	return c
+}
+
+func newTUIPane(name string) *tuiPane {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).SetTitle(" " + titleCase(name) + " ")
+	return &tuiPane{name: name, view: view}
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (c *tuiConsole) WaitReady() {
+	<-c.ready
+}
+
+func (c *tuiConsole) Stop() {
+	if c == nil {
+		return
+	}
+	c.stopOnce.Do(func() {
+		c.app.Stop()
+		close(c.quit)
+	})
+}
+
+func (c *tuiConsole) SetStats(lines []string) {
+	if c == nil {
+		return
+	}
+	text := strings.Join(lines, "\n")
+	c.app.QueueUpdateDraw(func() {
+		c.header.SetText(applyANSIMarkup(text, true))
+	})
+}
+
+func (c *tuiConsole) AppendCall(line string)       { c.append("calls", line) }
+func (c *tuiConsole) AppendUnlicensed(line string) { c.append("unlicensed", line) }
+func (c *tuiConsole) AppendHarmonic(line string)   { c.append("harmonics", line) }
+func (c *tuiConsole) AppendSystem(line string)     { c.append("system", line) }
+
+func (c *tuiConsole) SystemWriter() io.Writer {
+	if c == nil {
+		return nil
+	}
+	return c.writer
+}
+
+func (c *tuiConsole) append(paneName, line string) {
+	pane, ok := c.panes[paneName]
+	if !ok {
+		return
+	}
+	line = applyANSIMarkup(line, true)
+
+	pane.mu.Lock()
+	pane.lines = append(pane.lines, line)
+	truncated := false
+	if len(pane.lines) > tuiPaneScrollback {
+		pane.lines = pane.lines[len(pane.lines)-tuiPaneScrollback:]
+		truncated = true
+	}
+	filter := pane.filter
+	visible := make([]string, len(pane.lines))
+	copy(visible, pane.lines)
+	pane.mu.Unlock()
+
+	if filter != "" && !strings.Contains(strings.ToLower(line), filter) {
+		return
+	}
+	c.app.QueueUpdateDraw(func() {
+		if truncated {
+			// Scrollback just dropped its oldest line(s); the view's own
+			// buffer would otherwise grow without bound, so redraw it from
+			// the capped slice instead of just appending.
+			var kept []string
+			for _, l := range visible {
+				if filter == "" || strings.Contains(strings.ToLower(l), filter) {
+					kept = append(kept, l)
+				}
+			}
+			pane.view.Clear()
+			fmt.Fprintln(pane.view, strings.Join(kept, "\n"))
+			return
+		}
+		fmt.Fprintln(pane.view, line)
+	})
+}
+
+// setFilter applies a case-insensitive substring filter to pane, re-drawing
+// it from the retained scrollback. An empty filter clears it.
+func (c *tuiConsole) setFilter(paneName, needle string) {
+	pane, ok := c.panes[paneName]
+	if !ok {
+		return
+	}
+	needle = strings.ToLower(strings.TrimSpace(needle))
+
+	pane.mu.Lock()
+	pane.filter = needle
+	lines := make([]string, len(pane.lines))
+	copy(lines, pane.lines)
+	pane.mu.Unlock()
+
+	var kept []string
+	for _, line := range lines {
+		if needle == "" || strings.Contains(strings.ToLower(line), needle) {
+			kept = append(kept, line)
+		}
+	}
+	pane.view.Clear()
+	fmt.Fprintln(pane.view, strings.Join(kept, "\n"))
+}
+
+// dumpVisible writes the pane's currently visible (post-filter) text to a
+// timestamped file, for offline inspection the way inspect_decisions lets
+// an operator pull a slice of the decision log out for later review.
+func (c *tuiConsole) dumpVisible(paneName string) (string, error) {
+	pane, ok := c.panes[paneName]
+	if !ok {
+		return "", fmt.Errorf("unknown pane %q", paneName)
+	}
+	path := fmt.Sprintf("tui_dump_%s_%d.txt", paneName, time.Now().Unix())
+	return path, os.WriteFile(path, []byte(pane.view.GetText(true)), 0o644)
+}
+
+func (c *tuiConsole) installKeybindings(root tview.Primitive) {
+	var filterField *tview.InputField
+
+	c.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		c.focusMu.Lock()
+		focused := c.focused
+		c.focusMu.Unlock()
+
+		switch {
+		case event.Rune() == '/' && filterField == nil:
+			field := tview.NewInputField().SetLabel("filter " + focused + ": ")
+			field.SetDoneFunc(func(key tcell.Key) {
+				if key == tcell.KeyEnter {
+					c.setFilter(focused, field.GetText())
+				}
+				c.app.SetRoot(root, true)
+				c.app.SetFocus(c.panes[focused].view)
+				filterField = nil
+			})
+			filterField = field
+			c.app.SetRoot(field, true)
+			return nil
+		case event.Key() == tcell.KeyCtrlD:
+			if path, err := c.dumpVisible(focused); err == nil {
+				c.AppendSystem(fmt.Sprintf("dumped %s pane to %s", focused, path))
+			} else {
+				c.AppendSystem(fmt.Sprintf("dump failed: %v", err))
+			}
+			return nil
+		case event.Key() == tcell.KeyTab:
+			c.focusNext(1)
+			return nil
+		case event.Key() == tcell.KeyBacktab:
+			c.focusNext(-1)
+			return nil
+		}
+		return event
+	})
+}
+
+func (c *tuiConsole) focusNext(dir int) {
+	c.focusMu.Lock()
+	idx := 0
+	for i, name := range c.order {
+		if name == c.focused {
+			idx = i
+			break
+		}
+	}
+	idx = (idx + dir + len(c.order)) % len(c.order)
+	c.focused = c.order[idx]
+	pane := c.panes[c.focused]
+	c.focusMu.Unlock()
+
+	c.app.SetFocus(pane.view)
+}
+
+// installMouseFocus gives every pane a mouse capture that sets it as
+// focused on click, so "click-to-focus" works the way a desktop terminal
+// multiplexer would, on top of tview's native mouse scrolling.
+func (c *tuiConsole) installMouseFocus() {
+	for name, pane := range c.panes {
+		name, pane := name, pane
+		pane.view.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+			if action == tview.MouseLeftClick {
+				c.focusMu.Lock()
+				c.focused = name
+				c.focusMu.Unlock()
+				c.app.SetFocus(pane.view)
+			}
+			return action, event
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Package wwv validates, dedupes, stores, and relays solar/geomagnetic
+// propagation bulletins (WWV and WCY frames) received from cluster peers.
+// It replaces the bare field-unpacking peer.parseWWV used to do, adding the
+// validation, storage, and fan-out a real propagation display needs.
+package wwv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"dxcluster/peer"
+)
+
+// Kind distinguishes the two bulletin frame types this package parses.
+type Kind string
+
+const (
+	KindWWV Kind = "WWV" // PC23: solar flux / A / K plus a short forecast
+	KindWCY Kind = "WCY" // PC73: WWV fields plus expanded geomagnetic fields
+)
+
+// Event is a validated, normalized propagation bulletin.
+type Event struct {
+	Kind      Kind
+	Origin    string
+	Timestamp time.Time // combined date+hour, UTC
+	SFI       int
+	A         int
+	K         int
+
+	// AuroraForecast and BandForecast come from splitting a WWV frame's
+	// free-text forecast field; see splitForecast.
+	AuroraForecast string
+	BandForecast   string
+
+	// WCY-only fields.
+	ExpectedK     int    // expK
+	SolarActivity string // SA
+	GeomagField   string // GMF
+	Aurora        string // Au
+
+	Hop int
+	Raw string
+}
+
+// DedupeKey identifies an event for replay suppression. It's scoped to
+// (kind, origin, date, hour) per the cluster convention that a given
+// origin emits at most one bulletin of each kind per hour; Kind is
+// included so a WWV and WCY bulletin sharing an origin/hour don't collide.
+func (e Event) DedupeKey() string {
+	return string(e.Kind) + "|" + e.Origin + "|" + e.Timestamp.Format("2006-01-02T15")
+}
+
+const (
+	minSFI, maxSFI = 0, 300
+	minA, maxA     = 0, 400
+	minK, maxK     = 0, 9
+)
+
+// ParseWWV validates and normalizes a PC23 frame into an Event.
+func ParseWWV(frame *peer.Frame) (Event, error) {
+	return parseBulletin(KindWWV, frame)
+}
+
+// ParseWCY validates and normalizes a PC73 frame into an Event.
+func ParseWCY(frame *peer.Frame) (Event, error) {
+	return parseBulletin(KindWCY, frame)
+}
+
+func parseBulletin(kind Kind, frame *peer.Frame) (Event, error) {
+	if frame == nil {
+		return Event{}, fmt.Errorf("wwv: nil frame")
+	}
+	fields := frame.PayloadFields()
+	if len(fields) < 6 {
+		return Event{}, fmt.Errorf("wwv: %s frame has %d fields, need at least 6", kind, len(fields))
+	}
+
+	ts, err := parseDateHour(strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]))
+	if err != nil {
+		return Event{}, fmt.Errorf("wwv: %w", err)
+	}
+	sfi, err := parseIntRange(fields[2], minSFI, maxSFI, "SFI")
+	if err != nil {
+		return Event{}, fmt.Errorf("wwv: %w", err)
+	}
+	a, err := parseIntRange(fields[3], minA, maxA, "A")
+	if err != nil {
+		return Event{}, fmt.Errorf("wwv: %w", err)
+	}
+	k, err := parseIntRange(fields[4], minK, maxK, "K")
+	if err != nil {
+		return Event{}, fmt.Errorf("wwv: %w", err)
+	}
+
+	ev := Event{
+		Kind:      kind,
+		Timestamp: ts,
+		SFI:       sfi,
+		A:         a,
+		K:         k,
+		Hop:       frame.Hop,
+		Raw:       frame.Raw,
+	}
+
+	rest := fields[5:]
+	switch kind {
+	case KindWCY:
+		if len(rest) < 4 {
+			return Event{}, fmt.Errorf("wwv: WCY frame has %d extra fields, need at least 4", len(rest))
+		}
+		expK, err := parseIntRange(rest[0], minK, maxK, "expK")
+		if err != nil {
+			return Event{}, fmt.Errorf("wwv: %w", err)
+		}
+		ev.ExpectedK = expK
+		ev.SolarActivity = strings.TrimSpace(rest[1])
+		ev.GeomagField = strings.TrimSpace(rest[2])
+		ev.Aurora = strings.TrimSpace(rest[3])
+		if len(rest) > 4 {
+			ev.Origin = strings.TrimSpace(rest[len(rest)-1])
+		}
+	default:
+		ev.AuroraForecast, ev.BandForecast = splitForecast(rest)
+		if len(rest) > 1 {
+			ev.Origin = strings.TrimSpace(rest[len(rest)-1])
+		}
+	}
+
+	return ev, nil
+}
+
+// splitForecast separates a WWV forecast field into its aurora and
+// band-conditions halves. Forecast text conventionally joins the two with
+// "; " (e.g. "No Aurora Expected; Fair to Good conditions"). When more than
+// one field remains, the last one is the origin and is excluded, so the
+// trailing node callsign never leaks into the forecast; a single remaining
+// field is taken as the forecast itself.
+func splitForecast(rest []string) (aurora, band string) {
+	if len(rest) == 0 {
+		return "", ""
+	}
+	forecast := rest[0]
+	if len(rest) > 1 {
+		forecast = strings.Join(rest[:len(rest)-1], " ")
+	}
+	if idx := strings.Index(forecast, "; "); idx >= 0 {
+		return strings.TrimSpace(forecast[:idx]), strings.TrimSpace(forecast[idx+2:])
+	}
+	return "", strings.TrimSpace(forecast)
+}
+
+// parseDateHour parses a cluster-style "02-Jan-2006 1504Z" date/hour pair.
+// Unlike peer's spot-ingestion path, which silently falls back to "now" on
+// a bad timestamp, a bulletin with an unparseable timestamp is rejected
+// outright - defaulting it to "now" would poison both the ring history and
+// the dedupe key with a fabricated time.
+func parseDateHour(dateStr, hourStr string) (time.Time, error) {
+	if dateStr == "" || hourStr == "" {
+		return time.Time{}, fmt.Errorf("empty date/hour")
+	}
+	combined := dateStr + " " + hourStr
+	ts, err := time.ParseInLocation("02-Jan-2006 1504Z", combined, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse date/hour %q: %w", combined, err)
+	}
+	return ts, nil
+}
+
+func parseIntRange(field string, min, max int, name string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimSpace(field))
+	if err != nil {
+		return 0, fmt.Errorf("%s: not an integer: %q", name, field)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("%s: %d out of range [%d,%d]", name, v, min, max)
+	}
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package wwv
+
+import (
+	"testing"
+
+	"dxcluster/peer"
+)
+
+func mustFrame(t *testing.T, line string) *peer.Frame {
+	t.Helper()
+	f, err := peer.ParseFrame(line)
+	if err != nil {
+		t.Fatalf("ParseFrame(%q): %v", line, err)
+	}
+	return f
+}
+
+func TestParseWWVValid(t *testing.T) {
+	f := mustFrame(t, "WWV^25-Nov-2024^2200Z^120^5^1^No Aurora Expected; Fair to good conditions^VE7CC-2^H1")
+	ev, err := ParseWWV(f)
+	if err != nil {
+		t.Fatalf("ParseWWV: %v", err)
+	}
+	if ev.SFI != 120 || ev.A != 5 || ev.K != 1 {
+		t.Fatalf("expected SFI=120 A=5 K=1, got %+v", ev)
+	}
+	if ev.Origin != "VE7CC-2" {
+		t.Fatalf("expected origin VE7CC-2, got %q", ev.Origin)
+	}
+	if ev.AuroraForecast != "No Aurora Expected" || ev.BandForecast != "Fair to good conditions" {
+		t.Fatalf("expected forecast to split on '; ', got aurora=%q band=%q", ev.AuroraForecast, ev.BandForecast)
+	}
+	if ev.Hop != 1 {
+		t.Fatalf("expected hop 1, got %d", ev.Hop)
+	}
+}
+
+func TestParseWWVRejectsOutOfRangeK(t *testing.T) {
+	f := mustFrame(t, "WWV^25-Nov-2024^2200Z^120^5^99^No Storms^VE7CC-2^H1")
+	if _, err := ParseWWV(f); err == nil {
+		t.Fatal("expected out-of-range K to be rejected")
+	}
+}
+
+func TestParseWWVRejectsBadTimestamp(t *testing.T) {
+	f := mustFrame(t, "WWV^not-a-date^nope^120^5^1^No Storms^VE7CC-2^H1")
+	if _, err := ParseWWV(f); err == nil {
+		t.Fatal("expected an unparseable date/hour to be rejected, not defaulted to now")
+	}
+}
+
+func TestParseWCYValid(t *testing.T) {
+	f := mustFrame(t, "WCY^25-Nov-2024^2200Z^120^5^1^3^QUIET^47.2^0^VE7CC-2^H1")
+	ev, err := ParseWCY(f)
+	if err != nil {
+		t.Fatalf("ParseWCY: %v", err)
+	}
+	if ev.ExpectedK != 3 || ev.SolarActivity != "QUIET" || ev.GeomagField != "47.2" || ev.Aurora != "0" {
+		t.Fatalf("unexpected WCY fields: %+v", ev)
+	}
+	if ev.Origin != "VE7CC-2" {
+		t.Fatalf("expected origin VE7CC-2, got %q", ev.Origin)
+	}
+}
+
+func TestDedupeKeyDistinctAcrossKinds(t *testing.T) {
+	wwv, err := ParseWWV(mustFrame(t, "WWV^25-Nov-2024^2200Z^120^5^1^No Storms^VE7CC-2^H1"))
+	if err != nil {
+		t.Fatalf("ParseWWV: %v", err)
+	}
+	wcy, err := ParseWCY(mustFrame(t, "WCY^25-Nov-2024^2200Z^120^5^1^3^QUIET^47.2^0^VE7CC-2^H1"))
+	if err != nil {
+		t.Fatalf("ParseWCY: %v", err)
+	}
+	if wwv.DedupeKey() == wcy.DedupeKey() {
+		t.Fatal("expected WWV and WCY events from the same origin/hour to have distinct dedupe keys")
+	}
+}
@@ -0,0 +1,218 @@
+package wwv
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// persister keeps propagation conditions in SQLite so they survive a
+// restart: wwv_latest holds one row per origin for fast recovery of
+// Store.latest, and wwv_events holds the full ingested history (pruned by
+// retention) so a range query can serve time-series export without relying
+// on the in-memory ring, which is capped at Config.RingSize.
+type persister struct {
+	db *sql.DB
+}
+
+// eventExtras carries the fields of Event that aren't broken out into their
+// own wwv_events columns. It's marshaled to JSON so the schema doesn't need
+// a new column every time a frame variant adds a field.
+type eventExtras struct {
+	AuroraForecast string `json:"aurora_forecast,omitempty"`
+	BandForecast   string `json:"band_forecast,omitempty"`
+	ExpectedK      int    `json:"expected_k,omitempty"`
+	SolarActivity  string `json:"solar_activity,omitempty"`
+	GeomagField    string `json:"geomag_field,omitempty"`
+	Aurora         string `json:"aurora,omitempty"`
+	Hop            int    `json:"hop,omitempty"`
+	Raw            string `json:"raw,omitempty"`
+}
+
+func newPersister(dbPath string) (*persister, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return nil, fmt.Errorf("wwv: mkdir: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("wwv: open db: %w", err)
+	}
+	if _, err := db.Exec(`pragma journal_mode=WAL; pragma synchronous=NORMAL;`); err != nil {
+		return nil, fmt.Errorf("wwv: pragmas: %w", err)
+	}
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+	return &persister{db: db}, nil
+}
+
+func ensureSchema(db *sql.DB) error {
+	_, err := db.Exec(`
+	create table if not exists wwv_latest (
+		origin text primary key,
+		kind text,
+		ts integer,
+		sfi integer,
+		a integer,
+		k integer,
+		aurora_forecast text,
+		band_forecast text,
+		expected_k integer,
+		solar_activity text,
+		geomag_field text,
+		aurora text,
+		hop integer,
+		raw text
+	);
+	create table if not exists wwv_events (
+		id integer primary key autoincrement,
+		kind text,
+		origin text,
+		ts integer,
+		sfi integer,
+		a integer,
+		k integer,
+		extras text,
+		received_at integer
+	);
+	create index if not exists wwv_events_kind_ts on wwv_events(kind, ts);
+	`)
+	if err != nil {
+		return fmt.Errorf("wwv: ensure schema: %w", err)
+	}
+	return nil
+}
+
+func (p *persister) saveLatest(ev Event) error {
+	_, err := p.db.Exec(`
+		insert into wwv_latest (origin, kind, ts, sfi, a, k, aurora_forecast, band_forecast, expected_k, solar_activity, geomag_field, aurora, hop, raw)
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		on conflict(origin) do update set
+			kind=excluded.kind, ts=excluded.ts, sfi=excluded.sfi, a=excluded.a, k=excluded.k,
+			aurora_forecast=excluded.aurora_forecast, band_forecast=excluded.band_forecast,
+			expected_k=excluded.expected_k, solar_activity=excluded.solar_activity,
+			geomag_field=excluded.geomag_field, aurora=excluded.aurora, hop=excluded.hop, raw=excluded.raw
+	`,
+		ev.Origin, string(ev.Kind), ev.Timestamp.Unix(), ev.SFI, ev.A, ev.K,
+		ev.AuroraForecast, ev.BandForecast, ev.ExpectedK, ev.SolarActivity,
+		ev.GeomagField, ev.Aurora, ev.Hop, ev.Raw,
+	)
+	if err != nil {
+		return fmt.Errorf("wwv: save latest: %w", err)
+	}
+	return nil
+}
+
+func (p *persister) loadLatest() (map[string]Event, error) {
+	rows, err := p.db.Query(`
+		select origin, kind, ts, sfi, a, k, aurora_forecast, band_forecast, expected_k, solar_activity, geomag_field, aurora, hop, raw
+		from wwv_latest
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("wwv: load latest: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]Event)
+	for rows.Next() {
+		var ev Event
+		var kind string
+		var ts int64
+		if err := rows.Scan(&ev.Origin, &kind, &ts, &ev.SFI, &ev.A, &ev.K,
+			&ev.AuroraForecast, &ev.BandForecast, &ev.ExpectedK, &ev.SolarActivity,
+			&ev.GeomagField, &ev.Aurora, &ev.Hop, &ev.Raw); err != nil {
+			return nil, fmt.Errorf("wwv: scan latest: %w", err)
+		}
+		ev.Kind = Kind(kind)
+		ev.Timestamp = time.Unix(ts, 0).UTC()
+		out[ev.Origin] = ev
+	}
+	return out, rows.Err()
+}
+
+// saveEvent appends ev to the wwv_events history table, stamped with the
+// time it was ingested so prune can later enforce retention against wall
+// clock rather than the (possibly stale) bulletin timestamp.
+func (p *persister) saveEvent(ev Event, receivedAt time.Time) error {
+	extras, err := json.Marshal(eventExtras{
+		AuroraForecast: ev.AuroraForecast,
+		BandForecast:   ev.BandForecast,
+		ExpectedK:      ev.ExpectedK,
+		SolarActivity:  ev.SolarActivity,
+		GeomagField:    ev.GeomagField,
+		Aurora:         ev.Aurora,
+		Hop:            ev.Hop,
+		Raw:            ev.Raw,
+	})
+	if err != nil {
+		return fmt.Errorf("wwv: marshal extras: %w", err)
+	}
+	_, err = p.db.Exec(`
+		insert into wwv_events (kind, origin, ts, sfi, a, k, extras, received_at)
+		values (?, ?, ?, ?, ?, ?, ?, ?)
+	`, string(ev.Kind), ev.Origin, ev.Timestamp.Unix(), ev.SFI, ev.A, ev.K, string(extras), receivedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("wwv: save event: %w", err)
+	}
+	return nil
+}
+
+// prune deletes wwv_events rows ingested before now-retention, mirroring
+// peer.TopologyStore.Prune's cutoff-by-wall-clock approach.
+func (p *persister) prune(now time.Time, retention time.Duration) error {
+	cutoff := now.Add(-retention).Unix()
+	if _, err := p.db.Exec(`delete from wwv_events where received_at < ?`, cutoff); err != nil {
+		return fmt.Errorf("wwv: prune events: %w", err)
+	}
+	return nil
+}
+
+// rangeQuery returns every stored event of kind with a bulletin timestamp
+// in [start, end), oldest first, for time-series export.
+func (p *persister) rangeQuery(kind Kind, start, end time.Time) ([]Event, error) {
+	rows, err := p.db.Query(`
+		select origin, ts, sfi, a, k, extras
+		from wwv_events
+		where kind = ? and ts >= ? and ts < ?
+		order by ts asc
+	`, string(kind), start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("wwv: range query: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		var ev Event
+		var ts int64
+		var extrasJSON string
+		if err := rows.Scan(&ev.Origin, &ts, &ev.SFI, &ev.A, &ev.K, &extrasJSON); err != nil {
+			return nil, fmt.Errorf("wwv: scan range: %w", err)
+		}
+		ev.Kind = kind
+		ev.Timestamp = time.Unix(ts, 0).UTC()
+		var extras eventExtras
+		if err := json.Unmarshal([]byte(extrasJSON), &extras); err != nil {
+			return nil, fmt.Errorf("wwv: unmarshal extras: %w", err)
+		}
+		ev.AuroraForecast = extras.AuroraForecast
+		ev.BandForecast = extras.BandForecast
+		ev.ExpectedK = extras.ExpectedK
+		ev.SolarActivity = extras.SolarActivity
+		ev.GeomagField = extras.GeomagField
+		ev.Aurora = extras.Aurora
+		ev.Hop = extras.Hop
+		ev.Raw = extras.Raw
+		out = append(out, ev)
+	}
+	return out, rows.Err()
+}
+
+func (p *persister) close() error {
+	return p.db.Close()
+}
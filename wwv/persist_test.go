@@ -0,0 +1,89 @@
+package wwv
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStorePersistsLatestAcrossRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "wwv.db")
+
+	s1, err := NewStore(Config{}, dbPath)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if _, _, _, err := s1.IngestFrame(KindWWV, wwvFrame(t, "2200", 1)); err != nil {
+		t.Fatalf("IngestFrame: %v", err)
+	}
+	if err := s1.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s2, err := NewStore(Config{}, dbPath)
+	if err != nil {
+		t.Fatalf("NewStore (restart): %v", err)
+	}
+	defer s2.Close()
+
+	latest, ok := s2.Latest("VE7CC-2")
+	if !ok {
+		t.Fatal("expected the restarted store to recover the latest event for VE7CC-2")
+	}
+	if latest.SFI != 120 {
+		t.Fatalf("expected recovered SFI=120, got %d", latest.SFI)
+	}
+}
+
+func TestStoreRangeReturnsHistoryAcrossBulletins(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "wwv.db")
+
+	s, err := NewStore(Config{}, dbPath)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	for _, hour := range []string{"2000", "2100", "2200"} {
+		if _, _, _, err := s.IngestFrame(KindWWV, wwvFrame(t, hour, 1)); err != nil {
+			t.Fatalf("IngestFrame: %v", err)
+		}
+	}
+
+	events, err := s.Range(KindWWV, time.Time{}, time.Now().Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Range: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 history rows across the ingested bulletins, got %d", len(events))
+	}
+	if events[0].Timestamp.After(events[1].Timestamp) || events[1].Timestamp.After(events[2].Timestamp) {
+		t.Fatalf("expected events ordered oldest first, got %+v", events)
+	}
+}
+
+func TestPersisterPruneRemovesOldEvents(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "wwv.db")
+	p, err := newPersister(dbPath)
+	if err != nil {
+		t.Fatalf("newPersister: %v", err)
+	}
+	defer p.close()
+
+	old := time.Now().Add(-48 * time.Hour)
+	ev := Event{Kind: KindWWV, Origin: "VE7CC-2", Timestamp: old, SFI: 100, A: 5, K: 1}
+	if err := p.saveEvent(ev, old); err != nil {
+		t.Fatalf("saveEvent: %v", err)
+	}
+	if err := p.prune(time.Now(), 24*time.Hour); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	remaining, err := p.rangeQuery(KindWWV, time.Time{}, time.Now().Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("rangeQuery: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Fatalf("expected prune to remove the event ingested beyond retention, got %d rows", len(remaining))
+	}
+}
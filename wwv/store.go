@@ -0,0 +1,270 @@
+package wwv
+
+import (
+	"sync"
+	"time"
+
+	"dxcluster/events"
+	"dxcluster/peer"
+)
+
+const (
+	defaultRingSize     = 50
+	defaultMaxHops      = 10
+	defaultDedupeWindow = time.Hour
+	defaultRetention    = 30 * 24 * time.Hour
+	subscriberQueueSize = 16
+)
+
+// Config tunes a Store's retention, relay, and dedupe behavior.
+type Config struct {
+	RingSize     int           // events kept per origin; 0 selects defaultRingSize
+	MaxHops      int           // frames with Hop at or beyond this are never relayed (the relayed hop would exceed it); 0 selects defaultMaxHops
+	DedupeWindow time.Duration // how long a (kind, origin, hour) key suppresses replays; 0 selects defaultDedupeWindow
+	Retention    time.Duration // how long wwv_events history rows are kept; 0 selects defaultRetention
+}
+
+func (c Config) normalize() Config {
+	if c.RingSize <= 0 {
+		c.RingSize = defaultRingSize
+	}
+	if c.MaxHops <= 0 {
+		c.MaxHops = defaultMaxHops
+	}
+	if c.DedupeWindow <= 0 {
+		c.DedupeWindow = defaultDedupeWindow
+	}
+	if c.Retention <= 0 {
+		c.Retention = defaultRetention
+	}
+	return c
+}
+
+// Store holds recent propagation events per origin, suppresses replays
+// re-entering through peer loops, and fans validated events out to
+// subscribers (e.g. the broadcast and web layers).
+type Store struct {
+	cfg    Config
+	logger events.Logger
+
+	mu     sync.Mutex
+	ring   map[string][]Event
+	latest map[string]Event
+	seen   map[string]time.Time
+
+	subMu sync.Mutex
+	subs  map[chan Event]struct{}
+
+	persist *persister
+}
+
+// NewStore builds a Store. If dbPath is non-empty, the latest event per
+// origin is persisted there and reloaded on startup so conditions survive
+// a restart; pass "" to run in-memory only (e.g. in tests).
+func NewStore(cfg Config, dbPath string) (*Store, error) {
+	cfg = cfg.normalize()
+	s := &Store{
+		cfg:    cfg,
+		ring:   make(map[string][]Event),
+		latest: make(map[string]Event),
+		seen:   make(map[string]time.Time),
+		subs:   make(map[chan Event]struct{}),
+	}
+	if dbPath != "" {
+		p, err := newPersister(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		s.persist = p
+		recovered, err := p.loadLatest()
+		if err != nil {
+			return nil, err
+		}
+		for origin, ev := range recovered {
+			s.latest[origin] = ev
+			s.ring[origin] = []Event{ev}
+		}
+	}
+	return s, nil
+}
+
+// SetLogger wires a structured event sink for ingest/relay decisions.
+func (s *Store) SetLogger(l events.Logger) {
+	if s == nil {
+		return
+	}
+	s.logger = l
+}
+
+// Close releases the persistence handle, if any.
+func (s *Store) Close() error {
+	if s == nil || s.persist == nil {
+		return nil
+	}
+	return s.persist.close()
+}
+
+// IngestFrame parses frame as kind, then ingests the result. It's the
+// single entry point peer connection handling should call for PC23/PC73
+// frames.
+func (s *Store) IngestFrame(kind Kind, frame *peer.Frame) (ev Event, relay string, shouldRelay bool, err error) {
+	switch kind {
+	case KindWCY:
+		ev, err = ParseWCY(frame)
+	default:
+		ev, err = ParseWWV(frame)
+	}
+	if err != nil {
+		events.Warn(s.logger, "wwv.parse_error", events.String("kind", string(kind)), events.Err(err))
+		return Event{}, "", false, err
+	}
+
+	if !s.ingest(ev) {
+		events.Debug(s.logger, "wwv.duplicate_dropped", events.String("origin", ev.Origin), events.String("key", ev.DedupeKey()))
+		return ev, "", false, nil
+	}
+
+	relay, shouldRelay = s.relay(frame)
+	return ev, relay, shouldRelay, nil
+}
+
+// ingest records ev if it isn't a replay, returning false when it was
+// already seen within the dedupe window.
+func (s *Store) ingest(ev Event) bool {
+	s.mu.Lock()
+	now := time.Now().UTC()
+	key := ev.DedupeKey()
+	if expiry, ok := s.seen[key]; ok && now.Before(expiry) {
+		s.mu.Unlock()
+		return false
+	}
+	s.seen[key] = now.Add(s.cfg.DedupeWindow)
+
+	ring := append(s.ring[ev.Origin], ev)
+	if len(ring) > s.cfg.RingSize {
+		ring = ring[len(ring)-s.cfg.RingSize:]
+	}
+	s.ring[ev.Origin] = ring
+	s.latest[ev.Origin] = ev
+	s.mu.Unlock()
+
+	if s.persist != nil {
+		if err := s.persist.saveLatest(ev); err != nil {
+			events.Warn(s.logger, "wwv.persist_error", events.Err(err))
+		}
+		if err := s.persist.saveEvent(ev, now); err != nil {
+			events.Warn(s.logger, "wwv.persist_error", events.Err(err))
+		}
+		if err := s.persist.prune(now, s.cfg.Retention); err != nil {
+			events.Warn(s.logger, "wwv.prune_error", events.Err(err))
+		}
+	}
+	s.broadcast(ev)
+	return true
+}
+
+// relay increments frame's hop count and reports whether the result should
+// still be forwarded; frames that would exceed MaxHops are dropped here.
+func (s *Store) relay(frame *peer.Frame) (encoded string, ok bool) {
+	if frame == nil {
+		return "", false
+	}
+	nextHop := frame.Hop + 1
+	if nextHop > s.cfg.MaxHops {
+		return "", false
+	}
+	return frame.Encode(nextHop), true
+}
+
+// Latest returns the most recently ingested event for origin.
+func (s *Store) Latest(origin string) (Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ev, ok := s.latest[origin]
+	return ev, ok
+}
+
+// LatestKind returns the most recently ingested event of kind across every
+// origin, for surfacing a single "current conditions" line (e.g. an SFI/A/K
+// summary) rather than per-origin detail.
+func (s *Store) LatestKind(kind Kind) (Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var best Event
+	var found bool
+	for _, ev := range s.latest {
+		if ev.Kind != kind {
+			continue
+		}
+		if !found || ev.Timestamp.After(best.Timestamp) {
+			best = ev
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Range returns every persisted event of kind with a bulletin timestamp in
+// [start, end), oldest first, for time-series export. It requires the
+// Store to have been opened with a dbPath; a nil Store or one opened
+// in-memory-only returns (nil, nil) since there's no history to query.
+func (s *Store) Range(kind Kind, start, end time.Time) ([]Event, error) {
+	if s == nil || s.persist == nil {
+		return nil, nil
+	}
+	return s.persist.rangeQuery(kind, start, end)
+}
+
+// LatestAll returns the most recent event for every known origin.
+func (s *Store) LatestAll() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Event, 0, len(s.latest))
+	for _, ev := range s.latest {
+		out = append(out, ev)
+	}
+	return out
+}
+
+// Recent returns a copy of the retained event ring for origin, oldest
+// first.
+func (s *Store) Recent(origin string) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ring := s.ring[origin]
+	out := make([]Event, len(ring))
+	copy(out, ring)
+	return out
+}
+
+// Subscribe returns a channel receiving every newly ingested event plus an
+// unsubscribe function. The channel is buffered and dropped from
+// (never blocking ingest) if the subscriber falls behind.
+func (s *Store) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberQueueSize)
+	s.subMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	unsubscribe := func() {
+		s.subMu.Lock()
+		if _, ok := s.subs[ch]; ok {
+			delete(s.subs, ch)
+			close(ch)
+		}
+		s.subMu.Unlock()
+	}
+	return ch, unsubscribe
+}
+
+func (s *Store) broadcast(ev Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+			events.Debug(s.logger, "wwv.subscriber_drop", events.String("origin", ev.Origin))
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package wwv
+
+import (
+	"testing"
+
+	"dxcluster/peer"
+)
+
+func wwvFrame(t *testing.T, hour string, hop int) *peer.Frame {
+	t.Helper()
+	line := "WWV^25-Nov-2024^" + hour + "Z^120^5^1^No Storms^VE7CC-2"
+	if hop > 0 {
+		line += "^H" + itoa(hop)
+	}
+	return mustFrame(t, line)
+}
+
+func itoa(v int) string {
+	digits := "0123456789"
+	if v == 0 {
+		return "0"
+	}
+	var out []byte
+	for v > 0 {
+		out = append([]byte{digits[v%10]}, out...)
+		v /= 10
+	}
+	return string(out)
+}
+
+func TestStoreIngestFrameDropsReplays(t *testing.T) {
+	s, err := NewStore(Config{}, "")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	frame := wwvFrame(t, "2200", 1)
+	_, _, relayed, err := s.IngestFrame(KindWWV, frame)
+	if err != nil {
+		t.Fatalf("IngestFrame: %v", err)
+	}
+	if !relayed {
+		t.Fatal("expected the first sighting to be relayed")
+	}
+
+	_, _, relayed, err = s.IngestFrame(KindWWV, frame)
+	if err != nil {
+		t.Fatalf("IngestFrame (replay): %v", err)
+	}
+	if relayed {
+		t.Fatal("expected a replay of the same (origin, date, hour) to be dropped")
+	}
+}
+
+func TestStoreRelayDropsBeyondMaxHops(t *testing.T) {
+	s, err := NewStore(Config{MaxHops: 3}, "")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	frame := wwvFrame(t, "2200", 3)
+	_, _, relayed, err := s.IngestFrame(KindWWV, frame)
+	if err != nil {
+		t.Fatalf("IngestFrame: %v", err)
+	}
+	if relayed {
+		t.Fatal("expected a frame whose relayed hop (4) exceeds MaxHops (3) to be dropped")
+	}
+}
+
+func TestStoreLatestAndRecent(t *testing.T) {
+	s, err := NewStore(Config{RingSize: 2}, "")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	for _, hour := range []string{"2000", "2100", "2200"} {
+		if _, _, _, err := s.IngestFrame(KindWWV, wwvFrame(t, hour, 1)); err != nil {
+			t.Fatalf("IngestFrame: %v", err)
+		}
+	}
+
+	latest, ok := s.Latest("VE7CC-2")
+	if !ok {
+		t.Fatal("expected a latest event for VE7CC-2")
+	}
+	if latest.Timestamp.Hour() != 22 {
+		t.Fatalf("expected the latest event to be the 2200 bulletin, got hour=%d", latest.Timestamp.Hour())
+	}
+
+	recent := s.Recent("VE7CC-2")
+	if len(recent) != 2 {
+		t.Fatalf("expected RingSize=2 to cap retained history at 2, got %d", len(recent))
+	}
+}
+
+func TestStoreLatestKindAcrossOrigins(t *testing.T) {
+	s, err := NewStore(Config{}, "")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	if _, _, _, err := s.IngestFrame(KindWWV, wwvFrame(t, "2000", 1)); err != nil {
+		t.Fatalf("IngestFrame: %v", err)
+	}
+	other := mustFrame(t, "WWV^25-Nov-2024^2100Z^150^3^2^No Storms^W1AW-2^H1")
+	if _, _, _, err := s.IngestFrame(KindWWV, other); err != nil {
+		t.Fatalf("IngestFrame: %v", err)
+	}
+
+	latest, ok := s.LatestKind(KindWWV)
+	if !ok {
+		t.Fatal("expected a latest WWV event across origins")
+	}
+	if latest.Origin != "W1AW-2" || latest.SFI != 150 {
+		t.Fatalf("expected the most recent bulletin (W1AW-2, SFI=150), got %+v", latest)
+	}
+
+	if _, ok := s.LatestKind(KindWCY); ok {
+		t.Fatal("expected no WCY events to have been ingested")
+	}
+}
+
+func TestStoreSubscribeReceivesIngestedEvents(t *testing.T) {
+	s, err := NewStore(Config{}, "")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	ch, unsubscribe := s.Subscribe()
+	defer unsubscribe()
+
+	if _, _, _, err := s.IngestFrame(KindWWV, wwvFrame(t, "2200", 1)); err != nil {
+		t.Fatalf("IngestFrame: %v", err)
+	}
+
+	select {
+	case ev := <-ch:
+		if ev.Origin != "VE7CC-2" {
+			t.Fatalf("expected the subscriber to see the VE7CC-2 event, got %+v", ev)
+		}
+	default:
+		t.Fatal("expected the subscriber channel to have a buffered event")
+	}
+}